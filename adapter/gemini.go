@@ -0,0 +1,178 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// DefaultGeminiEmbeddingDimension is the dimension of text-embedding-004 vectors
+const DefaultGeminiEmbeddingDimension = 768
+
+// ErrGeminiBlocked is returned when Gemini withholds a response because of its safety settings
+var ErrGeminiBlocked = errors.New("gemini response blocked")
+
+// geminiBlockedReasons are the finish reasons Gemini reports for withheld responses
+var geminiBlockedReasons = map[string]bool{
+	"FinishReasonSafety":     true,
+	"FinishReasonRecitation": true,
+}
+
+// GeminiAdapter adapts a Gemini model, e.g. one created with langchaingo's googleai.New,
+// to rag.LLMInterface. Safety settings are configured on the client with
+// googleai.WithHarmThreshold; responses blocked by them fail with ErrGeminiBlocked.
+type GeminiAdapter struct {
+	llm   llms.Model
+	model string
+
+	// Temperature, TopK and TopP are sent with every request when non-zero
+	Temperature float64
+	TopK        int
+	TopP        float64
+
+	// StreamCallback, if set, receives the response chunks as they are generated
+	StreamCallback func(chunk string)
+}
+
+// NewGeminiAdapter creates a new adapter for a Gemini model such as "gemini-1.5-flash".
+// An empty model uses the client's default model.
+func NewGeminiAdapter(client llms.Model, model string) *GeminiAdapter {
+	return &GeminiAdapter{
+		llm:   client,
+		model: model,
+	}
+}
+
+// Generate implements the simple generation interface
+func (g *GeminiAdapter) Generate(ctx context.Context, prompt string) (string, error) {
+	return g.generate(ctx, []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeHuman, prompt),
+	}, g.options(nil))
+}
+
+// GenerateWithConfig implements the simple generation interface with configuration.
+// Supported keys are temperature, max_tokens, top_k and top_p.
+func (g *GeminiAdapter) GenerateWithConfig(ctx context.Context, prompt string, config map[string]any) (string, error) {
+	return g.generate(ctx, []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeHuman, prompt),
+	}, g.options(config))
+}
+
+// GenerateWithSystem implements the simple generation interface with system prompt.
+// Gemini receives the system prompt as its system instruction.
+func (g *GeminiAdapter) GenerateWithSystem(ctx context.Context, system, prompt string) (string, error) {
+	return g.generate(ctx, []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, system),
+		llms.TextParts(llms.ChatMessageTypeHuman, prompt),
+	}, g.options(nil))
+}
+
+// options builds the call options from the adapter settings, overridden by config
+func (g *GeminiAdapter) options(config map[string]any) []llms.CallOption {
+	var options []llms.CallOption
+	if g.model != "" {
+		options = append(options, llms.WithModel(g.model))
+	}
+	if g.Temperature != 0 {
+		options = append(options, llms.WithTemperature(g.Temperature))
+	}
+	if g.TopK != 0 {
+		options = append(options, llms.WithTopK(g.TopK))
+	}
+	if g.TopP != 0 {
+		options = append(options, llms.WithTopP(g.TopP))
+	}
+
+	if temp, ok := config["temperature"].(float64); ok {
+		options = append(options, llms.WithTemperature(temp))
+	}
+	if maxTokens, ok := config["max_tokens"].(int); ok {
+		options = append(options, llms.WithMaxTokens(maxTokens))
+	}
+	if topK, ok := config["top_k"].(int); ok {
+		options = append(options, llms.WithTopK(topK))
+	}
+	if topP, ok := config["top_p"].(float64); ok {
+		options = append(options, llms.WithTopP(topP))
+	}
+
+	if g.StreamCallback != nil {
+		options = append(options, llms.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
+			g.StreamCallback(string(chunk))
+			return nil
+		}))
+	}
+	return options
+}
+
+func (g *GeminiAdapter) generate(ctx context.Context, messages []llms.MessageContent, options []llms.CallOption) (string, error) {
+	response, err := g.llm.GenerateContent(ctx, messages, options...)
+	if err != nil {
+		return "", err
+	}
+	if len(response.Choices) == 0 {
+		return "", nil
+	}
+
+	choice := response.Choices[0]
+	if choice.Content == "" && geminiBlockedReasons[choice.StopReason] {
+		return "", fmt.Errorf("%w: %s", ErrGeminiBlocked, choice.StopReason)
+	}
+	return choice.Content, nil
+}
+
+// GeminiEmbeddingClient creates embeddings, e.g. langchaingo's *googleai.GoogleAI
+type GeminiEmbeddingClient interface {
+	CreateEmbedding(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// GeminiEmbedder implements rag.Embedder with a Gemini embedding model. The model is
+// selected on the client, e.g. googleai.WithDefaultEmbeddingModel("text-embedding-004").
+type GeminiEmbedder struct {
+	client    GeminiEmbeddingClient
+	dimension int
+}
+
+// NewGeminiEmbedder creates a new embedder producing vectors of the given dimension.
+// A non-positive dimension defaults to DefaultGeminiEmbeddingDimension.
+func NewGeminiEmbedder(client GeminiEmbeddingClient, dimension int) *GeminiEmbedder {
+	if dimension <= 0 {
+		dimension = DefaultGeminiEmbeddingDimension
+	}
+	return &GeminiEmbedder{
+		client:    client,
+		dimension: dimension,
+	}
+}
+
+// EmbedDocument generates an embedding for a single text
+func (e *GeminiEmbedder) EmbedDocument(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := e.EmbedDocuments(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// EmbedDocuments generates embeddings for multiple texts
+func (e *GeminiEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+
+	embeddings, err := e.client.CreateEmbedding(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gemini embeddings: %w", err)
+	}
+	if len(embeddings) != len(texts) {
+		return nil, fmt.Errorf("gemini returned %d embeddings for %d texts", len(embeddings), len(texts))
+	}
+	return embeddings, nil
+}
+
+// GetDimension returns the embedding dimension
+func (e *GeminiEmbedder) GetDimension() int {
+	return e.dimension
+}
@@ -0,0 +1,144 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// recordingLLM records the messages and call options of GenerateContent
+type recordingLLM struct {
+	choice   *llms.ContentChoice
+	chunks   []string
+	messages []llms.MessageContent
+	options  llms.CallOptions
+}
+
+func (r *recordingLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	r.messages = messages
+	r.options = llms.CallOptions{}
+	for _, opt := range options {
+		opt(&r.options)
+	}
+	if r.options.StreamingFunc != nil {
+		for _, chunk := range r.chunks {
+			if err := r.options.StreamingFunc(ctx, []byte(chunk)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{r.choice}}, nil
+}
+
+func (r *recordingLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, r, prompt, options...)
+}
+
+func TestGeminiAdapter_Options(t *testing.T) {
+	llm := &recordingLLM{choice: &llms.ContentChoice{Content: "answer"}}
+	gemini := NewGeminiAdapter(llm, "gemini-1.5-flash")
+	gemini.Temperature = 0.2
+	gemini.TopK = 40
+	gemini.TopP = 0.9
+
+	result, err := gemini.GenerateWithConfig(context.Background(), "question", map[string]any{
+		"temperature": 0.7,
+		"max_tokens":  256,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "answer" {
+		t.Errorf("expected %q, got %q", "answer", result)
+	}
+
+	opts := llm.options
+	if opts.Model != "gemini-1.5-flash" {
+		t.Errorf("expected model gemini-1.5-flash, got %q", opts.Model)
+	}
+	if opts.Temperature != 0.7 {
+		t.Errorf("expected config temperature 0.7 to override the adapter's, got %v", opts.Temperature)
+	}
+	if opts.MaxTokens != 256 || opts.TopK != 40 || opts.TopP != 0.9 {
+		t.Errorf("unexpected options: max_tokens=%d top_k=%d top_p=%v", opts.MaxTokens, opts.TopK, opts.TopP)
+	}
+}
+
+func TestGeminiAdapter_GenerateWithSystem(t *testing.T) {
+	llm := &recordingLLM{choice: &llms.ContentChoice{Content: "ok"}}
+	gemini := NewGeminiAdapter(llm, "")
+
+	if _, err := gemini.GenerateWithSystem(context.Background(), "be brief", "hello"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(llm.messages) != 2 || llm.messages[0].Role != llms.ChatMessageTypeSystem {
+		t.Fatalf("expected a system and a human message, got %+v", llm.messages)
+	}
+	if llm.options.Model != "" {
+		t.Errorf("expected the client's default model, got %q", llm.options.Model)
+	}
+}
+
+func TestGeminiAdapter_Streaming(t *testing.T) {
+	llm := &recordingLLM{choice: &llms.ContentChoice{Content: "Hello world"}, chunks: []string{"Hello", " world"}}
+	gemini := NewGeminiAdapter(llm, "gemini-1.5-flash")
+
+	var streamed []string
+	gemini.StreamCallback = func(chunk string) {
+		streamed = append(streamed, chunk)
+	}
+
+	if _, err := gemini.Generate(context.Background(), "hi"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(streamed) != 2 || streamed[0] != "Hello" || streamed[1] != " world" {
+		t.Errorf("unexpected chunks: %v", streamed)
+	}
+}
+
+func TestGeminiAdapter_Blocked(t *testing.T) {
+	llm := &recordingLLM{choice: &llms.ContentChoice{StopReason: "FinishReasonSafety"}}
+	gemini := NewGeminiAdapter(llm, "gemini-1.5-flash")
+
+	_, err := gemini.Generate(context.Background(), "something unsafe")
+	if !errors.Is(err, ErrGeminiBlocked) {
+		t.Errorf("expected ErrGeminiBlocked, got %v", err)
+	}
+}
+
+type mockEmbeddingClient struct {
+	err error
+}
+
+func (m *mockEmbeddingClient) CreateEmbedding(ctx context.Context, texts []string) ([][]float32, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i] = []float32{float32(len(text)), 1}
+	}
+	return embeddings, nil
+}
+
+func TestGeminiEmbedder(t *testing.T) {
+	embedder := NewGeminiEmbedder(&mockEmbeddingClient{}, 0)
+	if embedder.GetDimension() != DefaultGeminiEmbeddingDimension {
+		t.Errorf("expected default dimension %d, got %d", DefaultGeminiEmbeddingDimension, embedder.GetDimension())
+	}
+
+	embedding, err := embedder.EmbedDocument(context.Background(), "abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if embedding[0] != 3 {
+		t.Errorf("unexpected embedding: %v", embedding)
+	}
+
+	failing := NewGeminiEmbedder(&mockEmbeddingClient{err: errors.New("quota exceeded")}, 768)
+	if _, err := failing.EmbedDocuments(context.Background(), []string{"a"}); err == nil {
+		t.Error("expected an error from the client")
+	}
+}
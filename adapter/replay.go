@@ -0,0 +1,178 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// ErrNoRecordedResponse is returned by ReplayLLM for requests that are not in its log
+var ErrNoRecordedResponse = errors.New("no recorded response for request")
+
+// LLMInteraction is a recorded LLM request with its response
+type LLMInteraction struct {
+	// Request is the canonical text form of the request messages, used to match replays
+	Request string `json:"request"`
+	// Response is the model's response, nil if the request failed
+	Response *llms.ContentResponse `json:"response,omitempty"`
+	// Error is the message of the request's error
+	Error string `json:"error,omitempty"`
+}
+
+// RequestKey returns the canonical text form of request messages. Requests with the
+// same roles and parts have the same key; call options are not part of it.
+func RequestKey(messages []llms.MessageContent) string {
+	var sb strings.Builder
+	for _, msg := range messages {
+		fmt.Fprintf(&sb, "[%s]\n", msg.Role)
+		for _, part := range msg.Parts {
+			switch p := part.(type) {
+			case llms.TextContent:
+				sb.WriteString(p.Text)
+			case llms.ToolCall:
+				if p.FunctionCall != nil {
+					fmt.Fprintf(&sb, "<tool_call id=%q name=%q>%s", p.ID, p.FunctionCall.Name, p.FunctionCall.Arguments)
+				} else {
+					fmt.Fprintf(&sb, "<tool_call id=%q>", p.ID)
+				}
+			case llms.ToolCallResponse:
+				fmt.Fprintf(&sb, "<tool_response id=%q name=%q>%s", p.ToolCallID, p.Name, p.Content)
+			default:
+				fmt.Fprintf(&sb, "%v", p)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// LLMRecorder wraps an llms.Model and records every request and response, so that a
+// run can be reproduced offline with ReplayLLM
+type LLMRecorder struct {
+	llms.Model
+
+	mu           sync.Mutex
+	interactions []LLMInteraction
+}
+
+// NewLLMRecorder creates a recorder for llm
+func NewLLMRecorder(llm llms.Model) *LLMRecorder {
+	return &LLMRecorder{Model: llm}
+}
+
+// GenerateContent calls the wrapped model and records the interaction
+func (r *LLMRecorder) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	response, err := r.Model.GenerateContent(ctx, messages, options...)
+
+	interaction := LLMInteraction{Request: RequestKey(messages), Response: response}
+	if err != nil {
+		interaction.Error = err.Error()
+	}
+	r.mu.Lock()
+	r.interactions = append(r.interactions, interaction)
+	r.mu.Unlock()
+
+	return response, err
+}
+
+// Call implements the single prompt interface on top of GenerateContent, so that it is recorded too
+func (r *LLMRecorder) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, r, prompt, options...)
+}
+
+// Interactions returns the recorded interactions in call order
+func (r *LLMRecorder) Interactions() []LLMInteraction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	interactions := make([]LLMInteraction, len(r.interactions))
+	copy(interactions, r.interactions)
+	return interactions
+}
+
+// Save writes the recorded interactions as JSON
+func (r *LLMRecorder) Save(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r.Interactions()); err != nil {
+		return fmt.Errorf("failed to save LLM interactions: %w", err)
+	}
+	return nil
+}
+
+// LoadInteractions reads interactions written by LLMRecorder.Save
+func LoadInteractions(rd io.Reader) ([]LLMInteraction, error) {
+	var interactions []LLMInteraction
+	if err := json.NewDecoder(rd).Decode(&interactions); err != nil {
+		return nil, fmt.Errorf("failed to load LLM interactions: %w", err)
+	}
+	return interactions, nil
+}
+
+// ReplayLLM is an llms.Model that answers requests with recorded responses instead of
+// calling a model. A request that was recorded several times gets its responses in
+// recording order. Streaming callers receive each replayed response as a single chunk.
+type ReplayLLM struct {
+	mu        sync.Mutex
+	responses map[string][]LLMInteraction
+}
+
+// NewReplayLLM creates a model replaying the given interactions
+func NewReplayLLM(interactions []LLMInteraction) *ReplayLLM {
+	responses := make(map[string][]LLMInteraction)
+	for _, interaction := range interactions {
+		responses[interaction.Request] = append(responses[interaction.Request], interaction)
+	}
+	return &ReplayLLM{responses: responses}
+}
+
+// GenerateContent returns the next recorded response for the request
+func (r *ReplayLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	key := RequestKey(messages)
+
+	r.mu.Lock()
+	queue := r.responses[key]
+	if len(queue) == 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w:\n%s", ErrNoRecordedResponse, key)
+	}
+	interaction := queue[0]
+	r.responses[key] = queue[1:]
+	r.mu.Unlock()
+
+	if interaction.Error != "" {
+		return nil, errors.New(interaction.Error)
+	}
+
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	if opts.StreamingFunc != nil && interaction.Response != nil && len(interaction.Response.Choices) > 0 {
+		if err := opts.StreamingFunc(ctx, []byte(interaction.Response.Choices[0].Content)); err != nil {
+			return nil, err
+		}
+	}
+	return interaction.Response, nil
+}
+
+// Call implements the single prompt interface on top of GenerateContent
+func (r *ReplayLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, r, prompt, options...)
+}
+
+// Remaining returns the number of recorded responses that have not been replayed
+func (r *ReplayLLM) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	remaining := 0
+	for _, queue := range r.responses {
+		remaining += len(queue)
+	}
+	return remaining
+}
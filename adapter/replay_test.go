@@ -0,0 +1,96 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// sequenceLLM answers with numbered responses, so repeated requests get different answers
+type sequenceLLM struct {
+	calls int
+	err   error
+}
+
+func (s *sequenceLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	text := messages[len(messages)-1].Parts[0].(llms.TextContent).Text
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: fmt.Sprintf("%s #%d", text, s.calls)}}}, nil
+}
+
+func (s *sequenceLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, s, prompt, options...)
+}
+
+func TestLLMRecorder_Replay(t *testing.T) {
+	ctx := context.Background()
+	live := &sequenceLLM{}
+	recorder := NewLLMRecorder(live)
+
+	var recorded []string
+	for _, prompt := range []string{"plan", "act", "plan"} {
+		answer, err := llms.GenerateFromSinglePrompt(ctx, recorder, prompt)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		recorded = append(recorded, answer)
+	}
+
+	var buf bytes.Buffer
+	if err := recorder.Save(&buf); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	interactions, err := LoadInteractions(&buf)
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if len(interactions) != 3 {
+		t.Fatalf("expected 3 interactions, got %d", len(interactions))
+	}
+
+	replay := NewReplayLLM(interactions)
+	var streamed string
+	for i, prompt := range []string{"plan", "act", "plan"} {
+		answer, err := llms.GenerateFromSinglePrompt(ctx, replay, prompt, llms.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
+			streamed = string(chunk)
+			return nil
+		}))
+		if err != nil {
+			t.Fatalf("unexpected replay error: %v", err)
+		}
+		if answer != recorded[i] || streamed != recorded[i] {
+			t.Errorf("replay %d: expected %q, got %q (streamed %q)", i, recorded[i], answer, streamed)
+		}
+	}
+	if live.calls != 3 {
+		t.Errorf("replay must not call the live model, got %d calls", live.calls)
+	}
+	if replay.Remaining() != 0 {
+		t.Errorf("expected all responses replayed, %d remaining", replay.Remaining())
+	}
+
+	if _, err := replay.Call(ctx, "plan"); !errors.Is(err, ErrNoRecordedResponse) {
+		t.Errorf("expected ErrNoRecordedResponse, got %v", err)
+	}
+}
+
+func TestLLMRecorder_RecordsErrors(t *testing.T) {
+	ctx := context.Background()
+	recorder := NewLLMRecorder(&sequenceLLM{err: errors.New("rate limited")})
+
+	if _, err := recorder.Call(ctx, "hello"); err == nil {
+		t.Fatal("expected the model error")
+	}
+
+	replay := NewReplayLLM(recorder.Interactions())
+	if _, err := replay.Call(ctx, "hello"); err == nil || err.Error() != "rate limited" {
+		t.Errorf("expected the recorded error, got %v", err)
+	}
+}
@@ -203,6 +203,11 @@ func BuildOrderGraph(store graph.CheckpointStore) *graph.CheckpointableRunnable[
 
 	g.AddEdge("warehouse_notify", graph.END)
 
+	// Side effects must happen at most once per order, even across retries and resumes
+	orderKey := func(state OrderState) string { return state.OrderId }
+	g.SetIdempotencyKey("payment_processing", orderKey)
+	g.SetIdempotencyKey("warehouse_notify", orderKey)
+
 	runnable, err := g.CompileCheckpointable()
 	if err != nil {
 		log.Fatalf("Failed to compile graph: %v", err)
@@ -283,15 +288,11 @@ func (s *Server) HandleChat(w http.ResponseWriter, r *http.Request) {
 		// Check if the latest checkpoint has interrupt metadata
 		if event, ok := latestCP.Metadata["event"].(string); ok && event == "step" {
 			// The checkpoint was saved after a step completed
-			// We need to check if there was an interrupt by looking at the state
-			// State might be OrderState or map[string]any (from JSON deserialization)
+			// We need to check if there was an interrupt by looking at the state.
+			// The file store decodes states into OrderState, the state type the graph
+			// registered with it when compiled.
 			if state, ok := latestCP.State.(OrderState); ok && state.IsInterrupt {
 				isResuming = true
-			} else if m, ok := latestCP.State.(map[string]any); ok {
-				// Check for is_interrupt in map
-				if isInterrupt, ok := m["is_interrupt"].(bool); ok && isInterrupt {
-					isResuming = true
-				}
 			}
 		}
 	}
@@ -301,25 +302,7 @@ func (s *Server) HandleChat(w http.ResponseWriter, r *http.Request) {
 
 	if isResuming && latestCP != nil {
 		// RESUMING FROM INTERRUPT
-		// Convert checkpoint state to OrderState
-		if cpState, ok := latestCP.State.(OrderState); ok {
-			initialState = cpState
-		} else {
-			// Handle case where state is map[string]any
-			if m, ok := latestCP.State.(map[string]any); ok {
-				// Convert map to OrderState (simplified - in production use proper JSON unmarshaling)
-				initialState.SessionId = toString(m["session_id"])
-				initialState.UserInput = req.Content
-				initialState.ProductInfo = toString(m["product_info"])
-				initialState.OrderId = toString(m["order_id"])
-				initialState.Price = toFloat64(m["price"])
-				initialState.OrderStatus = toString(m["order_status"])
-				initialState.Message = toString(m["message"])
-				initialState.UpdateAt = toTime(m["update_at"])
-				initialState.NextNode = toString(m["next_node"])
-				initialState.IsInterrupt = toBool(m["is_interrupt"])
-			}
-		}
+		initialState = latestCP.State.(OrderState)
 
 		// Update with new user input
 		initialState.UserInput = req.Content
@@ -394,53 +377,6 @@ func (s *Server) HandleChat(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// Helper functions for type conversion
-func toString(v any) string {
-	if v == nil {
-		return ""
-	}
-	if s, ok := v.(string); ok {
-		return s
-	}
-	return fmt.Sprintf("%v", v)
-}
-
-func toBool(v any) bool {
-	if v == nil {
-		return false
-	}
-	if b, ok := v.(bool); ok {
-		return b
-	}
-	return false
-}
-
-func toFloat64(v any) float64 {
-	if v == nil {
-		return 0
-	}
-	if f, ok := v.(float64); ok {
-		return f
-	}
-	if f, ok := v.(float32); ok {
-		return float64(f)
-	}
-	return 0
-}
-
-func toTime(v any) time.Time {
-	if v == nil {
-		return time.Time{}
-	}
-	if s, ok := v.(string); ok {
-		t, err := time.Parse(time.RFC3339Nano, s)
-		if err == nil {
-			return t
-		}
-	}
-	return time.Time{}
-}
-
 func main() {
 	server, err := NewServer()
 	if err != nil {
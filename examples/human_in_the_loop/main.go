@@ -77,6 +77,7 @@ func main() {
 		var interrupt *graph.GraphInterrupt
 		if errors.As(err, &interrupt) {
 			fmt.Printf("Workflow interrupted at node: %s\n", interrupt.Node)
+			fmt.Printf("Next nodes: %v\n", interrupt.NextNodes)
 			fmt.Printf("Current State: %+v\n", interrupt.State)
 		} else {
 			log.Fatalf("Unexpected error: %v", err)
@@ -102,9 +103,9 @@ func main() {
 
 	// 2. Resume execution
 	fmt.Println("\n=== Resuming Workflow (Phase 2) ===")
-	resumeConfig := &graph.Config{
-		ResumeFrom: []string{interrupt.Node}, // Resume from the interrupted node
-	}
+	// Continue from the nodes the executor would have run next; pass node names to
+	// Resume to take a different path instead.
+	resumeConfig := interrupt.Resume(nil)
 
 	finalRes, err := runnable.InvokeWithConfig(context.Background(), currentState, resumeConfig)
 	if err != nil {
@@ -84,6 +84,8 @@ func runHybridRAGDemo() {
 		[]float64{0.4, 0.6}, // BM25: 40%, Vector: 60%
 		hybridConfig,
 	)
+	// BM25 scores are unbounded, so scale each retriever's scores to [0, 1] before weighting
+	hybridRetriever.SetNormalizeScores(true)
 	fmt.Println("   ✓ Hybrid retriever created")
 	fmt.Println("   → Weights: BM25 40%, Vector 60%")
 	fmt.Println("   → Best for: combining keyword precision with semantic understanding\n")
@@ -128,6 +130,10 @@ func runHybridRAGDemo() {
 		for i, r := range detailedResults {
 			fmt.Printf("   %d. [%s] Score: %.3f - %.50s...\n",
 				i+1, r.Document.ID, r.Score, r.Document.Content)
+			if breakdown, ok := r.Metadata[retriever.ScoreBreakdownMetadataKey].(map[string]float64); ok {
+				fmt.Printf("      bm25: %.3f, vector: %.3f, fused: %.3f\n",
+					breakdown["bm25"], breakdown["vector"], breakdown["fused"])
+			}
 		}
 		fmt.Println()
 	}
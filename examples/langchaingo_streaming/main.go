@@ -4,13 +4,12 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"log"
 	"os"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/smallnest/langgraphgo/graph"
 	"github.com/tmc/langchaingo/llms"
@@ -102,63 +101,19 @@ func streamingWithEventsExample() {
 
 	ctx := context.Background()
 
-	// Create a listenable graph for event tracking
-	g := graph.NewListenableStateGraph[StreamingState]()
+	// Create a streaming graph that reports token metrics every 500ms
+	g := graph.NewStreamingStateGraph[StreamingState]()
+	config := graph.DefaultStreamConfig()
+	config.TokenMetricsInterval = 500 * time.Millisecond
+	g.SetStreamConfig(config)
 
-	// Custom listener that can be triggered from streaming callback
-	type ProgressListener struct {
-		graph.NodeListenerFunc[StreamingState]
-		chunkCount int
-		chunks     [][]byte   // Store all chunks in order
-		mu         sync.Mutex // Thread-safe chunk access
-	}
-
-	progressListener := &ProgressListener{}
-
-	progressListener.NodeListenerFunc = graph.NodeListenerFunc[StreamingState](func(ctx context.Context, event graph.NodeEvent, nodeName string, state StreamingState, err error) {
-		switch event {
-		case graph.NodeEventStart:
-			fmt.Printf("\n[EVENT] Node '%s' started\n", nodeName)
-		case graph.NodeEventComplete:
-			// Calculate total bytes from stored chunks
-			totalBytes := 0
-			for _, chunk := range progressListener.chunks {
-				totalBytes += len(chunk)
-			}
-			fmt.Printf("[EVENT] Node '%s' completed (chunks: %d, bytes: %d)\n", nodeName, progressListener.chunkCount, totalBytes)
-
-			// Verify chunks are in order by concatenating them
-			reconstructed := string(bytes.Join(progressListener.chunks, nil))
-			fmt.Printf("[EVENT] Reconstructed response length: %d chars\n", len(reconstructed))
-		case graph.NodeEventProgress:
-			// Called for each streaming chunk
-			progressListener.chunkCount++
-			if progressListener.chunkCount%10 == 1 {
-				// Print every first chunk to avoid too much output
-				fmt.Printf("\n[EVENT] Node '%s' progress: chunk #%d received", nodeName, progressListener.chunkCount)
-			}
-		case graph.NodeEventError:
-			fmt.Printf("[EVENT] Node '%s' error: %v\n", nodeName, err)
-		}
-	})
-
-	node := g.AddNode("stream_with_events", "stream_with_events", func(ctx context.Context, state StreamingState) (StreamingState, error) {
-		// Stream the response with progress events
+	g.AddNode("stream_with_events", "stream_with_events", func(ctx context.Context, state StreamingState) (StreamingState, error) {
+		// Stream the response to the Tokens channel, counting each chunk for the token metrics
+		emit := graph.TokenEmitter(ctx)
 		response, err := llm.GenerateContent(ctx, state.Messages,
-			llms.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
-				// Save chunk to listener (thread-safe)
-				progressListener.mu.Lock()
-				// Make a copy of chunk since the underlying array may be reused
-				chunkCopy := make([]byte, len(chunk))
-				copy(chunkCopy, chunk)
-				progressListener.chunks = append(progressListener.chunks, chunkCopy)
-				progressListener.mu.Unlock()
-
-				// Emit progress event for each chunk
-				progressListener.OnNodeEvent(ctx, graph.NodeEventProgress, "stream_with_events", state, nil)
-
-				// Also stream to output
-				state.StreamCallback(string(chunk))
+			llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+				graph.ReportTokens(ctx, chunk)
+				emit(string(chunk))
 				return nil
 			}),
 			llms.WithTemperature(0.8),
@@ -175,30 +130,48 @@ func streamingWithEventsExample() {
 		return state, nil
 	})
 
-	node.AddListener(progressListener)
-
 	g.AddEdge("stream_with_events", graph.END)
 	g.SetEntryPoint("stream_with_events")
 
-	runnable, err := g.CompileListenable()
+	runnable, err := g.CompileStreaming()
 	if err != nil {
 		log.Fatalf("Failed to compile graph: %v", err)
 	}
 
-	// Prepare state
+	// Prepare state; no callback is needed, the chunks arrive on result.Tokens
 	state := StreamingState{
 		Messages: []llms.MessageContent{
 			llms.TextParts(llms.ChatMessageTypeHuman, "Write a short haiku about programming."),
 		},
-		StreamCallback: func(chunk string) {
-			fmt.Print(chunk)
-		},
 	}
 
 	fmt.Println("\nStreaming response with progress events:")
 	fmt.Println("-----------------------------------------")
-	_, err = runnable.Invoke(ctx, state)
-	if err != nil {
+	result := runnable.StreamTokens(ctx, state)
+	tokensDone := make(chan struct{})
+	go func() {
+		defer close(tokensDone)
+		for token := range result.Tokens {
+			fmt.Print(token.Chunk)
+		}
+	}()
+	for event := range result.Events {
+		switch event.Event {
+		case graph.NodeEventStart:
+			fmt.Printf("\n[EVENT] Node '%s' started\n", event.NodeName)
+		case graph.NodeEventProgress:
+			if m, ok := event.Metadata[graph.TokenMetricsMetadataKey].(graph.TokenMetrics); ok {
+				fmt.Printf("\n[EVENT] Node '%s': %d tokens, %d bytes (%.1f tokens/s)\n", event.NodeName, m.TokensEmitted, m.Bytes, m.TokensPerSec)
+			}
+		case graph.NodeEventComplete:
+			fmt.Printf("[EVENT] Node '%s' completed\n", event.NodeName)
+		case graph.NodeEventError:
+			fmt.Printf("[EVENT] Node '%s' error: %v\n", event.NodeName, event.Error)
+		}
+	}
+	<-tokensDone
+	// Errors is closed once the run finishes, so a receive only yields a real error
+	if err, ok := <-result.Errors; ok && err != nil {
 		log.Printf("Execution failed: %v", err)
 		return
 	}
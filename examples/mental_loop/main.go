@@ -34,8 +34,10 @@ import (
 	"math/rand"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/smallnest/langgraphgo/graph"
+	"github.com/smallnest/langgraphgo/prebuilt"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/openai"
 )
@@ -62,6 +64,14 @@ type MarketSimulator struct {
 	Drift      float64 // General trend (daily return)
 	MarketNews string
 	Portfolio  Portfolio
+
+	rng *rand.Rand // random source, seeded per rollout by prebuilt.Simulate
+}
+
+// MarketAction is a concrete trade executed in one market step
+type MarketAction struct {
+	Action string // "buy", "sell", or "hold"
+	Amount float64
 }
 
 // ProposedAction represents the high-level strategy proposed by the analyst
@@ -97,19 +107,39 @@ type AgentState struct {
 
 // ==================== Market Simulator Methods ====================
 
+// Seed resets the market's random source, making its evolution reproducible
+func (m *MarketSimulator) Seed(seed int64) {
+	m.rng = rand.New(rand.NewSource(seed))
+}
+
+// random returns the market's random source, creating a time-seeded one if needed
+func (m *MarketSimulator) random() *rand.Rand {
+	if m.rng == nil {
+		m.Seed(time.Now().UnixNano())
+	}
+	return m.rng
+}
+
+// Value returns the portfolio value at the current price
+func (m *MarketSimulator) Value() float64 {
+	return m.Portfolio.Value(m.Price)
+}
+
 // Step advances the simulation by one day, executing a trade first
-func (m *MarketSimulator) Step(action string, amount float64) {
+func (m *MarketSimulator) Step(trade MarketAction) {
+	rng := m.random()
+
 	// 1. Execute trade
-	switch action {
+	switch trade.Action {
 	case "buy": // amount is number of shares
-		sharesToBuy := int(amount)
+		sharesToBuy := int(trade.Amount)
 		cost := float64(sharesToBuy) * m.Price
 		if m.Portfolio.Cash >= cost {
 			m.Portfolio.Shares += sharesToBuy
 			m.Portfolio.Cash -= cost
 		}
 	case "sell": // amount is number of shares
-		sharesToSell := int(amount)
+		sharesToSell := int(trade.Amount)
 		if m.Portfolio.Shares >= sharesToSell {
 			m.Portfolio.Shares -= sharesToSell
 			m.Portfolio.Cash += float64(sharesToSell) * m.Price
@@ -118,21 +148,21 @@ func (m *MarketSimulator) Step(action string, amount float64) {
 
 	// 2. Update market price using Geometric Brownian Motion
 	// daily_return = normal(drift, volatility)
-	dailyReturn := rand.NormFloat64()*m.Volatility + m.Drift
+	dailyReturn := rng.NormFloat64()*m.Volatility + m.Drift
 	m.Price *= (1 + dailyReturn)
 
 	// 3. Advance time
 	m.Day++
 
 	// 4. Potentially update news
-	if rand.Float64() < 0.1 { // 10% chance of new news
+	if rng.Float64() < 0.1 { // 10% chance of new news
 		newsOptions := []string{
 			"Positive earnings report expected.",
 			"New competitor enters the market.",
 			"Macroeconomic outlook is strong.",
 			"Regulatory concerns are growing.",
 		}
-		m.MarketNews = newsOptions[rand.Intn(len(newsOptions))]
+		m.MarketNews = newsOptions[rng.Intn(len(newsOptions))]
 
 		// News affects drift
 		if strings.Contains(m.MarketNews, "Positive") || strings.Contains(m.MarketNews, "strong") {
@@ -153,7 +183,7 @@ func (m *MarketSimulator) GetStateString() string {
 }
 
 // Copy creates a deep copy for simulation (sandboxing)
-func (m *MarketSimulator) Copy() *MarketSimulator {
+func (m *MarketSimulator) Copy() prebuilt.Environment[MarketAction] {
 	return &MarketSimulator{
 		Day:        m.Day,
 		Price:      m.Price,
@@ -164,6 +194,7 @@ func (m *MarketSimulator) Copy() *MarketSimulator {
 			Cash:   m.Portfolio.Cash,
 			Shares: m.Portfolio.Shares,
 		},
+		rng: m.rng,
 	}
 }
 
@@ -211,55 +242,47 @@ func RunSimulationNode(ctx context.Context, state map[string]any) (map[string]an
 
 	fmt.Println("\n--- 🤖 Running Simulations ---")
 
+	// Translate strategy to a concrete action for the simulation
 	strategy := agentState.ProposedAction.Strategy
-	numSimulations := 5
-	simulationHorizon := 10 // days
-	results := make([]SimulationResult, numSimulations)
-
-	for i := 0; i < numSimulations; i++ {
-		// IMPORTANT: Create a deep copy to not affect the real market state
-		simulatedMarket := agentState.RealMarket.Copy()
-		initialValue := simulatedMarket.Portfolio.Value(simulatedMarket.Price)
-
-		// Translate strategy to a concrete action for the simulation
-		var action string
-		var amount float64
-
-		if strings.Contains(strategy, "buy") {
-			action = "buy"
-			// Aggressively = 25% of cash, Cautiously = 10%
-			cashRatio := 0.25
-			if strings.Contains(strategy, "cautiously") {
-				cashRatio = 0.1
-			}
-			amount = math.Floor((simulatedMarket.Portfolio.Cash * cashRatio) / simulatedMarket.Price)
-		} else if strings.Contains(strategy, "sell") {
-			action = "sell"
-			// Aggressively = 25% of shares, Cautiously = 10%
-			sharesRatio := 0.25
-			if strings.Contains(strategy, "cautiously") {
-				sharesRatio = 0.1
-			}
-			amount = math.Floor(float64(simulatedMarket.Portfolio.Shares) * sharesRatio)
-		} else {
-			action = "hold"
-			amount = 0
+	market := agentState.RealMarket
+	action := MarketAction{Action: "hold"}
+
+	if strings.Contains(strategy, "buy") {
+		// Aggressively = 25% of cash, Cautiously = 10%
+		cashRatio := 0.25
+		if strings.Contains(strategy, "cautiously") {
+			cashRatio = 0.1
 		}
-
-		// Run the simulation forward
-		simulatedMarket.Step(action, amount)
-		for j := 0; j < simulationHorizon-1; j++ {
-			simulatedMarket.Step("hold", 0) // Just hold after the initial action
+		action = MarketAction{Action: "buy", Amount: math.Floor((market.Portfolio.Cash * cashRatio) / market.Price)}
+	} else if strings.Contains(strategy, "sell") {
+		// Aggressively = 25% of shares, Cautiously = 10%
+		sharesRatio := 0.25
+		if strings.Contains(strategy, "cautiously") {
+			sharesRatio = 0.1
 		}
+		action = MarketAction{Action: "sell", Amount: math.Floor(float64(market.Portfolio.Shares) * sharesRatio)}
+	}
 
-		finalValue := simulatedMarket.Portfolio.Value(simulatedMarket.Price)
-		returnPct := (finalValue - initialValue) / initialValue * 100
+	// Run seeded simulations forward on deep copies, so the real market state is not affected
+	stats, err := prebuilt.Simulate[MarketAction](ctx, market, action, prebuilt.SimulationConfig[MarketAction]{
+		Rollouts: 5,
+		Horizon:  10, // days
+		Seed:     int64(market.Day),
+		NextAction: func(prebuilt.Environment[MarketAction], int) MarketAction {
+			return MarketAction{Action: "hold"} // Just hold after the initial action
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
 
+	results := make([]SimulationResult, len(stats.FinalValues))
+	for i, finalValue := range stats.FinalValues {
 		results[i] = SimulationResult{
 			SimNum:       i + 1,
-			InitialValue: initialValue,
+			InitialValue: stats.InitialValue,
 			FinalValue:   finalValue,
-			ReturnPct:    returnPct,
+			ReturnPct:    (finalValue - stats.InitialValue) / stats.InitialValue * 100,
 		}
 	}
 
@@ -361,7 +384,7 @@ func ExecuteInRealWorldNode(ctx context.Context, state map[string]any) (map[stri
 	realMarket := agentState.RealMarket
 
 	fmt.Printf("Before: %s\n", realMarket.GetStateString())
-	realMarket.Step(decision.Action, decision.Amount)
+	realMarket.Step(MarketAction{Action: decision.Action, Amount: decision.Amount})
 	fmt.Printf("After: %s\n", realMarket.GetStateString())
 
 	return state, nil
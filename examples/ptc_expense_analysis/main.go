@@ -39,6 +39,12 @@ func main() {
 		SystemPrompt: `You are a helpful financial analysis assistant.
 You can write Python code to analyze expense data efficiently.`,
 		MaxIterations: 10,
+		// Allow a few extra standard-library modules for the analysis
+		AllowedImports: append(ptc.DefaultAllowedImports(ptc.LanguagePython), "csv"),
+		Globals: map[string]any{
+			"STANDARD_BUDGET": 5000.0,
+			"over_budget":     ptc.Code("def over_budget(total, budget=None):\n    return total > (budget or STANDARD_BUDGET)"),
+		},
 	})
 	if err != nil {
 		log.Fatalf("Failed to create PTC agent: %v", err)
@@ -111,6 +117,21 @@ Only count approved expenses.`,
 			}
 		}
 
+		// Audit trail of the tools the generated code called
+		toolCalls, _ := result[ptc.ToolCallsKey].([]ptc.ToolCall)
+		fmt.Println("\n--- Tool Calls ---")
+		for idx, call := range toolCalls {
+			if call.Error != "" {
+				fmt.Printf("[%d] %s(%v) failed: %s\n", idx+1, call.Tool, call.Input, call.Error)
+				continue
+			}
+			output := call.Result
+			if len(output) > 200 {
+				output = output[:200] + "... (truncated)"
+			}
+			fmt.Printf("[%d] %s(%v) -> %s\n", idx+1, call.Tool, call.Input, output)
+		}
+
 		fmt.Printf("\n--- Execution Stats ---")
 		fmt.Printf("Total time: %v\n", elapsed)
 		fmt.Printf("Messages exchanged: %d\n", len(messages))
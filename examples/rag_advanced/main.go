@@ -132,6 +132,7 @@ func main() {
 	config.TopK = 5
 	config.UseReranking = true
 	config.IncludeCitations = true
+	config.MaxContextChars = 8000 // keep the prompt size predictable for large chunks
 	config.SystemPrompt = "You are a knowledgeable AI assistant. Answer questions based on the provided context. " +
 		"Always cite your sources using the document numbers provided. If the context doesn't contain " +
 		"enough information, acknowledge the limitations and provide what you can."
@@ -220,6 +221,18 @@ func main() {
 
 		fmt.Println("\n" + strings.Repeat("=", 100) + "\n")
 	}
+
+	// Restrict retrieval to documents of one category with a metadata filter
+	fmt.Println("=== Filtered Query (Framework documents only) ===")
+	result, err := pipeline.QueryWithFilter(ctx, "How do agents coordinate their work?",
+		map[string]any{"category": "Framework"})
+	if err != nil {
+		log.Fatalf("Failed to process filtered query: %v", err)
+	}
+	for j, doc := range result.Documents {
+		fmt.Printf("  [%d] %s (Category: %v)\n", j+1, truncate(doc.Content, 80), doc.Metadata["category"])
+	}
+	fmt.Printf("\nAnswer: %s\n", result.Answer)
 }
 
 func truncate(s string, maxLen int) string {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -115,13 +116,9 @@ func main() {
 		},
 	}
 
-	// 添加实体到知识图谱
-	for _, entity := range entities {
-		err := kg.AddEntity(ctx, entity)
-		if err != nil {
-			log.Printf("Failed to add entity %s: %v", entity.ID, err)
-		}
-	}
+	// 批量添加实体到知识图谱（每种实体类型一次查询）
+	batchKG := kg.(rag.BatchWriteKnowledgeGraph)
+	logBatchErrors(batchKG.AddEntities(ctx, entities), func(i int) string { return entities[i].ID })
 
 	// 手动定义关系
 	relationships := []*rag.Relationship{
@@ -175,13 +172,8 @@ func main() {
 		},
 	}
 
-	// 添加关系到知识图谱
-	for _, rel := range relationships {
-		err := kg.AddRelationship(ctx, rel)
-		if err != nil {
-			log.Printf("Failed to add relationship %s: %v", rel.ID, err)
-		}
-	}
+	// 批量添加关系到知识图谱
+	logBatchErrors(batchKG.AddRelationships(ctx, relationships), func(i int) string { return relationships[i].ID })
 
 	entityAddTime := time.Since(startTime)
 	fmt.Printf("Added %d entities and %d relationships in %v\n\n", len(entities), len(relationships), entityAddTime)
@@ -386,3 +378,16 @@ Answer:`, question, context.String())
 
 	return answer
 }
+
+// logBatchErrors logs the items of a batch write that failed, identified by id
+func logBatchErrors(err error, id func(int) string) {
+	var batchErr *rag.BatchWriteError
+	switch {
+	case errors.As(err, &batchErr):
+		for _, i := range batchErr.Indices() {
+			log.Printf("Failed to add %s: %v", id(i), batchErr.Errors[i])
+		}
+	case err != nil:
+		log.Printf("Batch write failed: %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"strings"
 	"time"
 
@@ -32,13 +33,17 @@ func main() {
 	// FalkorDB connection string
 	// Format: falkordb://host:port/graph_name
 	// For local FalkorDB: falkordb://localhost:6379/rag_graph
+	// Set KNOWLEDGE_GRAPH_URL=memory:// to run without FalkorDB, using the in-memory graph
 	falkorDBConnStr := "falkordb://localhost:6379/rag_graph"
+	if url := os.Getenv("KNOWLEDGE_GRAPH_URL"); url != "" {
+		falkorDBConnStr = url
+	}
 
-	// Create FalkorDB knowledge graph
-	fmt.Println("Initializing FalkorDB knowledge graph...")
-	kg, err := store.NewFalkorDBGraph(falkorDBConnStr)
+	// Create the knowledge graph
+	fmt.Println("Initializing knowledge graph...")
+	kg, err := store.NewKnowledgeGraph(falkorDBConnStr)
 	if err != nil {
-		log.Fatalf("Failed to create FalkorDB knowledge graph: %v", err)
+		log.Fatalf("Failed to create knowledge graph: %v", err)
 	}
 	// Close the connection when done (type assert to access Close method)
 	defer func() {
@@ -73,6 +78,8 @@ Text: %s`,
 			"CONCEPT",
 		},
 		MaxDepth: 3,
+		// Fail instead of hanging on a slow Redis or LLM
+		QueryTimeout: 30 * time.Second,
 	}
 
 	// Create GraphRAG engine
@@ -202,7 +209,12 @@ Text: %s`,
 		fmt.Printf("  - Entities Found: %v\n", result.Metadata["entities_found"])
 		fmt.Printf("  - Relationships: %v\n", result.Metadata["relationships"])
 		fmt.Printf("  - Confidence: %.2f\n", result.Confidence)
-		fmt.Printf("  - Response Time: %v\n", result.ResponseTime)
+		if signals, ok := result.Metadata[rag.GraphConfidenceSignalsMetadataKey].(rag.GraphConfidenceSignals); ok {
+			fmt.Printf("      entities: %d, relationships: %d, query entities matched: %d/%d\n",
+				signals.EntitiesFound, signals.RelationshipsFound, signals.MatchedEntities, signals.QueryEntities)
+		}
+		fmt.Printf("  - Response Time: %v (entity extraction: %v, graph search: %v)\n",
+			result.ResponseTime, result.Metadata["extraction_time"], result.Metadata["search_time"])
 
 		fmt.Println("\n" + strings.Repeat("=", 80) + "\n")
 
@@ -150,7 +150,8 @@ func main() {
 	// Create a Qwen3-Embedding-4B reranker using LLM-based reranking
 	// The reranker will re-score the retrieved documents
 	rerankerConfig := retriever.DefaultLLMRerankerConfig()
-	rerankerConfig.TopK = 3 // Return top 3 after reranking
+	rerankerConfig.TopK = 3          // Return top 3 after reranking
+	rerankerConfig.MinCandidates = 3 // Skip the LLM call when vector search finds fewer candidates
 	rerankerConfig.SystemPrompt = "You are a relevance scoring assistant for AI and machine learning topics. " +
 		"Rate how well each document answers the query on a scale of 0.0 to 1.0, " +
 		"where 1.0 is perfectly relevant and 0.0 is not relevant. " +
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/smallnest/langgraphgo/rag"
+	"github.com/smallnest/langgraphgo/rag/eval"
 	"github.com/smallnest/langgraphgo/rag/retriever"
 	"github.com/smallnest/langgraphgo/rag/splitter"
 	"github.com/smallnest/langgraphgo/rag/store"
@@ -68,6 +69,13 @@ func main() {
 	query := "What is LangGraph and how does it help with multi-agent systems?"
 	fmt.Printf("Query: %s\n\n", query)
 
+	// Ground-truth relevance of each document for the query (document ID -> grade)
+	judgments := map[string]float64{
+		"langgraph_intro": 3,
+		"multi_agent":     2,
+		"rag_overview":    1,
+	}
+
 	// Create base retriever
 	baseRetriever := retriever.NewVectorStoreRetriever(vectorStore, embedder, 5)
 
@@ -80,6 +88,14 @@ func main() {
 			name:     "SimpleReranker (keyword-based)",
 			reranker: retriever.NewSimpleReranker(),
 		},
+		{
+			name:     "SimpleReranker (Jaccard)",
+			reranker: retriever.NewSimpleRerankerWithScorer(retriever.JaccardScorer),
+		},
+		{
+			name:     "SimpleReranker (BM25)",
+			reranker: retriever.NewSimpleRerankerWithScorer(retriever.NewBM25Scorer(chunks, retriever.DefaultBM25Config())),
+		},
 		{
 			name:     "LLMReranker",
 			reranker: retriever.NewLLMReranker(llm, retriever.DefaultLLMRerankerConfig()),
@@ -113,6 +129,7 @@ func main() {
 	}
 
 	// Test each reranker
+	var comparison []rankingMetrics
 	for _, rr := range rerankers {
 		fmt.Printf("\n--- %s ---\n", rr.name)
 
@@ -131,16 +148,8 @@ func main() {
 			continue
 		}
 
-		runnable, err := pipeline.Compile()
-		if err != nil {
-			log.Printf("Failed to compile pipeline: %v", err)
-			continue
-		}
-
 		// Run query
-		result, err := runnable.Invoke(ctx, map[string]any{
-			"query": query,
-		})
+		result, err := pipeline.Query(ctx, query)
 		if err != nil {
 			log.Printf("Failed to process query: %v", err)
 			continue
@@ -148,6 +157,24 @@ func main() {
 
 		// Display results
 		displayResults(result)
+
+		// Measure ranking quality against the relevance judgments
+		metrics := rankingMetrics{
+			name:   rr.name,
+			ndcg:   eval.NDCG(result.RankedDocuments, judgments, config.TopK),
+			mrr:    eval.MRR(result.RankedDocuments, judgments),
+			recall: eval.RecallAtK(result.RankedDocuments, judgments, config.TopK),
+		}
+		fmt.Printf("\nNDCG@%d: %.3f  MRR: %.3f  Recall@%d: %.3f\n",
+			config.TopK, metrics.ndcg, metrics.mrr, config.TopK, metrics.recall)
+		comparison = append(comparison, metrics)
+	}
+
+	// Compare rerankers
+	fmt.Println("\n" + strings.Repeat("=", 80))
+	fmt.Printf("\n%-35s %8s %8s %8s\n", "Reranker", "NDCG@3", "MRR", "Recall@3")
+	for _, m := range comparison {
+		fmt.Printf("%-35s %8.3f %8.3f %8.3f\n", m.name, m.ndcg, m.mrr, m.recall)
 	}
 
 	fmt.Println("\n" + strings.Repeat("=", 80))
@@ -159,9 +186,16 @@ func main() {
 	fmt.Println("\nFor cross-encoder reranking, see scripts/cross_encoder_server.py")
 }
 
+// rankingMetrics holds the ranking quality of one reranker
+type rankingMetrics struct {
+	name              string
+	ndcg, mrr, recall float64
+}
+
 func createSampleDocuments() []rag.Document {
 	return []rag.Document{
 		{
+			ID: "langgraph_intro",
 			Content: "LangGraph is a library for building stateful, multi-actor applications with LLMs. " +
 				"It extends LangChain Expression Language with the ability to coordinate multiple chains " +
 				"across multiple steps of computation in a cyclic manner. LangGraph is particularly useful " +
@@ -174,6 +208,7 @@ func createSampleDocuments() []rag.Document {
 			},
 		},
 		{
+			ID: "multi_agent",
 			Content: "Multi-agent systems in LangGraph enable multiple AI agents to work together on complex tasks. " +
 				"Each agent can have specialized roles, tools, and objectives. The graph-based architecture allows " +
 				"agents to pass messages, share state, and coordinate their actions. This enables sophisticated " +
@@ -185,6 +220,7 @@ func createSampleDocuments() []rag.Document {
 			},
 		},
 		{
+			ID: "rag_overview",
 			Content: "RAG (Retrieval-Augmented Generation) is a technique that combines information retrieval " +
 				"with text generation. It retrieves relevant documents from a knowledge base and uses them " +
 				"to augment the context provided to a language model for generation. This approach helps " +
@@ -196,6 +232,7 @@ func createSampleDocuments() []rag.Document {
 			},
 		},
 		{
+			ID: "vector_db",
 			Content: "Vector databases store embeddings, which are numerical representations of text. " +
 				"They enable efficient similarity search by comparing vector distances using metrics like " +
 				"cosine similarity or Euclidean distance. Popular vector databases include Pinecone, Weaviate, " +
@@ -207,6 +244,7 @@ func createSampleDocuments() []rag.Document {
 			},
 		},
 		{
+			ID: "reranking",
 			Content: "Document reranking is a technique to improve retrieval quality by re-scoring retrieved " +
 				"documents based on their relevance to the query. Cross-encoder models are often used for " +
 				"reranking as they can better capture query-document interactions compared to bi-encoders " +
@@ -218,6 +256,7 @@ func createSampleDocuments() []rag.Document {
 			},
 		},
 		{
+			ID: "state_management",
 			Content: "State management in LangGraph is handled through a stateful graph where each node " +
 				"can read and modify the state. The state flows through the graph and evolves at each step. " +
 				"This allows agents to maintain context, remember previous interactions, and make decisions " +
@@ -231,11 +270,11 @@ func createSampleDocuments() []rag.Document {
 	}
 }
 
-func displayResults(result map[string]any) {
+func displayResults(result *rag.Result) {
 	// Display retrieved documents
-	if docs, ok := result["documents"].([]rag.RAGDocument); ok && len(docs) > 0 {
+	if len(result.Documents) > 0 {
 		fmt.Println("Top Retrieved Documents:")
-		for i, doc := range docs {
+		for i, doc := range result.Documents {
 			source := "Unknown"
 			if s, ok := doc.Metadata["source"].(string); ok {
 				source = s
@@ -250,9 +289,9 @@ func displayResults(result map[string]any) {
 	}
 
 	// Display reranked scores if available
-	if rankedDocs, ok := result["ranked_documents"].([]rag.DocumentSearchResult); ok && len(rankedDocs) > 0 {
+	if len(result.RankedDocuments) > 0 {
 		fmt.Println("\nRelevance Scores:")
-		for i, rd := range rankedDocs {
+		for i, rd := range result.RankedDocuments {
 			if i >= 3 {
 				break
 			}
@@ -265,8 +304,8 @@ func displayResults(result map[string]any) {
 	}
 
 	// Display answer if available
-	if answer, ok := result["answer"].(string); ok {
-		fmt.Printf("\nAnswer: %s\n", truncate(answer, 200))
+	if result.Answer != "" {
+		fmt.Printf("\nAnswer: %s\n", truncate(result.Answer, 200))
 	}
 }
 
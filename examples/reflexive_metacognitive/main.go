@@ -149,6 +149,9 @@ REASONING: [justification]
 	analysis := parseMetacognitiveAnalysis(resp)
 	agentState.MetacognitiveAnalysis = analysis
 
+	// Report the reasoning step to the caller as well as to stdout
+	graph.Logf(ctx, "confidence %.2f, strategy %s: %s", analysis.Confidence, analysis.Strategy, analysis.Reasoning)
+
 	fmt.Println("┌─────────────────────────────────────────────────────────────┐")
 	fmt.Printf("│ Confidence: %.2f                                            │\n", analysis.Confidence)
 	fmt.Printf("│ Strategy: %s                                                │\n", analysis.Strategy)
@@ -262,6 +265,11 @@ func RouteStrategy(ctx context.Context, state map[string]any) string {
 	}
 }
 
+// AnalysisConfidence returns the confidence of the metacognitive analysis
+func AnalysisConfidence(state map[string]any) float64 {
+	return state["agent_state"].(*AgentState).MetacognitiveAnalysis.Confidence
+}
+
 // ==================== Parsing Helpers ====================
 
 func parseMetacognitiveAnalysis(response string) *MetacognitiveAnalysis {
@@ -362,8 +370,15 @@ func main() {
 	// Set entry point
 	workflow.SetEntryPoint("analyze")
 
-	// Add conditional edges from analyze node
-	workflow.AddConditionalEdge("analyze", RouteStrategy)
+	// Add conditional edges from analyze node: below the self-model's confidence
+	// threshold the agent escalates, otherwise it follows the chosen strategy
+	belowThreshold := graph.RouteByThreshold(AnalysisConfidence, medicalAgentModel.ConfidenceThreshold, "", "escalate")
+	workflow.AddConditionalEdge("analyze", func(ctx context.Context, state map[string]any) string {
+		if route := belowThreshold(ctx, state); route != "" {
+			return route
+		}
+		return RouteStrategy(ctx, state)
+	})
 
 	// Add edges for each strategy
 	workflow.AddEdge("reason", graph.END)
@@ -415,7 +430,7 @@ func main() {
 			"agent_state": agentState,
 		}
 
-		result, err := app.Invoke(ctx, input)
+		result, logs, err := app.InvokeWithLogs(ctx, input, nil)
 		if err != nil {
 			log.Printf("Error: %v\n", err)
 			continue
@@ -423,6 +438,11 @@ func main() {
 
 		finalState := result["agent_state"].(*AgentState)
 
+		fmt.Println("\n🧾 Reasoning log:")
+		for _, entry := range logs {
+			fmt.Printf("  [%s] %s\n", entry.Node, entry.Message)
+		}
+
 		fmt.Println("\n📋 Response:")
 		fmt.Println(finalState.FinalResponse)
 		fmt.Println(strings.Repeat("=", 70))
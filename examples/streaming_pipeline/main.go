@@ -13,22 +13,24 @@ func main() {
 	// Create a streaming graph for a text processing pipeline
 	g := graph.NewStreamingStateGraph[map[string]any]()
 
-	// Define nodes
-	analyze := g.AddNode("analyze", "analyze", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+	// Define nodes, declaring the state keys each one reads and writes so that
+	// compilation fails if a node would read a key before it is written
+	g.SetInputKeys("input")
+	analyze := g.AddNodeWithIO("analyze", "analyze", []string{"input"}, []string{"analysis"}, func(ctx context.Context, state map[string]any) (map[string]any, error) {
 		input := state["input"].(string)
 		time.Sleep(200 * time.Millisecond)
 		state["analysis"] = fmt.Sprintf("Length: %d", len(input))
 		return state, nil
 	})
 
-	enhance := g.AddNode("enhance", "enhance", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+	enhance := g.AddNodeWithIO("enhance", "enhance", []string{"input"}, []string{"enhanced"}, func(ctx context.Context, state map[string]any) (map[string]any, error) {
 		input := state["input"].(string)
 		time.Sleep(300 * time.Millisecond)
 		state["enhanced"] = strings.ToUpper(input)
 		return state, nil
 	})
 
-	summarize := g.AddNode("summarize", "summarize", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+	summarize := g.AddNodeWithIO("summarize", "summarize", []string{"analysis", "enhanced"}, []string{"summary"}, func(ctx context.Context, state map[string]any) (map[string]any, error) {
 		analysis := state["analysis"].(string)
 		enhanced := state["enhanced"].(string)
 		time.Sleep(200 * time.Millisecond)
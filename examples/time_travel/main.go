@@ -83,7 +83,7 @@ func main() {
 		// We use InvokeWithConfig with ResumeFrom (this logic is app specific usually)
 
 		// But here let's just inspect
-		loadedState, err := runnable.LoadCheckpoint(ctx, targetCP.ID)
+		loadedState, err := runnable.LoadTyped(ctx, targetCP.ID)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -101,7 +101,7 @@ func main() {
 
 		// Let's modify state "in place" conceptually (forking)
 		// by passing modified state to Invoke
-		forkedState := loadedState.State.(map[string]any)
+		forkedState := loadedState.State
 		forkedState["forked"] = true
 
 		resFork, err := runnable.InvokeWithConfig(ctx, forkedState, config)
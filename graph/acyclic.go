@@ -0,0 +1,107 @@
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// RequireAcyclic makes Compile reject graphs with cycles, which guarantees that pipelines
+// without loops (e.g. RAG) cannot run forever by accident. The check follows static edges,
+// joins and the declared targets of conditional edges; Compile fails with
+// ErrUndeclaredTargets for conditional edges without SetConditionalTargets, and with a
+// *CycleError naming the nodes of the first cycle found. Command.Goto routing is not checked.
+// It returns ErrGraphFrozen once the graph has been compiled.
+func (g *StateGraph[S]) RequireAcyclic() error {
+	if g.frozen {
+		return fmt.Errorf("%w: cannot require acyclic", ErrGraphFrozen)
+	}
+	g.requireAcyclic = true
+	return nil
+}
+
+// SetConditionalTargets declares the nodes the conditional edge of from can route to.
+// The declaration is used by RequireAcyclic; routing is still decided by the condition.
+//
+// Example:
+//
+//	g.AddConditionalEdge("rerank", route)
+//	g.SetConditionalTargets("rerank", "generate", "fallback_search")
+//
+// It returns ErrGraphFrozen, without declaring the targets, once the graph has been compiled.
+func (g *StateGraph[S]) SetConditionalTargets(from string, targets ...string) error {
+	if g.frozen {
+		return fmt.Errorf("%w: cannot set conditional targets of %s", ErrGraphFrozen, from)
+	}
+	if g.conditionalTargets == nil {
+		g.conditionalTargets = make(map[string][]string)
+	}
+	g.conditionalTargets[from] = slices.Clone(targets)
+	return nil
+}
+
+// checkAcyclic returns an error if the graph may contain a cycle
+func (g *StateGraph[S]) checkAcyclic() error {
+	successors := make(map[string][]string)
+	for _, edge := range g.edges {
+		successors[edge.From] = append(successors[edge.From], edge.To)
+	}
+	for _, from := range g.conditionalSources() {
+		targets, ok := g.conditionalTargets[from]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUndeclaredTargets, from)
+		}
+		successors[from] = append(successors[from], targets...)
+	}
+	for target, preds := range g.joins {
+		for _, pred := range preds {
+			successors[pred] = append(successors[pred], target)
+		}
+	}
+
+	nodes := make([]string, 0, len(successors))
+	for node := range successors {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	// Depth-first search; a successor that is still on the path closes a cycle
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	state := make(map[string]int)
+	var path []string
+	var visit func(node string) []string
+	visit = func(node string) []string {
+		state[node] = onPath
+		path = append(path, node)
+		for _, next := range successors[node] {
+			switch state[next] {
+			case onPath:
+				cycle := slices.Clone(path[slices.Index(path, next):])
+				return append(cycle, next)
+			case unvisited:
+				if next == END {
+					continue
+				}
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[node] = done
+		return nil
+	}
+
+	for _, node := range nodes {
+		if state[node] == unvisited {
+			if cycle := visit(node); cycle != nil {
+				return &CycleError{Cycle: cycle}
+			}
+		}
+	}
+	return nil
+}
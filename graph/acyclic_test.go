@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateGraph_RequireAcyclic(t *testing.T) {
+	newGraph := func() *StateGraph[int] {
+		g := NewStateGraph[int]()
+		for _, name := range []string{"retrieve", "grade", "rewrite", "generate"} {
+			g.AddNode(name, name, func(ctx context.Context, s int) (int, error) { return s + 1, nil })
+		}
+		g.SetEntryPoint("retrieve")
+		g.AddEdge("retrieve", "grade")
+		g.AddConditionalEdge("grade", func(ctx context.Context, s int) string { return "generate" })
+		g.AddEdge("generate", END)
+		g.RequireAcyclic()
+		return g
+	}
+
+	t.Run("Accepts a DAG", func(t *testing.T) {
+		g := newGraph()
+		g.SetConditionalTargets("grade", "generate", "rewrite")
+		g.AddEdge("rewrite", "generate")
+
+		runnable, err := g.Compile()
+		require.NoError(t, err)
+		result, err := runnable.Invoke(context.Background(), 0)
+		require.NoError(t, err)
+		assert.Equal(t, 3, result)
+	})
+
+	t.Run("Rejects a cycle through a conditional edge", func(t *testing.T) {
+		g := newGraph()
+		g.SetConditionalTargets("grade", "generate", "rewrite")
+		g.AddEdge("rewrite", "retrieve")
+
+		_, err := g.Compile()
+		var cycleErr *CycleError
+		require.True(t, errors.As(err, &cycleErr), "got %v", err)
+		assert.Equal(t, []string{"grade", "rewrite", "retrieve", "grade"}, cycleErr.Cycle)
+		assert.EqualError(t, err, "graph has a cycle: grade -> rewrite -> retrieve -> grade")
+	})
+
+	t.Run("Rejects undeclared conditional targets", func(t *testing.T) {
+		_, err := newGraph().Compile()
+		assert.ErrorIs(t, err, ErrUndeclaredTargets)
+	})
+
+	t.Run("Cycles are allowed by default", func(t *testing.T) {
+		g := NewStateGraph[int]()
+		g.AddNode("loop", "loop", func(ctx context.Context, s int) (int, error) { return s + 1, nil })
+		g.SetEntryPoint("loop")
+		g.AddConditionalEdge("loop", func(ctx context.Context, s int) string {
+			if s < 3 {
+				return "loop"
+			}
+			return END
+		})
+		_, err := g.Compile()
+		assert.NoError(t, err)
+	})
+}
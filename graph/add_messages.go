@@ -3,6 +3,7 @@ package graph
 import (
 	"fmt"
 	"reflect"
+	"slices"
 
 	"github.com/tmc/langchaingo/llms"
 )
@@ -151,3 +152,77 @@ func getMessageID(msg any) string {
 
 	return ""
 }
+
+// ReplaceLastMessage is an update for MessageReducer that replaces the last message of the
+// history instead of appending to it, e.g. to swap a draft answer for a revised one.
+// Message must have the element type of the history.
+type ReplaceLastMessage struct {
+	Message any
+}
+
+// MessageReducer is the reducer for conversation histories, registered on the "messages" key
+// by the prebuilt agents. It appends new messages, given as a slice or a single message, and
+// replaces the messages whose ID (see MessageWithID) is already in the history, like
+// AddMessages. A ReplaceLastMessage update replaces the last message instead.
+func MessageReducer(current, new any) (any, error) {
+	switch update := new.(type) {
+	case ReplaceLastMessage:
+		return replaceLastMessage(current, update.Message)
+	case *ReplaceLastMessage:
+		return replaceLastMessage(current, update.Message)
+	}
+
+	if current == nil {
+		return AppendReducer(nil, new)
+	}
+	return AddMessages(current, new)
+}
+
+// replaceLastMessage returns a copy of history with its last message replaced by msg
+func replaceLastMessage(history, msg any) (any, error) {
+	if history == nil {
+		return AppendReducer(nil, msg)
+	}
+
+	historyVal := reflect.ValueOf(history)
+	if historyVal.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("current value is not a slice")
+	}
+	if historyVal.Len() == 0 {
+		return AddMessages(history, msg)
+	}
+
+	msgVal := reflect.ValueOf(msg)
+	if !msgVal.IsValid() || !msgVal.Type().AssignableTo(historyVal.Type().Elem()) {
+		return nil, fmt.Errorf("cannot replace the last message of %s with %T", historyVal.Type(), msg)
+	}
+
+	result := reflect.MakeSlice(historyVal.Type(), historyVal.Len(), historyVal.Len())
+	reflect.Copy(result, historyVal)
+	result.Index(historyVal.Len() - 1).Set(msgVal)
+	return result.Interface(), nil
+}
+
+// MessagesState is a typed state for chat agents holding the conversation history.
+// Used with NewMessagesStateSchema, nodes return only the messages they add.
+type MessagesState struct {
+	Messages []llms.MessageContent
+	// ReplaceLast makes an update replace the last message of the history with its first
+	// message; the others are appended. It is not kept in the merged state.
+	ReplaceLast bool
+}
+
+// NewMessagesStateSchema returns the schema for MessagesState, which appends the messages of
+// node results to the history, or replaces the last message for ReplaceLast updates
+func NewMessagesStateSchema() *StructSchema[MessagesState] {
+	return NewStructSchema(MessagesState{}, func(current, update MessagesState) (MessagesState, error) {
+		history := current.Messages
+		messages := update.Messages
+		if update.ReplaceLast && len(messages) > 0 && len(history) > 0 {
+			history = slices.Clone(history)
+			history[len(history)-1] = messages[0]
+			messages = messages[1:]
+		}
+		return MessagesState{Messages: append(slices.Clip(history), messages...)}, nil
+	})
+}
@@ -1,6 +1,7 @@
 package graph
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -101,3 +102,59 @@ func TestAddMessages(t *testing.T) {
 		assert.Len(t, slice, 3)
 	})
 }
+
+func TestMessageReducer(t *testing.T) {
+	user := llms.TextParts(llms.ChatMessageTypeHuman, "Hello")
+	draft := llms.TextParts(llms.ChatMessageTypeAI, "Draft")
+	final := llms.TextParts(llms.ChatMessageTypeAI, "Final")
+
+	t.Run("Starts a history from a single message", func(t *testing.T) {
+		res, err := MessageReducer(nil, user)
+		assert.NoError(t, err)
+		assert.Equal(t, []llms.MessageContent{user}, res)
+	})
+
+	t.Run("Replaces the last message", func(t *testing.T) {
+		history := []llms.MessageContent{user, draft}
+		res, err := MessageReducer(history, ReplaceLastMessage{Message: final})
+		assert.NoError(t, err)
+		assert.Equal(t, []llms.MessageContent{user, final}, res)
+		assert.Equal(t, draft, history[1], "the current history is not modified")
+
+		_, err = MessageReducer(history, ReplaceLastMessage{Message: "not a message"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Deduplicates by ID", func(t *testing.T) {
+		res, err := MessageReducer([]TestMessage{{ID: "1", Content: "a"}}, []TestMessage{{ID: "1", Content: "b"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []TestMessage{{ID: "1", Content: "b"}}, res)
+	})
+}
+
+func TestMessagesState(t *testing.T) {
+	g := NewStateGraph[MessagesState]()
+	g.SetSchema(NewMessagesStateSchema())
+	g.AddNode("draft", "draft", func(ctx context.Context, state MessagesState) (MessagesState, error) {
+		return MessagesState{Messages: []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeAI, "Draft")}}, nil
+	})
+	g.AddNode("revise", "revise", func(ctx context.Context, state MessagesState) (MessagesState, error) {
+		return MessagesState{Messages: []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeAI, "Final")}, ReplaceLast: true}, nil
+	})
+	g.AddEdge("draft", "revise")
+	g.AddEdge("revise", END)
+	g.SetEntryPoint("draft")
+
+	app, err := g.Compile()
+	assert.NoError(t, err)
+
+	res, err := app.Invoke(context.Background(), MessagesState{
+		Messages: []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "Hello")},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeHuman, "Hello"),
+		llms.TextParts(llms.ChatMessageTypeAI, "Final"),
+	}, res.Messages)
+	assert.False(t, res.ReplaceLast)
+}
@@ -0,0 +1,191 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+// ErrAsyncDeadlineExceeded is returned when an async node is awaited after its deadline.
+var ErrAsyncDeadlineExceeded = errors.New("async node deadline exceeded")
+
+// AsyncHandlesMetadataKey is the checkpoint metadata key under which the handles of
+// interrupted async nodes are recorded, keyed by node name
+const AsyncHandlesMetadataKey = "async_handles"
+
+// AsyncHandle identifies external work started by an async node.
+// It is returned as the InterruptValue of the GraphInterrupt raised after the work
+// is started, and must be passed back as Config.ResumeValue to await the result.
+type AsyncHandle struct {
+	// Node is the name of the async node that started the work
+	Node string
+
+	// Handle is the value returned by the start function (e.g. a job ID)
+	Handle any
+
+	// StartedAt is when the work was started
+	StartedAt time.Time
+
+	// Deadline is when awaiting the work gives up (zero means no deadline)
+	Deadline time.Time
+}
+
+// AddAsyncNode adds a node whose work completes outside the graph execution.
+//
+// When the node runs, start kicks off the work and the graph is interrupted with an
+// *AsyncHandle as the interrupt value, so the state can be checkpointed and the
+// goroutine released. Resuming the graph from the node with the handle as
+// Config.ResumeValue calls await to collect the result.
+// If interrupts are disabled via Config.DisableInterrupts, await is called immediately.
+//
+// A checkpointable runnable records the handle in the interrupt checkpoint, so resuming
+// the thread awaits the work without starting it again, also after a restart. With a
+// store that serializes checkpoints, await receives the handle in its decoded form (e.g.
+// a map[string]any for a struct handle with the JSON codec).
+//
+// Example:
+//
+//	g.AddAsyncNode("notify", "Notify warehouse",
+//	    func(ctx context.Context, s MyState) (any, error) { return api.StartJob(ctx, s.Order) },
+//	    func(ctx context.Context, h any) (MyState, error) { return api.WaitJob(ctx, h.(string)) },
+//	)
+//
+//	_, err := app.Invoke(ctx, state)
+//	var gi *graph.GraphInterrupt
+//	if errors.As(err, &gi) {
+//	    // ... later, once the webhook fired:
+//	    result, err := app.InvokeWithConfig(ctx, gi.State.(MyState), &graph.Config{
+//	        ResumeFrom:  []string{"notify"},
+//	        ResumeValue: gi.InterruptValue,
+//	    })
+//	}
+func (g *StateGraph[S]) AddAsyncNode(
+	name string,
+	description string,
+	start func(ctx context.Context, state S) (any, error),
+	await func(ctx context.Context, handle any) (S, error),
+) error {
+	return g.AddAsyncNodeWithDeadline(name, description, start, await, 0)
+}
+
+// AddAsyncNodeWithDeadline adds an async node like AddAsyncNode whose result must be
+// awaited within timeout of the work being started. A timeout of 0 means no deadline.
+// It returns the AddNode error, such as ErrGraphFrozen once the graph has been compiled.
+func (g *StateGraph[S]) AddAsyncNodeWithDeadline(
+	name string,
+	description string,
+	start func(ctx context.Context, state S) (any, error),
+	await func(ctx context.Context, handle any) (S, error),
+	timeout time.Duration,
+) error {
+	return g.AddNode(name, description, asyncNodeFunc(name, start, await, timeout))
+}
+
+// asyncNodeFunc returns the node function of an async node
+func asyncNodeFunc[S any](
+	name string,
+	start func(ctx context.Context, state S) (any, error),
+	await func(ctx context.Context, handle any) (S, error),
+	timeout time.Duration,
+) func(ctx context.Context, state S) (S, error) {
+	return func(ctx context.Context, state S) (S, error) {
+		if handle := asyncHandleFromResume(ctx, name); handle != nil {
+			return awaitAsync(ctx, handle, await)
+		}
+
+		value, err := start(ctx, state)
+		if err != nil {
+			return state, fmt.Errorf("failed to start async node %s: %w", name, err)
+		}
+
+		handle := &AsyncHandle{
+			Node:      name,
+			Handle:    value,
+			StartedAt: time.Now(),
+		}
+		if timeout > 0 {
+			handle.Deadline = handle.StartedAt.Add(timeout)
+		}
+
+		if config := GetConfig(ctx); config != nil && config.DisableInterrupts {
+			return awaitAsync(ctx, handle, await)
+		}
+
+		return state, &NodeInterrupt{Node: name, Value: handle}
+	}
+}
+
+// asyncHandleKey is the context key of the async handle of an interrupted step
+type asyncHandleKey struct{}
+
+// withInterruptedAsyncHandle records the interrupt value in ctx if it is an async handle, so
+// a checkpoint saved for the interrupted step can persist it
+func withInterruptedAsyncHandle(ctx context.Context, value any) context.Context {
+	if handle, ok := value.(*AsyncHandle); ok && handle != nil {
+		return context.WithValue(ctx, asyncHandleKey{}, handle)
+	}
+	return ctx
+}
+
+// interruptedAsyncHandle returns the async handle of the interrupted step, if any
+func interruptedAsyncHandle(ctx context.Context) *AsyncHandle {
+	handle, _ := ctx.Value(asyncHandleKey{}).(*AsyncHandle)
+	return handle
+}
+
+// asyncHandlesFromMetadata returns the async handles recorded in checkpoint metadata,
+// keyed by node. Handles loaded from a serializing store are decoded into AsyncHandle.
+func asyncHandlesFromMetadata(metadata map[string]any) map[string]*AsyncHandle {
+	switch handles := metadata[AsyncHandlesMetadataKey].(type) {
+	case map[string]*AsyncHandle:
+		return handles
+	case map[string]any:
+		result := make(map[string]*AsyncHandle, len(handles))
+		for node, h := range handles {
+			handle, err := store.DecodeState[AsyncHandle](h)
+			if err != nil || handle.Node != node {
+				continue
+			}
+			result[node] = &handle
+		}
+		return result
+	}
+	return nil
+}
+
+// asyncHandleFromResume returns the handle for the given node from the resume value, if any
+func asyncHandleFromResume(ctx context.Context, node string) *AsyncHandle {
+	switch h := GetResumeValue(ctx).(type) {
+	case *AsyncHandle:
+		if h != nil && h.Node == node {
+			return h
+		}
+	case AsyncHandle:
+		if h.Node == node {
+			return &h
+		}
+	}
+	return nil
+}
+
+// awaitAsync awaits the async work, enforcing the handle's deadline
+func awaitAsync[S any](ctx context.Context, handle *AsyncHandle, await func(ctx context.Context, handle any) (S, error)) (S, error) {
+	if !handle.Deadline.IsZero() {
+		if time.Now().After(handle.Deadline) {
+			var zero S
+			return zero, fmt.Errorf("%w: node %s", ErrAsyncDeadlineExceeded, handle.Node)
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, handle.Deadline)
+		defer cancel()
+	}
+
+	result, err := await(ctx, handle.Handle)
+	if err != nil && errors.Is(err, context.DeadlineExceeded) && !handle.Deadline.IsZero() {
+		return result, fmt.Errorf("%w: node %s: %w", ErrAsyncDeadlineExceeded, handle.Node, err)
+	}
+	return result, err
+}
@@ -0,0 +1,126 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAsyncTestGraph(started *int, timeout time.Duration) *StateRunnable[map[string]any] {
+	g := NewStateGraph[map[string]any]()
+	g.AddAsyncNodeWithDeadline("job", "Run external job",
+		func(ctx context.Context, state map[string]any) (any, error) {
+			*started++
+			return "job-42", nil
+		},
+		func(ctx context.Context, handle any) (map[string]any, error) {
+			return map[string]any{"result": handle.(string) + " done"}, nil
+		},
+		timeout,
+	)
+	g.AddNode("after", "after", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		state["after"] = true
+		return state, nil
+	})
+	g.SetEntryPoint("job")
+	g.AddEdge("job", "after")
+	g.AddEdge("after", END)
+
+	runnable, _ := g.Compile()
+	return runnable
+}
+
+func TestAsyncNode_InterruptsAndResumes(t *testing.T) {
+	var started int
+	runnable := newAsyncTestGraph(&started, 0)
+
+	_, err := runnable.Invoke(context.Background(), map[string]any{"input": "x"})
+	var gi *GraphInterrupt
+	require.True(t, errors.As(err, &gi))
+	assert.Equal(t, 1, started)
+
+	handle, ok := gi.InterruptValue.(*AsyncHandle)
+	require.True(t, ok)
+	assert.Equal(t, "job", handle.Node)
+	assert.Equal(t, "job-42", handle.Handle)
+
+	result, err := runnable.InvokeWithConfig(context.Background(), gi.State.(map[string]any), &Config{
+		ResumeFrom:  []string{"job"},
+		ResumeValue: gi.InterruptValue,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, started, "start must not run again on resume")
+	assert.Equal(t, "job-42 done", result["result"])
+	assert.Equal(t, true, result["after"])
+}
+
+func TestAsyncNode_AwaitsInlineWhenInterruptsDisabled(t *testing.T) {
+	var started int
+	runnable := newAsyncTestGraph(&started, 0)
+
+	result, err := runnable.InvokeWithConfig(context.Background(), map[string]any{}, &Config{DisableInterrupts: true})
+	require.NoError(t, err)
+	assert.Equal(t, "job-42 done", result["result"])
+}
+
+func TestAsyncNode_DeadlineExceeded(t *testing.T) {
+	var started int
+	runnable := newAsyncTestGraph(&started, time.Millisecond)
+
+	_, err := runnable.Invoke(context.Background(), map[string]any{})
+	var gi *GraphInterrupt
+	require.True(t, errors.As(err, &gi))
+
+	time.Sleep(5 * time.Millisecond)
+	_, err = runnable.InvokeWithConfig(context.Background(), map[string]any{}, &Config{
+		ResumeFrom:  []string{"job"},
+		ResumeValue: gi.InterruptValue,
+	})
+	assert.ErrorIs(t, err, ErrAsyncDeadlineExceeded)
+}
+
+func TestAsyncNode_ResumesFromFileCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	var started, awaited int
+	newRunnable := func() *CheckpointableRunnable[map[string]any] {
+		cpStore, err := NewFileCheckpointStore(dir)
+		require.NoError(t, err)
+
+		g := NewCheckpointableStateGraphWithConfig[map[string]any](CheckpointConfig{Store: cpStore, AutoSave: true})
+		g.AddAsyncNode("job", "Run external job",
+			func(ctx context.Context, state map[string]any) (any, error) {
+				started++
+				return "job-42", nil
+			},
+			func(ctx context.Context, handle any) (map[string]any, error) {
+				awaited++
+				return map[string]any{"result": handle.(string) + " done"}, nil
+			},
+		)
+		g.AddEdge("job", END)
+		g.SetEntryPoint("job")
+
+		runnable, err := g.CompileCheckpointable()
+		require.NoError(t, err)
+		return runnable
+	}
+	config := func() *Config {
+		return &Config{Configurable: map[string]any{"thread_id": "async-thread"}}
+	}
+
+	_, err := newRunnable().InvokeWithConfig(context.Background(), map[string]any{"input": "x"}, config())
+	var gi *GraphInterrupt
+	require.True(t, errors.As(err, &gi), "got %v", err)
+	assert.Equal(t, 1, started)
+
+	// A new runnable over the same directory, as after a restart
+	result, err := newRunnable().InvokeWithConfig(context.Background(), map[string]any{}, config())
+	require.NoError(t, err)
+	assert.Equal(t, 1, started, "start must not run again on resume")
+	assert.Equal(t, 1, awaited)
+	assert.Equal(t, "job-42 done", result["result"])
+}
@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchConfig configures StateRunnable.Batch
+type BatchConfig struct {
+	// MaxConcurrency is the number of inputs run at the same time (0 or less runs all at once)
+	MaxConcurrency int
+	// Config is passed to every invocation (may be nil)
+	Config *Config
+}
+
+// Batch runs every input through the graph, at most MaxConcurrency at a time, and returns
+// the final states and errors in input order. A failed input does not stop the others.
+// Once ctx is done, inputs that have not started are not run and report ctx.Err().
+//
+// Example:
+//
+//	results, errs := app.Batch(ctx, queries, graph.BatchConfig{MaxConcurrency: 4})
+//	for i := range results {
+//	    if errs[i] != nil {
+//	        log.Printf("query %d failed: %v", i, errs[i])
+//	    }
+//	}
+func (r *StateRunnable[S]) Batch(ctx context.Context, inputs []S, config BatchConfig) ([]S, []error) {
+	results := make([]S, len(inputs))
+	errs := make([]error, len(inputs))
+
+	concurrency := config.MaxConcurrency
+	if concurrency <= 0 || concurrency > len(inputs) {
+		concurrency = len(inputs)
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(concurrency, 1))
+	for i, input := range inputs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			for j := i; j < len(inputs); j++ {
+				errs[j] = err
+			}
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], errs[i] = r.InvokeWithConfig(ctx, input, config.Config)
+		}()
+	}
+	wg.Wait()
+
+	return results, errs
+}
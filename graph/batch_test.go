@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateRunnable_Batch(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	g := NewStateGraph[int]()
+	g.AddNode("square", "square", func(ctx context.Context, n int) (int, error) {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			peak := maxRunning.Load()
+			if current <= peak || maxRunning.CompareAndSwap(peak, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if n < 0 {
+			return 0, errors.New("negative input")
+		}
+		return n * n, nil
+	})
+	g.SetEntryPoint("square")
+	g.AddEdge("square", END)
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	t.Run("Preserves order and collects errors", func(t *testing.T) {
+		maxRunning.Store(0)
+		results, errs := runnable.Batch(context.Background(), []int{1, 2, -3, 4, 5, 6}, BatchConfig{MaxConcurrency: 2})
+
+		assert.Equal(t, []int{1, 4, 0, 16, 25, 36}, results)
+		for i, err := range errs {
+			if i == 2 {
+				assert.ErrorContains(t, err, "negative input")
+			} else {
+				assert.NoError(t, err)
+			}
+		}
+		assert.Equal(t, int32(2), maxRunning.Load())
+	})
+
+	t.Run("Stops dispatching when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		results, errs := runnable.Batch(ctx, []int{1, 2, 3}, BatchConfig{MaxConcurrency: 1})
+
+		assert.Equal(t, []int{0, 0, 0}, results)
+		for _, err := range errs {
+			assert.ErrorIs(t, err, context.Canceled)
+		}
+	})
+
+	t.Run("Empty input", func(t *testing.T) {
+		results, errs := runnable.Batch(context.Background(), nil, BatchConfig{})
+		assert.Empty(t, results)
+		assert.Empty(t, errs)
+	})
+}
@@ -2,6 +2,7 @@ package graph
 
 import (
 	"context"
+	"slices"
 	"time"
 )
 
@@ -32,10 +33,26 @@ type CallbackHandler interface {
 // GraphCallbackHandler extends CallbackHandler with graph-specific events
 type GraphCallbackHandler interface {
 	CallbackHandler
-	// OnGraphStep is called after a step (node execution + state update) is completed
+	// OnGraphStep is called after a step (node execution + state update) is completed.
+	// stepNode is the last node of the step; StepNodes(ctx) returns all of them.
 	OnGraphStep(ctx context.Context, stepNode string, state any)
 }
 
+// stepNodesKey is the context key of the nodes of the step passed to OnGraphStep
+type stepNodesKey struct{}
+
+// withStepNodes records the nodes of a completed step in ctx
+func withStepNodes(ctx context.Context, nodes []string) context.Context {
+	return context.WithValue(ctx, stepNodesKey{}, nodes)
+}
+
+// StepNodes returns the nodes of the completed step inside GraphCallbackHandler.OnGraphStep,
+// more than one when nodes ran in parallel
+func StepNodes(ctx context.Context) []string {
+	nodes, _ := ctx.Value(stepNodesKey{}).([]string)
+	return slices.Clone(nodes)
+}
+
 // Config represents configuration for graph invocation
 // This matches Python's config dict pattern
 type Config struct {
@@ -54,22 +71,68 @@ type Config struct {
 	// RunName for this execution
 	RunName string `json:"run_name"`
 
-	// Timeout for the execution
+	// Timeout caps the total run time of the invocation. When exceeded, in-flight nodes are
+	// cancelled through their context and an *ExecutionError wrapping a *ExecutionTimeoutError
+	// is returned
 	Timeout *time.Duration `json:"timeout"`
 
-	// InterruptBefore nodes to stop before execution
+	// InterruptBefore nodes to stop before execution, except when resuming from them
 	InterruptBefore []string `json:"interrupt_before"`
 
+	// InterruptBeforeIf stops before a node only when its predicate holds for the current
+	// state (e.g. a payment above a review threshold); use InterruptIf for typed predicates.
+	// Like InterruptBefore, it does not stop before the nodes a run resumes from, so the
+	// config that raised the interrupt can be reused to resume.
+	InterruptBeforeIf map[string]func(state any) bool `json:"-"`
+
 	// InterruptAfter nodes to stop after execution
 	InterruptAfter []string `json:"interrupt_after"`
 
-	// ResumeFrom nodes to start execution from (bypassing entry point)
+	// ResumeFrom nodes to start execution from (bypassing entry point). They run without
+	// stopping for InterruptBefore or InterruptBeforeIf, which may have raised the interrupt
 	ResumeFrom []string `json:"resume_from"`
 
 	// ResumeValue provides the value to return from an Interrupt() call when resuming
 	ResumeValue any `json:"resume_value"`
+
+	// DisableInterrupts runs the graph unattended: InterruptBefore/InterruptAfter
+	// are ignored and Interrupt() returns DefaultResumeValue instead of pausing
+	DisableInterrupts bool `json:"disable_interrupts"`
+
+	// DefaultResumeValue is returned by Interrupt() when DisableInterrupts is set
+	DefaultResumeValue any `json:"default_resume_value"`
+
+	// CollectTrace records the start time and duration of every node execution.
+	// For map[string]any states the trace is stored under TraceStateKey in the result.
+	CollectTrace bool `json:"collect_trace"`
+
+	// CollectLogs records the messages nodes log with Logf.
+	// For map[string]any states the log is stored under LogsStateKey in the result.
+	CollectLogs bool `json:"collect_logs"`
+
+	// DisablePanicRecovery lets node panics crash the process instead of being
+	// returned as a *NodePanicError (useful to fail fast during development)
+	DisablePanicRecovery bool `json:"disable_panic_recovery"`
+
+	// IdempotencyKeys lists idempotency keys (see StateGraph.SetIdempotencyKey) that already
+	// completed; nodes running with one of these keys are skipped. Checkpointable runnables
+	// fill it from the latest checkpoint when resuming a thread.
+	IdempotencyKeys []string `json:"idempotency_keys"`
+
+	// MaxSteps limits the number of node executions of an invocation, protecting cyclic graphs
+	// whose router never reaches END: the run fails with a *MaxStepsExceededError once it
+	// would exceed the limit. 0 uses DefaultMaxSteps and a negative value disables the limit.
+	MaxSteps int `json:"max_steps"`
+
+	// DetectStalls fails the run with a *StalledError when join targets wait on predecessors
+	// that can no longer run, instead of releasing them once nothing else is left to execute
+	DetectStalls bool `json:"detect_stalls"`
 }
 
+// DefaultMaxSteps is the number of node executions an invocation may run when
+// Config.MaxSteps is not set
+const DefaultMaxSteps = 10000
+
 // NoOpCallbackHandler provides a no-op implementation of CallbackHandler
 type NoOpCallbackHandler struct{}
 
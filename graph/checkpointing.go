@@ -3,6 +3,8 @@ package graph
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,19 +16,37 @@ import (
 // Checkpoint is an alias for store.Checkpoint
 type Checkpoint = store.Checkpoint
 
+// TypedCheckpoint is an alias for store.TypedCheckpoint
+type TypedCheckpoint[S any] = store.TypedCheckpoint[S]
+
 // CheckpointStore is an alias for store.CheckpointStore
 type CheckpointStore = store.CheckpointStore
 
+// StateCodec is an alias for store.StateCodec
+type StateCodec = store.StateCodec
+
 // NewMemoryCheckpointStore creates a new in-memory checkpoint store
 func NewMemoryCheckpointStore() store.CheckpointStore {
 	return memory.NewMemoryCheckpointStore()
 }
 
+// NewMemoryCheckpointStoreWithCodec creates a new in-memory checkpoint store that encodes
+// states with codec
+func NewMemoryCheckpointStoreWithCodec(codec StateCodec) store.CheckpointStore {
+	return memory.NewMemoryCheckpointStoreWithCodec(codec)
+}
+
 // NewFileCheckpointStore creates a new file-based checkpoint store
 func NewFileCheckpointStore(path string) (store.CheckpointStore, error) {
 	return file.NewFileCheckpointStore(path)
 }
 
+// NewFileCheckpointStoreWithCodec creates a new file-based checkpoint store that encodes
+// states with codec
+func NewFileCheckpointStoreWithCodec(path string, codec StateCodec) (store.CheckpointStore, error) {
+	return file.NewFileCheckpointStoreWithCodec(path, codec)
+}
+
 // CheckpointConfig configures checkpointing behavior
 type CheckpointConfig struct {
 	// Store is the checkpoint storage backend
@@ -42,6 +62,10 @@ type CheckpointConfig struct {
 	MaxCheckpoints int
 }
 
+// StepNodesMetadataKey is the checkpoint metadata key under which the nodes of a parallel
+// step are recorded; the NodeName of the checkpoint is the last of them
+const StepNodesMetadataKey = "step_nodes"
+
 // DefaultCheckpointConfig returns a default checkpoint configuration
 func DefaultCheckpointConfig() CheckpointConfig {
 	return CheckpointConfig{
@@ -65,7 +89,23 @@ type CheckpointListener[S any] struct {
 func (cl *CheckpointListener[S]) OnGraphStep(ctx context.Context, nodeName string, state any) {
 	if cl.autoSave {
 		if s, ok := state.(S); ok {
-			cl.saveCheckpoint(ctx, nodeName, s)
+			cl.saveCheckpoint(ctx, "step", nodeName, s)
+		}
+	}
+}
+
+// failedStepHandler is implemented by callbacks that record the partial state of a step in
+// which a node failed
+type failedStepHandler interface {
+	onStepFailed(ctx context.Context, nodeName string, state any)
+}
+
+// onStepFailed saves a checkpoint of a failed step, so that the idempotency keys completed in
+// it are not repeated when the thread is resumed. Resuming runs the step again.
+func (cl *CheckpointListener[S]) onStepFailed(ctx context.Context, nodeName string, state any) {
+	if cl.autoSave {
+		if s, ok := state.(S); ok {
+			cl.saveCheckpoint(ctx, "error", nodeName, s)
 		}
 	}
 }
@@ -88,7 +128,7 @@ func (cl *CheckpointListener[S]) OnRetrieverStart(context.Context, map[string]an
 func (cl *CheckpointListener[S]) OnRetrieverEnd(context.Context, []any, string)   {}
 func (cl *CheckpointListener[S]) OnRetrieverError(context.Context, error, string) {}
 
-func (cl *CheckpointListener[S]) saveCheckpoint(ctx context.Context, nodeName string, state S) {
+func (cl *CheckpointListener[S]) saveCheckpoint(ctx context.Context, event, nodeName string, state S) {
 	// Get current version from existing checkpoints
 	var checkpoints []*store.Checkpoint
 	var err error
@@ -105,13 +145,22 @@ func (cl *CheckpointListener[S]) saveCheckpoint(ctx context.Context, nodeName st
 	}
 
 	metadata := map[string]any{
-		"event": "step",
+		"event": event,
 	}
 	if cl.threadID != "" {
 		metadata["thread_id"] = cl.threadID
 	} else {
 		metadata["execution_id"] = cl.executionID
 	}
+	if keys := CompletedIdempotencyKeys(ctx); len(keys) > 0 {
+		metadata[IdempotencyKeysMetadataKey] = keys
+	}
+	if nodes := StepNodes(ctx); len(nodes) > 1 {
+		metadata[StepNodesMetadataKey] = nodes
+	}
+	if handle := interruptedAsyncHandle(ctx); handle != nil {
+		metadata[AsyncHandlesMetadataKey] = map[string]*AsyncHandle{handle.Node: handle}
+	}
 
 	checkpoint := &store.Checkpoint{
 		ID:        generateCheckpointID(),
@@ -209,8 +258,16 @@ type CheckpointableRunnable[S any] struct {
 	listener    *CheckpointListener[S]
 }
 
-// NewCheckpointableRunnable creates a new checkpointable runnable from a listenable runnable
+// NewCheckpointableRunnable creates a new checkpointable runnable from a listenable runnable.
+// If the store implements store.StateTypeSetter, it is given the state type S so loaded
+// checkpoints carry S rather than the generic form of their encoding.
 func NewCheckpointableRunnable[S any](runnable *ListenableRunnable[S], config CheckpointConfig) *CheckpointableRunnable[S] {
+	if setter, ok := config.Store.(store.StateTypeSetter); ok {
+		if stateType := reflect.TypeFor[S](); stateType.Kind() != reflect.Interface {
+			setter.SetStateType(stateType)
+		}
+	}
+
 	executionID := generateExecutionID()
 	cr := &CheckpointableRunnable[S]{
 		runnable:    runnable,
@@ -239,6 +296,14 @@ func (cr *CheckpointableRunnable[S]) Invoke(ctx context.Context, initialState S)
 
 // InvokeWithConfig executes the graph with checkpointing support and config
 func (cr *CheckpointableRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S, config *Config) (S, error) {
+	// Work on a copy, the caller's config may be reused for other invocations
+	if config != nil {
+		copied := *config
+		copied.IdempotencyKeys = slices.Clone(config.IdempotencyKeys)
+		copied.Callbacks = slices.Clone(config.Callbacks)
+		config = &copied
+	}
+
 	// Extract thread_id from config if present
 	var threadID string
 	if config != nil && config.Configurable != nil {
@@ -254,8 +319,16 @@ func (cr *CheckpointableRunnable[S]) InvokeWithConfig(ctx context.Context, initi
 		if config == nil || config.ResumeFrom == nil {
 			if latestCP, err := cr.getLatestCheckpoint(ctx, threadID); err == nil && latestCP != nil {
 				// Found existing checkpoint - this is a resume
-				checkpointState, ok := latestCP.State.(S)
-				if ok {
+				checkpointState, err := store.DecodeState[S](latestCP.State)
+				if err == nil && latestCP.State != nil {
+					// Nodes that already completed with the same idempotency key are not re-run
+					if keys := idempotencyKeysFromMetadata(latestCP.Metadata); len(keys) > 0 {
+						if config == nil {
+							config = &Config{}
+						}
+						config.IdempotencyKeys = append(config.IdempotencyKeys, keys...)
+					}
+
 					// Merge checkpoint state with new input using Schema
 					initialState = cr.mergeStates(ctx, checkpointState, initialState)
 
@@ -273,6 +346,14 @@ func (cr *CheckpointableRunnable[S]) InvokeWithConfig(ctx context.Context, initi
 						config = &Config{}
 					}
 					config.ResumeFrom = []string{latestCP.NodeName}
+					if nodes := stepNodesFromMetadata(latestCP.Metadata); len(nodes) > 0 {
+						config.ResumeFrom = nodes
+					}
+
+					// An interrupted async node awaits the work it already started
+					if handle, ok := asyncHandlesFromMetadata(latestCP.Metadata)[latestCP.NodeName]; ok && config.ResumeValue == nil {
+						config.ResumeValue = handle
+					}
 				}
 			}
 		}
@@ -293,6 +374,23 @@ func (cr *CheckpointableRunnable[S]) InvokeWithConfig(ctx context.Context, initi
 	return cr.runnable.InvokeWithConfig(ctx, initialState, config)
 }
 
+// stepNodesFromMetadata returns the nodes of a parallel step recorded in checkpoint metadata
+func stepNodesFromMetadata(metadata map[string]any) []string {
+	switch nodes := metadata[StepNodesMetadataKey].(type) {
+	case []string:
+		return nodes
+	case []any:
+		result := make([]string, 0, len(nodes))
+		for _, n := range nodes {
+			if s, ok := n.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
 // Stream executes the graph with checkpointing and streaming support
 func (cr *CheckpointableRunnable[S]) Stream(ctx context.Context, initialState S) <-chan StreamEvent[S] {
 	return cr.runnable.Stream(ctx, initialState)
@@ -479,6 +577,31 @@ func (cr *CheckpointableRunnable[S]) LoadCheckpoint(ctx context.Context, checkpo
 	return cr.config.Store.Load(ctx, checkpointID)
 }
 
+// LoadTyped loads a checkpoint by ID with its state decoded into S
+func (cr *CheckpointableRunnable[S]) LoadTyped(ctx context.Context, checkpointID string) (*TypedCheckpoint[S], error) {
+	cp, err := cr.config.Store.Load(ctx, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	return store.NewTypedCheckpoint[S](cp)
+}
+
+// ListTyped lists all checkpoints for the current execution with their states decoded into S
+func (cr *CheckpointableRunnable[S]) ListTyped(ctx context.Context) ([]*TypedCheckpoint[S], error) {
+	checkpoints, err := cr.config.Store.List(ctx, cr.executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	typed := make([]*TypedCheckpoint[S], len(checkpoints))
+	for i, cp := range checkpoints {
+		if typed[i], err = store.NewTypedCheckpoint[S](cp); err != nil {
+			return nil, err
+		}
+	}
+	return typed, nil
+}
+
 // ClearCheckpoints removes all checkpoints for this execution
 func (cr *CheckpointableRunnable[S]) ClearCheckpoints(ctx context.Context) error {
 	return cr.config.Store.Clear(ctx, cr.executionID)
@@ -563,6 +686,32 @@ func (cr *CheckpointableRunnable[S]) UpdateState(ctx context.Context, config *Co
 	}, nil
 }
 
+// HistoryEntry is a single step of a checkpointed execution and the state changes it made
+type HistoryEntry struct {
+	Node string
+	Diff store.StateDiff
+}
+
+// History returns the ordered (node, state diff) pairs of the current thread,
+// or of the current execution if no thread_id has been used.
+func (cr *CheckpointableRunnable[S]) History(ctx context.Context) ([]HistoryEntry, error) {
+	id := cr.executionID
+	if cr.listener != nil && cr.listener.threadID != "" {
+		id = cr.listener.threadID
+	}
+
+	steps, err := store.Diff(ctx, cr.config.Store, id)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]HistoryEntry, len(steps))
+	for i, step := range steps {
+		history[i] = HistoryEntry{Node: step.NodeName, Diff: step.Diff}
+	}
+	return history, nil
+}
+
 // GetExecutionID returns the current execution ID
 func (cr *CheckpointableRunnable[S]) GetExecutionID() string {
 	return cr.executionID
@@ -576,6 +725,11 @@ func (cr *CheckpointableRunnable[S]) SetExecutionID(executionID string) {
 	}
 }
 
+// LastTrace returns the execution trace of the most recent invocation that collected one
+func (cr *CheckpointableRunnable[S]) LastTrace() []TraceEntry {
+	return cr.runnable.LastTrace()
+}
+
 // GetTracer returns the tracer from the underlying runnable
 func (cr *CheckpointableRunnable[S]) GetTracer() *Tracer {
 	return cr.runnable.GetTracer()
@@ -637,6 +791,23 @@ func WithInterruptBefore(nodes ...string) *Config {
 	}
 }
 
+// InterruptIf adapts a typed predicate for Config.InterruptBeforeIf.
+// The predicate is false for states of another type.
+//
+// Example:
+//
+//	config := &graph.Config{
+//	    InterruptBeforeIf: map[string]func(any) bool{
+//	        "payment": graph.InterruptIf(func(s OrderState) bool { return s.Amount > 1000 && !s.Approved }),
+//	    },
+//	}
+func InterruptIf[S any](predicate func(S) bool) func(any) bool {
+	return func(state any) bool {
+		s, ok := state.(S)
+		return ok && predicate(s)
+	}
+}
+
 // WithInterruptAfter creates a Config with interrupt points set after specified nodes.
 //
 // Example:
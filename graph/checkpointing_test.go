@@ -603,6 +603,58 @@ func TestCheckpointableRunnable_LoadCheckpoint(t *testing.T) {
 	}
 }
 
+func TestCheckpointableRunnable_LoadTyped(t *testing.T) {
+	t.Parallel()
+
+	type orderState struct {
+		OrderID string
+		Steps   []string
+	}
+
+	g := graph.NewListenableStateGraph[orderState]()
+	g.AddNode(testNode, testNode, func(ctx context.Context, state orderState) (orderState, error) {
+		state.Steps = append(state.Steps, testNode)
+		return state, nil
+	})
+	g.AddEdge(testNode, graph.END)
+	g.SetEntryPoint(testNode)
+
+	listenableRunnable, err := g.CompileListenable()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	// A file store returns states in their generic JSON form
+	fileStore, err := graph.NewFileCheckpointStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create file checkpoint store: %v", err)
+	}
+	config := graph.DefaultCheckpointConfig()
+	config.Store = fileStore
+	checkpointableRunnable := graph.NewCheckpointableRunnable(listenableRunnable, config)
+
+	ctx := context.Background()
+	if _, err := checkpointableRunnable.Invoke(ctx, orderState{OrderID: "o-1"}); err != nil {
+		t.Fatalf("Failed to invoke: %v", err)
+	}
+
+	checkpoints, err := checkpointableRunnable.ListTyped(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list typed checkpoints: %v", err)
+	}
+	if len(checkpoints) == 0 {
+		t.Fatal("No checkpoints found")
+	}
+
+	loaded, err := checkpointableRunnable.LoadTyped(ctx, checkpoints[0].ID)
+	if err != nil {
+		t.Fatalf("Failed to load typed checkpoint: %v", err)
+	}
+	if loaded.State.OrderID != "o-1" || !slices.Equal(loaded.State.Steps, []string{testNode}) {
+		t.Errorf("Unexpected typed state: %+v", loaded.State)
+	}
+}
+
 func TestCheckpointableRunnable_ClearCheckpoints(t *testing.T) {
 	t.Parallel()
 
@@ -1386,3 +1438,137 @@ func TestMaxCheckpoints_AutoCleanup_RedisStore(t *testing.T) {
 		t.Errorf("Expected latest checkpoint by thread to be step5")
 	}
 }
+
+func TestCheckpointableRunnable_History(t *testing.T) {
+	t.Parallel()
+
+	g := graph.NewCheckpointableStateGraph[map[string]any]()
+	g.AddNode("step1", "step1", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"a": 1}, nil
+	})
+	g.AddNode("step2", "step2", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"a": 1, "b": 2}, nil
+	})
+	g.AddEdge("step1", "step2")
+	g.AddEdge("step2", graph.END)
+	g.SetEntryPoint("step1")
+
+	runnable, err := g.CompileCheckpointable()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := runnable.Invoke(ctx, map[string]any{}); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+
+	history, err := runnable.History(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 history entries, got %d", len(history))
+	}
+	if history[0].Node != "step1" || history[1].Node != "step2" {
+		t.Errorf("Unexpected node order: %s, %s", history[0].Node, history[1].Node)
+	}
+	if _, ok := history[1].Diff.Added["b"]; !ok || len(history[1].Diff.Added) != 1 {
+		t.Errorf("Expected step2 to add only 'b', got %v", history[1].Diff)
+	}
+}
+
+type codecOrderState struct {
+	OrderID string
+	Price   float64
+	Steps   []string
+}
+
+func TestCheckpointStoreDecodesStateType(t *testing.T) {
+	t.Parallel()
+
+	for name, codec := range map[string]graph.StateCodec{
+		"json": st.JSONCodec{},
+		"gob":  st.GobCodec{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			store, err := graph.NewFileCheckpointStoreWithCodec(t.TempDir(), codec)
+			if err != nil {
+				t.Fatalf("Failed to create store: %v", err)
+			}
+
+			g := graph.NewCheckpointableStateGraphWithConfig[codecOrderState](graph.CheckpointConfig{Store: store, AutoSave: true})
+			g.AddNode("price", "price", func(ctx context.Context, state codecOrderState) (codecOrderState, error) {
+				state.Price = 42.5
+				state.Steps = append(state.Steps, "price")
+				return state, nil
+			})
+			g.AddEdge("price", graph.END)
+			g.SetEntryPoint("price")
+
+			runnable, err := g.CompileCheckpointable()
+			if err != nil {
+				t.Fatalf("Failed to compile: %v", err)
+			}
+			ctx := context.Background()
+			if _, err := runnable.Invoke(ctx, codecOrderState{OrderID: "A-1"}); err != nil {
+				t.Fatalf("Failed to invoke: %v", err)
+			}
+
+			checkpoints, err := runnable.ListCheckpoints(ctx)
+			if err != nil || len(checkpoints) == 0 {
+				t.Fatalf("Expected checkpoints, got %v, %v", checkpoints, err)
+			}
+			state, ok := checkpoints[len(checkpoints)-1].State.(codecOrderState)
+			if !ok {
+				t.Fatalf("Expected a codecOrderState, got %T", checkpoints[len(checkpoints)-1].State)
+			}
+			if state.OrderID != "A-1" || state.Price != 42.5 || !slices.Equal(state.Steps, []string{"price"}) {
+				t.Errorf("Unexpected state %+v", state)
+			}
+		})
+	}
+}
+
+func TestCheckpointParallelStepNodeName(t *testing.T) {
+	t.Parallel()
+
+	g := graph.NewCheckpointableStateGraph[map[string]any]()
+	for _, name := range []string{"start", "a", "b"} {
+		g.AddNode(name, name, func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			return map[string]any{name: true}, nil
+		})
+	}
+	g.AddEdge("start", "a")
+	g.AddEdge("start", "b")
+	g.AddEdge("a", graph.END)
+	g.AddEdge("b", graph.END)
+	g.SetEntryPoint("start")
+
+	runnable, err := g.CompileCheckpointable()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := runnable.Invoke(ctx, map[string]any{}); err != nil {
+		t.Fatalf("Failed to invoke: %v", err)
+	}
+
+	checkpoints, err := runnable.ListCheckpoints(ctx)
+	if err != nil || len(checkpoints) != 2 {
+		t.Fatalf("Expected 2 checkpoints, got %v, %v", checkpoints, err)
+	}
+	step := checkpoints[1]
+	if step.NodeName != "a" && step.NodeName != "b" {
+		t.Errorf("Expected the node name of a step node, got %q", step.NodeName)
+	}
+	nodes, _ := step.Metadata[graph.StepNodesMetadataKey].([]string)
+	slices.Sort(nodes)
+	if !slices.Equal(nodes, []string{"a", "b"}) {
+		t.Errorf("Expected step nodes [a b], got %v", step.Metadata[graph.StepNodesMetadataKey])
+	}
+	if _, ok := checkpoints[0].Metadata[graph.StepNodesMetadataKey]; ok {
+		t.Error("Expected no step nodes for a single-node step")
+	}
+}
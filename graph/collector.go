@@ -0,0 +1,76 @@
+package graph
+
+import (
+	"context"
+	"maps"
+	"sync"
+)
+
+// collector accumulates entries of type E, such as trace entries or node log messages,
+// for a single invocation.
+type collector[E any] struct {
+	mu      sync.Mutex
+	entries []E
+}
+
+func (c *collector[E]) record(entry E) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = append(c.entries, entry)
+}
+
+func (c *collector[E]) snapshot() []E {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := make([]E, len(c.entries))
+	copy(entries, c.entries)
+	return entries
+}
+
+// collectorKey is the context key of the collector of entries of type E
+type collectorKey[E any] struct{}
+
+func withCollector[E any](ctx context.Context, c *collector[E]) context.Context {
+	return context.WithValue(ctx, collectorKey[E]{}, c)
+}
+
+func getCollector[E any](ctx context.Context) *collector[E] {
+	if c, ok := ctx.Value(collectorKey[E]{}).(*collector[E]); ok {
+		return c
+	}
+	return nil
+}
+
+// ensureCollector returns ctx with a new collector of entries of type E when enabled
+// and ctx does not carry one yet
+func ensureCollector[E any](ctx context.Context, enabled bool) context.Context {
+	if enabled && getCollector[E](ctx) == nil {
+		return withCollector(ctx, &collector[E]{})
+	}
+	return ctx
+}
+
+// attachCollected stores the entries collected in ctx in map states under key.
+// Other state types are returned unchanged.
+func attachCollected[E, S any](ctx context.Context, state S, key string) S {
+	m, ok := any(state).(map[string]any)
+	if !ok {
+		return state
+	}
+	var entries []E
+	if c := getCollector[E](ctx); c != nil {
+		entries = c.snapshot()
+	}
+	result := make(map[string]any, len(m)+1)
+	maps.Copy(result, m)
+	result[key] = entries
+	return any(result).(S)
+}
+
+// invokeCollecting executes the graph with a new collector of entries of type E and
+// returns the final state together with the collected entries, even if the execution fails.
+func invokeCollecting[E, S any](ctx context.Context, r *StateRunnable[S], initialState S, config *Config) (S, []E, error) {
+	c := &collector[E]{}
+	state, err := r.InvokeWithConfig(withCollector(ctx, c), initialState, config)
+	return state, c.snapshot(), err
+}
@@ -0,0 +1,73 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileWithOptions_NodeHooks(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("a", "a", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"a": true}, nil
+	})
+	g.AddNode("b", "b", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return nil, errors.New("boom")
+	})
+	g.AddEdge("a", "b")
+	g.AddEdge("b", END)
+	g.SetEntryPoint("a")
+
+	var mu sync.Mutex
+	var started, ended []string
+	var endErrs []error
+	app, err := g.CompileWithOptions(CompileOptions[map[string]any]{
+		OnNodeStart: func(ctx context.Context, node string, state map[string]any) {
+			mu.Lock()
+			defer mu.Unlock()
+			started = append(started, node)
+		},
+		OnNodeEnd: func(ctx context.Context, node string, state map[string]any, d time.Duration, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			ended = append(ended, node)
+			endErrs = append(endErrs, err)
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = app.Invoke(context.Background(), map[string]any{})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"a", "b"}, started)
+	assert.Equal(t, []string{"a", "b"}, ended)
+	assert.NoError(t, endErrs[0])
+	assert.EqualError(t, endErrs[1], "boom")
+}
+
+func TestCompileWithOptions_OnInterrupt(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("a", "a", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return state, nil
+	})
+	g.AddEdge("a", END)
+	g.SetEntryPoint("a")
+
+	var got *GraphInterrupt
+	app, err := g.CompileWithOptions(CompileOptions[map[string]any]{
+		OnInterrupt: func(ctx context.Context, interrupt *GraphInterrupt) {
+			got = interrupt
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = app.InvokeWithConfig(context.Background(), map[string]any{}, WithInterruptBefore("a"))
+	var gi *GraphInterrupt
+	require.ErrorAs(t, err, &gi)
+	require.NotNil(t, got)
+	assert.Equal(t, "a", got.Node)
+}
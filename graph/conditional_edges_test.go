@@ -337,3 +337,37 @@ func TestConditionalEdges_ChainedConditions(t *testing.T) {
 		t.Errorf("Expected result -10, got %v", result)
 	}
 }
+
+func TestRouteByThreshold(t *testing.T) {
+	t.Parallel()
+
+	g := graph.NewStateGraph[float64]()
+	g.AddNode("analyze", "analyze", func(ctx context.Context, confidence float64) (float64, error) {
+		return confidence, nil
+	})
+	g.AddNode("answer", "answer", func(ctx context.Context, confidence float64) (float64, error) {
+		return 1, nil
+	})
+	g.AddNode("escalate", "escalate", func(ctx context.Context, confidence float64) (float64, error) {
+		return -1, nil
+	})
+	g.SetEntryPoint("analyze")
+	g.AddConditionalEdge("analyze", graph.RouteByThreshold(func(c float64) float64 { return c }, 0.6, "answer", "escalate"))
+	g.AddEdge("answer", graph.END)
+	g.AddEdge("escalate", graph.END)
+
+	runnable, err := g.Compile()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	for confidence, want := range map[float64]float64{0.9: 1, 0.6: 1, 0.3: -1} {
+		got, err := runnable.Invoke(context.Background(), confidence)
+		if err != nil {
+			t.Fatalf("Invoke(%v) failed: %v", confidence, err)
+		}
+		if got != want {
+			t.Errorf("Invoke(%v) = %v, want %v", confidence, got, want)
+		}
+	}
+}
@@ -122,6 +122,23 @@
 //	// Execute with context
 //	result, err := runnable.Invoke(context.Background(), initialState)
 //
+// Human-in-the-Loop
+//
+//	// Interrupts work on any compiled graph; no checkpoint store is needed as long as the
+//	// caller keeps the interrupted state. The entry point can be interrupted too.
+//	config := &graph.Config{InterruptBefore: []string{"human_approval"}}
+//	_, err := runnable.InvokeWithConfig(ctx, initialState, config)
+//
+//	var interrupt *graph.GraphInterrupt
+//	if errors.As(err, &interrupt) {
+//		state := interrupt.State.(MyState) // state before human_approval runs
+//		state.Approved = true
+//
+//		// Continue from interrupt.NextNodes with the updated state. The same config can be
+//		// reused: human_approval runs now, and later visits stop before it again.
+//		result, err = runnable.InvokeWithConfig(ctx, state, interrupt.Resume(config))
+//	}
+//
 // Streaming
 //
 //	// Create listenable graph for streaming
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -245,6 +246,124 @@ func TestPanicRecovery(t *testing.T) {
 	}
 }
 
+// TestNodePanicError tests that panics are returned as *NodePanicError with node and stack
+func TestNodePanicError(t *testing.T) {
+	t.Parallel()
+
+	g := graph.NewStateGraph[map[string]any]()
+	g.AddNode("bad_assert", "bad_assert", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		_ = state["trace"].([]string)
+		return state, nil
+	})
+	g.AddEdge("bad_assert", graph.END)
+	g.SetEntryPoint("bad_assert")
+
+	var hookErr error
+	runnable, err := g.CompileWithOptions(graph.CompileOptions[map[string]any]{
+		OnNodeEnd: func(ctx context.Context, node string, state map[string]any, duration time.Duration, err error) {
+			hookErr = err
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	_, err = runnable.Invoke(context.Background(), map[string]any{"trace": "not a slice"})
+	var panicErr *graph.NodePanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Expected NodePanicError, got: %v", err)
+	}
+	if panicErr.Node != "bad_assert" || len(panicErr.Stack) == 0 {
+		t.Errorf("Unexpected panic error: node=%q stack=%d bytes", panicErr.Node, len(panicErr.Stack))
+	}
+	var runtimeErr runtime.Error
+	if !errors.As(err, &runtimeErr) {
+		t.Errorf("Expected panic value to unwrap to runtime.Error, got: %v", err)
+	}
+	if !errors.As(hookErr, &panicErr) {
+		t.Errorf("Expected OnNodeEnd to receive the panic error, got: %v", hookErr)
+	}
+}
+
+// TestExecutionErrorPartialState tests that a failed run exposes the state accumulated so far
+func TestExecutionErrorPartialState(t *testing.T) {
+	t.Parallel()
+
+	errReport := errors.New("report engine failed")
+	g := graph.NewStateGraph[map[string]any]()
+	g.AddNode("research", "research", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"paragraphs": []string{"p1", "p2"}}, nil
+	})
+	g.AddNode("report", "report", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return nil, errReport
+	})
+	g.AddEdge("research", "report")
+	g.AddEdge("report", graph.END)
+	g.SetEntryPoint("research")
+
+	runnable, err := g.Compile()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	_, err = runnable.Invoke(context.Background(), map[string]any{})
+	var execErr *graph.ExecutionError
+	if !errors.As(err, &execErr) {
+		t.Fatalf("Expected ExecutionError, got: %v", err)
+	}
+	if !errors.Is(err, errReport) {
+		t.Errorf("Expected error to wrap the node error, got: %v", err)
+	}
+	if execErr.Node != "report" {
+		t.Errorf("Expected failed node report, got %q", execErr.Node)
+	}
+	partial, ok := execErr.PartialState.(map[string]any)
+	if !ok || len(partial["paragraphs"].([]string)) != 2 {
+		t.Errorf("Expected partial state with paragraphs, got: %v", execErr.PartialState)
+	}
+}
+
+// TestExecutionErrorMatching tests that wrapped run errors still match with errors.Is and
+// errors.As, and that interrupts are returned unwrapped
+func TestExecutionErrorMatching(t *testing.T) {
+	t.Parallel()
+
+	newRunnable := func(fn func(ctx context.Context, state map[string]any) (map[string]any, error), next string) *graph.StateRunnable[map[string]any] {
+		g := graph.NewStateGraph[map[string]any]()
+		g.AddNode("step", "step", fn)
+		g.AddEdge("step", next)
+		g.SetEntryPoint("step")
+		runnable, err := g.Compile()
+		if err != nil {
+			t.Fatalf("Failed to compile: %v", err)
+		}
+		return runnable
+	}
+	ok := func(ctx context.Context, state map[string]any) (map[string]any, error) { return state, nil }
+
+	_, err := newRunnable(ok, "missing").Invoke(context.Background(), map[string]any{})
+	if !errors.Is(err, graph.ErrNodeNotFound) {
+		t.Errorf("Expected errors.Is to match ErrNodeNotFound, got: %v", err)
+	}
+
+	_, err = newRunnable(func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		panic("boom")
+	}, graph.END).Invoke(context.Background(), map[string]any{})
+	var panicErr *graph.NodePanicError
+	if !errors.As(err, &panicErr) || panicErr.Node != "step" {
+		t.Errorf("Expected errors.As to find a NodePanicError for step, got: %v", err)
+	}
+
+	_, err = newRunnable(ok, graph.END).InvokeWithConfig(context.Background(), map[string]any{}, graph.WithInterruptBefore("step"))
+	if _, isInterrupt := err.(*graph.GraphInterrupt); !isInterrupt {
+		t.Errorf("Expected an unwrapped GraphInterrupt, got: %T", err)
+	}
+	var execErr *graph.ExecutionError
+	if errors.As(err, &execErr) {
+		t.Error("Expected the interrupt not to be wrapped in an ExecutionError")
+	}
+}
+
 // TestComplexConditionalRouting tests complex conditional edge scenarios
 func TestComplexConditionalRouting(t *testing.T) {
 	t.Parallel()
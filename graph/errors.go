@@ -1,6 +1,12 @@
 package graph
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
 
 // NodeInterrupt is returned when a node requests an interrupt (e.g. waiting for human input).
 type NodeInterrupt struct {
@@ -13,3 +19,176 @@ type NodeInterrupt struct {
 func (e *NodeInterrupt) Error() string {
 	return fmt.Sprintf("interrupt at node %s: %v", e.Node, e.Value)
 }
+
+// NodePanicError is returned when a node panics and panic recovery is enabled.
+type NodePanicError struct {
+	// Node is the name of the node that panicked
+	Node string
+	// Value is the value passed to panic
+	Value any
+	// Stack is the goroutine stack at the time of the panic
+	Stack []byte
+}
+
+func (e *NodePanicError) Error() string {
+	return fmt.Sprintf("panic in node %s: %v", e.Node, e.Value)
+}
+
+// Unwrap returns the panic value if it is an error
+func (e *NodePanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// StalledError is returned when Config.DetectStalls is set and the graph cannot make progress:
+// no node is runnable, END has not been reached, and the remaining join targets wait on
+// predecessors that will never run.
+type StalledError struct {
+	// Blocked maps each blocked join target to its predecessors that have not completed
+	Blocked map[string][]string
+}
+
+func (e *StalledError) Error() string {
+	targets := make([]string, 0, len(e.Blocked))
+	for target := range e.Blocked {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	parts := make([]string, len(targets))
+	for i, target := range targets {
+		parts[i] = fmt.Sprintf("%s waits on %v", target, e.Blocked[target])
+	}
+	return "graph stalled: " + strings.Join(parts, "; ")
+}
+
+// CycleError is returned when compiling a graph that requires acyclicity (see
+// RequireAcyclic) and contains a cycle.
+type CycleError struct {
+	// Cycle lists the nodes of the cycle, starting and ending with the same node
+	Cycle []string
+}
+
+func (e *CycleError) Error() string {
+	return "graph has a cycle: " + strings.Join(e.Cycle, " -> ")
+}
+
+// UnsatisfiedReadsError is returned by Compile when nodes declared with AddNodeWithIO read
+// state keys that are neither graph inputs nor written by a node that can run before them.
+type UnsatisfiedReadsError struct {
+	// Missing maps each node to the keys it reads that nothing provides
+	Missing map[string][]string
+}
+
+func (e *UnsatisfiedReadsError) Error() string {
+	nodes := make([]string, 0, len(e.Missing))
+	for node := range e.Missing {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	parts := make([]string, len(nodes))
+	for i, node := range nodes {
+		parts[i] = fmt.Sprintf("%s reads %v", node, e.Missing[node])
+	}
+	return "state keys read before they are written: " + strings.Join(parts, "; ")
+}
+
+// CompensationError is returned, joined to the run error, when the compensation of a node
+// fails after a run failed (see AddNodeWithCompensation).
+type CompensationError struct {
+	// Node is the node whose compensation failed
+	Node string
+	// Err is the error returned by the compensation
+	Err error
+}
+
+func (e *CompensationError) Error() string {
+	return fmt.Sprintf("compensation of node %s failed: %v", e.Node, e.Err)
+}
+
+// Unwrap returns the error of the compensation
+func (e *CompensationError) Unwrap() error {
+	return e.Err
+}
+
+// NodeTimeoutError is returned when a node exceeds its NodeOptions.Timeout.
+type NodeTimeoutError struct {
+	// Node is the node that timed out
+	Node string
+	// Timeout is the configured limit
+	Timeout time.Duration
+	// Elapsed is how long the node ran before the executor gave up on it
+	Elapsed time.Duration
+}
+
+func (e *NodeTimeoutError) Error() string {
+	return fmt.Sprintf("node %s timed out after %s", e.Node, e.Elapsed.Round(time.Millisecond))
+}
+
+// Unwrap returns context.DeadlineExceeded
+func (e *NodeTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// ExecutionTimeoutError is returned, wrapped in an *ExecutionError, when an invocation
+// exceeds Config.Timeout. The PartialState of the ExecutionError is the state after the
+// last completed step.
+type ExecutionTimeoutError struct {
+	// Timeout is the configured limit
+	Timeout time.Duration
+	// Nodes are the nodes that were running when the deadline passed
+	Nodes []string
+	// LastCompletedNodes are the nodes of the last step that completed, more than one when
+	// they ran in parallel; the latest checkpoint of checkpointable graphs is for this step
+	LastCompletedNodes []string
+}
+
+func (e *ExecutionTimeoutError) Error() string {
+	return fmt.Sprintf("graph execution timed out after %s while running %s", e.Timeout, strings.Join(e.Nodes, ", "))
+}
+
+// Unwrap returns context.DeadlineExceeded
+func (e *ExecutionTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// MaxStepsExceededError is returned when an invocation would execute more nodes than
+// Config.MaxSteps, typically because a conditional edge keeps routing back into a loop.
+type MaxStepsExceededError struct {
+	// Steps is the number of node executions allowed, which the run has reached
+	Steps int
+	// LastNode is the last node that executed before the run was stopped
+	LastNode string
+}
+
+func (e *MaxStepsExceededError) Error() string {
+	return fmt.Sprintf("graph execution exceeded %d steps (last node: %s)", e.Steps, e.LastNode)
+}
+
+// ExecutionError is returned when a graph run fails after it started executing nodes.
+// It carries the state accumulated so far, so callers can salvage completed work.
+//
+// Because run errors are wrapped, match them with errors.Is and errors.As rather than
+// comparisons or type assertions. A *GraphInterrupt is control flow, not a failure, and is
+// returned unwrapped.
+type ExecutionError struct {
+	// Node is the node that failed, if the failure is attributable to a single node
+	Node string
+	// PartialState is the state after the last completed step, including the updates of
+	// nodes that succeeded in the failing step. Its type is the graph's state type.
+	PartialState any
+	// Err is the underlying error
+	Err error
+}
+
+func (e *ExecutionError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *ExecutionError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,51 @@
+package graph
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// TraceStateKey is the key under which the execution trace is stored in
+// map[string]any results when Config.CollectTrace is enabled.
+const TraceStateKey = "_trace"
+
+// TraceEntry records the execution of a single node.
+type TraceEntry struct {
+	// Node is the name of the executed node
+	Node string
+
+	// Start is when the node started executing
+	Start time.Time
+
+	// Duration is how long the node took
+	Duration time.Duration
+
+	// Err is the error returned by the node, if any
+	Err error
+}
+
+// InvokeWithTrace executes the graph and returns the final state together with
+// the execution trace of every node that ran, in completion order.
+// The trace is returned even if the execution fails.
+func (r *StateRunnable[S]) InvokeWithTrace(ctx context.Context, initialState S, config *Config) (S, []TraceEntry, error) {
+	return invokeCollecting[TraceEntry](ctx, r, initialState, config)
+}
+
+// LastTrace returns the execution trace of the most recent invocation that collected one
+// (with Config.CollectTrace or InvokeWithTrace), in completion order, or nil if none did.
+// The entries' Start times order nodes that ran in parallel. When invocations run
+// concurrently, it is the trace of the one that finished last.
+//
+// Example:
+//
+//	_, err := runnable.InvokeWithConfig(ctx, state, &graph.Config{CollectTrace: true})
+//	for _, entry := range runnable.LastTrace() {
+//	    fmt.Println(entry.Node, entry.Duration, entry.Err)
+//	}
+func (r *StateRunnable[S]) LastTrace() []TraceEntry {
+	if trace := r.lastTrace.Load(); trace != nil {
+		return slices.Clone(*trace)
+	}
+	return nil
+}
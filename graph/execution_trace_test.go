@@ -0,0 +1,131 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectTrace(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("a", "a", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"a": 1}, nil
+	})
+	g.AddNode("b", "b", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"b": 2}, nil
+	})
+	g.AddEdge("a", "b")
+	g.AddEdge("b", END)
+	g.SetEntryPoint("a")
+
+	app, err := g.Compile()
+	require.NoError(t, err)
+
+	t.Run("Map state carries trace", func(t *testing.T) {
+		res, err := app.InvokeWithConfig(context.Background(), map[string]any{}, &Config{CollectTrace: true})
+		require.NoError(t, err)
+
+		trace, ok := res[TraceStateKey].([]TraceEntry)
+		require.True(t, ok)
+		require.Len(t, trace, 2)
+		assert.Equal(t, "a", trace[0].Node)
+		assert.Equal(t, "b", trace[1].Node)
+		assert.False(t, trace[0].Start.IsZero())
+	})
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		res, err := app.Invoke(context.Background(), map[string]any{})
+		require.NoError(t, err)
+		assert.NotContains(t, res, TraceStateKey)
+	})
+}
+
+func TestInvokeWithTrace(t *testing.T) {
+	type state struct{ N int }
+	g := NewStateGraph[state]()
+	g.AddNode("inc", "inc", func(ctx context.Context, s state) (state, error) {
+		s.N++
+		return s, nil
+	})
+	g.AddNode("fail", "fail", func(ctx context.Context, s state) (state, error) {
+		return s, errors.New("boom")
+	})
+	g.AddEdge("inc", "fail")
+	g.AddEdge("fail", END)
+	g.SetEntryPoint("inc")
+
+	app, err := g.Compile()
+	require.NoError(t, err)
+
+	_, trace, err := app.InvokeWithTrace(context.Background(), state{}, nil)
+	assert.Error(t, err)
+	require.Len(t, trace, 2)
+	assert.NoError(t, trace[0].Err)
+	assert.Equal(t, "fail", trace[1].Node)
+	assert.Error(t, trace[1].Err)
+}
+
+func TestLastTrace(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	for _, name := range []string{"route", "left", "right"} {
+		g.AddNode(name, name, func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			return state, nil
+		})
+	}
+	g.AddConditionalEdge("route", func(ctx context.Context, state map[string]any) string {
+		if state["side"] == "left" {
+			return "left"
+		}
+		return "right"
+	})
+	g.AddEdge("left", END)
+	g.AddEdge("right", END)
+	g.SetEntryPoint("route")
+
+	app, err := g.Compile()
+	require.NoError(t, err)
+
+	_, err = app.Invoke(context.Background(), map[string]any{"side": "left"})
+	require.NoError(t, err)
+	assert.Nil(t, app.LastTrace(), "no trace without CollectTrace")
+
+	_, err = app.InvokeWithConfig(context.Background(), map[string]any{"side": "left"}, &Config{CollectTrace: true})
+	require.NoError(t, err)
+	trace := app.LastTrace()
+	require.Len(t, trace, 2)
+	assert.Equal(t, "route", trace[0].Node)
+	assert.Equal(t, "left", trace[1].Node)
+
+	_, _, err = app.InvokeWithTrace(context.Background(), map[string]any{"side": "right"}, nil)
+	require.NoError(t, err)
+	trace = app.LastTrace()
+	require.Len(t, trace, 2)
+	assert.Equal(t, "right", trace[1].Node)
+
+	// Invocations without a trace keep the last one
+	_, err = app.Invoke(context.Background(), map[string]any{"side": "left"})
+	require.NoError(t, err)
+	assert.Equal(t, "right", app.LastTrace()[1].Node)
+}
+
+func TestLastTraceListenable(t *testing.T) {
+	g := NewListenableStateGraph[map[string]any]()
+	g.AddNode("fail", "fail", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return nil, errors.New("boom")
+	})
+	g.AddEdge("fail", END)
+	g.SetEntryPoint("fail")
+
+	app, err := g.CompileListenable()
+	require.NoError(t, err)
+
+	_, err = app.InvokeWithConfig(context.Background(), map[string]any{}, &Config{CollectTrace: true})
+	require.Error(t, err)
+	trace := app.LastTrace()
+	require.Len(t, trace, 1)
+	assert.Equal(t, "fail", trace[0].Node)
+	assert.Error(t, trace[0].Err)
+}
@@ -0,0 +1,98 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+)
+
+// RunErrorStateKey is the key under which the final node of a map[string]any graph finds
+// the error of a failed run
+const RunErrorStateKey = "_error"
+
+type runErrorKey struct{}
+
+// SetFinalNode sets a node that runs at the end of every invocation, like a defer: after
+// the graph completes and after it fails, so it can write audit records or release
+// resources on every path. It does not run when the graph is interrupted, but when the
+// resumed run ends. The final node should not be reachable through edges.
+//
+// After a failure, the node gets the partial state of the failed run and finds the error
+// with RunError (and, for map states, under RunErrorStateKey); the invocation still
+// returns that error.
+//
+// It returns ErrGraphFrozen, leaving the final node unchanged, once the graph has been compiled.
+func (g *StateGraph[S]) SetFinalNode(name string) error {
+	if g.frozen {
+		return fmt.Errorf("%w: cannot set final node %s", ErrGraphFrozen, name)
+	}
+	g.finalNode = name
+	return nil
+}
+
+// RunError returns the error of the failed run inside the final node, or nil if the run succeeded
+func RunError(ctx context.Context) error {
+	err, _ := ctx.Value(runErrorKey{}).(error)
+	return err
+}
+
+// runFinalNode runs the final node after the run ended with state and runErr
+func (r *StateRunnable[S]) runFinalNode(ctx context.Context, state S, runErr error, config *Config) (S, error) {
+	var interrupt *GraphInterrupt
+	if errors.As(runErr, &interrupt) {
+		return state, runErr
+	}
+
+	name := r.graph.finalNode
+	node, ok := r.graph.nodes[name]
+	if !ok {
+		return state, errors.Join(runErr, fmt.Errorf("%w: final node %s", ErrNodeNotFound, name))
+	}
+
+	if config != nil {
+		ctx = WithConfig(ctx, config)
+	}
+	input := state
+	if runErr != nil {
+		var execErr *ExecutionError
+		if errors.As(runErr, &execErr) {
+			if partial, ok := execErr.PartialState.(S); ok {
+				input = partial
+			}
+		}
+		input = attachRunError(input, runErr)
+		ctx = context.WithValue(ctx, runErrorKey{}, runErr)
+	}
+
+	result, err := r.callNode(withNodeLogger(ctx, name), node, input)
+	if err != nil {
+		err = fmt.Errorf("final node %s failed: %w", name, err)
+		if runErr != nil {
+			return state, errors.Join(runErr, err)
+		}
+		return r.fail(name, state, err)
+	}
+	if runErr != nil {
+		return state, runErr
+	}
+
+	merged, err := r.mergeState(ctx, state, []S{result})
+	if err != nil {
+		return r.fail(name, state, err)
+	}
+	return merged, nil
+}
+
+// attachRunError stores the run error in map states under RunErrorStateKey.
+// Other state types are returned unchanged.
+func attachRunError[S any](state S, err error) S {
+	m, ok := any(state).(map[string]any)
+	if !ok {
+		return state
+	}
+	result := make(map[string]any, len(m)+1)
+	maps.Copy(result, m)
+	result[RunErrorStateKey] = err
+	return any(result).(S)
+}
@@ -0,0 +1,101 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateGraph_SetFinalNode(t *testing.T) {
+	errPayment := errors.New("payment declined")
+
+	var audits []string
+	newGraph := func(fail bool) *StateGraph[map[string]any] {
+		g := NewStateGraph[map[string]any]()
+		g.SetSchema(NewMapSchema())
+		g.AddNode("validate", "validate", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			return map[string]any{"validated": true}, nil
+		})
+		g.AddNode("charge", "charge", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			if fail {
+				return nil, errPayment
+			}
+			return map[string]any{"charged": true}, nil
+		})
+		g.AddNode("audit", "audit", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			if err := RunError(ctx); err != nil {
+				assert.Equal(t, err, state[RunErrorStateKey])
+				assert.Equal(t, true, state["validated"], "the final node gets the partial state")
+				audits = append(audits, "failed: "+err.Error())
+			} else {
+				audits = append(audits, "completed")
+			}
+			return map[string]any{"audited": true}, nil
+		})
+		g.AddEdge("validate", "charge")
+		g.AddEdge("charge", END)
+		g.SetEntryPoint("validate")
+		g.SetFinalNode("audit")
+		return g
+	}
+
+	t.Run("Runs after completion", func(t *testing.T) {
+		audits = nil
+		app, err := newGraph(false).Compile()
+		require.NoError(t, err)
+
+		res, err := app.Invoke(context.Background(), map[string]any{})
+		require.NoError(t, err)
+		assert.Equal(t, true, res["charged"])
+		assert.Equal(t, true, res["audited"])
+		assert.Equal(t, []string{"completed"}, audits)
+	})
+
+	t.Run("Runs after an error", func(t *testing.T) {
+		audits = nil
+		app, err := newGraph(true).Compile()
+		require.NoError(t, err)
+
+		_, err = app.Invoke(context.Background(), map[string]any{})
+		assert.ErrorIs(t, err, errPayment)
+		require.Len(t, audits, 1)
+		assert.Contains(t, audits[0], "payment declined")
+	})
+
+	t.Run("Waits for an interrupted run to resume", func(t *testing.T) {
+		audits = nil
+		app, err := newGraph(false).Compile()
+		require.NoError(t, err)
+
+		res, err := app.InvokeWithConfig(context.Background(), map[string]any{}, &Config{InterruptBefore: []string{"charge"}})
+		var interrupt *GraphInterrupt
+		require.ErrorAs(t, err, &interrupt)
+		assert.Empty(t, audits)
+
+		_, err = app.InvokeWithConfig(context.Background(), res, &Config{ResumeFrom: []string{"charge"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"completed"}, audits)
+	})
+
+	t.Run("Final node errors are reported", func(t *testing.T) {
+		g := NewStateGraph[int]()
+		g.AddNode("work", "work", func(ctx context.Context, state int) (int, error) { return state + 1, nil })
+		g.AddNode("cleanup", "cleanup", func(ctx context.Context, state int) (int, error) {
+			return state, errors.New("disk full")
+		})
+		g.AddEdge("work", END)
+		g.SetEntryPoint("work")
+		g.SetFinalNode("cleanup")
+		app, err := g.Compile()
+		require.NoError(t, err)
+
+		_, err = app.Invoke(context.Background(), 1)
+		var execErr *ExecutionError
+		require.ErrorAs(t, err, &execErr)
+		assert.Equal(t, "cleanup", execErr.Node)
+		assert.ErrorContains(t, err, "disk full")
+	})
+}
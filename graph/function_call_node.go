@@ -0,0 +1,90 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// ErrNoFunctionCall is returned when the model neither calls the function nor returns JSON arguments
+var ErrNoFunctionCall = errors.New("model did not call the function")
+
+// NewFunctionCallNode creates a node function that uses the model's native function calling
+// to obtain structured output instead of parsing free text.
+//
+// The model is forced to call fn with the messages returned by getMessages. The call
+// arguments are decoded into T and passed to handler, which returns the new state.
+// If the model answers with plain text instead of a function call, the first JSON object
+// in the text is decoded as a fallback.
+//
+// Example:
+//
+//	type Decision struct {
+//	    Action    string  `json:"action"`
+//	    Amount    float64 `json:"amount"`
+//	    Reasoning string  `json:"reasoning"`
+//	}
+//
+//	g.AddNode("analyst", "Analyst decision", graph.NewFunctionCallNode(model,
+//	    llms.FunctionDefinition{Name: "decide", Description: "Record the trading decision", Parameters: schema},
+//	    func(s State) []llms.MessageContent { return s.Messages },
+//	    func(ctx context.Context, s State, d Decision) (State, error) {
+//	        s.Decision = d
+//	        return s, nil
+//	    },
+//	))
+func NewFunctionCallNode[S, T any](
+	model llms.Model,
+	fn llms.FunctionDefinition,
+	getMessages func(S) []llms.MessageContent,
+	handler func(ctx context.Context, state S, args T) (S, error),
+) func(ctx context.Context, state S) (S, error) {
+	tool := llms.Tool{Type: "function", Function: &fn}
+	toolChoice := llms.ToolChoice{Type: "function", Function: &llms.FunctionReference{Name: fn.Name}}
+
+	return func(ctx context.Context, state S) (S, error) {
+		resp, err := model.GenerateContent(ctx, getMessages(state), llms.WithTools([]llms.Tool{tool}), llms.WithToolChoice(toolChoice))
+		if err != nil {
+			return state, fmt.Errorf("failed to call model: %w", err)
+		}
+		if len(resp.Choices) == 0 {
+			return state, fmt.Errorf("%w %s: empty response", ErrNoFunctionCall, fn.Name)
+		}
+
+		arguments, err := functionArguments(resp.Choices[0], fn.Name)
+		if err != nil {
+			return state, err
+		}
+
+		var args T
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return state, fmt.Errorf("failed to decode %s arguments: %w", fn.Name, err)
+		}
+		return handler(ctx, state, args)
+	}
+}
+
+// functionArguments returns the JSON arguments of the named function call in choice,
+// falling back to a JSON object embedded in the text content
+func functionArguments(choice *llms.ContentChoice, name string) (string, error) {
+	for _, tc := range choice.ToolCalls {
+		if tc.FunctionCall != nil && tc.FunctionCall.Name == name {
+			return tc.FunctionCall.Arguments, nil
+		}
+	}
+	if choice.FuncCall != nil && choice.FuncCall.Name == name {
+		return choice.FuncCall.Arguments, nil
+	}
+
+	content := choice.Content
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start >= 0 && end > start && json.Valid([]byte(content[start:end+1])) {
+		return content[start : end+1], nil
+	}
+	return "", fmt.Errorf("%w %s", ErrNoFunctionCall, name)
+}
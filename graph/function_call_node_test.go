@@ -0,0 +1,73 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+type functionCallModel struct {
+	choice  *llms.ContentChoice
+	options llms.CallOptions
+}
+
+func (m *functionCallModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	for _, opt := range options {
+		opt(&m.options)
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{m.choice}}, nil
+}
+
+func (m *functionCallModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return "", nil
+}
+
+type tradeDecision struct {
+	Action    string  `json:"action"`
+	Amount    float64 `json:"amount"`
+	Reasoning string  `json:"reasoning"`
+}
+
+func newTradeNode(model llms.Model) func(ctx context.Context, state map[string]any) (map[string]any, error) {
+	return NewFunctionCallNode(model,
+		llms.FunctionDefinition{Name: "decide", Parameters: map[string]any{"type": "object"}},
+		func(state map[string]any) []llms.MessageContent {
+			return []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "trade?")}
+		},
+		func(ctx context.Context, state map[string]any, d tradeDecision) (map[string]any, error) {
+			return map[string]any{"decision": d}, nil
+		},
+	)
+}
+
+func TestFunctionCallNode(t *testing.T) {
+	t.Run("Decodes native function call", func(t *testing.T) {
+		model := &functionCallModel{choice: &llms.ContentChoice{ToolCalls: []llms.ToolCall{{
+			FunctionCall: &llms.FunctionCall{Name: "decide", Arguments: `{"action":"buy","amount":10,"reasoning":"cheap"}`},
+		}}}}
+
+		result, err := newTradeNode(model)(context.Background(), map[string]any{})
+		require.NoError(t, err)
+		assert.Equal(t, tradeDecision{Action: "buy", Amount: 10, Reasoning: "cheap"}, result["decision"])
+		require.Len(t, model.options.Tools, 1)
+		assert.Equal(t, "decide", model.options.Tools[0].Function.Name)
+	})
+
+	t.Run("Falls back to JSON content", func(t *testing.T) {
+		model := &functionCallModel{choice: &llms.ContentChoice{Content: "Sure:\n```json\n{\"action\":\"hold\",\"amount\":0}\n```"}}
+
+		result, err := newTradeNode(model)(context.Background(), map[string]any{})
+		require.NoError(t, err)
+		assert.Equal(t, "hold", result["decision"].(tradeDecision).Action)
+	})
+
+	t.Run("No function call", func(t *testing.T) {
+		model := &functionCallModel{choice: &llms.ContentChoice{Content: "I would buy."}}
+
+		_, err := newTradeNode(model)(context.Background(), map[string]any{})
+		assert.ErrorIs(t, err, ErrNoFunctionCall)
+	})
+}
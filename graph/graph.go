@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 )
 
 // END is a special constant used to represent the end node in the graph.
@@ -18,6 +19,13 @@ var (
 
 	// ErrNoOutgoingEdge is returned when no outgoing edge is found for a node.
 	ErrNoOutgoingEdge = errors.New("no outgoing edge found for node")
+
+	// ErrUndeclaredTargets is returned when compiling a graph that requires acyclicity and has a
+	// conditional edge whose targets were not declared with SetConditionalTargets.
+	ErrUndeclaredTargets = errors.New("conditional edge targets not declared")
+
+	// ErrGraphFrozen is returned when a graph definition is changed after it has been compiled.
+	ErrGraphFrozen = errors.New("graph is frozen after compilation")
 )
 
 // GraphInterrupt is returned when execution is interrupted by configuration or dynamic interrupt
@@ -26,7 +34,9 @@ type GraphInterrupt struct {
 	Node string
 	// State at the time of interruption
 	State any
-	// NextNodes that would have been executed if not interrupted
+	// NextNodes are the nodes the executor would run next: the nodes of the interrupted step
+	// for InterruptBefore and dynamic interrupts, the successors of Node for InterruptAfter.
+	// Resume continues from them.
 	NextNodes []string
 	// InterruptValue is the value provided by the dynamic interrupt (if any)
 	InterruptValue any
@@ -39,12 +49,34 @@ func (e *GraphInterrupt) Error() string {
 	return fmt.Sprintf("graph interrupted at node %s", e.Node)
 }
 
+// Resume returns a copy of config (which may be nil) that continues the interrupted run from
+// nextNodes, or from NextNodes when none are given. Pass the returned config to
+// InvokeWithConfig with the state to resume. The resumed nodes run even if config lists
+// them in InterruptBefore or InterruptBeforeIf, so the config that raised the interrupt can
+// be passed; later steps still stop at its interrupts.
+func (e *GraphInterrupt) Resume(config *Config, nextNodes ...string) *Config {
+	resumed := &Config{}
+	if config != nil {
+		*resumed = *config
+	}
+	if len(nextNodes) == 0 {
+		nextNodes = e.NextNodes
+	}
+	resumed.ResumeFrom = slices.Clone(nextNodes)
+	return resumed
+}
+
 // Interrupt pauses execution and waits for input.
 // If resuming, it returns the value provided in the resume command.
+// If interrupts are disabled via Config.DisableInterrupts, it returns
+// Config.DefaultResumeValue without pausing.
 func Interrupt(ctx context.Context, value any) (any, error) {
 	if resumeVal := GetResumeValue(ctx); resumeVal != nil {
 		return resumeVal, nil
 	}
+	if config := GetConfig(ctx); config != nil && config.DisableInterrupts {
+		return config.DefaultResumeValue, nil
+	}
 	return nil, &NodeInterrupt{Value: value}
 }
 
@@ -87,17 +119,17 @@ type ListenableStateGraphMap = ListenableStateGraph[map[string]any]
 type ListenableRunnableMap = ListenableRunnable[map[string]any]
 
 // NewMessageGraph creates a new instance of StateGraph[map[string]any] with a default schema
-// that handles "messages" using the AddMessages reducer.
+// that handles "messages" using the MessageReducer.
 // This is the recommended constructor for chat-based agents that use
 // map[string]any as state with a "messages" key.
 //
-// Deprecated: Use NewStateGraph[MessageState]() for type-safe state management.
+// Deprecated: Use NewStateGraph[MessagesState]() with NewMessagesStateSchema() for type-safe state management.
 func NewMessageGraph() *StateGraph[map[string]any] {
 	g := NewStateGraph[map[string]any]()
 
 	// Initialize default schema for message handling
 	schema := NewMapSchema()
-	schema.RegisterReducer("messages", AddMessages)
+	schema.RegisterReducer("messages", MessageReducer)
 
 	g.SetSchema(schema)
 
@@ -0,0 +1,272 @@
+// Package httpstream serves the events of a streaming graph run to HTTP clients as
+// Server-Sent Events (SSE).
+//
+// Each graph event is written as an SSE message whose event name is the node event (e.g.
+// "start", "complete", "token") and whose data is the event as JSON. The run ends with a
+// "result" message carrying the final state or an "error" message. Messages are numbered,
+// so a client that reconnects with the Last-Event-ID header (which browsers' EventSource
+// sends automatically) receives the messages it missed. Comment lines are sent as
+// heartbeats to keep proxies from closing idle connections, and the run is cancelled once
+// its clients have disconnected.
+//
+// Example:
+//
+//	http.HandleFunc("/run", func(w http.ResponseWriter, r *http.Request) {
+//	    result := runnable.Stream(r.Context(), map[string]any{"input": r.URL.Query().Get("q")})
+//	    httpstream.Serve(w, r, result, httpstream.DefaultOptions())
+//	})
+package httpstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+// Options configures how a run is served
+type Options struct {
+	// Heartbeat is the interval of the comment lines sent while no event is written
+	// (0 disables heartbeats)
+	Heartbeat time.Duration
+	// ReconnectGrace is how long a run keeps going after its last client disconnected,
+	// waiting for it to reconnect. 0 cancels the run as soon as no client is connected.
+	ReconnectGrace time.Duration
+	// OmitState leaves the state out of node events, which keeps messages small for large
+	// states. The final "result" message always carries the state.
+	OmitState bool
+}
+
+// DefaultOptions returns the default options: a heartbeat every 15 seconds and no
+// reconnect grace period
+func DefaultOptions() Options {
+	return Options{
+		Heartbeat: 15 * time.Second,
+	}
+}
+
+// Event is the JSON data of a node event message
+type Event struct {
+	Node       string         `json:"node,omitempty"`
+	Event      string         `json:"event"`
+	Timestamp  time.Time      `json:"timestamp"`
+	DurationMs int64          `json:"duration_ms,omitempty"`
+	State      any            `json:"state,omitempty"`
+	Metadata   map[string]any `json:"metadata,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// message is an SSE message of a run
+type message struct {
+	id    int
+	event string
+	data  []byte
+}
+
+// Run serves one streaming graph run to any number of SSE connections. It consumes the
+// run's events as soon as it is created and keeps every message, so connections that
+// start late or reconnect with Last-Event-ID replay what they missed. To make a run
+// resumable across requests, keep the Run (e.g. in a map keyed by a run ID) and set
+// Options.ReconnectGrace.
+type Run[S any] struct {
+	result *graph.StreamResult[S]
+	opts   Options
+
+	mu       sync.Mutex
+	messages []message
+	finished bool
+	updated  chan struct{} // closed and replaced whenever a message is added
+	clients  int
+	idle     *time.Timer
+	done     chan struct{}
+}
+
+// NewRun starts consuming the events of result
+func NewRun[S any](result *graph.StreamResult[S], opts Options) *Run[S] {
+	r := &Run[S]{
+		result:  result,
+		opts:    opts,
+		updated: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go r.consume()
+	return r
+}
+
+// Serve streams a run to a single client and cancels it when the client disconnects.
+// It returns when the run has ended or the client is gone.
+func Serve[S any](w http.ResponseWriter, req *http.Request, result *graph.StreamResult[S], opts Options) {
+	NewRun(result, opts).ServeHTTP(w, req)
+}
+
+// Done returns a channel closed when the run has ended and its last message was recorded
+func (r *Run[S]) Done() <-chan struct{} {
+	return r.done
+}
+
+// Cancel stops the run
+func (r *Run[S]) Cancel() {
+	if r.result.Cancel != nil {
+		r.result.Cancel()
+	}
+}
+
+// ServeHTTP streams the messages of the run to the client, starting after the message
+// named by the Last-Event-ID header (or the "last_event_id" query parameter), until the
+// run ends or the client disconnects.
+func (r *Run[S]) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	next := lastEventID(req)
+	r.connect()
+	defer r.disconnect()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var heartbeat <-chan time.Time
+	if r.opts.Heartbeat > 0 {
+		ticker := time.NewTicker(r.opts.Heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		r.mu.Lock()
+		pending := r.messages[min(next, len(r.messages)):]
+		finished, updated := r.finished, r.updated
+		r.mu.Unlock()
+
+		for _, msg := range pending {
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.id, msg.event, msg.data); err != nil {
+				return
+			}
+			next = msg.id
+		}
+		if len(pending) > 0 {
+			flusher.Flush()
+		}
+		if finished {
+			return
+		}
+
+		select {
+		case <-updated:
+		case <-heartbeat:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-req.Context().Done():
+			return
+		}
+	}
+}
+
+// lastEventID returns the id of the last message the client received, 0 if none
+func lastEventID(req *http.Request) int {
+	value := req.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = req.URL.Query().Get("last_event_id")
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || id < 0 {
+		return 0
+	}
+	return id
+}
+
+// connect registers a client, stopping a pending idle cancellation
+func (r *Run[S]) connect() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients++
+	if r.idle != nil {
+		r.idle.Stop()
+		r.idle = nil
+	}
+}
+
+// disconnect unregisters a client and cancels the unfinished run once no client is left
+// for the reconnect grace period
+func (r *Run[S]) disconnect() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients--
+	if r.clients > 0 || r.finished {
+		return
+	}
+	if r.opts.ReconnectGrace <= 0 {
+		r.Cancel()
+		return
+	}
+	r.idle = time.AfterFunc(r.opts.ReconnectGrace, r.Cancel)
+}
+
+// consume records the events of the run, then its result or error
+func (r *Run[S]) consume() {
+	for event := range r.result.Events {
+		data := Event{
+			Node:       event.NodeName,
+			Event:      string(event.Event),
+			Timestamp:  event.Timestamp,
+			DurationMs: event.Duration.Milliseconds(),
+			Metadata:   event.Metadata,
+		}
+		if !r.opts.OmitState {
+			data.State = event.State
+		}
+		if event.Error != nil {
+			data.Error = event.Error.Error()
+		}
+		r.add(string(event.Event), data, false)
+	}
+
+	if state, ok := <-r.result.Result; ok {
+		r.add("result", map[string]any{"state": state}, true)
+		return
+	}
+	err, ok := <-r.result.Errors
+	if !ok {
+		err = context.Canceled
+	}
+	r.add("error", map[string]any{"error": err.Error()}, true)
+}
+
+// add appends a message and wakes up the connections waiting for it
+func (r *Run[S]) add(event string, data any, last bool) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		encoded, _ = json.Marshal(map[string]any{
+			"event": event,
+			"error": fmt.Sprintf("failed to encode event: %v", err),
+		})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, message{id: len(r.messages) + 1, event: event, data: encoded})
+	if last {
+		r.finished = true
+		if r.idle != nil {
+			r.idle.Stop()
+			r.idle = nil
+		}
+		close(r.done)
+	}
+	close(r.updated)
+	r.updated = make(chan struct{})
+}
@@ -0,0 +1,136 @@
+package httpstream
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sseMessage struct {
+	id, event, data string
+}
+
+// readMessages reads SSE messages until the stream ends, skipping comment lines
+func readMessages(t *testing.T, resp *http.Response) []sseMessage {
+	t.Helper()
+	var messages []sseMessage
+	var msg sseMessage
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if msg.event != "" {
+				messages = append(messages, msg)
+			}
+			msg = sseMessage{}
+		case strings.HasPrefix(line, "id: "):
+			msg.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			msg.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			msg.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	return messages
+}
+
+func newRunnable(t *testing.T, node func(ctx context.Context, s map[string]any) (map[string]any, error)) *graph.StreamingRunnable[map[string]any] {
+	t.Helper()
+	g := graph.NewStreamingStateGraph[map[string]any]()
+	g.AddNode("answer", "answer", node)
+	g.SetEntryPoint("answer")
+	g.AddEdge("answer", graph.END)
+	runnable, err := g.CompileStreaming()
+	require.NoError(t, err)
+	return runnable
+}
+
+func TestServe(t *testing.T) {
+	runnable := newRunnable(t, func(ctx context.Context, s map[string]any) (map[string]any, error) {
+		return map[string]any{"answer": "42"}, nil
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Serve(w, r, runnable.Stream(r.Context(), map[string]any{}), DefaultOptions())
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	messages := readMessages(t, resp)
+	require.Len(t, messages, 3)
+	assert.Equal(t, sseMessage{"1", "start", messages[0].data}, messages[0])
+	assert.Contains(t, messages[0].data, `"node":"answer"`)
+	assert.Equal(t, "complete", messages[1].event)
+	assert.Contains(t, messages[1].data, `"state":{"answer":"42"}`)
+	assert.Equal(t, sseMessage{"3", "result", `{"state":{"answer":"42"}}`}, messages[2])
+}
+
+func TestRun_Resume(t *testing.T) {
+	release := make(chan struct{})
+	runnable := newRunnable(t, func(ctx context.Context, s map[string]any) (map[string]any, error) {
+		<-release
+		return map[string]any{"answer": "42"}, nil
+	})
+	run := NewRun(runnable.Stream(context.Background(), map[string]any{}), Options{OmitState: true, ReconnectGrace: time.Minute})
+	server := httptest.NewServer(run)
+	defer server.Close()
+	close(release)
+	<-run.Done()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	messages := readMessages(t, resp)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "2", messages[0].id)
+	assert.NotContains(t, messages[0].data, "state")
+	assert.Equal(t, "result", messages[1].event)
+}
+
+func TestServe_CancelsOnDisconnect(t *testing.T) {
+	cancelled := make(chan struct{})
+	runnable := newRunnable(t, func(ctx context.Context, s map[string]any) (map[string]any, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return s, ctx.Err()
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Detach from the request context so only the disconnect handling can cancel the run
+		Serve(w, r, runnable.Stream(context.Background(), map[string]any{}), Options{Heartbeat: 10 * time.Millisecond})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "id: 1\n", line)
+	cancel()
+	resp.Body.Close()
+
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("run was not cancelled after the client disconnected")
+	}
+}
@@ -0,0 +1,141 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// IdempotencyKeysMetadataKey is the checkpoint metadata key holding the idempotency keys of
+// nodes that completed successfully
+const IdempotencyKeysMetadataKey = "idempotency_keys"
+
+// SetIdempotencyKey declares an idempotency key for a node, derived from the state the node
+// is about to run with. Once the node has completed with a key, it is skipped (its update is
+// not applied again) whenever it would run with the same key, e.g. when a checkpointed graph
+// is resumed after a crash or retried after an error.
+//
+// Use it for nodes with side effects that must happen at most once, such as charging a card:
+//
+//	g.SetIdempotencyKey("payment_processing", func(s OrderState) string { return s.OrderID })
+//
+// It returns ErrGraphFrozen, without declaring the key, once the graph has been compiled.
+func (g *StateGraph[S]) SetIdempotencyKey(node string, keyFn func(state S) string) error {
+	if g.frozen {
+		return fmt.Errorf("%w: cannot set idempotency key of %s", ErrGraphFrozen, node)
+	}
+	if g.idempotencyKeys == nil {
+		g.idempotencyKeys = make(map[string]func(S) string)
+	}
+	g.idempotencyKeys[node] = keyFn
+	return nil
+}
+
+// idempotencyLedger records the idempotency keys that completed during a run
+type idempotencyLedger struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (l *idempotencyLedger) contains(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Contains(l.keys, key)
+}
+
+func (l *idempotencyLedger) add(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !slices.Contains(l.keys, key) {
+		l.keys = append(l.keys, key)
+	}
+}
+
+func (l *idempotencyLedger) snapshot() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.keys)
+}
+
+type idempotencyLedgerKey struct{}
+
+func withIdempotencyLedger(ctx context.Context, ledger *idempotencyLedger) context.Context {
+	return context.WithValue(ctx, idempotencyLedgerKey{}, ledger)
+}
+
+func getIdempotencyLedger(ctx context.Context) *idempotencyLedger {
+	ledger, _ := ctx.Value(idempotencyLedgerKey{}).(*idempotencyLedger)
+	return ledger
+}
+
+// CompletedIdempotencyKeys returns the idempotency keys completed so far in the current run
+func CompletedIdempotencyKeys(ctx context.Context) []string {
+	if ledger := getIdempotencyLedger(ctx); ledger != nil {
+		return ledger.snapshot()
+	}
+	return nil
+}
+
+// idempotencyKey returns the scoped idempotency key of a node for the given state,
+// or "" if the node has none
+func (r *StateRunnable[S]) idempotencyKey(node string, state S) string {
+	keyFn, ok := r.graph.idempotencyKeys[node]
+	if !ok {
+		return ""
+	}
+	key := keyFn(state)
+	if key == "" {
+		return ""
+	}
+	return node + ":" + key
+}
+
+// skipCompleted splits nodes into those to execute and the keys of the others.
+// Nodes whose idempotency key already completed are dropped.
+func (r *StateRunnable[S]) skipCompleted(ctx context.Context, nodes []string, state S) ([]string, []string) {
+	ledger := getIdempotencyLedger(ctx)
+	if ledger == nil {
+		return nodes, make([]string, len(nodes))
+	}
+
+	run := make([]string, 0, len(nodes))
+	keys := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		key := r.idempotencyKey(node, state)
+		if key != "" && ledger.contains(key) {
+			continue
+		}
+		run = append(run, node)
+		keys = append(keys, key)
+	}
+	return run, keys
+}
+
+// completedKeys reports whether a node of a step completed with an idempotency key
+func completedKeys(keys []string, errorsList []error) bool {
+	for i, key := range keys {
+		if key != "" && errorsList[i] == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// idempotencyKeysFromMetadata reads the idempotency keys stored in checkpoint metadata,
+// which are []any after a JSON round-trip
+func idempotencyKeysFromMetadata(metadata map[string]any) []string {
+	switch keys := metadata[IdempotencyKeysMetadataKey].(type) {
+	case []string:
+		return keys
+	case []any:
+		result := make([]string, 0, len(keys))
+		for _, k := range keys {
+			if s, ok := k.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	t.Run("Skips completed keys from config", func(t *testing.T) {
+		g := graph.NewStateGraph[map[string]any]()
+		runs := 0
+		g.AddNode("notify", "notify", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			runs++
+			state["notified"] = true
+			return state, nil
+		})
+		g.AddEdge("notify", graph.END)
+		g.SetEntryPoint("notify")
+		g.SetIdempotencyKey("notify", func(state map[string]any) string { return state["order"].(string) })
+
+		runnable, err := g.Compile()
+		require.NoError(t, err)
+
+		result, err := runnable.InvokeWithConfig(context.Background(), map[string]any{"order": "o1"},
+			&graph.Config{IdempotencyKeys: []string{"notify:o1"}})
+		require.NoError(t, err)
+		assert.Equal(t, 0, runs)
+		assert.Nil(t, result["notified"])
+
+		_, err = runnable.Invoke(context.Background(), map[string]any{"order": "o2"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, runs)
+	})
+
+	t.Run("Resume does not repeat side effects", func(t *testing.T) {
+		g := graph.NewCheckpointableStateGraph[map[string]any]()
+		g.SetSchema(graph.NewMapSchema())
+		charges, shipAttempts := 0, 0
+		g.AddNode("payment_processing", "charge", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			charges++
+			state["charged"] = true
+			return state, nil
+		})
+		g.AddNode("warehouse_notify", "ship", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			shipAttempts++
+			if shipAttempts == 1 {
+				return state, errors.New("warehouse unavailable")
+			}
+			state["shipped"] = true
+			return state, nil
+		})
+		g.AddEdge("payment_processing", "warehouse_notify")
+		g.AddEdge("warehouse_notify", graph.END)
+		g.SetEntryPoint("payment_processing")
+		g.SetIdempotencyKey("payment_processing", func(state map[string]any) string { return fmt.Sprint(state["order"]) })
+
+		runnable, err := g.CompileCheckpointable()
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_, err = runnable.InvokeWithConfig(ctx, map[string]any{"order": "o1"}, graph.WithThreadID("order-o1"))
+		require.Error(t, err)
+
+		// The latest checkpoint is after payment_processing, which auto-resume would re-run
+		config := graph.WithThreadID("order-o1")
+		result, err := runnable.InvokeWithConfig(ctx, map[string]any{}, config)
+		require.NoError(t, err)
+		assert.Equal(t, 1, charges)
+		assert.Equal(t, true, result["shipped"])
+		assert.Equal(t, true, result["charged"])
+
+		// The caller's config is left as it was
+		assert.Empty(t, config.IdempotencyKeys)
+		assert.Empty(t, config.ResumeFrom)
+		assert.Empty(t, config.Callbacks)
+	})
+
+	t.Run("Resume after a failing sibling does not repeat side effects", func(t *testing.T) {
+		g := graph.NewCheckpointableStateGraph[map[string]any]()
+		g.SetSchema(graph.NewMapSchema())
+		charges, shipAttempts := 0, 0
+		g.AddNode("checkout", "checkout", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			return map[string]any{}, nil
+		})
+		g.AddNode("payment_processing", "charge", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			charges++
+			return map[string]any{"charged": true}, nil
+		})
+		g.AddNode("warehouse_notify", "ship", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			shipAttempts++
+			if shipAttempts == 1 {
+				return nil, errors.New("warehouse unavailable")
+			}
+			return map[string]any{"shipped": true}, nil
+		})
+		// payment_processing and warehouse_notify run in the same step
+		g.AddEdge("checkout", "payment_processing")
+		g.AddEdge("checkout", "warehouse_notify")
+		g.AddEdge("payment_processing", graph.END)
+		g.AddEdge("warehouse_notify", graph.END)
+		g.SetEntryPoint("checkout")
+		g.SetIdempotencyKey("payment_processing", func(state map[string]any) string { return fmt.Sprint(state["order"]) })
+
+		runnable, err := g.CompileCheckpointable()
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_, err = runnable.InvokeWithConfig(ctx, map[string]any{"order": "o2"}, graph.WithThreadID("order-o2"))
+		require.Error(t, err)
+		assert.Equal(t, 1, charges)
+
+		result, err := runnable.InvokeWithConfig(ctx, map[string]any{}, graph.WithThreadID("order-o2"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, charges)
+		assert.Equal(t, 2, shipAttempts)
+		assert.Equal(t, true, result["charged"])
+		assert.Equal(t, true, result["shipped"])
+	})
+}
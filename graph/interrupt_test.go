@@ -72,3 +72,37 @@ func TestGraphInterrupt(t *testing.T) {
 		assert.Equal(t, "StartAB", res["value"])
 	})
 }
+
+func TestGraphInterrupt_Disabled(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("review", "review", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		answer, err := Interrupt(ctx, "approve?")
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"answer": answer}, nil
+	})
+	g.SetEntryPoint("review")
+	g.AddEdge("review", END)
+
+	runnable, err := g.Compile()
+	assert.NoError(t, err)
+
+	t.Run("Review mode pauses", func(t *testing.T) {
+		_, err := runnable.InvokeWithConfig(context.Background(), map[string]any{}, &Config{})
+		var interrupt *GraphInterrupt
+		assert.ErrorAs(t, err, &interrupt)
+		assert.Equal(t, "approve?", interrupt.InterruptValue)
+	})
+
+	t.Run("Autonomous mode continues", func(t *testing.T) {
+		config := &Config{
+			DisableInterrupts:  true,
+			DefaultResumeValue: "approved",
+			InterruptBefore:    []string{"review"},
+		}
+		res, err := runnable.InvokeWithConfig(context.Background(), map[string]any{}, config)
+		assert.NoError(t, err)
+		assert.Equal(t, "approved", res["answer"])
+	})
+}
@@ -0,0 +1,167 @@
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// AddJoin declares that target runs only after all requiredPredecessors have completed.
+//
+// Each required predecessor routes to target when it completes (in addition to its own
+// edges), and target is held back until every predecessor has run, so it executes once
+// even when the branches have different lengths. If a predecessor never runs (e.g. it was
+// skipped by conditional routing), target is released as soon as no other nodes remain
+// to execute, unless Config.DetectStalls is set, in which case the run fails with a
+// *StalledError naming the missing predecessors.
+//
+// Join progress is tracked per invocation and is not restored when resuming from an interrupt.
+//
+// Example:
+//
+//	g.AddEdge("start", "search")
+//	g.AddEdge("start", "summarize")
+//	g.AddEdge("search", "rank")
+//	g.AddJoin("aggregate", []string{"rank", "summarize"})
+//
+// It returns ErrGraphFrozen, without adding the join, once the graph has been compiled.
+func (g *StateGraph[S]) AddJoin(target string, requiredPredecessors []string) error {
+	if g.frozen {
+		return fmt.Errorf("%w: cannot add join %s", ErrGraphFrozen, target)
+	}
+	if g.joins == nil {
+		g.joins = make(map[string][]string)
+	}
+	g.joins[target] = slices.Clone(requiredPredecessors)
+	return nil
+}
+
+// joinTargetsOf returns the join targets that node is a required predecessor of
+func (g *StateGraph[S]) joinTargetsOf(node string) []string {
+	var targets []string
+	for target, preds := range g.joins {
+		if slices.Contains(preds, node) {
+			targets = append(targets, target)
+		}
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// joinTracker holds join targets back until their predecessors have completed
+type joinTracker struct {
+	joins   map[string][]string
+	arrived map[string]map[string]bool
+	pending map[string]bool
+}
+
+func newJoinTracker(joins map[string][]string) *joinTracker {
+	return &joinTracker{
+		joins:   joins,
+		arrived: make(map[string]map[string]bool),
+		pending: make(map[string]bool),
+	}
+}
+
+// complete records that nodes finished executing
+func (t *joinTracker) complete(nodes []string) {
+	for target, preds := range t.joins {
+		for _, node := range nodes {
+			if !slices.Contains(preds, node) {
+				continue
+			}
+			if t.arrived[target] == nil {
+				t.arrived[target] = make(map[string]bool)
+			}
+			t.arrived[target][node] = true
+			t.pending[target] = true
+		}
+	}
+}
+
+// gate removes join targets from next and adds back those that are ready to run
+func (t *joinTracker) gate(next []string) []string {
+	if len(t.joins) == 0 {
+		return next
+	}
+
+	gated := make([]string, 0, len(next))
+	active := false
+	for _, node := range next {
+		if _, isJoin := t.joins[node]; isJoin {
+			t.pending[node] = true
+			continue
+		}
+		gated = append(gated, node)
+		if node != END {
+			active = true
+		}
+	}
+
+	var ready []string
+	for target := range t.pending {
+		if !active || t.satisfied(target) {
+			ready = append(ready, target)
+		}
+	}
+	sort.Strings(ready)
+
+	for _, target := range ready {
+		delete(t.pending, target)
+		delete(t.arrived, target)
+		gated = append(gated, target)
+	}
+	return gated
+}
+
+// stall returns a *StalledError if nothing in next can run and every pending join target
+// still misses predecessors, or nil if the graph can make progress
+func (t *joinTracker) stall(next []string) *StalledError {
+	for _, node := range next {
+		if _, isJoin := t.joins[node]; !isJoin && node != END {
+			return nil
+		}
+	}
+
+	blocked := make(map[string][]string)
+	for _, target := range t.blockedTargets(next) {
+		if t.satisfied(target) {
+			return nil
+		}
+		var missing []string
+		for _, pred := range t.joins[target] {
+			if !t.arrived[target][pred] {
+				missing = append(missing, pred)
+			}
+		}
+		blocked[target] = missing
+	}
+	if len(blocked) == 0 {
+		return nil
+	}
+	return &StalledError{Blocked: blocked}
+}
+
+// blockedTargets returns the pending join targets together with join targets in next
+func (t *joinTracker) blockedTargets(next []string) []string {
+	var targets []string
+	for target := range t.pending {
+		targets = append(targets, target)
+	}
+	for _, node := range next {
+		if _, isJoin := t.joins[node]; isJoin && !slices.Contains(targets, node) {
+			targets = append(targets, node)
+		}
+	}
+	return targets
+}
+
+// satisfied reports whether all required predecessors of target have completed
+func (t *joinTracker) satisfied(target string) bool {
+	for _, pred := range t.joins[target] {
+		if !t.arrived[target][pred] {
+			return false
+		}
+	}
+	return true
+}
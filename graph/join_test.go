@@ -0,0 +1,104 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type joinRecorder struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (r *joinRecorder) node(name string) func(ctx context.Context, state map[string]any) (map[string]any, error) {
+	return func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.order = append(r.order, name)
+		return map[string]any{name: true}, nil
+	}
+}
+
+func newJoinTestGraph(rec *joinRecorder) *StateGraph[map[string]any] {
+	g := NewStateGraph[map[string]any]()
+	for _, name := range []string{"start", "a1", "a2", "b", "agg"} {
+		g.AddNode(name, name, rec.node(name))
+	}
+	g.SetEntryPoint("start")
+	g.AddEdge("start", "a1")
+	g.AddEdge("start", "b")
+	g.AddEdge("a1", "a2")
+	g.AddEdge("agg", END)
+	return g
+}
+
+func TestAddJoin_WaitsForUnevenBranches(t *testing.T) {
+	rec := &joinRecorder{}
+	g := newJoinTestGraph(rec)
+	g.AddJoin("agg", []string{"a2", "b"})
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	result, err := runnable.Invoke(context.Background(), map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, true, result["agg"])
+
+	count := 0
+	for _, n := range rec.order {
+		if n == "agg" {
+			count++
+		}
+	}
+	assert.Equal(t, 1, count)
+	assert.Equal(t, "agg", rec.order[len(rec.order)-1])
+}
+
+func TestAddJoin_ReleasesWhenBranchSkipped(t *testing.T) {
+	rec := &joinRecorder{}
+	g := NewStateGraph[map[string]any]()
+	for _, name := range []string{"router", "a", "b", "agg"} {
+		g.AddNode(name, name, rec.node(name))
+	}
+	g.SetEntryPoint("router")
+	g.AddConditionalEdge("router", func(ctx context.Context, state map[string]any) string {
+		return "a"
+	})
+	g.AddJoin("agg", []string{"a", "b"})
+	g.AddEdge("agg", END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	_, err = runnable.Invoke(context.Background(), map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"router", "a", "agg"}, rec.order)
+}
+
+func TestAddJoin_DetectStalls(t *testing.T) {
+	rec := &joinRecorder{}
+	g := NewStateGraph[map[string]any]()
+	for _, name := range []string{"router", "a", "b", "agg"} {
+		g.AddNode(name, name, rec.node(name))
+	}
+	g.SetEntryPoint("router")
+	g.AddConditionalEdge("router", func(ctx context.Context, state map[string]any) string {
+		return "a"
+	})
+	g.AddJoin("agg", []string{"a", "b"})
+	g.AddEdge("agg", END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	_, err = runnable.InvokeWithConfig(context.Background(), map[string]any{}, &Config{DetectStalls: true})
+	var stalled *StalledError
+	require.ErrorAs(t, err, &stalled)
+	assert.Equal(t, map[string][]string{"agg": {"b"}}, stalled.Blocked)
+	assert.Equal(t, "graph stalled: agg waits on [b]", err.Error())
+	assert.Equal(t, []string{"router", "a"}, rec.order)
+}
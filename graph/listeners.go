@@ -24,6 +24,9 @@ const (
 	// NodeEventError indicates a node encountered an error
 	NodeEventError NodeEvent = "error"
 
+	// NodeEventRetry indicates a node added with AddNodeWithRetry failed and is retried
+	NodeEventRetry NodeEvent = "retry"
+
 	// EventChainStart indicates the graph execution has started
 	EventChainStart NodeEvent = "chain_start"
 
@@ -99,6 +102,7 @@ type ListenableNode[S any] struct {
 	listeners []listenerWrapper[S]
 	mutex     sync.RWMutex
 	nextID    int64
+	err       error
 }
 
 // NewListenableNode creates a new listenable node from a regular typed node
@@ -110,6 +114,12 @@ func NewListenableNode[S any](node TypedNode[S]) *ListenableNode[S] {
 	}
 }
 
+// Err returns the error from adding the node to its graph, such as ErrGraphFrozen. A node
+// with an error is not part of the graph; its listeners are never called.
+func (ln *ListenableNode[S]) Err() error {
+	return ln.err
+}
+
 // AddListener adds a listener to the node and returns the listenable node for chaining
 func (ln *ListenableNode[S]) AddListener(listener NodeListener[S]) *ListenableNode[S] {
 	ln.mutex.Lock()
@@ -204,13 +214,16 @@ func (ln *ListenableNode[S]) Execute(ctx context.Context, state S) (S, error) {
 	// Notify start
 	ln.NotifyListeners(ctx, NodeEventStart, state, nil)
 
-	// Execute the node function
-	result, err := ln.Function(ctx, state)
+	// Execute the node function, letting it report progress to the listeners
+	nodeCtx := withRetryReporter(withProgressReporter(withTokenCounter(ctx), ln, state), ln, state)
+	nodeCtx = withTokenEmitter(nodeCtx, ln.Name)
+	result, err := ln.Function(nodeCtx, state)
 
 	// Notify completion or error
 	if err != nil {
 		ln.NotifyListeners(ctx, NodeEventError, state, err)
 	} else {
+		flushTokenMetrics(nodeCtx)
 		ln.NotifyListeners(ctx, NodeEventComplete, result, nil)
 	}
 
@@ -255,7 +268,8 @@ func NewListenableStateGraph[S any]() *ListenableStateGraph[S] {
 	}
 }
 
-// AddNode adds a node with listener capabilities
+// AddNode adds a node with listener capabilities.
+// Once the graph has been compiled, the node is not added and its Err returns ErrGraphFrozen.
 func (g *ListenableStateGraph[S]) AddNode(name string, description string, fn func(ctx context.Context, state S) (S, error)) *ListenableNode[S] {
 	node := TypedNode[S]{
 		Name:        name,
@@ -263,15 +277,74 @@ func (g *ListenableStateGraph[S]) AddNode(name string, description string, fn fu
 		Function:    fn,
 	}
 
-	listenableNode := NewListenableNode(node)
-
 	// Add to both the base graph and our listenable nodes map
-	g.StateGraph.AddNode(name, description, fn)
+	listenableNode := NewListenableNode(node)
+	if err := g.StateGraph.AddNode(name, description, fn); err != nil {
+		listenableNode.err = err
+		return listenableNode
+	}
 	g.listenableNodes[name] = listenableNode
 
 	return listenableNode
 }
 
+// AddNodeWithIO adds a node with listener capabilities and declares the state keys it reads
+// and writes, see StateGraph.AddNodeWithIO.
+// Once the graph has been compiled, the node is not added and its Err returns ErrGraphFrozen.
+func (g *ListenableStateGraph[S]) AddNodeWithIO(name, description string, reads, writes []string, fn func(ctx context.Context, state S) (S, error)) *ListenableNode[S] {
+	listenableNode := g.AddNode(name, description, fn)
+	if listenableNode.Err() != nil {
+		return listenableNode
+	}
+	g.declareNodeIO(name, reads, writes)
+	return listenableNode
+}
+
+// AddNodeWithCompensation adds a node with listener capabilities and a compensating
+// action, see StateGraph.AddNodeWithCompensation.
+// Once the graph has been compiled, the node is not added and its Err returns ErrGraphFrozen.
+func (g *ListenableStateGraph[S]) AddNodeWithCompensation(name, description string, fn func(ctx context.Context, state S) (S, error), compensate func(ctx context.Context, state S) error) *ListenableNode[S] {
+	listenableNode := g.AddNode(name, description, fn)
+	if listenableNode.Err() != nil {
+		return listenableNode
+	}
+	g.setCompensation(name, compensate)
+	return listenableNode
+}
+
+// AddNodeWithRetry adds a node with listener capabilities and retry logic, see
+// StateGraph.AddNodeWithRetry. Its listeners receive a NodeEventRetry event before each retry.
+// Once the graph has been compiled, the node is not added and its Err returns ErrGraphFrozen.
+func (g *ListenableStateGraph[S]) AddNodeWithRetry(name, description string, fn func(context.Context, S) (S, error), config *RetryConfig) *ListenableNode[S] {
+	retryNode := NewRetryNode(TypedNode[S]{Name: name, Description: description, Function: fn}, config)
+	return g.AddNode(name, description, retryNode.Execute)
+}
+
+// AddAsyncNode adds an async node with listener capabilities, see StateGraph.AddAsyncNode.
+// Once the graph has been compiled, the node is not added and its Err returns ErrGraphFrozen.
+func (g *ListenableStateGraph[S]) AddAsyncNode(name, description string, start func(ctx context.Context, state S) (any, error), await func(ctx context.Context, handle any) (S, error)) *ListenableNode[S] {
+	return g.AddAsyncNodeWithDeadline(name, description, start, await, 0)
+}
+
+// AddAsyncNodeWithDeadline adds an async node with listener capabilities whose result must be
+// awaited within timeout, see StateGraph.AddAsyncNodeWithDeadline.
+// Once the graph has been compiled, the node is not added and its Err returns ErrGraphFrozen.
+func (g *ListenableStateGraph[S]) AddAsyncNodeWithDeadline(name, description string, start func(ctx context.Context, state S) (any, error), await func(ctx context.Context, handle any) (S, error), timeout time.Duration) *ListenableNode[S] {
+	return g.AddNode(name, description, asyncNodeFunc(name, start, await, timeout))
+}
+
+// AddNodeWithOptions adds a node with listener capabilities, run with the given options, see
+// StateGraph.AddNodeWithOptions.
+// Once the graph has been compiled, the node is not added and its Err returns ErrGraphFrozen.
+func (g *ListenableStateGraph[S]) AddNodeWithOptions(name, description string, fn func(ctx context.Context, state S) (S, error), opts NodeOptions) *ListenableNode[S] {
+	listenableNode := g.AddNode(name, description, fn)
+	if listenableNode.Err() != nil {
+		return listenableNode
+	}
+	g.setNodeOptions(name, opts)
+	return listenableNode
+}
+
 // GetListenableNode returns the listenable node by name
 func (g *ListenableStateGraph[S]) GetListenableNode(name string) *ListenableNode[S] {
 	return g.listenableNodes[name]
@@ -392,6 +465,11 @@ func (lr *ListenableRunnable[S]) Stream(ctx context.Context, initialState S) <-c
 	return eventChan
 }
 
+// LastTrace returns the execution trace of the most recent invocation that collected one
+func (lr *ListenableRunnable[S]) LastTrace() []TraceEntry {
+	return lr.runnable.LastTrace()
+}
+
 // SetTracer sets a tracer for the underlying runnable
 func (lr *ListenableRunnable[S]) SetTracer(tracer *Tracer) {
 	lr.runnable.SetTracer(tracer)
@@ -0,0 +1,57 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// LogsStateKey is the key under which node log messages are stored in
+// map[string]any results when Config.CollectLogs is enabled.
+const LogsStateKey = "_logs"
+
+// LogEntry is a message logged by a node with Logf.
+type LogEntry struct {
+	// Node is the name of the node that logged the message
+	Node string
+
+	// Time is when the message was logged
+	Time time.Time
+
+	// Message is the formatted message
+	Message string
+}
+
+type nodeLoggerKey struct{}
+
+// nodeLogger attributes the messages of a running node to it
+type nodeLogger struct {
+	collector *collector[LogEntry]
+	node      string
+}
+
+// withNodeLogger returns the context passed to a node, whose Logf calls are
+// recorded for the node when logs are collected
+func withNodeLogger(ctx context.Context, node string) context.Context {
+	if c := getCollector[LogEntry](ctx); c != nil {
+		return context.WithValue(ctx, nodeLoggerKey{}, nodeLogger{collector: c, node: node})
+	}
+	return ctx
+}
+
+// Logf records a diagnostic message from a running node in the per-invocation log,
+// so reasoning steps reach the caller instead of stdout. The log is returned by
+// InvokeWithLogs or, with Config.CollectLogs, stored under LogsStateKey in map results.
+// It is a no-op when logs are not collected.
+func Logf(ctx context.Context, format string, args ...any) {
+	if l, ok := ctx.Value(nodeLoggerKey{}).(nodeLogger); ok {
+		l.collector.record(LogEntry{Node: l.node, Time: time.Now(), Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// InvokeWithLogs executes the graph and returns the final state together with
+// the messages nodes logged with Logf, in logging order.
+// The log is returned even if the execution fails.
+func (r *StateRunnable[S]) InvokeWithLogs(ctx context.Context, initialState S, config *Config) (S, []LogEntry, error) {
+	return invokeCollecting[LogEntry](ctx, r, initialState, config)
+}
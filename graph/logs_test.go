@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectLogs(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("analyze", "analyze", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		Logf(ctx, "confidence %.1f", 0.4)
+		return map[string]any{"analyzed": true}, nil
+	})
+	g.AddNode("escalate", "escalate", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		Logf(ctx, "escalating to a human")
+		return map[string]any{"escalated": true}, nil
+	})
+	g.AddEdge("analyze", "escalate")
+	g.AddEdge("escalate", END)
+	g.SetEntryPoint("analyze")
+
+	app, err := g.Compile()
+	require.NoError(t, err)
+
+	t.Run("Map state carries logs", func(t *testing.T) {
+		res, err := app.InvokeWithConfig(context.Background(), map[string]any{}, &Config{CollectLogs: true})
+		require.NoError(t, err)
+
+		logs, ok := res[LogsStateKey].([]LogEntry)
+		require.True(t, ok)
+		require.Len(t, logs, 2)
+		assert.Equal(t, LogEntry{Node: "analyze", Time: logs[0].Time, Message: "confidence 0.4"}, logs[0])
+		assert.Equal(t, "escalate", logs[1].Node)
+		assert.False(t, logs[0].Time.IsZero())
+	})
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		res, err := app.Invoke(context.Background(), map[string]any{})
+		require.NoError(t, err)
+		assert.NotContains(t, res, LogsStateKey)
+	})
+}
+
+func TestInvokeWithLogs(t *testing.T) {
+	type state struct{ N int }
+	g := NewStateGraph[state]()
+	g.AddNode("inc", "inc", func(ctx context.Context, s state) (state, error) {
+		s.N++
+		Logf(ctx, "incremented to %d", s.N)
+		return s, nil
+	})
+	g.AddNode("fail", "fail", func(ctx context.Context, s state) (state, error) {
+		Logf(ctx, "about to fail")
+		return s, errors.New("boom")
+	})
+	g.AddEdge("inc", "fail")
+	g.AddEdge("fail", END)
+	g.SetEntryPoint("inc")
+
+	app, err := g.Compile()
+	require.NoError(t, err)
+
+	_, logs, err := app.InvokeWithLogs(context.Background(), state{}, nil)
+	require.Error(t, err)
+	require.Len(t, logs, 2)
+	assert.Equal(t, "incremented to 1", logs[0].Message)
+	assert.Equal(t, "fail", logs[1].Node)
+}
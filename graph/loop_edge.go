@@ -0,0 +1,83 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"maps"
+)
+
+// AddLoopEdge routes from back to to at most maxIterations times, then to exit. The number
+// of times from has run is kept in state under counterKey, so the loop cannot run forever.
+// The state must be a map[string]any, and from must already be added.
+//
+// Example:
+//
+//	g.AddNode("generate", "Generate code", generate)
+//	g.AddNode("test", "Run the tests", test)
+//	g.AddEdge("generate", "test")
+//	g.AddLoopEdge("test", "generate", "report", 3, "attempts")
+func (g *StateGraph[S]) AddLoopEdge(from, to, exit string, maxIterations int, counterKey string) error {
+	return g.AddLoopEdgeUntil(from, to, exit, maxIterations, counterKey, nil)
+}
+
+// AddLoopEdgeUntil is like AddLoopEdge, but also routes to exit as soon as done returns true,
+// e.g. once the tests pass. A nil done never ends the loop early.
+func (g *StateGraph[S]) AddLoopEdgeUntil(from, to, exit string, maxIterations int, counterKey string, done func(ctx context.Context, state S) bool) error {
+	if g.frozen {
+		return fmt.Errorf("%w: cannot add loop edge %s -> %s", ErrGraphFrozen, from, to)
+	}
+	var zero S
+	if _, ok := any(zero).(map[string]any); !ok {
+		return fmt.Errorf("loop edge %s -> %s requires a map[string]any state, got %T", from, to, zero)
+	}
+	node, ok := g.nodes[from]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNodeNotFound, from)
+	}
+	if maxIterations < 0 {
+		return fmt.Errorf("loop edge %s -> %s: maxIterations must not be negative, got %d", from, to, maxIterations)
+	}
+
+	// Count the runs of from in its result
+	fn := node.Function
+	node.Function = func(ctx context.Context, state S) (S, error) {
+		result, err := fn(ctx, state)
+		if err != nil {
+			return result, err
+		}
+		updated := maps.Clone(any(result).(map[string]any))
+		if updated == nil {
+			updated = make(map[string]any)
+		}
+		updated[counterKey] = loopCount(any(state).(map[string]any), counterKey) + 1
+		return any(updated).(S), nil
+	}
+	g.nodes[from] = node
+
+	g.clearConditionalEdge(from)
+	g.conditionalEdges[from] = func(ctx context.Context, state S) string {
+		if done != nil && done(ctx, state) {
+			return exit
+		}
+		// After the k-th run of from, the loop has gone back k-1 times
+		if loopCount(any(state).(map[string]any), counterKey) > maxIterations {
+			return exit
+		}
+		return to
+	}
+	g.SetConditionalTargets(from, to, exit)
+	return nil
+}
+
+// loopCount returns the loop counter stored in state, which is a float64 after a JSON
+// round trip (e.g. through a checkpoint store)
+func loopCount(state map[string]any, key string) int {
+	switch v := state[key].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
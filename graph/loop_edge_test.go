@@ -0,0 +1,84 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLoopGraph builds attempt -> check, where check loops back to attempt
+func newLoopGraph(t *testing.T, maxIterations int, done func(ctx context.Context, state map[string]any) bool) *StateGraph[map[string]any] {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("attempt", "Attempt", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		attempts, _ := state["attempts"].(int)
+		state["attempts"] = attempts + 1
+		return state, nil
+	})
+	g.AddNode("check", "Check", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return state, nil
+	})
+	g.AddNode("exit", "Exit", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		state["exited"] = true
+		return state, nil
+	})
+	g.AddEdge("attempt", "check")
+	g.AddEdge("exit", END)
+	g.SetEntryPoint("attempt")
+	require.NoError(t, g.AddLoopEdgeUntil("check", "attempt", "exit", maxIterations, "loops", done))
+	return g
+}
+
+func TestAddLoopEdge(t *testing.T) {
+	t.Run("Loops maxIterations times", func(t *testing.T) {
+		runnable, err := newLoopGraph(t, 3, nil).Compile()
+		require.NoError(t, err)
+
+		result, err := runnable.Invoke(context.Background(), map[string]any{})
+		require.NoError(t, err)
+		assert.Equal(t, 4, result["attempts"])
+		assert.Equal(t, 4, result["loops"])
+		assert.Equal(t, true, result["exited"])
+	})
+
+	t.Run("Exits when done", func(t *testing.T) {
+		done := func(ctx context.Context, state map[string]any) bool {
+			return state["attempts"] == 2
+		}
+		runnable, err := newLoopGraph(t, 10, done).Compile()
+		require.NoError(t, err)
+
+		result, err := runnable.Invoke(context.Background(), map[string]any{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, result["attempts"])
+		assert.Equal(t, true, result["exited"])
+	})
+
+	t.Run("Zero iterations", func(t *testing.T) {
+		runnable, err := newLoopGraph(t, 0, nil).Compile()
+		require.NoError(t, err)
+
+		result, err := runnable.Invoke(context.Background(), map[string]any{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, result["attempts"])
+	})
+
+	t.Run("Rejected by RequireAcyclic", func(t *testing.T) {
+		g := newLoopGraph(t, 3, nil)
+		g.RequireAcyclic()
+		_, err := g.Compile()
+		var cycleErr *CycleError
+		assert.True(t, errors.As(err, &cycleErr))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		g := NewStateGraph[map[string]any]()
+		assert.ErrorIs(t, g.AddLoopEdge("missing", "a", "b", 1, "loops"), ErrNodeNotFound)
+
+		typed := NewStateGraph[int]()
+		typed.AddNode("a", "A", func(ctx context.Context, state int) (int, error) { return state, nil })
+		assert.Error(t, typed.AddLoopEdge("a", "a", END, 1, "loops"))
+	})
+}
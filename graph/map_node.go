@@ -0,0 +1,225 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// NewMapNode creates a node function that runs perItem on every element of the slice held
+// in state under itemsFrom, at most concurrency at a time (0 or less runs all at once), and
+// writes the results in item order under resultsTo. It packages the map step of map-reduce,
+// e.g. summarizing each retrieved document with an LLM.
+//
+// The state is either a map[string]any, where itemsFrom and resultsTo are keys, or a struct
+// (or pointer to one), where they name fields by Go name or JSON tag. In a map, the results
+// are stored as []any in a copy of the map; a struct field receives them converted to its
+// slice type. A missing or nil items value counts as empty.
+//
+// If perItem fails or panics for any item, the remaining items are cancelled and the node
+// returns the error.
+//
+// Example:
+//
+//	g.AddNode("summarize", "Summarize each document", graph.NewMapNode[map[string]any]("documents",
+//	    func(ctx context.Context, item any) (any, error) {
+//	        return summarize(ctx, item.(rag.Document))
+//	    }, "summaries", 4))
+func NewMapNode[S any](itemsFrom string, perItem func(ctx context.Context, item any) (any, error), resultsTo string, concurrency int) func(ctx context.Context, state S) (S, error) {
+	return func(ctx context.Context, state S) (S, error) {
+		var itemsValue reflect.Value
+		if m, ok := any(state).(map[string]any); ok {
+			itemsValue = reflect.ValueOf(m[itemsFrom])
+		} else {
+			var err error
+			if itemsValue, err = stateValue(reflect.ValueOf(state), itemsFrom); err != nil {
+				return state, err
+			}
+		}
+		items, err := sliceItems(itemsValue, itemsFrom)
+		if err != nil {
+			return state, err
+		}
+
+		results, err := mapItems(ctx, items, perItem, concurrency)
+		if err != nil {
+			return state, err
+		}
+
+		if m, ok := any(state).(map[string]any); ok {
+			m = maps.Clone(m)
+			m[resultsTo] = results
+			return any(m).(S), nil
+		}
+		if err := setStateValue(reflect.ValueOf(&state).Elem(), resultsTo, results); err != nil {
+			return state, err
+		}
+		return state, nil
+	}
+}
+
+// mapItems runs perItem on every item with at most concurrency calls in flight, returning
+// the results in item order or the error of the first item that failed
+func mapItems(ctx context.Context, items []any, perItem func(ctx context.Context, item any) (any, error), concurrency int) ([]any, error) {
+	if concurrency <= 0 || concurrency > len(items) {
+		concurrency = len(items)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]any, len(items))
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	sem := make(chan struct{}, max(concurrency, 1))
+	for i, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer func() {
+				if r := recover(); r != nil {
+					fail(fmt.Errorf("panic in map node item %d: %v", i, r))
+				}
+			}()
+
+			result, err := perItem(ctx, item)
+			if err != nil {
+				fail(fmt.Errorf("map node item %d failed: %w", i, err))
+				return
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// sliceItems returns the elements of a slice or array value
+func sliceItems(v reflect.Value, key string) ([]any, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("map node items %q is a %s, not a slice", key, v.Type())
+	}
+
+	items := make([]any, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items, nil
+}
+
+// stateValue returns the named field of a struct state
+func stateValue(state reflect.Value, key string) (reflect.Value, error) {
+	for state.Kind() == reflect.Pointer || state.Kind() == reflect.Interface {
+		if state.IsNil() {
+			return reflect.Value{}, fmt.Errorf("state is nil")
+		}
+		state = state.Elem()
+	}
+
+	switch state.Kind() {
+	case reflect.Struct:
+		field, ok := structField(state, key)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("state %s has no field %q", state.Type(), key)
+		}
+		return field, nil
+	default:
+		return reflect.Value{}, fmt.Errorf("state %s is neither a map[string]any nor a struct", state.Type())
+	}
+}
+
+// setStateValue sets the named field of a struct state to results, converted to the field's
+// slice type
+func setStateValue(state reflect.Value, key string, results []any) error {
+	for state.Kind() == reflect.Pointer {
+		if state.IsNil() {
+			return fmt.Errorf("state is nil")
+		}
+		state = state.Elem()
+	}
+
+	field, err := stateValue(state, key)
+	if err != nil {
+		return err
+	}
+	if !field.CanSet() {
+		return fmt.Errorf("state field %q cannot be set", key)
+	}
+
+	if reflect.TypeOf(results).AssignableTo(field.Type()) {
+		field.Set(reflect.ValueOf(results))
+		return nil
+	}
+	if field.Kind() != reflect.Slice {
+		return fmt.Errorf("state field %q is a %s, not a slice", key, field.Type())
+	}
+
+	elemType := field.Type().Elem()
+	converted := reflect.MakeSlice(field.Type(), len(results), len(results))
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+		v := reflect.ValueOf(result)
+		if !v.Type().AssignableTo(elemType) {
+			return fmt.Errorf("map node result %d is a %s, which cannot be stored in %q (%s)", i, v.Type(), key, field.Type())
+		}
+		converted.Index(i).Set(v)
+	}
+	field.Set(converted)
+	return nil
+}
+
+// structField returns the exported field of a struct value with the given Go name or JSON tag
+func structField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.Name == name || tag == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
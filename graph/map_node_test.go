@@ -0,0 +1,101 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMapNode(t *testing.T) {
+	ctx := context.Background()
+	upper := func(ctx context.Context, item any) (any, error) {
+		return strings.ToUpper(item.(string)), nil
+	}
+
+	t.Run("Map state", func(t *testing.T) {
+		node := NewMapNode[map[string]any]("docs", upper, "summaries", 2)
+		state := map[string]any{"docs": []string{"a", "b", "c"}}
+
+		res, err := node(ctx, state)
+		require.NoError(t, err)
+		assert.Equal(t, []any{"A", "B", "C"}, res["summaries"])
+		assert.NotContains(t, state, "summaries", "the input state is not modified")
+
+		res, err = node(ctx, map[string]any{})
+		require.NoError(t, err)
+		assert.Equal(t, []any{}, res["summaries"])
+
+		_, err = node(ctx, map[string]any{"docs": "a"})
+		assert.ErrorContains(t, err, "not a slice")
+	})
+
+	t.Run("Struct state", func(t *testing.T) {
+		type state struct {
+			Docs      []string `json:"docs"`
+			Summaries []string
+		}
+		node := NewMapNode[state]("docs", upper, "Summaries", 0)
+
+		res, err := node(ctx, state{Docs: []string{"x", "y"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"X", "Y"}, res.Summaries)
+
+		_, err = NewMapNode[state]("missing", upper, "Summaries", 0)(ctx, state{})
+		assert.ErrorContains(t, err, `no field "missing"`)
+		_, err = NewMapNode[state]("docs", func(ctx context.Context, item any) (any, error) {
+			return len(item.(string)), nil
+		}, "Summaries", 0)(ctx, state{Docs: []string{"x"}})
+		assert.ErrorContains(t, err, "cannot be stored")
+	})
+
+	t.Run("Concurrency bound and order", func(t *testing.T) {
+		var running, peak atomic.Int32
+		node := NewMapNode[map[string]any]("items", func(ctx context.Context, item any) (any, error) {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			// Later items finish first, so results must be reordered
+			time.Sleep(time.Duration(10-item.(int)) * time.Millisecond)
+			running.Add(-1)
+			return item.(int) * 2, nil
+		}, "doubled", 3)
+
+		res, err := node(ctx, map[string]any{"items": []int{1, 2, 3, 4, 5, 6, 7, 8}})
+		require.NoError(t, err)
+		assert.Equal(t, []any{2, 4, 6, 8, 10, 12, 14, 16}, res["doubled"])
+		assert.LessOrEqual(t, peak.Load(), int32(3))
+	})
+
+	t.Run("Errors and panics cancel the rest", func(t *testing.T) {
+		var started atomic.Int32
+		node := NewMapNode[map[string]any]("items", func(ctx context.Context, item any) (any, error) {
+			started.Add(1)
+			switch item.(int) {
+			case 1:
+				return nil, errors.New("boom")
+			case 2:
+				panic("bad item")
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}, "out", 2)
+
+		_, err := node(ctx, map[string]any{"items": []int{0, 1, 2, 3, 4, 5}})
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "map node item 1 failed: boom")
+		assert.Less(t, started.Load(), int32(6))
+
+		_, err = node(ctx, map[string]any{"items": []int{2}})
+		assert.ErrorContains(t, err, "panic in map node item 0: bad item")
+	})
+}
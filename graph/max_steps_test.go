@@ -0,0 +1,101 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRunawayGraph builds agent -> tools, where the router always goes back to agent
+func newRunawayGraph(t *testing.T) *StateRunnable[map[string]any] {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("agent", "Agent", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		calls, _ := state["calls"].(int)
+		state["calls"] = calls + 1
+		return state, nil
+	})
+	g.AddNode("tools", "Tools", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return state, nil
+	})
+	g.AddEdge("agent", "tools")
+	g.AddConditionalEdge("tools", func(ctx context.Context, state map[string]any) string {
+		return "agent"
+	})
+	g.SetEntryPoint("agent")
+
+	app, err := g.Compile()
+	require.NoError(t, err)
+	return app
+}
+
+func TestMaxSteps(t *testing.T) {
+	app := newRunawayGraph(t)
+
+	_, err := app.InvokeWithConfig(context.Background(), map[string]any{}, &Config{MaxSteps: 5})
+	var stepsErr *MaxStepsExceededError
+	require.ErrorAs(t, err, &stepsErr)
+	assert.Equal(t, 5, stepsErr.Steps)
+	assert.Equal(t, "agent", stepsErr.LastNode)
+
+	var execErr *ExecutionError
+	require.ErrorAs(t, err, &execErr)
+	assert.Equal(t, 3, execErr.PartialState.(map[string]any)["calls"])
+}
+
+func TestMaxStepsDefault(t *testing.T) {
+	app := newRunawayGraph(t)
+
+	_, err := app.Invoke(context.Background(), map[string]any{})
+	var stepsErr *MaxStepsExceededError
+	require.ErrorAs(t, err, &stepsErr)
+	assert.Equal(t, DefaultMaxSteps, stepsErr.Steps)
+}
+
+func TestMaxStepsCountsParallelNodes(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.SetSchema(NewMapSchema())
+	for _, name := range []string{"start", "a", "b", "end"} {
+		g.AddNode(name, name, func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			return map[string]any{name: true}, nil
+		})
+	}
+	g.AddEdge("start", "a")
+	g.AddEdge("start", "b")
+	g.AddEdge("a", "end")
+	g.AddEdge("b", "end")
+	g.AddEdge("end", END)
+	g.SetEntryPoint("start")
+	app, err := g.Compile()
+	require.NoError(t, err)
+
+	_, err = app.InvokeWithConfig(context.Background(), map[string]any{}, &Config{MaxSteps: 4})
+	require.NoError(t, err)
+
+	_, err = app.InvokeWithConfig(context.Background(), map[string]any{}, &Config{MaxSteps: 2})
+	assert.True(t, errors.As(err, new(*MaxStepsExceededError)))
+}
+
+func TestMaxStepsDisabled(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	calls := 0
+	g.AddNode("loop", "Loop", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		if calls++; calls > DefaultMaxSteps+10 {
+			return nil, errors.New("stop")
+		}
+		return state, nil
+	})
+	g.AddConditionalEdge("loop", func(ctx context.Context, state map[string]any) string {
+		return "loop"
+	})
+	g.SetEntryPoint("loop")
+	app, err := g.Compile()
+	require.NoError(t, err)
+
+	_, err = app.InvokeWithConfig(context.Background(), map[string]any{}, &Config{MaxSteps: -1})
+	require.Error(t, err)
+	assert.False(t, errors.As(err, new(*MaxStepsExceededError)))
+	assert.Greater(t, calls, DefaultMaxSteps)
+}
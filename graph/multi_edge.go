@@ -0,0 +1,48 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// AddConditionalEdgeMulti adds a conditional edge whose router can select several next
+// nodes. All the returned nodes execute as parallel branches of the next step, and their
+// results are merged with the reducers of the graph's schema (see SetSchema). Returning an
+// empty slice (or only END) routes to END. A node has a single conditional edge, so this
+// replaces an edge added with AddConditionalEdge or AddConditionalEdgeSend and vice versa.
+// Declare the possible targets with SetConditionalTargets for RequireAcyclic and
+// read/write checks.
+//
+// Example:
+//
+//	g.AddConditionalEdgeMulti("classify", func(ctx context.Context, state map[string]any) []string {
+//	    var next []string
+//	    for _, topic := range state["topics"].([]string) {
+//	        next = append(next, "research_"+topic)
+//	    }
+//	    return next
+//	})
+//
+// It returns ErrGraphFrozen, without adding the edge, once the graph has been compiled.
+func (g *StateGraph[S]) AddConditionalEdgeMulti(from string, router func(ctx context.Context, state S) []string) error {
+	if g.frozen {
+		return fmt.Errorf("%w: cannot add conditional edge from %s", ErrGraphFrozen, from)
+	}
+	if g.multiConditionalEdges == nil {
+		g.multiConditionalEdges = make(map[string]func(ctx context.Context, state S) []string)
+	}
+	g.clearConditionalEdge(from)
+	g.multiConditionalEdges[from] = router
+	return nil
+}
+
+// conditionalSources returns the nodes with a conditional edge of any kind
+func (g *StateGraph[S]) conditionalSources() []string {
+	sources := slices.Collect(maps.Keys(g.conditionalEdges))
+	sources = slices.AppendSeq(sources, maps.Keys(g.multiConditionalEdges))
+	sources = slices.AppendSeq(sources, maps.Keys(g.sendEdges))
+	slices.Sort(sources)
+	return slices.Compact(sources)
+}
@@ -0,0 +1,125 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+func newFanOutGraph(route func(ctx context.Context, state map[string]any) []string) *graph.StateGraph[map[string]any] {
+	g := graph.NewStateGraph[map[string]any]()
+	schema := graph.NewMapSchema()
+	schema.RegisterReducer("results", graph.AppendReducer)
+	g.SetSchema(schema)
+
+	g.AddNode("router", "router", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{}, nil
+	})
+	for _, name := range []string{"a", "b", "c"} {
+		g.AddNode(name, name, func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			return map[string]any{"results": []string{name}}, nil
+		})
+		g.AddEdge(name, "collect")
+	}
+	g.AddNode("collect", "collect", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"collected": true}, nil
+	})
+	g.AddEdge("collect", graph.END)
+	g.SetEntryPoint("router")
+	g.AddConditionalEdgeMulti("router", route)
+	return g
+}
+
+func TestConditionalEdgeMultiFanOut(t *testing.T) {
+	g := newFanOutGraph(func(ctx context.Context, state map[string]any) []string {
+		return []string{"a", "c"}
+	})
+	runnable, err := g.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := runnable.Invoke(context.Background(), map[string]any{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := result["results"].([]string)
+	slices.Sort(got)
+	if !slices.Equal(got, []string{"a", "c"}) {
+		t.Errorf("results = %v, want [a c]", got)
+	}
+	if result["collected"] != true {
+		t.Errorf("expected the branches to continue to collect, got %v", result)
+	}
+}
+
+func TestConditionalEdgeMultiEmptyRoutesToEnd(t *testing.T) {
+	for name, targets := range map[string][]string{
+		"empty": nil,
+		"end":   {graph.END},
+	} {
+		t.Run(name, func(t *testing.T) {
+			g := newFanOutGraph(func(ctx context.Context, state map[string]any) []string {
+				return targets
+			})
+			runnable, err := g.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := runnable.Invoke(context.Background(), map[string]any{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := result["results"]; ok {
+				t.Errorf("expected no branch to run, got %v", result)
+			}
+			if _, ok := result["collected"]; ok {
+				t.Errorf("expected the run to end after router, got %v", result)
+			}
+		})
+	}
+}
+
+func TestConditionalEdgeMultiReplacesConditionalEdge(t *testing.T) {
+	g := newFanOutGraph(func(ctx context.Context, state map[string]any) []string {
+		return []string{"a", "b"}
+	})
+	g.AddConditionalEdge("router", func(ctx context.Context, state map[string]any) string {
+		return "c"
+	})
+	runnable, err := g.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := runnable.Invoke(context.Background(), map[string]any{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := result["results"].([]string); !slices.Equal(got, []string{"c"}) {
+		t.Errorf("results = %v, want [c]", got)
+	}
+}
+
+func TestConditionalEdgeMultiAcyclic(t *testing.T) {
+	g := newFanOutGraph(func(ctx context.Context, state map[string]any) []string {
+		return []string{"a"}
+	})
+	g.RequireAcyclic()
+	if _, err := g.Compile(); !errors.Is(err, graph.ErrUndeclaredTargets) {
+		t.Fatalf("expected ErrUndeclaredTargets, got %v", err)
+	}
+
+	g = newFanOutGraph(func(ctx context.Context, state map[string]any) []string {
+		return []string{"a"}
+	})
+	g.RequireAcyclic()
+	g.SetConditionalTargets("router", "a", "b", "c", graph.END)
+	if _, err := g.Compile(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
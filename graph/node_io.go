@@ -0,0 +1,143 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// nodeIO holds the state keys a node declared with AddNodeWithIO
+type nodeIO struct {
+	reads  []string
+	writes []string
+}
+
+// AddNodeWithIO adds a node like AddNode and declares the state keys it reads and writes.
+// Compile then checks that every key the node reads is either an input of the graph (see
+// SetInputKeys and the keys of the schema's initial map state) or written by a node that can
+// run before it, which catches ordering bugs such as a node reading "analysis" before
+// "analyze" has run. Keys may be dotted paths into nested maps; a write of "a" satisfies a
+// read of "a.b". A failed check makes Compile return an *UnsatisfiedReadsError.
+//
+// Example:
+//
+//	g.AddNodeWithIO("summarize", "Summarize the analysis",
+//	    []string{"analysis", "enhanced"}, []string{"summary"}, summarize)
+func (g *StateGraph[S]) AddNodeWithIO(name, description string, reads, writes []string, fn func(ctx context.Context, state S) (S, error)) error {
+	if err := g.AddNode(name, description, fn); err != nil {
+		return err
+	}
+	g.declareNodeIO(name, reads, writes)
+	return nil
+}
+
+// declareNodeIO records the state keys a node reads and writes
+func (g *StateGraph[S]) declareNodeIO(name string, reads, writes []string) {
+	if g.nodeIO == nil {
+		g.nodeIO = make(map[string]nodeIO)
+	}
+	g.nodeIO[name] = nodeIO{reads: slices.Clone(reads), writes: slices.Clone(writes)}
+}
+
+// SetInputKeys declares the state keys supplied by the caller of Invoke, which nodes added
+// with AddNodeWithIO may read without an upstream node writing them.
+// It returns ErrGraphFrozen, leaving the input keys unchanged, once the graph has been compiled.
+func (g *StateGraph[S]) SetInputKeys(keys ...string) error {
+	if g.frozen {
+		return fmt.Errorf("%w: cannot set input keys", ErrGraphFrozen)
+	}
+	g.inputKeys = slices.Clone(keys)
+	return nil
+}
+
+// checkNodeIO returns an *UnsatisfiedReadsError if a node declared with AddNodeWithIO reads
+// a key that is neither an input nor written upstream.
+//
+// The check follows static edges, joins and the declared targets of conditional edges; a
+// conditional edge without SetConditionalTargets may route to any node. Nodes added without
+// AddNodeWithIO may write any key, so reads downstream of them are not reported, and nodes
+// only reachable through Command.Goto are not checked.
+func (g *StateGraph[S]) checkNodeIO() error {
+	if len(g.nodeIO) == 0 {
+		return nil
+	}
+
+	inputs := slices.Clone(g.inputKeys)
+	if g.Schema != nil {
+		if init, ok := any(g.Schema.Init()).(map[string]any); ok {
+			inputs = slices.AppendSeq(inputs, maps.Keys(init))
+		}
+	}
+
+	predecessors := make(map[string][]string)
+	for _, edge := range g.edges {
+		predecessors[edge.To] = append(predecessors[edge.To], edge.From)
+	}
+	for _, from := range g.conditionalSources() {
+		targets, ok := g.conditionalTargets[from]
+		if !ok {
+			targets = slices.Collect(maps.Keys(g.nodes))
+		}
+		for _, to := range targets {
+			predecessors[to] = append(predecessors[to], from)
+		}
+	}
+	for target, preds := range g.joins {
+		predecessors[target] = append(predecessors[target], preds...)
+	}
+
+	missing := make(map[string][]string)
+	for name, decl := range g.nodeIO {
+		if len(decl.reads) == 0 || (name != g.entryPoint && len(predecessors[name]) == 0) {
+			continue
+		}
+
+		// Collect the keys that nodes able to run before this one may write
+		var written []string
+		writesAnything := false
+		seen := make(map[string]bool)
+		queue := slices.Clone(predecessors[name])
+		for len(queue) > 0 {
+			node := queue[0]
+			queue = queue[1:]
+			if seen[node] {
+				continue
+			}
+			seen[node] = true
+
+			if _, ok := g.nodes[node]; ok {
+				upstream, declared := g.nodeIO[node]
+				if !declared {
+					writesAnything = true
+					break
+				}
+				written = append(written, upstream.writes...)
+			}
+			queue = append(queue, predecessors[node]...)
+		}
+		if writesAnything {
+			continue
+		}
+
+		for _, key := range decl.reads {
+			if !slices.ContainsFunc(inputs, coversKey(key)) && !slices.ContainsFunc(written, coversKey(key)) {
+				missing[name] = append(missing[name], key)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return &UnsatisfiedReadsError{Missing: missing}
+	}
+	return nil
+}
+
+// coversKey returns a predicate reporting whether a written key provides the read key,
+// i.e. they are equal or one is a dotted path below the other
+func coversKey(read string) func(written string) bool {
+	return func(written string) bool {
+		return written == read || strings.HasPrefix(read, written+".") || strings.HasPrefix(written, read+".")
+	}
+}
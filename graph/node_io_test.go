@@ -0,0 +1,100 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateGraph_AddNodeWithIO(t *testing.T) {
+	pass := func(ctx context.Context, s map[string]any) (map[string]any, error) { return s, nil }
+	newGraph := func() *StateGraph[map[string]any] {
+		g := NewStateGraph[map[string]any]()
+		g.SetInputKeys("input")
+		g.AddNodeWithIO("analyze", "analyze", []string{"input"}, []string{"analysis"}, pass)
+		g.AddNodeWithIO("enhance", "enhance", []string{"input"}, []string{"enhanced"}, pass)
+		g.AddNodeWithIO("summarize", "summarize", []string{"analysis", "enhanced"}, []string{"summary"}, pass)
+		g.AddEdge("summarize", END)
+		return g
+	}
+
+	t.Run("Accepts reads written upstream", func(t *testing.T) {
+		g := newGraph()
+		g.SetEntryPoint("analyze")
+		g.AddEdge("analyze", "enhance")
+		g.AddEdge("enhance", "summarize")
+
+		_, err := g.Compile()
+		require.NoError(t, err)
+	})
+
+	t.Run("Rejects a read before the write", func(t *testing.T) {
+		g := newGraph()
+		g.SetEntryPoint("enhance")
+		g.AddEdge("enhance", "summarize")
+		g.AddEdge("summarize", "analyze")
+		g.AddEdge("analyze", END)
+
+		_, err := g.Compile()
+		var readsErr *UnsatisfiedReadsError
+		require.True(t, errors.As(err, &readsErr), "got %v", err)
+		assert.Equal(t, map[string][]string{"summarize": {"analysis"}}, readsErr.Missing)
+		assert.EqualError(t, err, "state keys read before they are written: summarize reads [analysis]")
+	})
+
+	t.Run("Inputs and schema keys", func(t *testing.T) {
+		g := NewStateGraph[map[string]any]()
+		g.AddNodeWithIO("answer", "answer", []string{"input", "config.model"}, nil, pass)
+		g.SetEntryPoint("answer")
+		g.AddEdge("answer", END)
+
+		_, err := g.Compile()
+		assert.EqualError(t, err, "state keys read before they are written: answer reads [input config.model]")
+
+		g = NewStateGraph[map[string]any]()
+		g.AddNodeWithIO("answer", "answer", []string{"input", "config.model"}, nil, pass)
+		g.SetEntryPoint("answer")
+		g.AddEdge("answer", END)
+		g.SetInputKeys("input")
+		g.SetSchema(&StructSchema[map[string]any]{InitialValue: map[string]any{"config": map[string]any{}}})
+		_, err = g.Compile()
+		assert.NoError(t, err)
+	})
+
+	t.Run("Conditional edges and undeclared nodes", func(t *testing.T) {
+		g := newGraph()
+		g.SetEntryPoint("analyze")
+		g.AddConditionalEdge("analyze", func(ctx context.Context, s map[string]any) string { return "enhance" })
+		g.AddEdge("enhance", "summarize")
+
+		// Undeclared targets may route anywhere, including to summarize via enhance
+		_, err := g.Compile()
+		require.NoError(t, err)
+
+		g = newGraph()
+		g.SetEntryPoint("route")
+		g.AddNode("route", "route", pass)
+		g.AddConditionalEdge("route", func(ctx context.Context, s map[string]any) string { return "enhance" })
+		g.SetConditionalTargets("route", "analyze", "enhance")
+		g.AddEdge("analyze", "summarize")
+		g.AddEdge("enhance", "summarize")
+
+		// route is not declared, so it may have written anything summarize reads
+		_, err = g.Compile()
+		require.NoError(t, err)
+	})
+
+	t.Run("Listenable graphs", func(t *testing.T) {
+		g := NewListenableStateGraph[map[string]any]()
+		require.NotNil(t, g.AddNodeWithIO("summarize", "summarize", []string{"analysis"}, nil, pass))
+		g.SetEntryPoint("summarize")
+		g.AddEdge("summarize", END)
+
+		_, err := g.CompileListenable()
+		var readsErr *UnsatisfiedReadsError
+		assert.True(t, errors.As(err, &readsErr), "got %v", err)
+	})
+}
@@ -0,0 +1,78 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// NodeOptions configures how the executor runs a node
+type NodeOptions struct {
+	// Timeout bounds each execution of the node, including the retries of the graph's
+	// retry policy (0 means no timeout). The node runs with a context that is cancelled when
+	// the timeout expires, and the executor returns a *NodeTimeoutError without waiting for
+	// a node that ignores the cancellation. Nodes running in parallel each have their own clock.
+	Timeout time.Duration
+}
+
+// AddNodeWithOptions adds a node like AddNode, run with the given options.
+// A checkpointing graph keeps the checkpoint saved before a node that timed out, so the run
+// can be resumed from the state the node started with.
+//
+// Example:
+//
+//	g.AddNodeWithOptions("query_graph", "Query FalkorDB", queryGraph,
+//	    graph.NodeOptions{Timeout: 10 * time.Second})
+func (g *StateGraph[S]) AddNodeWithOptions(name, description string, fn func(ctx context.Context, state S) (S, error), opts NodeOptions) error {
+	if err := g.AddNode(name, description, fn); err != nil {
+		return err
+	}
+	g.setNodeOptions(name, opts)
+	return nil
+}
+
+// setNodeOptions records the options of a node
+func (g *StateGraph[S]) setNodeOptions(name string, opts NodeOptions) {
+	if g.nodeOptions == nil {
+		g.nodeOptions = make(map[string]NodeOptions)
+	}
+	g.nodeOptions[name] = opts
+}
+
+// executeNode executes a node with retry logic, bounded by its NodeOptions.Timeout
+func (r *StateRunnable[S]) executeNode(ctx context.Context, node TypedNode[S], state S) (S, error) {
+	timeout := r.graph.nodeOptions[node.Name].Timeout
+	if timeout <= 0 {
+		return r.executeNodeWithRetry(ctx, node, state)
+	}
+
+	nodeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		state S
+		err   error
+	}
+	start := time.Now()
+	done := make(chan result, 1)
+	go func() {
+		state, err := r.executeNodeWithRetry(nodeCtx, node, state)
+		done <- result{state: state, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err != nil && ctx.Err() == nil && errors.Is(nodeCtx.Err(), context.DeadlineExceeded) {
+			return res.state, &NodeTimeoutError{Node: node.Name, Timeout: timeout, Elapsed: time.Since(start)}
+		}
+		return res.state, res.err
+	case <-nodeCtx.Done():
+		if ctx.Err() != nil {
+			// The run itself was cancelled; leave the node to react to it as usual
+			res := <-done
+			return res.state, res.err
+		}
+		var zero S
+		return zero, &NodeTimeoutError{Node: node.Name, Timeout: timeout, Elapsed: time.Since(start)}
+	}
+}
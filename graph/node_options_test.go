@@ -0,0 +1,103 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateGraph_AddNodeWithOptions(t *testing.T) {
+	sleep := func(d time.Duration, key string) func(ctx context.Context, s map[string]any) (map[string]any, error) {
+		return func(ctx context.Context, s map[string]any) (map[string]any, error) {
+			select {
+			case <-time.After(d):
+				return map[string]any{key: true}, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	t.Run("Times out a slow node", func(t *testing.T) {
+		g := NewStateGraph[map[string]any]()
+		g.AddNodeWithOptions("query", "query", sleep(time.Minute, "query"), NodeOptions{Timeout: 20 * time.Millisecond})
+		g.SetEntryPoint("query")
+		g.AddEdge("query", END)
+		runnable, err := g.Compile()
+		require.NoError(t, err)
+
+		_, err = runnable.Invoke(context.Background(), map[string]any{})
+		var timeoutErr *NodeTimeoutError
+		require.True(t, errors.As(err, &timeoutErr), "got %v", err)
+		assert.Equal(t, "query", timeoutErr.Node)
+		assert.Equal(t, 20*time.Millisecond, timeoutErr.Timeout)
+		assert.GreaterOrEqual(t, timeoutErr.Elapsed, 20*time.Millisecond)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Does not wait for a node ignoring its context", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		g := NewStateGraph[map[string]any]()
+		g.AddNodeWithOptions("stuck", "stuck", func(ctx context.Context, s map[string]any) (map[string]any, error) {
+			<-release
+			return s, nil
+		}, NodeOptions{Timeout: 20 * time.Millisecond})
+		g.SetEntryPoint("stuck")
+		g.AddEdge("stuck", END)
+		runnable, err := g.Compile()
+		require.NoError(t, err)
+
+		_, err = runnable.Invoke(context.Background(), map[string]any{})
+		var timeoutErr *NodeTimeoutError
+		assert.True(t, errors.As(err, &timeoutErr), "got %v", err)
+	})
+
+	t.Run("Parallel branches have independent clocks", func(t *testing.T) {
+		g := NewStateGraph[map[string]any]()
+		g.SetSchema(NewMapSchema())
+		g.AddNode("start", "start", func(ctx context.Context, s map[string]any) (map[string]any, error) { return s, nil })
+		g.AddNodeWithOptions("fast", "fast", sleep(60*time.Millisecond, "fast"), NodeOptions{Timeout: 200 * time.Millisecond})
+		g.AddNodeWithOptions("slow", "slow", sleep(150*time.Millisecond, "slow"), NodeOptions{Timeout: 200 * time.Millisecond})
+		g.SetEntryPoint("start")
+		g.AddEdge("start", "fast")
+		g.AddEdge("start", "slow")
+		g.AddEdge("fast", END)
+		g.AddEdge("slow", END)
+		runnable, err := g.Compile()
+		require.NoError(t, err)
+
+		result, err := runnable.Invoke(context.Background(), map[string]any{})
+		require.NoError(t, err)
+		assert.Equal(t, true, result["fast"])
+		assert.Equal(t, true, result["slow"])
+	})
+
+	t.Run("Checkpoint holds the state before the timed-out node", func(t *testing.T) {
+		g := NewCheckpointableStateGraph[map[string]any]()
+		g.AddNode("prepare", "prepare", func(ctx context.Context, s map[string]any) (map[string]any, error) {
+			return map[string]any{"prepared": true}, nil
+		})
+		g.AddNodeWithOptions("query", "query", sleep(time.Minute, "query"), NodeOptions{Timeout: 20 * time.Millisecond})
+		g.SetEntryPoint("prepare")
+		g.AddEdge("prepare", "query")
+		g.AddEdge("query", END)
+		runnable, err := g.CompileCheckpointable()
+		require.NoError(t, err)
+
+		_, err = runnable.Invoke(context.Background(), map[string]any{})
+		var timeoutErr *NodeTimeoutError
+		require.True(t, errors.As(err, &timeoutErr), "got %v", err)
+
+		checkpoints, err := runnable.ListCheckpoints(context.Background())
+		require.NoError(t, err)
+		require.NotEmpty(t, checkpoints)
+		last := checkpoints[len(checkpoints)-1]
+		assert.Equal(t, "prepare", last.NodeName)
+		assert.Equal(t, map[string]any{"prepared": true}, last.State)
+	})
+}
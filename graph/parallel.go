@@ -84,11 +84,12 @@ func (pn *ParallelNode[S]) Execute(ctx context.Context, state S) ([]S, error) {
 
 // AddParallelNodes adds a set of nodes that execute in parallel.
 // merger is used to combine the results from parallel execution into a single state S.
+// It returns the AddNode error, such as ErrGraphFrozen once the graph has been compiled.
 func (g *StateGraph[S]) AddParallelNodes(
 	groupName string,
 	nodes map[string]func(context.Context, S) (S, error),
 	merger func([]S) S,
-) {
+) error {
 	// Create parallel node group
 	parallelNodes := make([]TypedNode[S], 0, len(nodes))
 	for name, fn := range nodes {
@@ -102,7 +103,7 @@ func (g *StateGraph[S]) AddParallelNodes(
 	parallelNode := NewParallelNode(groupName, parallelNodes...)
 
 	// Wrap with merger
-	g.AddNode(groupName, "Parallel execution group: "+groupName, func(ctx context.Context, state S) (S, error) {
+	return g.AddNode(groupName, "Parallel execution group: "+groupName, func(ctx context.Context, state S) (S, error) {
 		results, err := parallelNode.Execute(ctx, state)
 		if err != nil {
 			var zero S
@@ -152,12 +153,13 @@ func (mr *MapReduceNode[S]) Execute(ctx context.Context, state S) (S, error) {
 	return zero, nil
 }
 
-// AddMapReduceNode adds a map-reduce pattern node
+// AddMapReduceNode adds a map-reduce pattern node.
+// It returns the AddNode error, such as ErrGraphFrozen once the graph has been compiled.
 func (g *StateGraph[S]) AddMapReduceNode(
 	name string,
 	mapFunctions map[string]func(context.Context, S) (S, error),
 	reducer func([]S) (S, error),
-) {
+) error {
 	// Create map nodes
 	mapNodes := make([]TypedNode[S], 0, len(mapFunctions))
 	for nodeName, fn := range mapFunctions {
@@ -169,11 +171,12 @@ func (g *StateGraph[S]) AddMapReduceNode(
 
 	// Create and add map-reduce node
 	mrNode := NewMapReduceNode(name, reducer, mapNodes...)
-	g.AddNode(name, "Map-reduce node: "+name, mrNode.Execute)
+	return g.AddNode(name, "Map-reduce node: "+name, mrNode.Execute)
 }
 
 // FanOutFanIn creates a fan-out/fan-in pattern.
 // aggregator merges worker results into a state S that is passed to the collector.
+// It returns the first error from adding the nodes or edges.
 func (g *StateGraph[S]) FanOutFanIn(
 	source string,
 	_ []string, // workers parameter kept for API compatibility
@@ -181,16 +184,22 @@ func (g *StateGraph[S]) FanOutFanIn(
 	workerFuncs map[string]func(context.Context, S) (S, error),
 	aggregator func([]S) S,
 	collectFunc func(S) (S, error),
-) {
+) error {
 	// Add parallel worker nodes
-	g.AddParallelNodes(source+"_workers", workerFuncs, aggregator)
+	if err := g.AddParallelNodes(source+"_workers", workerFuncs, aggregator); err != nil {
+		return err
+	}
 
 	// Add collector node
-	g.AddNode(collector, "Collector node: "+collector, func(ctx context.Context, state S) (S, error) {
+	if err := g.AddNode(collector, "Collector node: "+collector, func(ctx context.Context, state S) (S, error) {
 		return collectFunc(state)
-	})
+	}); err != nil {
+		return err
+	}
 
 	// Connect source to workers and workers to collector
-	g.AddEdge(source, source+"_workers")
-	g.AddEdge(source+"_workers", collector)
+	if err := g.AddEdge(source, source+"_workers"); err != nil {
+		return err
+	}
+	return g.AddEdge(source+"_workers", collector)
 }
@@ -0,0 +1,43 @@
+package graph
+
+import "context"
+
+// ProgressMetadataKey is the StreamEvent metadata key holding the Progress of a progress event
+const ProgressMetadataKey = "progress"
+
+// Progress is a progress update reported by a running node
+type Progress struct {
+	// Stage identifies the current step of the node (e.g. "search_media")
+	Stage string
+	// Message is a human readable description (e.g. "searching media…")
+	Message string
+}
+
+type progressReporterKey struct{}
+
+type progressKey struct{}
+
+// ReportProgress notifies the node's listeners of progress with a NodeEventProgress event,
+// so long multi-stage nodes can report live status ("searching media…", "generating insights…").
+// Streaming runnables deliver it as a StreamEvent with the Progress under ProgressMetadataKey.
+// It is a no-op when the node does not run in a listenable graph.
+func ReportProgress(ctx context.Context, stage, message string) {
+	if report, ok := ctx.Value(progressReporterKey{}).(func(context.Context, Progress)); ok {
+		report(ctx, Progress{Stage: stage, Message: message})
+	}
+}
+
+// ProgressFromContext returns the progress carried by the context of a NodeEventProgress
+// event, for use in NodeListener implementations
+func ProgressFromContext(ctx context.Context) (Progress, bool) {
+	p, ok := ctx.Value(progressKey{}).(Progress)
+	return p, ok
+}
+
+// withProgressReporter returns a context whose ReportProgress calls notify listeners
+// of ln with the given state
+func withProgressReporter[S any](ctx context.Context, ln *ListenableNode[S], state S) context.Context {
+	return context.WithValue(ctx, progressReporterKey{}, func(ctx context.Context, p Progress) {
+		ln.NotifyListeners(context.WithValue(ctx, progressKey{}, p), NodeEventProgress, state, nil)
+	})
+}
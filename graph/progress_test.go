@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportProgress(t *testing.T) {
+	g := NewStreamingStateGraph[map[string]any]()
+	g.AddNode("media", "Media engine", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		ReportProgress(ctx, "search", "searching media…")
+		ReportProgress(ctx, "summarize", "summarizing coverage…")
+		return map[string]any{"media": "done"}, nil
+	})
+	g.AddNode("insight", "Insight engine", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		ReportProgress(ctx, "insight", "generating insights…")
+		return map[string]any{"insight": "done"}, nil
+	})
+	g.AddEdge("media", "insight")
+	g.AddEdge("insight", END)
+	g.SetEntryPoint("media")
+
+	runnable, err := g.CompileStreaming()
+	require.NoError(t, err)
+
+	result := runnable.Stream(context.Background(), map[string]any{})
+	var progress []string
+	for event := range result.Events {
+		if event.Event != NodeEventProgress {
+			continue
+		}
+		p, ok := event.Metadata[ProgressMetadataKey].(Progress)
+		require.True(t, ok)
+		progress = append(progress, event.NodeName+"/"+p.Stage+": "+p.Message)
+	}
+
+	assert.Equal(t, []string{
+		"media/search: searching media…",
+		"media/summarize: summarizing coverage…",
+		"insight/insight: generating insights…",
+	}, progress)
+}
+
+func TestReportProgress_NoListeners(t *testing.T) {
+	// Outside a listenable graph ReportProgress is a no-op
+	assert.NotPanics(t, func() { ReportProgress(context.Background(), "stage", "message") })
+	_, ok := ProgressFromContext(context.Background())
+	assert.False(t, ok)
+}
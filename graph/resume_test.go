@@ -2,9 +2,11 @@ package graph
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGraphResume(t *testing.T) {
@@ -88,3 +90,150 @@ func TestGraphResume(t *testing.T) {
 		assert.Equal(t, "StartABC", res2["value"])
 	})
 }
+
+func TestGraphResumeEntryPoint(t *testing.T) {
+	type approvalState struct {
+		Request  string
+		Approved bool
+		Log      []string
+	}
+
+	g := NewStateGraph[approvalState]()
+	g.AddNode("review", "Review", func(ctx context.Context, state approvalState) (approvalState, error) {
+		if !state.Approved {
+			return state, errors.New("review ran without approval")
+		}
+		state.Log = append(state.Log, "review")
+		return state, nil
+	})
+	g.AddNode("deploy", "Deploy", func(ctx context.Context, state approvalState) (approvalState, error) {
+		state.Log = append(state.Log, "deploy")
+		return state, nil
+	})
+	g.SetEntryPoint("review")
+	g.AddEdge("review", "deploy")
+	g.AddEdge("deploy", END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	t.Run("InterruptBefore", func(t *testing.T) {
+		config := &Config{InterruptBefore: []string{"review"}}
+		_, err := runnable.InvokeWithConfig(context.Background(), approvalState{Request: "deploy"}, config)
+
+		var interrupt *GraphInterrupt
+		require.ErrorAs(t, err, &interrupt)
+		assert.Equal(t, "review", interrupt.Node)
+		assert.Equal(t, []string{"review"}, interrupt.NextNodes)
+		state := interrupt.State.(approvalState)
+		assert.Equal(t, approvalState{Request: "deploy"}, state)
+
+		// The InterruptBefore config can be kept: the resumed run starts at review
+		state.Approved = true
+		res, err := runnable.InvokeWithConfig(context.Background(), state, interrupt.Resume(config))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"review", "deploy"}, res.Log)
+	})
+
+	t.Run("InterruptBeforeIf", func(t *testing.T) {
+		config := &Config{InterruptBeforeIf: map[string]func(any) bool{
+			"review": InterruptIf(func(s approvalState) bool { return s.Request == "deploy" }),
+		}}
+		_, err := runnable.InvokeWithConfig(context.Background(), approvalState{Request: "deploy"}, config)
+
+		var interrupt *GraphInterrupt
+		require.ErrorAs(t, err, &interrupt)
+		assert.Equal(t, "review", interrupt.Node)
+
+		state := interrupt.State.(approvalState)
+		state.Approved = true
+		res, err := runnable.InvokeWithConfig(context.Background(), state, interrupt.Resume(config))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"review", "deploy"}, res.Log)
+	})
+
+	t.Run("InterruptAfter", func(t *testing.T) {
+		config := &Config{InterruptAfter: []string{"review"}}
+		_, err := runnable.InvokeWithConfig(context.Background(), approvalState{Approved: true}, config)
+
+		var interrupt *GraphInterrupt
+		require.ErrorAs(t, err, &interrupt)
+		assert.Equal(t, "review", interrupt.Node)
+		assert.Equal(t, []string{"deploy"}, interrupt.NextNodes)
+		assert.Equal(t, []string{"review"}, interrupt.State.(approvalState).Log)
+
+		// The InterruptAfter config can be kept: the resumed run starts after review
+		res, err := runnable.InvokeWithConfig(context.Background(), interrupt.State.(approvalState), interrupt.Resume(config))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"review", "deploy"}, res.Log)
+	})
+}
+
+func TestGraphResumeEntryPointWithSchema(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	schema := NewMapSchema()
+	schema.RegisterReducer("steps", AppendReducer)
+	g.SetSchema(schema)
+	g.AddNode("plan", "Plan", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"steps": []string{"plan:" + state["goal"].(string)}}, nil
+	})
+	g.AddNode("act", "Act", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"steps": []string{"act"}}, nil
+	})
+	g.SetEntryPoint("plan")
+	g.AddEdge("plan", "act")
+	g.AddEdge("act", END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	_, err = runnable.InvokeWithConfig(context.Background(), map[string]any{"goal": "draft"}, &Config{InterruptBefore: []string{"plan"}})
+	var interrupt *GraphInterrupt
+	require.ErrorAs(t, err, &interrupt)
+	state := interrupt.State.(map[string]any)
+	assert.Equal(t, "draft", state["goal"])
+	assert.NotContains(t, state, "steps")
+
+	// The human edits the goal before the entry point runs
+	state["goal"] = "final"
+	res, err := runnable.InvokeWithConfig(context.Background(), state, interrupt.Resume(nil))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"plan:final", "act"}, res["steps"])
+}
+
+func TestGraphResumeStopsAtLaterVisits(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("draft", "Draft", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		state["drafts"] = state["drafts"].(int) + 1
+		return state, nil
+	})
+	g.AddNode("review", "Review", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return state, nil
+	})
+	g.SetEntryPoint("draft")
+	g.AddEdge("draft", "review")
+	g.AddConditionalEdge("review", func(ctx context.Context, state map[string]any) string {
+		if state["drafts"].(int) < 2 {
+			return "draft"
+		}
+		return END
+	})
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	config := &Config{InterruptBefore: []string{"review"}}
+	_, err = runnable.InvokeWithConfig(context.Background(), map[string]any{"drafts": 0}, config)
+	var interrupt *GraphInterrupt
+	require.ErrorAs(t, err, &interrupt)
+
+	// Resuming with the original config runs review, then stops before its next visit
+	_, err = runnable.InvokeWithConfig(context.Background(), interrupt.State.(map[string]any), interrupt.Resume(config))
+	require.ErrorAs(t, err, &interrupt)
+	assert.Equal(t, "review", interrupt.Node)
+	assert.Equal(t, 2, interrupt.State.(map[string]any)["drafts"])
+
+	res, err := runnable.InvokeWithConfig(context.Background(), interrupt.State.(map[string]any), interrupt.Resume(config))
+	require.NoError(t, err)
+	assert.Equal(t, 2, res["drafts"])
+}
@@ -8,6 +8,46 @@ import (
 	"time"
 )
 
+// RetryMetadataKey is the StreamEvent metadata key holding the RetryAttempt of a retry event
+const RetryMetadataKey = "retry"
+
+// RetryAttempt describes a retry of a node added with AddNodeWithRetry
+type RetryAttempt struct {
+	// Attempt is the number of the attempt about to run, starting at 2 for the first retry
+	Attempt int
+	// MaxAttempts is the configured number of attempts
+	MaxAttempts int
+	// Delay is the backoff before the attempt
+	Delay time.Duration
+	// Err is the error of the failed attempt
+	Err error
+}
+
+type retryReporterKey struct{}
+
+type retryAttemptKey struct{}
+
+// RetryAttemptFromContext returns the retry carried by the context of a NodeEventRetry
+// event, for use in NodeListener implementations
+func RetryAttemptFromContext(ctx context.Context) (RetryAttempt, bool) {
+	a, ok := ctx.Value(retryAttemptKey{}).(RetryAttempt)
+	return a, ok
+}
+
+// withRetryReporter returns a context whose retries notify listeners of ln with the given state
+func withRetryReporter[S any](ctx context.Context, ln *ListenableNode[S], state S) context.Context {
+	return context.WithValue(ctx, retryReporterKey{}, func(ctx context.Context, a RetryAttempt) {
+		ln.NotifyListeners(context.WithValue(ctx, retryAttemptKey{}, a), NodeEventRetry, state, a.Err)
+	})
+}
+
+// reportRetry emits a NodeEventRetry event when the node runs in a listenable graph
+func reportRetry(ctx context.Context, a RetryAttempt) {
+	if report, ok := ctx.Value(retryReporterKey{}).(func(context.Context, RetryAttempt)); ok {
+		report(ctx, a)
+	}
+}
+
 // RetryConfig configures retry behavior for nodes
 type RetryConfig struct {
 	MaxAttempts     int
@@ -77,6 +117,12 @@ func (rn *RetryNode[S]) Execute(ctx context.Context, state S) (S, error) {
 
 		// Don't sleep after the last attempt
 		if attempt < rn.config.MaxAttempts {
+			// Give up early if the next attempt could not start before the deadline
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+				return zero, fmt.Errorf("deadline too close to retry %s after %d attempts: %w", rn.node.Name, attempt, lastErr)
+			}
+			reportRetry(ctx, RetryAttempt{Attempt: attempt + 1, MaxAttempts: rn.config.MaxAttempts, Delay: delay, Err: err})
+
 			// Sleep with exponential backoff
 			select {
 			case <-time.After(delay):
@@ -92,20 +138,25 @@ func (rn *RetryNode[S]) Execute(ctx context.Context, state S) (S, error) {
 		rn.config.MaxAttempts, rn.node.Name, lastErr)
 }
 
-// AddNodeWithRetry adds a node with retry logic
+// AddNodeWithRetry adds a node with retry logic: a failing attempt is retried with
+// exponential backoff until it succeeds, MaxAttempts is reached, the error is not retryable,
+// or the context deadline would pass before the next attempt. The last error is returned
+// wrapped. In listenable graphs the node emits a NodeEventRetry event before each retry and
+// a single NodeEventError on the final failure.
+// It returns the AddNode error, such as ErrGraphFrozen once the graph has been compiled.
 func (g *StateGraph[S]) AddNodeWithRetry(
 	name string,
 	description string,
 	fn func(context.Context, S) (S, error),
 	config *RetryConfig,
-) {
+) error {
 	node := TypedNode[S]{
 		Name:        name,
 		Description: description,
 		Function:    fn,
 	}
 	retryNode := NewRetryNode(node, config)
-	g.AddNode(name, description, retryNode.Execute)
+	return g.AddNode(name, description, retryNode.Execute)
 }
 
 // TimeoutNode wraps a node with timeout logic
@@ -151,20 +202,21 @@ func (tn *TimeoutNode[S]) Execute(ctx context.Context, state S) (S, error) {
 	}
 }
 
-// AddNodeWithTimeout adds a node with timeout
+// AddNodeWithTimeout adds a node with timeout.
+// It returns the AddNode error, such as ErrGraphFrozen once the graph has been compiled.
 func (g *StateGraph[S]) AddNodeWithTimeout(
 	name string,
 	description string,
 	fn func(context.Context, S) (S, error),
 	timeout time.Duration,
-) {
+) error {
 	node := TypedNode[S]{
 		Name:        name,
 		Description: description,
 		Function:    fn,
 	}
 	timeoutNode := NewTimeoutNode(node, timeout)
-	g.AddNode(name, description, timeoutNode.Execute)
+	return g.AddNode(name, description, timeoutNode.Execute)
 }
 
 // CircuitBreakerConfig configures circuit breaker behavior
@@ -255,20 +307,21 @@ func (cb *CircuitBreaker[S]) Execute(ctx context.Context, state S) (S, error) {
 	return result, nil
 }
 
-// AddNodeWithCircuitBreaker adds a node with circuit breaker
+// AddNodeWithCircuitBreaker adds a node with circuit breaker.
+// It returns the AddNode error, such as ErrGraphFrozen once the graph has been compiled.
 func (g *StateGraph[S]) AddNodeWithCircuitBreaker(
 	name string,
 	description string,
 	fn func(context.Context, S) (S, error),
 	config CircuitBreakerConfig,
-) {
+) error {
 	node := TypedNode[S]{
 		Name:        name,
 		Description: description,
 		Function:    fn,
 	}
 	cb := NewCircuitBreaker(node, config)
-	g.AddNode(name, description, cb.Execute)
+	return g.AddNode(name, description, cb.Execute)
 }
 
 // RateLimiter implements rate limiting for nodes
@@ -318,21 +371,22 @@ func (rl *RateLimiter[S]) Execute(ctx context.Context, state S) (S, error) {
 	return rl.node.Function(ctx, state)
 }
 
-// AddNodeWithRateLimit adds a node with rate limiting
+// AddNodeWithRateLimit adds a node with rate limiting.
+// It returns the AddNode error, such as ErrGraphFrozen once the graph has been compiled.
 func (g *StateGraph[S]) AddNodeWithRateLimit(
 	name string,
 	description string,
 	fn func(context.Context, S) (S, error),
 	maxCalls int,
 	window time.Duration,
-) {
+) error {
 	node := TypedNode[S]{
 		Name:        name,
 		Description: description,
 		Function:    fn,
 	}
 	rl := NewRateLimiter(node, maxCalls, window)
-	g.AddNode(name, description, rl.Execute)
+	return g.AddNode(name, description, rl.Execute)
 }
 
 // ExponentialBackoffRetry implements exponential backoff with jitter
@@ -3,6 +3,8 @@ package graph_test
 import (
 	"context"
 	"errors"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -169,6 +171,84 @@ func TestRetryNode(t *testing.T) {
 	})
 }
 
+func TestRetryNodeEvents(t *testing.T) {
+	t.Parallel()
+
+	g := graph.NewListenableStateGraph[map[string]any]()
+	var (
+		mu       sync.Mutex
+		events   []graph.NodeEvent
+		attempts []int
+	)
+	node := g.AddNodeWithRetry("flaky", "flaky", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return nil, errors.New("rate limited")
+	}, &graph.RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond, BackoffFactor: 2, MaxDelay: time.Second})
+	node.AddListener(graph.NodeListenerFunc[map[string]any](func(ctx context.Context, event graph.NodeEvent, nodeName string, state map[string]any, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+		if a, ok := graph.RetryAttemptFromContext(ctx); ok {
+			attempts = append(attempts, a.Attempt)
+		}
+	}))
+	g.SetEntryPoint("flaky")
+	g.AddEdge("flaky", graph.END)
+
+	runnable, err := g.CompileListenable()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	_, err = runnable.Invoke(context.Background(), map[string]any{})
+	if err == nil || err.Error() != "error in node flaky: max retries (3) exceeded for flaky: rate limited" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []graph.NodeEvent{graph.NodeEventStart, graph.NodeEventRetry, graph.NodeEventRetry, graph.NodeEventError}
+	if len(events) != len(want) {
+		t.Fatalf("Expected events %v, got %v", want, events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Fatalf("Expected events %v, got %v", want, events)
+		}
+	}
+	if len(attempts) != 2 || attempts[0] != 2 || attempts[1] != 3 {
+		t.Errorf("Expected retry attempts [2 3], got %v", attempts)
+	}
+}
+
+func TestRetryNodeDeadline(t *testing.T) {
+	t.Parallel()
+
+	g := graph.NewStateGraph[map[string]any]()
+	callCount := int32(0)
+	g.AddNodeWithRetry("slow_retry", "slow_retry", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		atomic.AddInt32(&callCount, 1)
+		return nil, errors.New("unavailable")
+	}, &graph.RetryConfig{MaxAttempts: 5, InitialDelay: time.Minute, BackoffFactor: 2, MaxDelay: time.Minute})
+	g.SetEntryPoint("slow_retry")
+	g.AddEdge("slow_retry", graph.END)
+
+	runnable, err := g.Compile()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err = runnable.Invoke(ctx, map[string]any{})
+	if err == nil || !strings.Contains(err.Error(), "deadline too close to retry slow_retry after 1 attempts: unavailable") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected to give up without waiting for the backoff, took %v", elapsed)
+	}
+	if atomic.LoadInt32(&callCount) != 1 {
+		t.Errorf("Expected 1 call, got %d", callCount)
+	}
+}
+
 func TestTimeoutNode(t *testing.T) {
 	t.Parallel()
 
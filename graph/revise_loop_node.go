@@ -0,0 +1,106 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// State keys read and written by the node returned by NewReviseLoopNode
+const (
+	// ReviseLoopInputKey holds the request (a string) the text is written for
+	ReviseLoopInputKey = "input"
+	// ReviseLoopOutputKey receives the final text
+	ReviseLoopOutputKey = "output"
+	// ReviseLoopCritiqueKey receives the last critique
+	ReviseLoopCritiqueKey = "critique"
+	// ReviseLoopIterationsKey receives the number of drafts that were generated
+	ReviseLoopIterationsKey = "revise_iterations"
+)
+
+// ReviseLoopApproved is the reply the critic gives when a draft needs no revision
+const ReviseLoopApproved = "APPROVED"
+
+// NewReviseLoopNode creates a node that runs a generate → critique → revise loop, the core of
+// the reflection agent, inside a single node of a larger graph.
+//
+// The node writes a draft for the request in ReviseLoopInputKey using generatePrompt as the
+// system prompt, then asks the model to critique it using critiquePrompt. The critic is told to
+// reply ReviseLoopApproved when the draft needs no changes; otherwise the draft is revised
+// with the critique and the loop continues, generating at most maxIterations drafts
+// (3 if maxIterations <= 0). The final text, the last critique and the number of drafts are
+// written to ReviseLoopOutputKey, ReviseLoopCritiqueKey and ReviseLoopIterationsKey.
+//
+// Example:
+//
+//	g.AddNode("answer", "Self-reviewed answer", graph.NewReviseLoopNode(model,
+//	    "Answer the question using the retrieved context.",
+//	    "Check the answer for unsupported claims and missing details.",
+//	    3,
+//	))
+func NewReviseLoopNode(model llms.Model, generatePrompt, critiquePrompt string, maxIterations int) func(ctx context.Context, state map[string]any) (map[string]any, error) {
+	if maxIterations <= 0 {
+		maxIterations = 3
+	}
+	critiquePrompt = fmt.Sprintf("%s\nIf the text needs no changes, reply with %s only.", critiquePrompt, ReviseLoopApproved)
+
+	return func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		input, ok := state[ReviseLoopInputKey].(string)
+		if !ok {
+			return nil, fmt.Errorf("%s key not found or not a string", ReviseLoopInputKey)
+		}
+
+		draft, err := generateText(ctx, model, generatePrompt, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate draft: %w", err)
+		}
+		iterations := 1
+
+		var critique string
+		for iterations < maxIterations {
+			critique, err = generateText(ctx, model, critiquePrompt, fmt.Sprintf("Request: %s\nText: %s", input, draft))
+			if err != nil {
+				return nil, fmt.Errorf("failed to critique draft %d: %w", iterations, err)
+			}
+			if isApproved(critique) {
+				break
+			}
+
+			revision := fmt.Sprintf("Revise the text based on the critique.\nRequest: %s\nText: %s\nCritique: %s", input, draft, critique)
+			draft, err = generateText(ctx, model, generatePrompt, revision)
+			if err != nil {
+				return nil, fmt.Errorf("failed to revise draft %d: %w", iterations, err)
+			}
+			iterations++
+		}
+
+		result := maps.Clone(state)
+		result[ReviseLoopOutputKey] = draft
+		result[ReviseLoopCritiqueKey] = critique
+		result[ReviseLoopIterationsKey] = iterations
+		return result, nil
+	}
+}
+
+// generateText returns the model's reply to prompt under the given system prompt
+func generateText(ctx context.Context, model llms.Model, system, prompt string) (string, error) {
+	resp, err := model.GenerateContent(ctx, []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, system),
+		llms.TextParts(llms.ChatMessageTypeHuman, prompt),
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("empty response")
+	}
+	return resp.Choices[0].Content, nil
+}
+
+// isApproved reports whether a critique approves the draft
+func isApproved(critique string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(critique)), ReviseLoopApproved)
+}
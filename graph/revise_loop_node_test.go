@@ -0,0 +1,59 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// scriptedModel replies with the given responses in order
+type scriptedModel struct {
+	replies []string
+	prompts []string
+}
+
+func (m *scriptedModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	m.prompts = append(m.prompts, messages[len(messages)-1].Parts[0].(llms.TextContent).Text)
+	reply := m.replies[0]
+	m.replies = m.replies[1:]
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: reply}}}, nil
+}
+
+func (m *scriptedModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return "", nil
+}
+
+func TestReviseLoopNode(t *testing.T) {
+	t.Run("Revises until approved", func(t *testing.T) {
+		model := &scriptedModel{replies: []string{"draft 1", "Too short.", "draft 2", "APPROVED"}}
+		node := NewReviseLoopNode(model, "Write a summary.", "Review the summary.", 5)
+
+		result, err := node(context.Background(), map[string]any{ReviseLoopInputKey: "summarize", "other": 1})
+		require.NoError(t, err)
+		assert.Equal(t, "draft 2", result[ReviseLoopOutputKey])
+		assert.Equal(t, "APPROVED", result[ReviseLoopCritiqueKey])
+		assert.Equal(t, 2, result[ReviseLoopIterationsKey])
+		assert.Equal(t, 1, result["other"])
+		assert.Contains(t, model.prompts[2], "Critique: Too short.")
+	})
+
+	t.Run("Stops at max iterations", func(t *testing.T) {
+		model := &scriptedModel{replies: []string{"draft 1", "Weak.", "draft 2"}}
+		node := NewReviseLoopNode(model, "Write.", "Review.", 2)
+
+		result, err := node(context.Background(), map[string]any{ReviseLoopInputKey: "q"})
+		require.NoError(t, err)
+		assert.Equal(t, "draft 2", result[ReviseLoopOutputKey])
+		assert.Equal(t, 2, result[ReviseLoopIterationsKey])
+		assert.Empty(t, model.replies)
+	})
+
+	t.Run("Missing input", func(t *testing.T) {
+		node := NewReviseLoopNode(&scriptedModel{}, "Write.", "Review.", 2)
+		_, err := node(context.Background(), map[string]any{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,18 @@
+package graph
+
+import "context"
+
+// RouteByThreshold returns a condition for AddConditionalEdge that routes on a numeric
+// score: to above when getConfidence(state) >= threshold, otherwise to below.
+// A typical use is abort-and-escalate, routing low-confidence answers to a human:
+//
+//	g.AddConditionalEdge("analyze", graph.RouteByThreshold(
+//	    func(s State) float64 { return s.Confidence }, 0.6, "answer", "escalate"))
+func RouteByThreshold[S any](getConfidence func(S) float64, threshold float64, above, below string) func(context.Context, S) string {
+	return func(_ context.Context, state S) string {
+		if getConfidence(state) >= threshold {
+			return above
+		}
+		return below
+	}
+}
@@ -0,0 +1,129 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"slices"
+	"sync"
+)
+
+// AddNodeWithCompensation adds a node with a compensating action, following the saga
+// pattern for workflows with side effects (e.g. reserve inventory, charge payment, notify
+// warehouse). When a run fails, the compensations of the nodes that completed successfully
+// are run in reverse order of completion, each with the state the node returned, so a
+// failed charge releases the reserved inventory.
+//
+// Compensations run after the run failed and before the final node (see SetFinalNode).
+// They run even if the run was cancelled or timed out, and one failing compensation does
+// not stop the others; their failures are returned as *CompensationError values joined to
+// the run error. Interrupted runs are not compensated, and completions are tracked per
+// invocation, so nodes completed before resuming from an interrupt are not compensated.
+//
+// Example:
+//
+//	g.AddNodeWithCompensation("reserve", "Reserve inventory", reserve,
+//	    func(ctx context.Context, state OrderState) error {
+//	        return inventory.Release(ctx, state.ReservationID)
+//	    })
+func (g *StateGraph[S]) AddNodeWithCompensation(name, description string, fn func(ctx context.Context, state S) (S, error), compensate func(ctx context.Context, state S) error) error {
+	if err := g.AddNode(name, description, fn); err != nil {
+		return err
+	}
+	g.setCompensation(name, compensate)
+	return nil
+}
+
+// setCompensation registers the compensating action of a node
+func (g *StateGraph[S]) setCompensation(name string, compensate func(ctx context.Context, state S) error) {
+	if g.compensations == nil {
+		g.compensations = make(map[string]func(ctx context.Context, state S) error)
+	}
+	g.compensations[name] = compensate
+}
+
+// completedStep is a node with a compensation that completed during a run
+type completedStep struct {
+	node   string
+	result any
+}
+
+// sagaLog records the compensable nodes that completed during a run, in order
+type sagaLog struct {
+	mu    sync.Mutex
+	steps []completedStep
+}
+
+func (l *sagaLog) add(node string, result any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.steps = append(l.steps, completedStep{node: node, result: result})
+}
+
+func (l *sagaLog) snapshot() []completedStep {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.steps)
+}
+
+type sagaLogKey struct{}
+
+func withSagaLog(ctx context.Context, log *sagaLog) context.Context {
+	return context.WithValue(ctx, sagaLogKey{}, log)
+}
+
+func getSagaLog(ctx context.Context) *sagaLog {
+	log, _ := ctx.Value(sagaLogKey{}).(*sagaLog)
+	return log
+}
+
+// recordCompletions records the nodes of a step that succeeded and have a compensation
+func (r *StateRunnable[S]) recordCompletions(ctx context.Context, nodes []string, results []S, errorsList []error) {
+	log := getSagaLog(ctx)
+	if log == nil {
+		return
+	}
+	for i, node := range nodes {
+		if _, ok := r.graph.compensations[node]; ok && errorsList[i] == nil {
+			log.add(node, results[i])
+		}
+	}
+}
+
+// compensate runs the compensations of the completed nodes in reverse order after the run
+// failed with runErr, returning runErr joined with the compensation failures
+func (r *StateRunnable[S]) compensate(ctx context.Context, runErr error, config *Config) error {
+	var interrupt *GraphInterrupt
+	log := getSagaLog(ctx)
+	if log == nil || errors.As(runErr, &interrupt) {
+		return runErr
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	if config != nil {
+		ctx = WithConfig(ctx, config)
+	}
+
+	errs := []error{runErr}
+	steps := log.snapshot()
+	for _, step := range slices.Backward(steps) {
+		state, _ := step.result.(S)
+		if err := r.callCompensation(withNodeLogger(ctx, step.node), step.node, state); err != nil {
+			errs = append(errs, &CompensationError{Node: step.node, Err: err})
+		}
+	}
+	if len(errs) == 1 {
+		return runErr
+	}
+	return errors.Join(errs...)
+}
+
+// callCompensation invokes the compensation of a node, converting panics into errors
+func (r *StateRunnable[S]) callCompensation(ctx context.Context, node string, state S) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = &NodePanicError{Node: node, Value: p, Stack: debug.Stack()}
+		}
+	}()
+	return r.graph.compensations[node](ctx, state)
+}
@@ -0,0 +1,117 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateGraph_AddNodeWithCompensation(t *testing.T) {
+	type order struct {
+		Reservation string
+		Charge      string
+	}
+
+	var (
+		mu          sync.Mutex
+		compensated []string
+	)
+	record := func(s string) {
+		mu.Lock()
+		defer mu.Unlock()
+		compensated = append(compensated, s)
+	}
+
+	newGraph := func(notify func(ctx context.Context, s order) (order, error), releaseErr error) *StateGraph[order] {
+		compensated = nil
+		g := NewStateGraph[order]()
+		g.AddNodeWithCompensation("reserve", "Reserve inventory",
+			func(ctx context.Context, s order) (order, error) {
+				s.Reservation = "r-1"
+				return s, nil
+			},
+			func(ctx context.Context, s order) error {
+				record("release " + s.Reservation)
+				return releaseErr
+			})
+		g.AddNodeWithCompensation("charge", "Charge payment",
+			func(ctx context.Context, s order) (order, error) {
+				s.Charge = "c-1"
+				return s, nil
+			},
+			func(ctx context.Context, s order) error {
+				require.NoError(t, ctx.Err(), "compensations run with a live context")
+				record("refund " + s.Charge)
+				return nil
+			})
+		g.AddNode("notify", "Notify warehouse", notify)
+		g.SetEntryPoint("reserve")
+		g.AddEdge("reserve", "charge")
+		g.AddEdge("charge", "notify")
+		g.AddEdge("notify", END)
+		return g
+	}
+	failNotify := func(ctx context.Context, s order) (order, error) {
+		return s, errors.New("warehouse unavailable")
+	}
+
+	t.Run("Compensates completed nodes in reverse order", func(t *testing.T) {
+		runnable, err := newGraph(failNotify, nil).Compile()
+		require.NoError(t, err)
+
+		_, err = runnable.Invoke(context.Background(), order{})
+		assert.EqualError(t, err, "error in node notify: warehouse unavailable")
+		assert.Equal(t, []string{"refund c-1", "release r-1"}, compensated)
+	})
+
+	t.Run("No compensation on success", func(t *testing.T) {
+		runnable, err := newGraph(func(ctx context.Context, s order) (order, error) { return s, nil }, nil).Compile()
+		require.NoError(t, err)
+
+		result, err := runnable.Invoke(context.Background(), order{})
+		require.NoError(t, err)
+		assert.Equal(t, order{Reservation: "r-1", Charge: "c-1"}, result)
+		assert.Empty(t, compensated)
+	})
+
+	t.Run("Compensation failures are joined to the run error", func(t *testing.T) {
+		runnable, err := newGraph(failNotify, errors.New("inventory service down")).Compile()
+		require.NoError(t, err)
+
+		_, err = runnable.Invoke(context.Background(), order{})
+		var compErr *CompensationError
+		require.True(t, errors.As(err, &compErr), "got %v", err)
+		assert.Equal(t, "reserve", compErr.Node)
+		var execErr *ExecutionError
+		assert.True(t, errors.As(err, &execErr))
+		assert.Equal(t, []string{"refund c-1", "release r-1"}, compensated)
+		assert.EqualError(t, err, "error in node notify: warehouse unavailable\ncompensation of node reserve failed: inventory service down")
+	})
+
+	t.Run("Cancelled runs are compensated", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		runnable, err := newGraph(func(ctx context.Context, s order) (order, error) {
+			cancel()
+			return s, ctx.Err()
+		}, nil).Compile()
+		require.NoError(t, err)
+
+		_, err = runnable.Invoke(ctx, order{})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, []string{"refund c-1", "release r-1"}, compensated)
+	})
+
+	t.Run("Interrupted runs are not compensated", func(t *testing.T) {
+		runnable, err := newGraph(failNotify, nil).Compile()
+		require.NoError(t, err)
+
+		_, err = runnable.InvokeWithConfig(context.Background(), order{}, &Config{InterruptBefore: []string{"notify"}})
+		var interrupt *GraphInterrupt
+		assert.True(t, errors.As(err, &interrupt))
+		assert.Empty(t, compensated)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"maps"
 	"reflect"
+	"strings"
 )
 
 // StateSchema defines the structure and update logic for the graph state with type safety.
@@ -228,7 +229,10 @@ func MinIntMerge(current, new reflect.Value) reflect.Value {
 type Reducer func(current, new any) (any, error)
 
 // MapSchema implements StateSchema for map[string]any.
-// It allows defining reducers for specific keys.
+// It allows defining reducers for specific keys. Keys may be dotted paths into
+// nested maps (e.g. "agents.researcher.findings"); the maps along such a path are
+// merged key by key instead of being overwritten, so parallel nodes can each
+// contribute to their own nested section of the state.
 type MapSchema struct {
 	Reducers map[string]Reducer
 }
@@ -240,7 +244,7 @@ func NewMapSchema() *MapSchema {
 	}
 }
 
-// RegisterReducer adds a reducer for a specific key.
+// RegisterReducer adds a reducer for a specific key or dotted path to a nested key.
 func (s *MapSchema) RegisterReducer(key string, reducer Reducer) {
 	s.Reducers[key] = reducer
 }
@@ -301,14 +305,20 @@ func (s *MapSchema) Update(current, new map[string]any) (map[string]any, error)
 		return new, nil
 	}
 
+	return s.merge("", current, new)
+}
+
+// merge merges new into a copy of current, applying the reducers registered under prefix
+func (s *MapSchema) merge(prefix string, current, new map[string]any) (map[string]any, error) {
 	// Create a copy of the current map to avoid mutating it directly
 	result := make(map[string]any, len(current))
 	maps.Copy(result, current)
 
 	for k, v := range new {
-		if reducer, ok := s.Reducers[k]; ok {
-			// Use reducer
-			currVal := result[k]
+		path := prefix + k
+		currVal := result[k]
+
+		if reducer, ok := s.Reducers[path]; ok {
 			// Check if current and new values are the same reference
 			// This can happen when a node modifies its input state and returns it
 			if sameValue(currVal, v) {
@@ -317,18 +327,45 @@ func (s *MapSchema) Update(current, new map[string]any) (map[string]any, error)
 			}
 			mergedVal, err := reducer(currVal, v)
 			if err != nil {
-				return nil, fmt.Errorf("failed to reduce key %s: %w", k, err)
+				return nil, fmt.Errorf("failed to reduce key %s: %w", path, err)
 			}
 			result[k] = mergedVal
-		} else {
-			// Default: Overwrite
-			result[k] = v
+			continue
 		}
+
+		// Merge nested maps that have reducers registered below them
+		newMap, isMap := v.(map[string]any)
+		if isMap && s.hasNestedReducers(path) {
+			currMap, _ := currVal.(map[string]any)
+			if currMap != nil && sameValue(currMap, newMap) {
+				continue
+			}
+			merged, err := s.merge(path+".", currMap, newMap)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = merged
+			continue
+		}
+
+		// Default: Overwrite
+		result[k] = v
 	}
 
 	return result, nil
 }
 
+// hasNestedReducers reports whether a reducer is registered for a dotted path below path
+func (s *MapSchema) hasNestedReducers(path string) bool {
+	prefix := path + "."
+	for key := range s.Reducers {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // Common Reducers
 
 // OverwriteReducer replaces the old value with the new one.
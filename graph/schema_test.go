@@ -461,6 +461,53 @@ func TestMapSchema_Update(t *testing.T) {
 	})
 }
 
+func TestMapSchema_NestedReducers(t *testing.T) {
+	schema := NewMapSchema()
+	schema.RegisterReducer("agents.researcher.findings", AppendReducer)
+	schema.RegisterReducer("agents.writer.drafts", AppendReducer)
+
+	current := map[string]any{
+		"agents": map[string]any{
+			"researcher": map[string]any{"findings": []string{"a"}, "status": "running"},
+			"writer":     map[string]any{"drafts": []string{"v1"}},
+		},
+		"topic": "go",
+	}
+
+	// Results of two parallel agents, each touching its own section
+	researcher := map[string]any{"agents": map[string]any{
+		"researcher": map[string]any{"findings": []string{"b"}, "status": "done"},
+	}}
+	writer := map[string]any{"agents": map[string]any{
+		"writer": map[string]any{"drafts": []string{"v2"}},
+	}}
+
+	result, err := schema.Update(current, researcher)
+	assert.NoError(t, err)
+	result, err = schema.Update(result, writer)
+	assert.NoError(t, err)
+
+	agents := result["agents"].(map[string]any)
+	assert.Equal(t, map[string]any{"findings": []string{"a", "b"}, "status": "done"}, agents["researcher"])
+	assert.Equal(t, map[string]any{"drafts": []string{"v1", "v2"}}, agents["writer"])
+	assert.Equal(t, "go", result["topic"])
+
+	// The current state is not mutated
+	assert.Equal(t, []string{"a"}, current["agents"].(map[string]any)["researcher"].(map[string]any)["findings"])
+
+	// Maps without nested reducers are still overwritten
+	result, err = schema.Update(result, map[string]any{"topic": map[string]any{"name": "rust"}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "rust"}, result["topic"])
+
+	// Reducer errors report the full path
+	schema.RegisterReducer("agents.writer.count", func(current, new any) (any, error) {
+		return nil, assert.AnError
+	})
+	_, err = schema.Update(result, map[string]any{"agents": map[string]any{"writer": map[string]any{"count": 1}}})
+	assert.ErrorContains(t, err, "agents.writer.count")
+}
+
 // Reducer Tests
 
 func TestOverwriteReducer(t *testing.T) {
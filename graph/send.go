@@ -0,0 +1,85 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+)
+
+// Send schedules one execution of a node with its own input, like LangGraph's Send API.
+// A conditional edge added with AddConditionalEdgeSend returns a Send per item of a
+// collection, e.g. per retrieved chunk, so the number of branches is decided at run time.
+type Send struct {
+	// Node is the node to execute
+	Node string
+	// State is the input of the node, which must be of the graph's state type.
+	// A nil State runs the node with the zero state.
+	State any
+}
+
+// NewSend returns a Send executing node with state as its input
+func NewSend(node string, state any) Send {
+	return Send{Node: node, State: state}
+}
+
+// AddConditionalEdgeSend adds a conditional edge whose router returns Sends. In the next
+// step, each Send executes its node with its own state, in parallel with the other Sends
+// (the same node can be sent many times), and the results are merged into the graph state
+// with the schema reducers (see SetSchema). The nodes then follow their own edges, so the
+// branches can converge on a node that combines their results. Returning no Sends (or only
+// Sends to END) routes to END.
+//
+// A node has a single conditional edge, so this replaces an edge added with
+// AddConditionalEdge or AddConditionalEdgeMulti and vice versa. Pending Sends are not part
+// of interrupts: a run interrupted before they execute resumes without them.
+//
+// Example:
+//
+//	g.AddConditionalEdgeSend("retrieve", func(ctx context.Context, state map[string]any) []graph.Send {
+//	    var sends []graph.Send
+//	    for _, chunk := range state["chunks"].([]string) {
+//	        sends = append(sends, graph.NewSend("summarize", map[string]any{"chunk": chunk}))
+//	    }
+//	    return sends
+//	})
+//	g.AddEdge("summarize", "combine")
+//
+// It returns ErrGraphFrozen, without adding the edge, once the graph has been compiled.
+func (g *StateGraph[S]) AddConditionalEdgeSend(from string, router func(ctx context.Context, state S) []Send) error {
+	if g.frozen {
+		return fmt.Errorf("%w: cannot add send edge from %s", ErrGraphFrozen, from)
+	}
+	if g.sendEdges == nil {
+		g.sendEdges = make(map[string]func(ctx context.Context, state S) []Send)
+	}
+	g.clearConditionalEdge(from)
+	g.sendEdges[from] = router
+	return nil
+}
+
+// clearConditionalEdge removes the conditional edge of a node, of any kind
+func (g *StateGraph[S]) clearConditionalEdge(from string) {
+	delete(g.conditionalEdges, from)
+	delete(g.multiConditionalEdges, from)
+	delete(g.sendEdges, from)
+}
+
+// sendInputs appends the Sends to the nodes of a step and returns the input of every node:
+// state for the nodes, the Send state for the Sends
+func sendInputs[S any](nodes []string, sends []Send, state S) ([]string, []S, error) {
+	inputs := make([]S, 0, len(nodes)+len(sends))
+	for range nodes {
+		inputs = append(inputs, state)
+	}
+	for _, send := range sends {
+		var input S
+		if send.State != nil {
+			var ok bool
+			if input, ok = send.State.(S); !ok {
+				return nil, nil, fmt.Errorf("send to %s: state is %T, want %T", send.Node, send.State, input)
+			}
+		}
+		nodes = append(nodes, send.Node)
+		inputs = append(inputs, input)
+	}
+	return nodes, inputs, nil
+}
@@ -0,0 +1,120 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+func newSendGraph(route func(ctx context.Context, state map[string]any) []graph.Send) (*graph.StateGraph[map[string]any], *atomic.Int32) {
+	g := graph.NewStateGraph[map[string]any]()
+	schema := graph.NewMapSchema()
+	schema.RegisterReducer("summaries", graph.AppendReducer)
+	g.SetSchema(schema)
+
+	combined := &atomic.Int32{}
+	g.AddNode("retrieve", "retrieve", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"chunks": []string{"alpha", "beta", "gamma"}}, nil
+	})
+	g.AddNode("summarize", "summarize", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		if _, ok := state["chunks"]; ok {
+			return nil, errors.New("summarize received the parent state")
+		}
+		return map[string]any{"summaries": []string{strings.ToUpper(state["chunk"].(string))}}, nil
+	})
+	g.AddNode("combine", "combine", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		combined.Add(1)
+		summaries, _ := state["summaries"].([]string)
+		sorted := slices.Sorted(slices.Values(summaries))
+		return map[string]any{"answer": strings.Join(sorted, ",")}, nil
+	})
+	g.SetEntryPoint("retrieve")
+	g.AddConditionalEdgeSend("retrieve", route)
+	g.AddEdge("summarize", "combine")
+	g.AddEdge("combine", graph.END)
+	return g, combined
+}
+
+func sendPerChunk(ctx context.Context, state map[string]any) []graph.Send {
+	var sends []graph.Send
+	for _, chunk := range state["chunks"].([]string) {
+		sends = append(sends, graph.NewSend("summarize", map[string]any{"chunk": chunk}))
+	}
+	return sends
+}
+
+func TestSendFanOut(t *testing.T) {
+	g, combined := newSendGraph(sendPerChunk)
+	runnable, err := g.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := runnable.Invoke(context.Background(), map[string]any{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["answer"] != "ALPHA,BETA,GAMMA" {
+		t.Errorf("answer = %v, want ALPHA,BETA,GAMMA", result["answer"])
+	}
+	if got := combined.Load(); got != 1 {
+		t.Errorf("combine ran %d times, want 1", got)
+	}
+}
+
+func TestSendNoneRoutesToEnd(t *testing.T) {
+	g, combined := newSendGraph(func(ctx context.Context, state map[string]any) []graph.Send {
+		return []graph.Send{graph.NewSend(graph.END, nil)}
+	})
+	runnable, err := g.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := runnable.Invoke(context.Background(), map[string]any{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := result["summaries"]; ok || combined.Load() != 0 {
+		t.Errorf("expected the run to end after retrieve, got %v", result)
+	}
+}
+
+func TestSendWrongStateType(t *testing.T) {
+	g, _ := newSendGraph(func(ctx context.Context, state map[string]any) []graph.Send {
+		return []graph.Send{graph.NewSend("summarize", "alpha")}
+	})
+	runnable, err := g.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = runnable.Invoke(context.Background(), map[string]any{})
+	if err == nil || !strings.Contains(err.Error(), "send to summarize: state is string") {
+		t.Fatalf("expected a state type error, got %v", err)
+	}
+}
+
+func TestSendNodeError(t *testing.T) {
+	g, combined := newSendGraph(func(ctx context.Context, state map[string]any) []graph.Send {
+		return append(sendPerChunk(ctx, state), graph.NewSend("summarize", map[string]any{"chunk": "delta", "chunks": nil}))
+	})
+	runnable, err := g.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = runnable.Invoke(context.Background(), map[string]any{})
+	var execErr *graph.ExecutionError
+	if !errors.As(err, &execErr) || execErr.Node != "summarize" {
+		t.Fatalf("expected an ExecutionError from summarize, got %v", err)
+	}
+	if combined.Load() != 0 {
+		t.Error("combine should not run after a failed Send")
+	}
+}
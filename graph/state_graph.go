@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"runtime/debug"
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -35,6 +38,12 @@ type StateGraph[S any] struct {
 	// conditionalEdges contains a map between "From" node, while "To" node is derived based on the condition
 	conditionalEdges map[string]func(ctx context.Context, state S) string
 
+	// multiConditionalEdges maps nodes to routers selecting several next nodes, see AddConditionalEdgeMulti
+	multiConditionalEdges map[string]func(ctx context.Context, state S) []string
+
+	// sendEdges maps nodes to routers returning Sends, see AddConditionalEdgeSend
+	sendEdges map[string]func(ctx context.Context, state S) []Send
+
 	// entryPoint is the name of the entry point node in the graph
 	entryPoint string
 
@@ -46,6 +55,41 @@ type StateGraph[S any] struct {
 
 	// Schema defines the state structure and update logic
 	Schema StateSchema[S]
+
+	// joins maps join target nodes to the predecessors they wait for
+	joins map[string][]string
+
+	// idempotencyKeys maps nodes to functions deriving their idempotency key from state
+	idempotencyKeys map[string]func(S) string
+
+	// finalNode is run at the end of every invocation, see SetFinalNode
+	finalNode string
+
+	// requireAcyclic makes Compile reject cyclic graphs, see RequireAcyclic
+	requireAcyclic bool
+
+	// conditionalTargets maps nodes to the declared targets of their conditional edge
+	conditionalTargets map[string][]string
+
+	// frozen is set by Compile; AddNode, AddEdge and SetSchema fail afterwards
+	frozen bool
+
+	// memoizesSubgraphs is set when a subgraph is added WithMemoization, so each run gets
+	// its own memo table
+	memoizesSubgraphs bool
+
+	// nodeIO holds the state keys declared by AddNodeWithIO, checked by Compile
+	nodeIO map[string]nodeIO
+
+	// inputKeys are the state keys supplied by the caller, see SetInputKeys
+	inputKeys []string
+
+	// compensations maps nodes to the actions undoing them when a run fails,
+	// see AddNodeWithCompensation
+	compensations map[string]func(ctx context.Context, state S) error
+
+	// nodeOptions maps nodes to the options they were added with, see AddNodeWithOptions
+	nodeOptions map[string]NodeOptions
 }
 
 // TypedNode represents a typed node in the graph.
@@ -80,20 +124,31 @@ func NewStateGraph[S any]() *StateGraph[S] {
 //	    state.Count++  // Type-safe access!
 //	    return state, nil
 //	})
-func (g *StateGraph[S]) AddNode(name string, description string, fn func(ctx context.Context, state S) (S, error)) {
+//
+// It returns ErrGraphFrozen, without adding the node, once the graph has been compiled.
+func (g *StateGraph[S]) AddNode(name string, description string, fn func(ctx context.Context, state S) (S, error)) error {
+	if g.frozen {
+		return fmt.Errorf("%w: cannot add node %q", ErrGraphFrozen, name)
+	}
 	g.nodes[name] = TypedNode[S]{
 		Name:        name,
 		Description: description,
 		Function:    fn,
 	}
+	return nil
 }
 
 // AddEdge adds a new edge to the state graph between the "from" and "to" nodes.
-func (g *StateGraph[S]) AddEdge(from, to string) {
+// It returns ErrGraphFrozen, without adding the edge, once the graph has been compiled.
+func (g *StateGraph[S]) AddEdge(from, to string) error {
+	if g.frozen {
+		return fmt.Errorf("%w: cannot add edge %s -> %s", ErrGraphFrozen, from, to)
+	}
 	g.edges = append(g.edges, Edge{
 		From: from,
 		To:   to,
 	})
+	return nil
 }
 
 // AddConditionalEdge adds a conditional edge where the target node is determined at runtime.
@@ -107,28 +162,55 @@ func (g *StateGraph[S]) AddEdge(from, to string) {
 //	    }
 //	    return "low"
 //	})
-func (g *StateGraph[S]) AddConditionalEdge(from string, condition func(ctx context.Context, state S) string) {
+//
+// It returns ErrGraphFrozen, without adding the edge, once the graph has been compiled.
+func (g *StateGraph[S]) AddConditionalEdge(from string, condition func(ctx context.Context, state S) string) error {
+	if g.frozen {
+		return fmt.Errorf("%w: cannot add conditional edge from %s", ErrGraphFrozen, from)
+	}
+	g.clearConditionalEdge(from)
 	g.conditionalEdges[from] = condition
+	return nil
 }
 
 // SetEntryPoint sets the entry point node name for the state graph.
-func (g *StateGraph[S]) SetEntryPoint(name string) {
+// It returns ErrGraphFrozen, leaving the entry point unchanged, once the graph has been compiled.
+func (g *StateGraph[S]) SetEntryPoint(name string) error {
+	if g.frozen {
+		return fmt.Errorf("%w: cannot set entry point %s", ErrGraphFrozen, name)
+	}
 	g.entryPoint = name
+	return nil
 }
 
 // SetRetryPolicy sets the retry policy for the graph.
-func (g *StateGraph[S]) SetRetryPolicy(policy *RetryPolicy) {
+// It returns ErrGraphFrozen, leaving the policy unchanged, once the graph has been compiled.
+func (g *StateGraph[S]) SetRetryPolicy(policy *RetryPolicy) error {
+	if g.frozen {
+		return fmt.Errorf("%w: cannot set retry policy", ErrGraphFrozen)
+	}
 	g.retryPolicy = policy
+	return nil
 }
 
 // SetStateMerger sets the state merger function for the state graph.
-func (g *StateGraph[S]) SetStateMerger(merger TypedStateMerger[S]) {
+// It returns ErrGraphFrozen, leaving the merger unchanged, once the graph has been compiled.
+func (g *StateGraph[S]) SetStateMerger(merger TypedStateMerger[S]) error {
+	if g.frozen {
+		return fmt.Errorf("%w: cannot set state merger", ErrGraphFrozen)
+	}
 	g.stateMerger = merger
+	return nil
 }
 
 // SetSchema sets the state schema for the graph.
-func (g *StateGraph[S]) SetSchema(schema StateSchema[S]) {
+// It returns ErrGraphFrozen, leaving the schema unchanged, once the graph has been compiled.
+func (g *StateGraph[S]) SetSchema(schema StateSchema[S]) error {
+	if g.frozen {
+		return fmt.Errorf("%w: cannot set schema", ErrGraphFrozen)
+	}
 	g.Schema = schema
+	return nil
 }
 
 // StateRunnable represents a compiled state graph that can be invoked with type safety.
@@ -136,20 +218,85 @@ type StateRunnable[S any] struct {
 	graph      *StateGraph[S]
 	tracer     *Tracer
 	nodeRunner func(ctx context.Context, nodeName string, state S) (S, error)
+	hooks      CompileOptions[S]
+
+	// lastTrace is the execution trace of the latest invocation that collected one
+	lastTrace atomic.Pointer[[]TraceEntry]
+}
+
+// CompileOptions configures graph-wide hooks that are applied to every node
+// of the compiled graph. All hooks are optional.
+//
+// Example:
+//
+//	app, err := g.CompileWithOptions(graph.CompileOptions[MyState]{
+//	    OnNodeEnd: func(ctx context.Context, node string, state MyState, d time.Duration, err error) {
+//	        log.Printf("node %s took %v", node, d)
+//	    },
+//	})
+type CompileOptions[S any] struct {
+	// OnNodeStart is called before a node is executed with the state it receives.
+	OnNodeStart func(ctx context.Context, node string, state S)
+
+	// OnNodeEnd is called after a node has executed with its result, the
+	// execution duration and any error returned by the node.
+	OnNodeEnd func(ctx context.Context, node string, state S, duration time.Duration, err error)
+
+	// OnInterrupt is called whenever the graph execution is interrupted,
+	// either statically (InterruptBefore/InterruptAfter) or dynamically.
+	OnInterrupt func(ctx context.Context, interrupt *GraphInterrupt)
 }
 
 // Compile compiles the state graph and returns a StateRunnable instance.
 func (g *StateGraph[S]) Compile() (*StateRunnable[S], error) {
+	return g.CompileWithOptions(CompileOptions[S]{})
+}
+
+// CompileWithOptions compiles the state graph with the given options and
+// returns a StateRunnable instance.
+//
+// The runnable runs a snapshot of the graph definition, and the graph is frozen: AddNode,
+// AddEdge, SetEntryPoint and every other method that changes the definition return
+// ErrGraphFrozen afterwards. The graph can still be compiled again, so one definition can
+// serve many concurrent requests.
+func (g *StateGraph[S]) CompileWithOptions(opts CompileOptions[S]) (*StateRunnable[S], error) {
 	if g.entryPoint == "" {
 		return nil, ErrEntryPointNotSet
 	}
+	if g.requireAcyclic {
+		if err := g.checkAcyclic(); err != nil {
+			return nil, err
+		}
+	}
+	if err := g.checkNodeIO(); err != nil {
+		return nil, err
+	}
 
+	g.frozen = true
 	return &StateRunnable[S]{
-		graph:  g,
+		graph:  g.snapshot(),
 		tracer: nil, // Initialize with no tracer
+		hooks:  opts,
 	}, nil
 }
 
+// snapshot returns a copy of the graph definition that later changes to g do not affect
+func (g *StateGraph[S]) snapshot() *StateGraph[S] {
+	s := *g
+	s.nodes = maps.Clone(g.nodes)
+	s.edges = slices.Clone(g.edges)
+	s.conditionalEdges = maps.Clone(g.conditionalEdges)
+	s.multiConditionalEdges = maps.Clone(g.multiConditionalEdges)
+	s.sendEdges = maps.Clone(g.sendEdges)
+	s.joins = maps.Clone(g.joins)
+	s.idempotencyKeys = maps.Clone(g.idempotencyKeys)
+	s.conditionalTargets = maps.Clone(g.conditionalTargets)
+	s.nodeIO = maps.Clone(g.nodeIO)
+	s.compensations = maps.Clone(g.compensations)
+	s.nodeOptions = maps.Clone(g.nodeOptions)
+	return &s
+}
+
 // SetTracer sets a tracer for observability.
 func (r *StateRunnable[S]) SetTracer(tracer *Tracer) {
 	r.tracer = tracer
@@ -163,8 +310,10 @@ func (r *StateRunnable[S]) GetTracer() *Tracer {
 // WithTracer returns a new StateRunnable with the given tracer.
 func (r *StateRunnable[S]) WithTracer(tracer *Tracer) *StateRunnable[S] {
 	return &StateRunnable[S]{
-		graph:  r.graph,
-		tracer: tracer,
+		graph:      r.graph,
+		tracer:     tracer,
+		nodeRunner: r.nodeRunner,
+		hooks:      r.hooks,
 	}
 }
 
@@ -182,6 +331,32 @@ func (r *StateRunnable[S]) Invoke(ctx context.Context, initialState S) (S, error
 
 // InvokeWithConfig executes the compiled state graph with the given input state and config.
 func (r *StateRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S, config *Config) (S, error) {
+	if len(r.graph.compensations) > 0 {
+		ctx = withSagaLog(ctx, &sagaLog{})
+	}
+	if config != nil {
+		ctx = ensureCollector[TraceEntry](ctx, config.CollectTrace)
+		ctx = ensureCollector[LogEntry](ctx, config.CollectLogs)
+	}
+	if collector := getCollector[TraceEntry](ctx); collector != nil {
+		defer func() {
+			trace := collector.snapshot()
+			r.lastTrace.Store(&trace)
+		}()
+	}
+
+	state, err := r.invoke(ctx, initialState, config)
+	if err != nil {
+		err = r.compensate(ctx, err, config)
+	}
+	if r.graph.finalNode != "" {
+		return r.runFinalNode(ctx, state, err, config)
+	}
+	return state, err
+}
+
+// invoke runs the graph from the entry point (or Config.ResumeFrom) until END
+func (r *StateRunnable[S]) invoke(ctx context.Context, initialState S, config *Config) (S, error) {
 	state := initialState
 
 	// If schema is defined, merge initialState into schema's initial state
@@ -197,6 +372,16 @@ func (r *StateRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S,
 
 	currentNodes := []string{r.graph.entryPoint}
 
+	// Bound the whole invocation by Config.Timeout
+	var deadlineCtx context.Context
+	if config != nil && config.Timeout != nil && *config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *config.Timeout)
+		defer cancel()
+		deadlineCtx = ctx
+	}
+	var lastCompleted []string
+
 	// Handle ResumeFrom
 	if config != nil && len(config.ResumeFrom) > 0 {
 		currentNodes = config.ResumeFrom
@@ -235,7 +420,33 @@ func (r *StateRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S,
 		graphSpan.State = initialState
 	}
 
-	for len(currentNodes) > 0 {
+	if len(r.graph.idempotencyKeys) > 0 && getIdempotencyLedger(ctx) == nil {
+		ledger := &idempotencyLedger{}
+		if config != nil {
+			for _, key := range config.IdempotencyKeys {
+				ledger.add(key)
+			}
+		}
+		ctx = withIdempotencyLedger(ctx, ledger)
+	}
+
+	if r.graph.memoizesSubgraphs && getSubgraphMemo(ctx) == nil {
+		ctx = withSubgraphMemo(ctx, &subgraphMemo{})
+	}
+
+	joins := newJoinTracker(r.graph.joins)
+
+	// sends are the Sends of the previous step, executed with the current nodes
+	var sends []Send
+
+	maxSteps := DefaultMaxSteps
+	if config != nil && config.MaxSteps != 0 {
+		maxSteps = config.MaxSteps
+	}
+	var steps int
+	var lastNode string
+
+	for len(currentNodes) > 0 || len(sends) > 0 {
 		// Filter out END nodes
 		activeNodes := make([]string, 0, len(currentNodes))
 		for _, node := range currentNodes {
@@ -245,31 +456,63 @@ func (r *StateRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S,
 		}
 		currentNodes = activeNodes
 
-		if len(currentNodes) == 0 {
+		if len(currentNodes) == 0 && len(sends) == 0 {
 			break
 		}
 
-		// Check InterruptBefore
-		if config != nil && len(config.InterruptBefore) > 0 {
+		// Check InterruptBefore, except for the nodes a resumed run starts from
+		if config != nil && !config.DisableInterrupts && (len(config.InterruptBefore) > 0 || len(config.InterruptBeforeIf) > 0) {
 			for _, node := range currentNodes {
-				if slices.Contains(config.InterruptBefore, node) {
-					return state, &GraphInterrupt{Node: node, State: state}
+				if steps == 0 && slices.Contains(config.ResumeFrom, node) {
+					continue
+				}
+				predicate := config.InterruptBeforeIf[node]
+				if slices.Contains(config.InterruptBefore, node) || (predicate != nil && predicate(state)) {
+					return state, r.interrupt(ctx, &GraphInterrupt{Node: node, State: state, NextNodes: slices.Clone(currentNodes)})
 				}
 			}
 		}
 
-		// Execute nodes in parallel
-		results, errorsList := r.executeNodesParallel(ctx, currentNodes, state, config, runID)
+		// Skip nodes whose idempotency key already completed, then execute the rest in parallel
+		runNodes, keys := r.skipCompleted(ctx, currentNodes, state)
+		runNodes, inputs, err := sendInputs(runNodes, sends, state)
+		if err != nil {
+			return r.fail("", state, err)
+		}
+		if maxSteps > 0 && steps+len(runNodes) > maxSteps {
+			return r.fail("", state, &MaxStepsExceededError{Steps: maxSteps, LastNode: lastNode})
+		}
+		steps += len(runNodes)
+		if len(runNodes) > 0 {
+			lastNode = runNodes[len(runNodes)-1]
+		}
+
+		results, errorsList, timedOut := r.executeStep(ctx, deadlineCtx, runNodes, inputs, config, runID)
+		r.recordCompletions(ctx, runNodes, results, errorsList)
+		if timedOut {
+			err := &ExecutionTimeoutError{Timeout: *config.Timeout, Nodes: runNodes, LastCompletedNodes: lastCompleted}
+			for _, cb := range config.Callbacks {
+				cb.OnChainError(ctx, err, runID)
+			}
+			return r.fail("", state, err)
+		}
+		if ledger := getIdempotencyLedger(ctx); ledger != nil {
+			for i, key := range keys {
+				if key != "" && errorsList[i] == nil {
+					ledger.add(key)
+				}
+			}
+		}
 
 		// Process results (including results from interrupted nodes)
 		processedResults, nextNodesFromCommands := r.processNodeResults(results)
 
 		// Merge results into state (this preserves state updates from interrupted nodes)
+		stepStart := state
 		var mergeErr error
 		state, mergeErr = r.mergeState(ctx, state, processedResults)
 		if mergeErr != nil {
-			var zero S
-			return zero, mergeErr
+			return r.fail("", stepStart, mergeErr)
 		}
 
 		// Now check for errors after merging state
@@ -286,89 +529,102 @@ func (r *StateRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S,
 		}
 
 		// Keep track of nodes that ran for callbacks and interrupts
-		nodesRan := make([]string, len(currentNodes))
-		copy(nodesRan, currentNodes)
+		nodesRan := slices.Clone(currentNodes)
+		for _, send := range sends {
+			nodesRan = append(nodesRan, send.Node)
+		}
 
 		// Notify callbacks of step completion (and save checkpoints)
 		// For NodeInterrupt: we DO want to save the checkpoint (Issue #70)
-		// For regular errors: we DON'T want to save checkpoints
+		// For regular errors: no step checkpoint, except to keep completed idempotency keys (below)
 		if config != nil && len(config.Callbacks) > 0 {
 			if hasNodeInterrupt {
 				// Save checkpoint before returning the interrupt
+				stepCtx := withInterruptedAsyncHandle(withStepNodes(ctx, nodesRan), nodeInterrupt.Value)
 				for _, cb := range config.Callbacks {
 					if gcb, ok := cb.(GraphCallbackHandler); ok {
-						var nodeName string
-						if len(nodesRan) == 1 {
-							nodeName = nodesRan[0]
-						} else {
-							nodeName = fmt.Sprintf("step:%v", nodesRan)
-						}
-						gcb.OnGraphStep(ctx, nodeName, state)
+						gcb.OnGraphStep(stepCtx, nodesRan[len(nodesRan)-1], state)
 					}
 				}
 			}
 		}
 
 		// Now handle the errors
-		for _, err := range errorsList {
+		for i, err := range errorsList {
 			if err != nil {
 				if hasNodeInterrupt && nodeInterrupt != nil {
 					// Return GraphInterrupt with the merged state
 					// OnGraphStep has already been called, so checkpoint was saved
-					return state, &GraphInterrupt{
+					return state, r.interrupt(ctx, &GraphInterrupt{
 						Node:           nodeInterrupt.Node,
 						State:          state,
 						InterruptValue: nodeInterrupt.Value,
 						NextNodes:      []string{nodeInterrupt.Node},
+					})
+				}
+
+				// For regular errors (not interrupts), don't save a step checkpoint, unless nodes
+				// completed idempotency keys that a resumed run must not repeat
+				partial := r.partialState(ctx, stepStart, processedResults, errorsList)
+				if config != nil && completedKeys(keys, errorsList) {
+					stepCtx := withStepNodes(ctx, nodesRan)
+					for _, cb := range config.Callbacks {
+						if handler, ok := cb.(failedStepHandler); ok {
+							handler.onStepFailed(stepCtx, nodesRan[len(nodesRan)-1], partial)
+						}
 					}
 				}
 
-				// For regular errors (not interrupts), don't save checkpoint
 				// Notify callbacks of error
 				if config != nil && len(config.Callbacks) > 0 {
 					for _, cb := range config.Callbacks {
 						cb.OnChainError(ctx, err, runID)
 					}
 				}
-				var zero S
-				return zero, err
+				return r.fail(runNodes[i], partial, err)
 			}
 		}
 
+		lastCompleted = nodesRan
+
 		// Determine next nodes
-		nextNodesList, err := r.determineNextNodes(ctx, currentNodes, state, nextNodesFromCommands)
+		nextNodesList, nextSends, err := r.determineNextNodes(ctx, nodesRan, state, nextNodesFromCommands)
 		if err != nil {
-			var zero S
-			return zero, err
+			return r.fail("", state, err)
+		}
+
+		// Hold back join targets until their predecessors have completed
+		joins.complete(nodesRan)
+		if config != nil && config.DetectStalls {
+			if stalled := joins.stall(nextNodesList); stalled != nil {
+				return r.fail("", state, stalled)
+			}
 		}
+		nextNodesList = joins.gate(nextNodesList)
 
 		// Update currentNodes
 		currentNodes = nextNodesList
+		sends = nextSends
 
 		// Notify callbacks of step completion for normal execution (no errors)
 		if config != nil && len(config.Callbacks) > 0 {
+			stepCtx := withStepNodes(ctx, nodesRan)
 			for _, cb := range config.Callbacks {
 				if gcb, ok := cb.(GraphCallbackHandler); ok {
-					var nodeName string
-					if len(nodesRan) == 1 {
-						nodeName = nodesRan[0]
-					} else {
-						nodeName = fmt.Sprintf("step:%v", nodesRan)
-					}
-					gcb.OnGraphStep(ctx, nodeName, state)
+					gcb.OnGraphStep(stepCtx, nodesRan[len(nodesRan)-1], state)
 				}
 			}
 		}
 
 		// Check InterruptAfter
-		if config != nil && len(config.InterruptAfter) > 0 {
+		if config != nil && !config.DisableInterrupts && len(config.InterruptAfter) > 0 {
 			for _, node := range nodesRan {
 				if slices.Contains(config.InterruptAfter, node) {
-					return state, &GraphInterrupt{
+					return state, r.interrupt(ctx, &GraphInterrupt{
 						Node:      node,
 						State:     state,
 						NextNodes: nextNodesList,
-					}
+					})
 				}
 			}
 		}
@@ -379,6 +635,14 @@ func (r *StateRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S,
 		r.tracer.EndSpan(ctx, graphSpan, state, nil)
 	}
 
+	// Attach the execution trace and the node log to map states
+	if config != nil && config.CollectTrace {
+		state = attachCollected[TraceEntry](ctx, state, TraceStateKey)
+	}
+	if config != nil && config.CollectLogs {
+		state = attachCollected[LogEntry](ctx, state, LogsStateKey)
+	}
+
 	// Notify callbacks of graph end
 	if config != nil && len(config.Callbacks) > 0 {
 		outputs := convertStateToMap(state)
@@ -390,6 +654,62 @@ func (r *StateRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S,
 	return state, nil
 }
 
+// fail returns err wrapped in an *ExecutionError carrying the partial state
+func (r *StateRunnable[S]) fail(node string, partial S, err error) (S, error) {
+	var zero S
+	return zero, &ExecutionError{Node: node, PartialState: partial, Err: err}
+}
+
+// partialState merges the results of the nodes that succeeded in a failing step
+// into the state the step started from
+func (r *StateRunnable[S]) partialState(ctx context.Context, stepStart S, results []S, errorsList []error) S {
+	succeeded := make([]S, 0, len(results))
+	for i, res := range results {
+		if errorsList[i] == nil {
+			succeeded = append(succeeded, res)
+		}
+	}
+	state, err := r.mergeState(ctx, stepStart, succeeded)
+	if err != nil {
+		return stepStart
+	}
+	return state
+}
+
+// executeStep executes nodes in parallel, each with its input, and reports whether the Config.Timeout deadline
+// (deadlineCtx) expired while they ran. Nodes are cancelled through their context and
+// are expected to return promptly once it is done.
+func (r *StateRunnable[S]) executeStep(ctx, deadlineCtx context.Context, nodes []string, inputs []S, config *Config, runID string) ([]S, []error, bool) {
+	results, errorsList := r.executeNodesParallel(ctx, nodes, inputs, config, runID)
+	timedOut := deadlineCtx != nil && errors.Is(deadlineCtx.Err(), context.DeadlineExceeded)
+	return results, errorsList, timedOut
+}
+
+// interrupt notifies the OnInterrupt hook (if any) and returns the interrupt as an error.
+func (r *StateRunnable[S]) interrupt(ctx context.Context, gi *GraphInterrupt) error {
+	if r.hooks.OnInterrupt != nil {
+		r.hooks.OnInterrupt(ctx, gi)
+	}
+	return gi
+}
+
+// callNode invokes a node once, converting panics into a *NodePanicError
+// unless Config.DisablePanicRecovery is set
+func (r *StateRunnable[S]) callNode(ctx context.Context, node TypedNode[S], state S) (result S, err error) {
+	if config := GetConfig(ctx); config == nil || !config.DisablePanicRecovery {
+		defer func() {
+			if p := recover(); p != nil {
+				err = &NodePanicError{Node: node.Name, Value: p, Stack: debug.Stack()}
+			}
+		}()
+	}
+
+	if r.nodeRunner != nil {
+		return r.nodeRunner(ctx, node.Name, state)
+	}
+	return node.Function(ctx, state)
+}
+
 // executeNodeWithRetry executes a node with retry logic based on the retry policy.
 func (r *StateRunnable[S]) executeNodeWithRetry(ctx context.Context, node TypedNode[S], state S) (S, error) {
 	var lastErr error
@@ -404,11 +724,7 @@ func (r *StateRunnable[S]) executeNodeWithRetry(ctx context.Context, node TypedN
 		var result S
 		var err error
 
-		if r.nodeRunner != nil {
-			result, err = r.nodeRunner(ctx, node.Name, state)
-		} else {
-			result, err = node.Function(ctx, state)
-		}
+		result, err = r.callNode(ctx, node, state)
 
 		if err == nil {
 			return result, nil
@@ -486,8 +802,8 @@ func (r *StateRunnable[S]) calculateBackoffDelay(attempt int) time.Duration {
 	}
 }
 
-// executeNodesParallel executes valid nodes in parallel and returns their results or errors.
-func (r *StateRunnable[S]) executeNodesParallel(ctx context.Context, nodes []string, state S, config *Config, runID string) ([]S, []error) {
+// executeNodesParallel executes valid nodes in parallel, each with its input, and returns their results or errors.
+func (r *StateRunnable[S]) executeNodesParallel(ctx context.Context, nodes []string, inputs []S, config *Config, runID string) ([]S, []error) {
 	var wg sync.WaitGroup
 	results := make([]S, len(nodes))
 	errorsList := make([]error, len(nodes))
@@ -503,6 +819,7 @@ func (r *StateRunnable[S]) executeNodesParallel(ctx context.Context, nodes []str
 		idx := i
 		n := node
 		name := nodeName
+		state := inputs[i]
 
 		SafeGo(&wg, func() {
 			// Start node tracing
@@ -515,8 +832,22 @@ func (r *StateRunnable[S]) executeNodesParallel(ctx context.Context, nodes []str
 			var err error
 			var res S
 
-			// Execute node with retry logic
-			res, err = r.executeNodeWithRetry(ctx, n, state)
+			if r.hooks.OnNodeStart != nil {
+				r.hooks.OnNodeStart(ctx, name, state)
+			}
+			start := time.Now()
+
+			// Execute node with retry logic and its timeout
+			res, err = r.executeNode(withNodeLogger(ctx, name), n, state)
+
+			duration := time.Since(start)
+
+			if r.hooks.OnNodeEnd != nil {
+				r.hooks.OnNodeEnd(ctx, name, res, duration, err)
+			}
+			if collector := getCollector[TraceEntry](ctx); collector != nil {
+				collector.record(TraceEntry{Node: name, Start: start, Duration: duration, Err: err})
+			}
 
 			// End node tracing
 			if r.tracer != nil && nodeSpan != nil {
@@ -558,7 +889,10 @@ func (r *StateRunnable[S]) executeNodesParallel(ctx context.Context, nodes []str
 				}
 			}
 		}, func(panicVal any) {
-			errorsList[idx] = fmt.Errorf("panic in node %s: %v", name, panicVal)
+			if config != nil && config.DisablePanicRecovery {
+				panic(panicVal)
+			}
+			errorsList[idx] = fmt.Errorf("error in node %s: %w", name, &NodePanicError{Node: name, Value: panicVal, Stack: debug.Stack()})
 		})
 	}
 	wg.Wait()
@@ -636,9 +970,11 @@ func (r *StateRunnable[S]) mergeState(ctx context.Context, currentState S, resul
 	return state, nil
 }
 
-// determineNextNodes determines the next nodes to execute based on static edges, conditional edges, or commands.
-func (r *StateRunnable[S]) determineNextNodes(ctx context.Context, currentNodes []string, state S, nextNodesFromCommands []string) ([]string, error) {
+// determineNextNodes determines the next nodes to execute, and the Sends to execute with them,
+// based on static edges, conditional edges, or commands.
+func (r *StateRunnable[S]) determineNextNodes(ctx context.Context, currentNodes []string, state S, nextNodesFromCommands []string) ([]string, []Send, error) {
 	var nextNodesList []string
+	var sends []Send
 
 	if len(nextNodesFromCommands) > 0 {
 		// Command.Goto overrides static edges
@@ -653,8 +989,15 @@ func (r *StateRunnable[S]) determineNextNodes(ctx context.Context, currentNodes
 	} else {
 		// Use static edges
 		nextNodesSet := make(map[string]bool)
+		routed := make(map[string]bool)
 
 		for _, nodeName := range currentNodes {
+			// A node sent several times routes once
+			if routed[nodeName] {
+				continue
+			}
+			routed[nodeName] = true
+
 			// First check for conditional edges
 			nextNodeFn, hasConditional := r.graph.conditionalEdges[nodeName]
 			if hasConditional {
@@ -662,9 +1005,23 @@ func (r *StateRunnable[S]) determineNextNodes(ctx context.Context, currentNodes
 				if nextNode == "" {
 					var zero S
 					_ = zero
-					return nil, fmt.Errorf("conditional edge returned empty next node from %s", nodeName)
+					return nil, nil, fmt.Errorf("conditional edge returned empty next node from %s", nodeName)
 				}
 				nextNodesSet[nextNode] = true
+			} else if router, ok := r.graph.multiConditionalEdges[nodeName]; ok {
+				// An empty selection routes to END
+				for _, nextNode := range router(ctx, state) {
+					if nextNode != END {
+						nextNodesSet[nextNode] = true
+					}
+				}
+			} else if router, ok := r.graph.sendEdges[nodeName]; ok {
+				// No Sends routes to END
+				for _, send := range router(ctx, state) {
+					if send.Node != END {
+						sends = append(sends, send)
+					}
+				}
 			} else {
 				// Then check regular edges
 				foundNext := false
@@ -676,8 +1033,9 @@ func (r *StateRunnable[S]) determineNextNodes(ctx context.Context, currentNodes
 					}
 				}
 
-				if !foundNext {
-					return nil, fmt.Errorf("%w: %s", ErrNoOutgoingEdge, nodeName)
+				// Join predecessors route to their join targets
+				if !foundNext && len(r.graph.joinTargetsOf(nodeName)) == 0 {
+					return nil, nil, fmt.Errorf("%w: %s", ErrNoOutgoingEdge, nodeName)
 				}
 			}
 		}
@@ -687,5 +1045,5 @@ func (r *StateRunnable[S]) determineNextNodes(ctx context.Context, currentNodes
 			nextNodesList = append(nextNodesList, node)
 		}
 	}
-	return nextNodesList, nil
+	return nextNodesList, sends, nil
 }
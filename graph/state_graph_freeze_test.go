@@ -0,0 +1,132 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStateGraph_FrozenAfterCompile(t *testing.T) {
+	g := NewStateGraph[int]()
+	if err := g.AddNode("inc", "Increment", func(ctx context.Context, state int) (int, error) {
+		return state + 1, nil
+	}); err != nil {
+		t.Fatalf("AddNode before compile: %v", err)
+	}
+	g.AddEdge("inc", END)
+	g.SetEntryPoint("inc")
+
+	runnable, err := g.Compile()
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	double := func(ctx context.Context, state int) (int, error) { return state * 2, nil }
+	if err := g.AddNode("double", "Double", double); !errors.Is(err, ErrGraphFrozen) {
+		t.Errorf("AddNode after compile: expected ErrGraphFrozen, got %v", err)
+	}
+	if err := g.AddEdge("inc", "double"); !errors.Is(err, ErrGraphFrozen) {
+		t.Errorf("AddEdge after compile: expected ErrGraphFrozen, got %v", err)
+	}
+	if err := g.SetSchema(nil); !errors.Is(err, ErrGraphFrozen) {
+		t.Errorf("SetSchema after compile: expected ErrGraphFrozen, got %v", err)
+	}
+
+	noStart := func(ctx context.Context, state int) (any, error) { return nil, nil }
+	noAwait := func(ctx context.Context, handle any) (int, error) { return 0, nil }
+	merge := func(states []int) int { return 0 }
+	reduce := func(states []int) (int, error) { return 0, nil }
+	workers := map[string]func(context.Context, int) (int, error){"w": double}
+	sub := NewStateGraph[int]()
+	sub.AddNode("double", "Double", double)
+	sub.AddEdge("double", END)
+	sub.SetEntryPoint("double")
+	helpers := map[string]error{
+		"AddNodeWithRetry":          g.AddNodeWithRetry("retry", "", double, nil),
+		"AddNodeWithTimeout":        g.AddNodeWithTimeout("timeout", "", double, time.Second),
+		"AddNodeWithCircuitBreaker": g.AddNodeWithCircuitBreaker("cb", "", double, CircuitBreakerConfig{}),
+		"AddNodeWithRateLimit":      g.AddNodeWithRateLimit("rl", "", double, 1, time.Second),
+		"AddAsyncNode":              g.AddAsyncNode("async", "", noStart, noAwait),
+		"AddAsyncNodeWithDeadline":  g.AddAsyncNodeWithDeadline("async2", "", noStart, noAwait, time.Second),
+		"AddParallelNodes":          g.AddParallelNodes("par", workers, merge),
+		"AddMapReduceNode":          g.AddMapReduceNode("mr", workers, reduce),
+		"FanOutFanIn":               g.FanOutFanIn("inc", nil, "collect", workers, merge, func(s int) (int, error) { return s, nil }),
+		"AddSubgraph":               AddSubgraph(g, "sub", sub, func(s int) int { return s }, func(s int) int { return s }),
+	}
+	for name, err := range helpers {
+		if !errors.Is(err, ErrGraphFrozen) {
+			t.Errorf("%s after compile: expected ErrGraphFrozen, got %v", name, err)
+		}
+	}
+
+	mutators := map[string]error{
+		"AddConditionalEdge":      g.AddConditionalEdge("inc", func(ctx context.Context, state int) string { return "double" }),
+		"AddConditionalEdgeMulti": g.AddConditionalEdgeMulti("inc", func(ctx context.Context, state int) []string { return nil }),
+		"AddConditionalEdgeSend":  g.AddConditionalEdgeSend("inc", func(ctx context.Context, state int) []Send { return nil }),
+		"SetEntryPoint":           g.SetEntryPoint("double"),
+		"SetFinalNode":            g.SetFinalNode("double"),
+		"SetRetryPolicy":          g.SetRetryPolicy(&RetryPolicy{MaxRetries: 1}),
+		"SetStateMerger":          g.SetStateMerger(func(ctx context.Context, current int, newStates []int) (int, error) { return current, nil }),
+		"AddJoin":                 g.AddJoin("double", []string{"inc"}),
+		"SetIdempotencyKey":       g.SetIdempotencyKey("inc", func(state int) string { return "key" }),
+		"RequireAcyclic":          g.RequireAcyclic(),
+		"SetConditionalTargets":   g.SetConditionalTargets("inc", "double"),
+		"SetInputKeys":            g.SetInputKeys("input"),
+		"AddLoopEdge":             g.AddLoopEdge("inc", "inc", END, 1, "count"),
+		"AddNodeWithIO":           g.AddNodeWithIO("io", "", nil, nil, double),
+		"AddNodeWithOptions":      g.AddNodeWithOptions("opts", "", double, NodeOptions{}),
+		"AddNodeWithCompensation": g.AddNodeWithCompensation("comp", "", double, func(ctx context.Context, state int) error { return nil }),
+	}
+	for name, err := range mutators {
+		if !errors.Is(err, ErrGraphFrozen) {
+			t.Errorf("%s after compile: expected ErrGraphFrozen, got %v", name, err)
+		}
+	}
+
+	result, err := runnable.Invoke(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Invoke: %v", err)
+	}
+	if result != 2 {
+		t.Errorf("expected 2, got %d", result)
+	}
+}
+
+func TestListenableStateGraph_FrozenAfterCompile(t *testing.T) {
+	g := NewListenableStateGraph[int]()
+	g.AddNode("inc", "Increment", func(ctx context.Context, state int) (int, error) {
+		return state + 1, nil
+	})
+	g.AddEdge("inc", END)
+	g.SetEntryPoint("inc")
+
+	if _, err := g.CompileListenable(); err != nil {
+		t.Fatalf("CompileListenable: %v", err)
+	}
+
+	double := func(ctx context.Context, state int) (int, error) { return state * 2, nil }
+	nodes := map[string]*ListenableNode[int]{
+		"AddNode":                 g.AddNode("double", "Double", double),
+		"AddNodeWithIO":           g.AddNodeWithIO("io", "", nil, nil, double),
+		"AddNodeWithCompensation": g.AddNodeWithCompensation("comp", "", double, func(ctx context.Context, state int) error { return nil }),
+		"AddNodeWithRetry":        g.AddNodeWithRetry("retry", "", double, nil),
+		"AddNodeWithOptions":      g.AddNodeWithOptions("opts", "", double, NodeOptions{}),
+		"AddAsyncNode": g.AddAsyncNode("async", "",
+			func(ctx context.Context, state int) (any, error) { return nil, nil },
+			func(ctx context.Context, handle any) (int, error) { return 0, nil }),
+	}
+	for name, node := range nodes {
+		if node == nil {
+			t.Fatalf("%s after compile: expected a node, got nil", name)
+		}
+		// Chaining on the returned node must not panic
+		node.AddListener(NodeListenerFunc[int](func(ctx context.Context, event NodeEvent, nodeName string, state int, err error) {}))
+		if !errors.Is(node.Err(), ErrGraphFrozen) {
+			t.Errorf("%s after compile: expected ErrGraphFrozen, got %v", name, node.Err())
+		}
+		if g.GetListenableNode(node.Name) != nil {
+			t.Errorf("%s after compile: node was registered", name)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package graph
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -220,3 +221,114 @@ func TestStateGraph_InterruptWithValueTypeState(t *testing.T) {
 		t.Errorf("Result BUG: Expected amount to be 100, got: %v", result.Amount)
 	}
 }
+
+func TestStateGraph_InterruptBeforeIf(t *testing.T) {
+	type order struct {
+		Amount   float64
+		Approved bool
+		Paid     bool
+	}
+
+	g := NewStateGraph[order]()
+	g.AddNode("payment", "payment", func(ctx context.Context, s order) (order, error) {
+		s.Paid = true
+		return s, nil
+	})
+	g.AddEdge("payment", END)
+	g.SetEntryPoint("payment")
+
+	runnable, err := g.Compile()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	config := &Config{
+		InterruptBeforeIf: map[string]func(any) bool{
+			"payment": InterruptIf(func(s order) bool { return s.Amount > 1000 && !s.Approved }),
+		},
+	}
+
+	// Below the threshold the graph proceeds autonomously
+	result, err := runnable.InvokeWithConfig(context.Background(), order{Amount: 50}, config)
+	if err != nil {
+		t.Fatalf("Expected no interrupt, got: %v", err)
+	}
+	if !result.Paid {
+		t.Error("Expected small payment to be processed")
+	}
+
+	// Above the threshold the graph pauses before the payment
+	result, err = runnable.InvokeWithConfig(context.Background(), order{Amount: 5000}, config)
+	var graphInterrupt *GraphInterrupt
+	if !errors.As(err, &graphInterrupt) || graphInterrupt.Node != "payment" {
+		t.Fatalf("Expected GraphInterrupt before payment, got: %v", err)
+	}
+	if result.Paid {
+		t.Error("Expected large payment to wait for review")
+	}
+
+	// Once approved, the same config lets it through
+	result, err = runnable.InvokeWithConfig(context.Background(), order{Amount: 5000, Approved: true}, config)
+	if err != nil || !result.Paid {
+		t.Fatalf("Expected approved payment to be processed, got %+v, %v", result, err)
+	}
+}
+
+func TestGraphInterrupt_NextNodesAndResume(t *testing.T) {
+	g := NewStateGraph[[]string]()
+	for _, name := range []string{"draft", "review", "publish"} {
+		g.AddNode(name, name, func(ctx context.Context, s []string) ([]string, error) {
+			return append(s, name), nil
+		})
+	}
+	g.AddEdge("draft", "review")
+	g.AddEdge("review", END)
+	g.AddEdge("publish", END)
+	g.SetEntryPoint("draft")
+
+	runnable, err := g.Compile()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	// InterruptBefore reports the node that was about to run
+	_, err = runnable.InvokeWithConfig(context.Background(), nil, &Config{InterruptBefore: []string{"review"}})
+	var graphInterrupt *GraphInterrupt
+	if !errors.As(err, &graphInterrupt) {
+		t.Fatalf("Expected GraphInterrupt, got: %v", err)
+	}
+	if len(graphInterrupt.NextNodes) != 1 || graphInterrupt.NextNodes[0] != "review" {
+		t.Errorf("Expected NextNodes [review] before review, got %v", graphInterrupt.NextNodes)
+	}
+
+	// InterruptAfter reports the successors of the node
+	config := &Config{InterruptAfter: []string{"draft"}}
+	state, err := runnable.InvokeWithConfig(context.Background(), nil, config)
+	if !errors.As(err, &graphInterrupt) {
+		t.Fatalf("Expected GraphInterrupt, got: %v", err)
+	}
+	if len(graphInterrupt.NextNodes) != 1 || graphInterrupt.NextNodes[0] != "review" {
+		t.Fatalf("Expected NextNodes [review] after draft, got %v", graphInterrupt.NextNodes)
+	}
+
+	// Resuming without nodes follows the graph
+	result, err := runnable.InvokeWithConfig(context.Background(), state, graphInterrupt.Resume(config))
+	if err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if got := fmt.Sprint(result); got != "[draft review]" {
+		t.Errorf("Expected [draft review], got %s", got)
+	}
+
+	// Resuming with nodes overrides the next step
+	result, err = runnable.InvokeWithConfig(context.Background(), state, graphInterrupt.Resume(config, "publish"))
+	if err != nil {
+		t.Fatalf("Resume with override failed: %v", err)
+	}
+	if got := fmt.Sprint(result); got != "[draft publish]" {
+		t.Errorf("Expected [draft publish], got %s", got)
+	}
+	if len(config.ResumeFrom) != 0 {
+		t.Errorf("Expected Resume to leave the original config untouched, got %v", config.ResumeFrom)
+	}
+}
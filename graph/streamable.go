@@ -0,0 +1,81 @@
+package graph
+
+import (
+	"context"
+	"time"
+)
+
+// Streamable is implemented by runnables that stream their execution, so events can be
+// consumed the same way whichever builder produced the runnable. StreamingRunnable and
+// StateRunnable (returned by the prebuilt agents) implement it.
+type Streamable[S any] interface {
+	Stream(ctx context.Context, input S) *StreamResult[S]
+}
+
+var (
+	_ Streamable[any] = (*StreamingRunnable[any])(nil)
+	_ Streamable[any] = (*StateRunnable[any])(nil)
+)
+
+// stepStreamer emits a NodeEventComplete event with the merged state after each step
+type stepStreamer[S any] struct {
+	NoOpCallbackHandler
+	events chan<- StreamEvent[S]
+	done   <-chan struct{}
+}
+
+func (s *stepStreamer[S]) OnGraphStep(ctx context.Context, stepNode string, state any) {
+	typed, _ := state.(S)
+	select {
+	case s.events <- StreamEvent[S]{Timestamp: time.Now(), NodeName: stepNode, Event: NodeEventComplete, State: typed}:
+	case <-s.done:
+	}
+}
+
+// Stream executes the graph in the background and emits a NodeEventComplete event with the
+// merged state after each step. Sending on a full event buffer blocks the execution until
+// the consumer catches up or the stream is cancelled.
+func (r *StateRunnable[S]) Stream(ctx context.Context, initialState S) *StreamResult[S] {
+	return r.StreamWithConfig(ctx, initialState, nil)
+}
+
+// StreamWithConfig is Stream with an invocation config. The config is not modified.
+func (r *StateRunnable[S]) StreamWithConfig(ctx context.Context, initialState S, config *Config) *StreamResult[S] {
+	eventChan := make(chan StreamEvent[S], DefaultStreamConfig().BufferSize)
+	resultChan := make(chan S, 1)
+	errorChan := make(chan error, 1)
+	doneChan := make(chan struct{})
+
+	streamCtx, cancel := context.WithCancel(ctx)
+
+	streamConfig := &Config{}
+	if config != nil {
+		*streamConfig = *config
+	}
+	streamConfig.Callbacks = append(append([]CallbackHandler{}, streamConfig.Callbacks...),
+		&stepStreamer[S]{events: eventChan, done: streamCtx.Done()})
+
+	go func() {
+		defer func() {
+			close(eventChan)
+			close(resultChan)
+			close(errorChan)
+			close(doneChan)
+		}()
+
+		result, err := r.InvokeWithConfig(streamCtx, initialState, streamConfig)
+		if err != nil {
+			errorChan <- err
+		} else {
+			resultChan <- result
+		}
+	}()
+
+	return &StreamResult[S]{
+		Events: eventChan,
+		Result: resultChan,
+		Errors: errorChan,
+		Done:   doneChan,
+		Cancel: cancel,
+	}
+}
@@ -33,6 +33,11 @@ type StreamConfig struct {
 
 	// Mode specifies what kind of events to stream
 	Mode StreamMode
+
+	// TokenMetricsInterval enables token metrics when positive: chunks reported with
+	// ReportTokens are counted and a progress event carrying TokenMetrics is emitted
+	// at most once per interval for each node
+	TokenMetricsInterval time.Duration
 }
 
 // DefaultStreamConfig returns the default streaming configuration
@@ -140,6 +145,15 @@ func (sl *StreamingListener[S]) OnNodeEvent(ctx context.Context, event NodeEvent
 		Error:     err,
 		Metadata:  make(map[string]any),
 	}
+	if p, ok := ProgressFromContext(ctx); ok && event == NodeEventProgress {
+		streamEvent.Metadata[ProgressMetadataKey] = p
+	}
+	if m, ok := TokenMetricsFromContext(ctx); ok && event == NodeEventProgress {
+		streamEvent.Metadata[TokenMetricsMetadataKey] = m
+	}
+	if a, ok := RetryAttemptFromContext(ctx); ok && event == NodeEventRetry {
+		streamEvent.Metadata[RetryMetadataKey] = a
+	}
 	sl.emitEvent(streamEvent)
 }
 
@@ -194,6 +208,9 @@ func (sr *StreamingRunnable[S]) Stream(ctx context.Context, initialState S) *Str
 
 	// Create cancellable context
 	streamCtx, cancel := context.WithCancel(ctx)
+	if sr.config.TokenMetricsInterval > 0 {
+		streamCtx = withTokenMetrics(streamCtx, sr.config.TokenMetricsInterval)
+	}
 
 	// Create streaming listener
 	streamingListener := NewStreamingListener(eventChan, sr.config)
@@ -93,3 +93,35 @@ func TestStreamingModes(t *testing.T) {
 		assert.True(t, foundB)
 	})
 }
+
+func TestStateRunnable_Stream(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.SetSchema(NewMapSchema())
+	g.AddNode("A", "A", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"a": true}, nil
+	})
+	g.AddNode("B", "B", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"b": true}, nil
+	})
+	g.SetEntryPoint("A")
+	g.AddEdge("A", "B")
+	g.AddEdge("B", END)
+
+	runnable, err := g.Compile()
+	assert.NoError(t, err)
+
+	var streamable Streamable[map[string]any] = runnable
+	res := streamable.Stream(context.Background(), map[string]any{})
+
+	var nodes []string
+	for event := range res.Events {
+		assert.Equal(t, NodeEventComplete, event.Event)
+		nodes = append(nodes, event.NodeName)
+	}
+	assert.Equal(t, []string{"A", "B"}, nodes)
+
+	result := <-res.Result
+	assert.Equal(t, map[string]any{"a": true, "b": true}, result)
+	assert.NoError(t, <-res.Errors)
+	<-res.Done
+}
@@ -2,7 +2,11 @@ package graph
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"sync"
 )
 
 // Subgraph represents a nested graph that can be used as a node
@@ -36,18 +40,52 @@ func (s *Subgraph[S]) Execute(ctx context.Context, state S) (S, error) {
 	return result, nil
 }
 
+// SubgraphOptions configures a subgraph added with AddSubgraph
+type SubgraphOptions struct {
+	// Memoize reuses the result of the subgraph for identical input states within a run
+	Memoize bool
+}
+
+// SubgraphOption is a functional option for AddSubgraph
+type SubgraphOption func(*SubgraphOptions)
+
+// WithMemoization makes the subgraph run at most once per distinct input state in a run of
+// the parent graph: later executions with the same input (after the converter) return the
+// first result without running the subgraph again. Concurrent executions with the same
+// input wait for the first one. Failed executions are not memoized.
+//
+// Input states are compared by the SHA-256 of their JSON encoding; states that cannot be
+// encoded are never memoized. Memoized results are shared between executions, not copied.
+func WithMemoization() SubgraphOption {
+	return func(o *SubgraphOptions) {
+		o.Memoize = true
+	}
+}
+
 // AddSubgraph adds a subgraph as a node in the parent graph
-func AddSubgraph[S, SubS any](g *StateGraph[S], name string, subgraph *StateGraph[SubS], converter func(S) SubS, resultConverter func(SubS) S) error {
+func AddSubgraph[S, SubS any](g *StateGraph[S], name string, subgraph *StateGraph[SubS], converter func(S) SubS, resultConverter func(SubS) S, opts ...SubgraphOption) error {
+	var options SubgraphOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	sg, err := NewSubgraph(name, subgraph)
 	if err != nil {
 		return err
 	}
 
+	execute := sg.Execute
+	if options.Memoize {
+		execute = func(ctx context.Context, state SubS) (SubS, error) {
+			return memoizeSubgraph(ctx, name, state, sg.Execute)
+		}
+	}
+
 	// Wrap the execute function to match the state type
 	wrappedFn := func(ctx context.Context, state S) (S, error) {
 		// Convert S to SubS
 		subState := converter(state)
-		result, err := sg.Execute(ctx, subState)
+		result, err := execute(ctx, subState)
 		if err != nil {
 			var zero S
 			return zero, err
@@ -56,17 +94,93 @@ func AddSubgraph[S, SubS any](g *StateGraph[S], name string, subgraph *StateGrap
 		return resultConverter(result), nil
 	}
 
-	g.AddNode(name, "Subgraph: "+name, wrappedFn)
+	if err := g.AddNode(name, "Subgraph: "+name, wrappedFn); err != nil {
+		return err
+	}
+	if options.Memoize {
+		g.memoizesSubgraphs = true
+	}
 	return nil
 }
 
 // CreateSubgraph creates and adds a subgraph using a builder function
-func CreateSubgraph[S, SubS any](g *StateGraph[S], name string, builder func(*StateGraph[SubS]) error, converter func(S) SubS, resultConverter func(SubS) S) error {
+func CreateSubgraph[S, SubS any](g *StateGraph[S], name string, builder func(*StateGraph[SubS]) error, converter func(S) SubS, resultConverter func(SubS) S, opts ...SubgraphOption) error {
 	subgraph := NewStateGraph[SubS]()
 	if err := builder(subgraph); err != nil {
 		return err
 	}
-	return AddSubgraph(g, name, subgraph, converter, resultConverter)
+	return AddSubgraph(g, name, subgraph, converter, resultConverter, opts...)
+}
+
+// subgraphMemo holds the memoized subgraph results of a run
+type subgraphMemo struct {
+	mu      sync.Mutex
+	entries map[string]*memoEntry
+}
+
+// memoEntry is a subgraph result, available once done is closed
+type memoEntry struct {
+	done   chan struct{}
+	result any
+	err    error
+}
+
+type subgraphMemoKey struct{}
+
+func withSubgraphMemo(ctx context.Context, memo *subgraphMemo) context.Context {
+	return context.WithValue(ctx, subgraphMemoKey{}, memo)
+}
+
+func getSubgraphMemo(ctx context.Context) *subgraphMemo {
+	memo, _ := ctx.Value(subgraphMemoKey{}).(*subgraphMemo)
+	return memo
+}
+
+// memoizeSubgraph runs execute for state unless the run already has its result
+func memoizeSubgraph[SubS any](ctx context.Context, name string, state SubS, execute func(context.Context, SubS) (SubS, error)) (SubS, error) {
+	memo := getSubgraphMemo(ctx)
+	data, err := json.Marshal(state)
+	if memo == nil || err != nil {
+		return execute(ctx, state)
+	}
+	sum := sha256.Sum256(data)
+	key := name + ":" + hex.EncodeToString(sum[:])
+
+	for {
+		memo.mu.Lock()
+		if memo.entries == nil {
+			memo.entries = make(map[string]*memoEntry)
+		}
+		entry, ok := memo.entries[key]
+		if !ok {
+			entry = &memoEntry{done: make(chan struct{})}
+			memo.entries[key] = entry
+		}
+		memo.mu.Unlock()
+
+		if !ok {
+			result, err := execute(ctx, state)
+			entry.result, entry.err = result, err
+			if err != nil {
+				memo.mu.Lock()
+				delete(memo.entries, key)
+				memo.mu.Unlock()
+			}
+			close(entry.done)
+			return result, err
+		}
+
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			var zero SubS
+			return zero, ctx.Err()
+		}
+		if entry.err == nil {
+			return entry.result.(SubS), nil
+		}
+		// The execution we waited for failed and was not memoized; run it ourselves
+	}
 }
 
 // CompositeGraph allows composing multiple graphs together
@@ -203,8 +317,7 @@ func AddRecursiveSubgraph[S, SubS any](
 		return resultConverter(result), nil
 	}
 
-	g.AddNode(name, "Recursive subgraph: "+name, wrappedFn)
-	return nil
+	return g.AddNode(name, "Recursive subgraph: "+name, wrappedFn)
 }
 
 // AddNestedConditionalSubgraph creates a subgraph with its own conditional routing
@@ -247,6 +360,5 @@ func AddNestedConditionalSubgraph[S, SubS any](
 		return resultConverter(result), nil
 	}
 
-	g.AddNode(name, "Nested conditional subgraph: "+name, wrappedFn)
-	return nil
+	return g.AddNode(name, "Nested conditional subgraph: "+name, wrappedFn)
 }
@@ -2,9 +2,14 @@ package graph
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSubgraph(t *testing.T) {
@@ -220,3 +225,121 @@ func TestRecursiveSubgraph_MaxDepth(t *testing.T) {
 	// Should not panic and should complete
 	assert.NotNil(t, result)
 }
+
+func TestAddSubgraph_WithMemoization(t *testing.T) {
+	var runs atomic.Int32
+	validate := NewStateGraph[map[string]any]()
+	validate.AddNode("check", "check", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		runs.Add(1)
+		return map[string]any{"item": state["item"], "valid": state["item"] != "bad"}, nil
+	})
+	validate.SetEntryPoint("check")
+	validate.AddEdge("check", END)
+
+	// The parent loops over its items, validating each with the subgraph
+	newParent := func(opts ...SubgraphOption) *StateRunnable[map[string]any] {
+		parent := NewStateGraph[map[string]any]()
+		schema := NewMapSchema()
+		schema.RegisterReducer("results", AppendReducer)
+		parent.SetSchema(schema)
+
+		err := AddSubgraph(parent, "validate", validate,
+			func(s map[string]any) map[string]any {
+				items := s["items"].([]string)
+				return map[string]any{"item": items[len(s["results"].([]bool))]}
+			},
+			func(s map[string]any) map[string]any {
+				return map[string]any{"results": []bool{s["valid"].(bool)}}
+			}, opts...)
+		require.NoError(t, err)
+		parent.SetEntryPoint("validate")
+		parent.AddConditionalEdge("validate", func(ctx context.Context, s map[string]any) string {
+			if len(s["results"].([]bool)) < len(s["items"].([]string)) {
+				return "validate"
+			}
+			return END
+		})
+
+		runnable, err := parent.Compile()
+		require.NoError(t, err)
+		return runnable
+	}
+	input := func() map[string]any {
+		return map[string]any{"items": []string{"a", "bad", "a", "a", "bad"}, "results": []bool{}}
+	}
+
+	runnable := newParent(WithMemoization())
+	res, err := runnable.Invoke(context.Background(), input())
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, true, true, false}, res["results"])
+	assert.Equal(t, int32(2), runs.Load(), "each distinct item is validated once")
+
+	// Results are memoized per run
+	_, err = runnable.Invoke(context.Background(), input())
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), runs.Load())
+
+	// Without the option every execution runs the subgraph
+	runs.Store(0)
+	res, err = newParent().Invoke(context.Background(), input())
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, true, true, false}, res["results"])
+	assert.Equal(t, int32(5), runs.Load())
+}
+
+func TestMemoizeSubgraph(t *testing.T) {
+	memo := &subgraphMemo{}
+	ctx := withSubgraphMemo(context.Background(), memo)
+
+	t.Run("Concurrent executions wait for the first", func(t *testing.T) {
+		var runs atomic.Int32
+		release := make(chan struct{})
+		execute := func(ctx context.Context, s string) (string, error) {
+			runs.Add(1)
+			<-release
+			return s + "!", nil
+		}
+
+		var wg sync.WaitGroup
+		results := make([]string, 5)
+		for i := range results {
+			wg.Go(func() {
+				results[i], _ = memoizeSubgraph(ctx, "sub", "x", execute)
+			})
+		}
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		assert.Equal(t, int32(1), runs.Load())
+		assert.Equal(t, []string{"x!", "x!", "x!", "x!", "x!"}, results)
+	})
+
+	t.Run("Failures are not memoized", func(t *testing.T) {
+		calls := 0
+		execute := func(ctx context.Context, s string) (string, error) {
+			calls++
+			if calls == 1 {
+				return "", errors.New("boom")
+			}
+			return "ok", nil
+		}
+		_, err := memoizeSubgraph(ctx, "flaky", "x", execute)
+		assert.Error(t, err)
+		res, err := memoizeSubgraph(ctx, "flaky", "x", execute)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", res)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Unencodable states are not memoized", func(t *testing.T) {
+		calls := 0
+		execute := func(ctx context.Context, s chan int) (chan int, error) {
+			calls++
+			return s, nil
+		}
+		ch := make(chan int)
+		_, _ = memoizeSubgraph(ctx, "chan", ch, execute)
+		_, _ = memoizeSubgraph(ctx, "chan", ch, execute)
+		assert.Equal(t, 2, calls)
+	})
+}
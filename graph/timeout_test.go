@@ -0,0 +1,61 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigTimeout(t *testing.T) {
+	config := graph.DefaultCheckpointConfig()
+	g := graph.NewCheckpointableStateGraphWithConfig[map[string]any](config)
+	g.AddNode("fast", "fast", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		state["fast"] = true
+		return state, nil
+	})
+	g.AddNode("slow", "slow", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		select {
+		case <-time.After(time.Second):
+			state["slow"] = true
+			return state, nil
+		case <-ctx.Done():
+			return state, ctx.Err()
+		}
+	})
+	g.AddEdge("fast", "slow")
+	g.AddEdge("slow", graph.END)
+	g.SetEntryPoint("fast")
+
+	runnable, err := g.CompileCheckpointable()
+	require.NoError(t, err)
+
+	timeout := 50 * time.Millisecond
+	runConfig := graph.WithThreadID("sla-thread")
+	runConfig.Timeout = &timeout
+
+	start := time.Now()
+	_, err = runnable.InvokeWithConfig(context.Background(), map[string]any{}, runConfig)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	var timeoutErr *graph.ExecutionTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, []string{"slow"}, timeoutErr.Nodes)
+	assert.Equal(t, []string{"fast"}, timeoutErr.LastCompletedNodes)
+
+	var execErr *graph.ExecutionError
+	require.ErrorAs(t, err, &execErr)
+	state := execErr.PartialState.(map[string]any)
+	assert.Equal(t, true, state["fast"])
+	assert.Nil(t, state["slow"])
+
+	// The latest checkpoint is the last completed node, so the run can be resumed
+	latest, err := config.Store.GetLatestByThread(context.Background(), "sla-thread")
+	require.NoError(t, err)
+	assert.Equal(t, "fast", latest.NodeName)
+}
@@ -0,0 +1,145 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TokenMetricsMetadataKey is the StreamEvent metadata key holding the TokenMetrics of a progress event
+const TokenMetricsMetadataKey = "token_metrics"
+
+// TokenMetricsStage is the Progress stage of the progress events emitted by ReportTokens
+const TokenMetricsStage = "tokens"
+
+// TokenMetrics is the streaming throughput of a running node
+type TokenMetrics struct {
+	// TokensEmitted is the number of chunks the node streamed so far
+	TokensEmitted int
+	// Bytes is the total size of the streamed chunks
+	Bytes int
+	// TokensPerSec is the average throughput since the node started
+	TokensPerSec float64
+}
+
+type tokenMetricsIntervalKey struct{}
+
+type tokenCounterKey struct{}
+
+type tokenMetricsKey struct{}
+
+// tokenCounter accumulates the chunks streamed by one node execution
+type tokenCounter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	start    time.Time
+	lastEmit time.Time
+	pending  bool
+	metrics  TokenMetrics
+}
+
+// add counts a chunk of n bytes and returns the metrics and whether they are due for reporting
+func (c *tokenCounter) add(n int) (TokenMetrics, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.metrics.TokensEmitted++
+	c.metrics.Bytes += n
+	c.metrics.TokensPerSec = tokensPerSec(c.metrics.TokensEmitted, now.Sub(c.start))
+
+	if now.Sub(c.lastEmit) < c.interval {
+		c.pending = true
+		return c.metrics, false
+	}
+	c.lastEmit = now
+	c.pending = false
+	return c.metrics, true
+}
+
+// flush returns the final metrics and whether they have not been reported yet
+func (c *tokenCounter) flush() (TokenMetrics, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.pending {
+		return c.metrics, false
+	}
+	c.pending = false
+	c.metrics.TokensPerSec = tokensPerSec(c.metrics.TokensEmitted, time.Since(c.start))
+	return c.metrics, true
+}
+
+func tokensPerSec(tokens int, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(tokens) / elapsed.Seconds()
+}
+
+// ReportTokens counts a chunk streamed by the running node, typically from an
+// llms.WithStreamingFunc callback. When the graph streams with a positive
+// StreamConfig.TokenMetricsInterval, a NodeEventProgress carrying the node's TokenMetrics
+// (under TokenMetricsMetadataKey) is emitted at most once per interval, plus once with
+// the final totals when the node completes. It is a no-op otherwise.
+//
+// Example:
+//
+//	llm.GenerateContent(ctx, messages, llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+//	    graph.ReportTokens(ctx, chunk)
+//	    return nil
+//	}))
+func ReportTokens(ctx context.Context, chunk []byte) {
+	counter, ok := ctx.Value(tokenCounterKey{}).(*tokenCounter)
+	if !ok {
+		return
+	}
+	if m, due := counter.add(len(chunk)); due {
+		reportTokenMetrics(ctx, m)
+	}
+}
+
+// TokenMetricsFromContext returns the token metrics carried by the context of a
+// NodeEventProgress event, for use in NodeListener implementations
+func TokenMetricsFromContext(ctx context.Context) (TokenMetrics, bool) {
+	m, ok := ctx.Value(tokenMetricsKey{}).(TokenMetrics)
+	return m, ok
+}
+
+// withTokenMetrics returns a context in which nodes count streamed tokens and report
+// their metrics every interval
+func withTokenMetrics(ctx context.Context, interval time.Duration) context.Context {
+	return context.WithValue(ctx, tokenMetricsIntervalKey{}, interval)
+}
+
+// withTokenCounter returns a context counting the tokens of one node execution when
+// token metrics are enabled
+func withTokenCounter(ctx context.Context) context.Context {
+	interval, ok := ctx.Value(tokenMetricsIntervalKey{}).(time.Duration)
+	if !ok {
+		return ctx
+	}
+	now := time.Now()
+	return context.WithValue(ctx, tokenCounterKey{}, &tokenCounter{interval: interval, start: now, lastEmit: now})
+}
+
+// flushTokenMetrics reports the final token metrics of the node if they have not been reported yet
+func flushTokenMetrics(ctx context.Context) {
+	counter, ok := ctx.Value(tokenCounterKey{}).(*tokenCounter)
+	if !ok {
+		return
+	}
+	if m, due := counter.flush(); due {
+		reportTokenMetrics(ctx, m)
+	}
+}
+
+func reportTokenMetrics(ctx context.Context, m TokenMetrics) {
+	if report, ok := ctx.Value(progressReporterKey{}).(func(context.Context, Progress)); ok {
+		report(context.WithValue(ctx, tokenMetricsKey{}, m), Progress{
+			Stage:   TokenMetricsStage,
+			Message: fmt.Sprintf("%d tokens (%.1f tokens/s)", m.TokensEmitted, m.TokensPerSec),
+		})
+	}
+}
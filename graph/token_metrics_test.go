@@ -0,0 +1,67 @@
+package graph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func streamTokenMetrics(t *testing.T, interval time.Duration) []TokenMetrics {
+	g := NewStreamingStateGraph[map[string]any]()
+	g.AddNode("llm", "LLM", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		for _, chunk := range []string{"Hello", ", ", "world"} {
+			ReportTokens(ctx, []byte(chunk))
+		}
+		return state, nil
+	})
+	g.AddEdge("llm", END)
+	g.SetEntryPoint("llm")
+
+	config := DefaultStreamConfig()
+	config.TokenMetricsInterval = interval
+	g.SetStreamConfig(config)
+
+	runnable, err := g.CompileStreaming()
+	require.NoError(t, err)
+
+	var metrics []TokenMetrics
+	for event := range runnable.Stream(context.Background(), map[string]any{}).Events {
+		if event.Event != NodeEventProgress {
+			continue
+		}
+		m, ok := event.Metadata[TokenMetricsMetadataKey].(TokenMetrics)
+		require.True(t, ok)
+		assert.Equal(t, TokenMetricsStage, event.Metadata[ProgressMetadataKey].(Progress).Stage)
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+func TestReportTokens(t *testing.T) {
+	t.Run("Reports final totals", func(t *testing.T) {
+		metrics := streamTokenMetrics(t, time.Hour)
+		require.Len(t, metrics, 1)
+		assert.Equal(t, 3, metrics[0].TokensEmitted)
+		assert.Equal(t, 12, metrics[0].Bytes)
+		assert.Greater(t, metrics[0].TokensPerSec, 0.0)
+	})
+
+	t.Run("Reports every interval", func(t *testing.T) {
+		metrics := streamTokenMetrics(t, time.Nanosecond)
+		require.Len(t, metrics, 3)
+		assert.Equal(t, 1, metrics[0].TokensEmitted)
+		assert.Equal(t, 3, metrics[2].TokensEmitted)
+		assert.Equal(t, 12, metrics[2].Bytes)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		assert.Empty(t, streamTokenMetrics(t, 0))
+	})
+
+	t.Run("Outside a graph", func(t *testing.T) {
+		assert.NotPanics(t, func() { ReportTokens(context.Background(), []byte("chunk")) })
+	})
+}
@@ -0,0 +1,130 @@
+package graph
+
+import (
+	"context"
+	"sync"
+)
+
+// TokenEvent is a chunk of text streamed by a node, typically an LLM token
+type TokenEvent struct {
+	// NodeName is the node that emitted the chunk
+	NodeName string
+	// Chunk is the streamed text
+	Chunk string
+	// Index is the position of the chunk in the node execution's stream, starting at 0
+	Index int
+}
+
+// TokenStreamResult is returned by StreamingRunnable.StreamTokens: the node lifecycle events
+// of Stream plus the token channel.
+type TokenStreamResult[S any] struct {
+	*StreamResult[S]
+
+	// Tokens receives the chunks emitted by nodes, in order per node. It is closed once
+	// the run has ended, whether it completed or failed. Emitting blocks until the chunk is
+	// received, so read Tokens until it is closed.
+	Tokens <-chan TokenEvent
+}
+
+type tokenSinkKey struct{}
+
+type tokenEmitterKey struct{}
+
+// tokenSink delivers the chunks of a run to its Tokens channel
+type tokenSink struct {
+	mu     sync.RWMutex
+	tokens chan TokenEvent
+	done   chan struct{}
+	closed bool
+}
+
+func newTokenSink(bufferSize int) *tokenSink {
+	return &tokenSink{
+		tokens: make(chan TokenEvent, max(bufferSize, 0)),
+		done:   make(chan struct{}),
+	}
+}
+
+// send delivers a chunk unless the run has ended or ctx is done
+func (s *tokenSink) send(ctx context.Context, event TokenEvent) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.tokens <- event:
+	case <-s.done:
+	case <-ctx.Done():
+	}
+}
+
+// close unblocks pending sends and closes the Tokens channel once they have returned
+func (s *tokenSink) close() {
+	close(s.done)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	close(s.tokens)
+}
+
+// StreamTokens executes the graph like Stream and also returns the chunks nodes emit with
+// TokenEmitter, so nodes can stream LLM output without carrying callbacks in the state.
+//
+// Example:
+//
+//	result := runnable.StreamTokens(ctx, state)
+//	go func() {
+//	    for range result.Events {
+//	    }
+//	}()
+//	for token := range result.Tokens {
+//	    fmt.Print(token.Chunk)
+//	}
+func (sr *StreamingRunnable[S]) StreamTokens(ctx context.Context, initialState S) *TokenStreamResult[S] {
+	sink := newTokenSink(sr.config.BufferSize)
+	result := sr.Stream(context.WithValue(ctx, tokenSinkKey{}, sink), initialState)
+	go func() {
+		<-result.Done
+		sink.close()
+	}()
+	return &TokenStreamResult[S]{StreamResult: result, Tokens: sink.tokens}
+}
+
+// TokenEmitter returns the function a node calls to stream a chunk to the Tokens channel of
+// StreamTokens. Chunks are numbered per node execution. The function is a no-op when the
+// graph does not run with StreamTokens, so nodes can always use it.
+//
+// Example:
+//
+//	emit := graph.TokenEmitter(ctx)
+//	llm.GenerateContent(ctx, messages, llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+//	    emit(string(chunk))
+//	    return nil
+//	}))
+func TokenEmitter(ctx context.Context) func(chunk string) {
+	emit, ok := ctx.Value(tokenEmitterKey{}).(func(string))
+	if !ok {
+		return func(string) {}
+	}
+	return emit
+}
+
+// withTokenEmitter returns a context whose TokenEmitter streams the chunks of one execution
+// of node when the run streams tokens
+func withTokenEmitter(ctx context.Context, node string) context.Context {
+	sink, ok := ctx.Value(tokenSinkKey{}).(*tokenSink)
+	if !ok {
+		return ctx
+	}
+	var (
+		mu    sync.Mutex
+		index int
+	)
+	return context.WithValue(ctx, tokenEmitterKey{}, func(chunk string) {
+		mu.Lock()
+		defer mu.Unlock()
+		sink.send(ctx, TokenEvent{NodeName: node, Chunk: chunk, Index: index})
+		index++
+	})
+}
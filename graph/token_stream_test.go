@@ -0,0 +1,81 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamingRunnable_StreamTokens(t *testing.T) {
+	newRunnable := func(t *testing.T, fail bool) *StreamingRunnable[map[string]any] {
+		g := NewStreamingStateGraph[map[string]any]()
+		g.SetSchema(NewMapSchema())
+		g.AddNode("start", "start", func(ctx context.Context, s map[string]any) (map[string]any, error) {
+			return s, nil
+		})
+		for _, name := range []string{"left", "right"} {
+			g.AddNode(name, name, func(ctx context.Context, s map[string]any) (map[string]any, error) {
+				emit := TokenEmitter(ctx)
+				for i := range 50 {
+					emit(fmt.Sprintf("%s-%d", name, i))
+				}
+				if fail && name == "right" {
+					return nil, errors.New("generation failed")
+				}
+				return map[string]any{name: true}, nil
+			})
+			g.AddEdge("start", name)
+			g.AddEdge(name, END)
+		}
+		g.SetEntryPoint("start")
+		runnable, err := g.CompileStreaming()
+		require.NoError(t, err)
+		return runnable
+	}
+	collect := func(result *TokenStreamResult[map[string]any]) map[string][]TokenEvent {
+		go func() {
+			for range result.Events {
+			}
+		}()
+		byNode := make(map[string][]TokenEvent)
+		for token := range result.Tokens {
+			byNode[token.NodeName] = append(byNode[token.NodeName], token)
+		}
+		return byNode
+	}
+
+	t.Run("Delivers chunks in order per node", func(t *testing.T) {
+		result := newRunnable(t, false).StreamTokens(context.Background(), map[string]any{})
+		byNode := collect(result)
+
+		require.Len(t, byNode, 2)
+		for _, name := range []string{"left", "right"} {
+			require.Len(t, byNode[name], 50)
+			for i, token := range byNode[name] {
+				assert.Equal(t, TokenEvent{NodeName: name, Chunk: fmt.Sprintf("%s-%d", name, i), Index: i}, token)
+			}
+		}
+		final := <-result.Result
+		assert.Equal(t, true, final["left"])
+	})
+
+	t.Run("Closes the channel when the run fails", func(t *testing.T) {
+		result := newRunnable(t, true).StreamTokens(context.Background(), map[string]any{})
+		byNode := collect(result)
+
+		assert.Len(t, byNode["right"], 50)
+		assert.ErrorContains(t, <-result.Errors, "generation failed")
+	})
+
+	t.Run("Emitting without StreamTokens is a no-op", func(t *testing.T) {
+		result := newRunnable(t, false).Stream(context.Background(), map[string]any{})
+		for range result.Events {
+		}
+		final := <-result.Result
+		assert.Equal(t, true, final["right"])
+	})
+}
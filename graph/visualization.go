@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 )
 
 // Exporter provides methods to export graphs in different formats
@@ -20,6 +21,9 @@ func NewExporter[S any](graph *StateGraph[S]) *Exporter[S] {
 type MermaidOptions struct {
 	// Direction of the flowchart (e.g., "TD", "LR")
 	Direction string
+	// Stats, if set, annotates nodes with the invocation counts and average latencies it
+	// collected and colors them by the share of the total time they took
+	Stats *MetricsListener
 }
 
 // DrawMermaid generates a Mermaid diagram representation of the graph
@@ -29,9 +33,23 @@ func (ge *Exporter[S]) DrawMermaid() string {
 	})
 }
 
+// DrawMermaidWithStats generates a Mermaid diagram annotated with the runtime stats collected
+// by metrics, e.g. a MetricsListener added to the nodes of a listenable graph. The node that
+// took the most total time is highlighted as the hot path.
+func (ge *Exporter[S]) DrawMermaidWithStats(metrics *MetricsListener) string {
+	return ge.DrawMermaidWithOptions(MermaidOptions{
+		Direction: "TD",
+		Stats:     metrics,
+	})
+}
+
 // DrawMermaidWithOptions generates a Mermaid diagram with custom options
 func (ge *Exporter[S]) DrawMermaidWithOptions(opts MermaidOptions) string {
 	var sb strings.Builder
+	label := func(name string) string { return name }
+	if opts.Stats != nil {
+		label = opts.Stats.nodeLabel
+	}
 
 	// Start Mermaid flowchart
 	direction := opts.Direction
@@ -42,7 +60,7 @@ func (ge *Exporter[S]) DrawMermaidWithOptions(opts MermaidOptions) string {
 
 	// Add entry point styling
 	if ge.graph.entryPoint != "" {
-		sb.WriteString(fmt.Sprintf("    %s[[\"%s\"]]\n", ge.graph.entryPoint, ge.graph.entryPoint))
+		sb.WriteString(fmt.Sprintf("    %s[[\"%s\"]]\n", ge.graph.entryPoint, label(ge.graph.entryPoint)))
 		sb.WriteString(fmt.Sprintf("    %s --> %s\n", "START", ge.graph.entryPoint))
 		sb.WriteString("    START([\"START\"])\n")
 		sb.WriteString("    style START fill:#90EE90\n")
@@ -59,7 +77,7 @@ func (ge *Exporter[S]) DrawMermaidWithOptions(opts MermaidOptions) string {
 
 	// Add regular nodes
 	for _, name := range nodeNames {
-		sb.WriteString(fmt.Sprintf("    %s[\"%s\"]\n", name, name))
+		sb.WriteString(fmt.Sprintf("    %s[\"%s\"]\n", name, label(name)))
 	}
 
 	// Add END node if referenced
@@ -82,7 +100,7 @@ func (ge *Exporter[S]) DrawMermaidWithOptions(opts MermaidOptions) string {
 	}
 
 	// Add conditional edges
-	for from := range ge.graph.conditionalEdges {
+	for _, from := range ge.graph.conditionalSources() {
 		sb.WriteString(fmt.Sprintf("    %s -.-> %s_condition((?))\n", from, from))
 		sb.WriteString(fmt.Sprintf("    style %s_condition fill:#FFFFE0,stroke:#333,stroke-dasharray: 5 5\n", from))
 	}
@@ -92,9 +110,89 @@ func (ge *Exporter[S]) DrawMermaidWithOptions(opts MermaidOptions) string {
 		sb.WriteString(fmt.Sprintf("    style %s fill:#87CEEB\n", ge.graph.entryPoint))
 	}
 
+	// Color nodes by their total time; later styles override the entry point's
+	if opts.Stats != nil {
+		names := nodeNames
+		if ge.graph.entryPoint != "" {
+			names = append([]string{ge.graph.entryPoint}, nodeNames...)
+		}
+		for _, line := range opts.Stats.nodeStyles(names) {
+			sb.WriteString("    " + line + "\n")
+		}
+	}
+
 	return sb.String()
 }
 
+// nodeLabel returns the Mermaid label of a node with its invocation count, average latency
+// and error count
+func (ml *MetricsListener) nodeLabel(name string) string {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+
+	calls := len(ml.nodeDurations[name])
+	if calls == 0 {
+		return name
+	}
+	var total time.Duration
+	for _, d := range ml.nodeDurations[name] {
+		total += d
+	}
+	label := fmt.Sprintf("%s<br/>%d calls, avg %s", name, calls, roundDuration(total/time.Duration(calls)))
+	if errs := ml.nodeErrors[name]; errs > 0 {
+		label += fmt.Sprintf(", %d errors", errs)
+	}
+	return label
+}
+
+// nodeStyles returns Mermaid style lines coloring the nodes by their total time relative to
+// the hottest node: red for the hottest, orange above half of it, yellow otherwise and grey
+// for nodes that did not run. Nodes with errors get a red border.
+func (ml *MetricsListener) nodeStyles(names []string) []string {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+
+	totals := make(map[string]time.Duration, len(names))
+	var hottest time.Duration
+	for _, name := range names {
+		for _, d := range ml.nodeDurations[name] {
+			totals[name] += d
+		}
+		hottest = max(hottest, totals[name])
+	}
+
+	styles := make([]string, 0, len(names))
+	for _, name := range names {
+		fill := "#FFE066"
+		switch {
+		case len(ml.nodeDurations[name]) == 0:
+			fill = "#E0E0E0"
+		case totals[name] == hottest:
+			fill = "#FF6B6B"
+		case totals[name]*2 > hottest:
+			fill = "#FFA94D"
+		}
+		style := fmt.Sprintf("style %s fill:%s", name, fill)
+		if ml.nodeErrors[name] > 0 {
+			style += ",stroke:#C00000,stroke-width:3px"
+		}
+		styles = append(styles, style)
+	}
+	return styles
+}
+
+// roundDuration rounds d for display
+func roundDuration(d time.Duration) time.Duration {
+	switch {
+	case d < time.Millisecond:
+		return d.Round(time.Microsecond)
+	case d < time.Second:
+		return d.Round(100 * time.Microsecond)
+	default:
+		return d.Round(10 * time.Millisecond)
+	}
+}
+
 // DrawDOT generates a DOT (Graphviz) representation of the graph
 func (ge *Exporter[S]) DrawDOT() string {
 	var sb strings.Builder
@@ -133,7 +231,7 @@ func (ge *Exporter[S]) DrawDOT() string {
 	}
 
 	// Add conditional edges
-	for from := range ge.graph.conditionalEdges {
+	for _, from := range ge.graph.conditionalSources() {
 		sb.WriteString(fmt.Sprintf("    %s -> %s_condition [style=dashed, label=\"?\"];\n", from, from))
 		sb.WriteString(fmt.Sprintf("    %s_condition [label=\"?\", shape=diamond, style=filled, fillcolor=lightyellow];\n", from))
 	}
@@ -195,7 +293,10 @@ func (ge *Exporter[S]) drawASCIINode(nodeName string, prefix string, isLast bool
 	}
 
 	// Check for conditional edge
-	if _, ok := ge.graph.conditionalEdges[nodeName]; ok {
+	_, single := ge.graph.conditionalEdges[nodeName]
+	_, multi := ge.graph.multiConditionalEdges[nodeName]
+	_, send := ge.graph.sendEdges[nodeName]
+	if single || multi || send {
 		outgoingEdges = append(outgoingEdges, "(Conditional)")
 	}
 
@@ -3,6 +3,7 @@ package graph
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -49,3 +50,36 @@ func TestVisualization(t *testing.T) {
 	// C is not reachable via static edges from B, so it won't be shown under B.
 	// This is expected behavior for static visualization of dynamic graphs.
 }
+
+func TestDrawMermaidWithStats(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	for _, name := range []string{"retrieve", "rerank", "generate", "fallback"} {
+		g.AddNode(name, name, func(ctx context.Context, state map[string]any) (map[string]any, error) { return state, nil })
+	}
+	g.SetEntryPoint("retrieve")
+	g.AddEdge("retrieve", "rerank")
+	g.AddEdge("rerank", "generate")
+	g.AddEdge("generate", END)
+
+	metrics := NewMetricsListener()
+	metrics.nodeDurations = map[string][]time.Duration{
+		"retrieve": {100 * time.Millisecond, 200 * time.Millisecond},
+		"rerank":   {40 * time.Millisecond},
+		"generate": {1500 * time.Millisecond},
+	}
+	metrics.nodeErrors = map[string]int{"rerank": 1}
+
+	mermaid := NewExporter(g).DrawMermaidWithStats(metrics)
+	assert.Contains(t, mermaid, `retrieve[["retrieve<br/>2 calls, avg 150ms"]]`)
+	assert.Contains(t, mermaid, `generate["generate<br/>1 calls, avg 1.5s"]`)
+	assert.Contains(t, mermaid, `rerank["rerank<br/>1 calls, avg 40ms, 1 errors"]`)
+	assert.Contains(t, mermaid, `fallback["fallback"]`)
+
+	assert.Contains(t, mermaid, "style generate fill:#FF6B6B")
+	assert.Contains(t, mermaid, "style retrieve fill:#FFE066")
+	assert.Contains(t, mermaid, "style rerank fill:#FFE066,stroke:#C00000,stroke-width:3px")
+	assert.Contains(t, mermaid, "style fallback fill:#E0E0E0")
+
+	// Without stats the diagram is unchanged
+	assert.Equal(t, NewExporter(g).DrawMermaid(), NewExporter(g).DrawMermaidWithOptions(MermaidOptions{}))
+}
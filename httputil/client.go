@@ -0,0 +1,232 @@
+// Package httputil provides the resilient HTTP client shared by the API-backed components,
+// such as the Cohere and Jina rerankers, the Chroma v2 vector store and the Qwen embedder.
+//
+// The client retries transient failures (network errors, 429 and 5xx responses) with
+// exponential backoff and jitter, honours Retry-After, pools connections per host and can
+// stop calling a failing host with a circuit breaker.
+//
+// Example:
+//
+//	client := httputil.NewResilientClient(httputil.DefaultOptions())
+//	reranker := retriever.NewCohereReranker("", retriever.CohereRerankerConfig{HTTPClient: client})
+package httputil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned for requests to a host whose circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Options configures a resilient client
+type Options struct {
+	// Timeout bounds a whole request, including retries (0 means no timeout)
+	Timeout time.Duration
+	// MaxRetries is the number of retries after the first attempt (0 disables retries)
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry; it doubles for each further retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between retries, including delays requested by Retry-After
+	MaxBackoff time.Duration
+	// Jitter randomizes each delay by up to this fraction (0 to 1) to spread out retries
+	Jitter float64
+	// MaxIdleConnsPerHost is the number of keep-alive connections pooled per host
+	MaxIdleConnsPerHost int
+	// Retryable reports whether a response status is retried (default: 429 and 5xx)
+	Retryable func(statusCode int) bool
+	// CircuitBreaker, if set, rejects requests to a host with ErrCircuitOpen after
+	// consecutive failures
+	CircuitBreaker *CircuitBreakerOptions
+}
+
+// CircuitBreakerOptions configures the per-host circuit breaker
+type CircuitBreakerOptions struct {
+	// FailureThreshold is the number of consecutive failed requests that opens the circuit
+	// (default: 5)
+	FailureThreshold int
+	// ResetTimeout is how long the circuit stays open before a request is let through again
+	// (default: 30s)
+	ResetTimeout time.Duration
+}
+
+// DefaultOptions returns the options used by the API-backed components
+func DefaultOptions() Options {
+	return Options{
+		Timeout:             30 * time.Second,
+		MaxRetries:          3,
+		InitialBackoff:      500 * time.Millisecond,
+		MaxBackoff:          10 * time.Second,
+		Jitter:              0.2,
+		MaxIdleConnsPerHost: 32,
+	}
+}
+
+// IsRetryableStatus reports whether a response status is transient: 429 or 5xx
+func IsRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// NewResilientClient creates an HTTP client with the given options
+func NewResilientClient(opts Options) *http.Client {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.MaxIdleConnsPerHost > 0 {
+		base.MaxIdleConns = max(base.MaxIdleConns, opts.MaxIdleConnsPerHost)
+		base.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	}
+	return &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: NewTransport(base, opts),
+	}
+}
+
+// NewTransport wraps base (http.DefaultTransport if nil) with retries and the circuit
+// breaker of opts. Options.Timeout and MaxIdleConnsPerHost are not used.
+func NewTransport(base http.RoundTripper, opts Options) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if opts.Retryable == nil {
+		opts.Retryable = IsRetryableStatus
+	}
+	if opts.CircuitBreaker != nil {
+		cb := *opts.CircuitBreaker
+		if cb.FailureThreshold <= 0 {
+			cb.FailureThreshold = 5
+		}
+		if cb.ResetTimeout <= 0 {
+			cb.ResetTimeout = 30 * time.Second
+		}
+		opts.CircuitBreaker = &cb
+	}
+	return &transport{
+		base:     base,
+		opts:     opts,
+		breakers: make(map[string]*breaker),
+	}
+}
+
+// transport is an http.RoundTripper with retries and a per-host circuit breaker
+type transport struct {
+	base http.RoundTripper
+	opts Options
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+// breaker is the circuit breaker state of a host
+type breaker struct {
+	failures int
+	openedAt time.Time
+}
+
+// RoundTrip sends the request, retrying transient failures
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.allow(req.URL.Host); err != nil {
+		return nil, err
+	}
+
+	// A request body can only be sent again if it can be recreated
+	retries := t.opts.MaxRetries
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		retries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to recreate request body: %w", err)
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(req)
+		failed := err != nil || t.opts.Retryable(resp.StatusCode)
+		if !failed || attempt >= retries {
+			t.record(req.URL.Host, failed)
+			return resp, err
+		}
+
+		delay := t.backoff(attempt, resp)
+		if resp != nil {
+			// Drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// backoff returns the delay before retry attempt+1, honouring Retry-After
+func (t *transport) backoff(attempt int, resp *http.Response) time.Duration {
+	delay := t.opts.InitialBackoff << attempt
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			delay = time.Duration(seconds) * time.Second
+		}
+	}
+	if t.opts.MaxBackoff > 0 && (delay > t.opts.MaxBackoff || delay < 0) {
+		delay = t.opts.MaxBackoff
+	}
+	if t.opts.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * t.opts.Jitter * float64(delay))
+	}
+	return max(delay, 0)
+}
+
+// allow returns ErrCircuitOpen if the circuit of host is open
+func (t *transport) allow(host string) error {
+	cb := t.opts.CircuitBreaker
+	if cb == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := t.breakers[host]
+	if b != nil && b.failures >= cb.FailureThreshold && time.Since(b.openedAt) < cb.ResetTimeout {
+		return fmt.Errorf("%w: %s", ErrCircuitOpen, host)
+	}
+	return nil
+}
+
+// record updates the circuit of host with the outcome of a request. After the reset
+// timeout, a single failure opens the circuit again.
+func (t *transport) record(host string, failed bool) {
+	cb := t.opts.CircuitBreaker
+	if cb == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := t.breakers[host]
+	if b == nil {
+		b = &breaker{}
+		t.breakers[host] = b
+	}
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= cb.FailureThreshold {
+		b.openedAt = time.Now()
+	}
+}
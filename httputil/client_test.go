@@ -0,0 +1,102 @@
+package httputil
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOptions() Options {
+	opts := DefaultOptions()
+	opts.InitialBackoff = time.Millisecond
+	opts.Jitter = 0
+	return opts
+}
+
+// failingServer fails the first failures requests with status, then echoes the request body
+func failingServer(t *testing.T, failures int32, status int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if calls.Add(1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestResilientClient_Retries(t *testing.T) {
+	server, calls := failingServer(t, 2, http.StatusTooManyRequests)
+	client := NewResilientClient(testOptions())
+
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "payload", string(body), "the body is sent again on retries")
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestResilientClient_GivesUp(t *testing.T) {
+	server, calls := failingServer(t, 10, http.StatusServiceUnavailable)
+	opts := testOptions()
+	opts.MaxRetries = 2
+	client := NewResilientClient(opts)
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestResilientClient_DoesNotRetryClientErrors(t *testing.T) {
+	server, calls := failingServer(t, 10, http.StatusBadRequest)
+	client := NewResilientClient(testOptions())
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestResilientClient_CircuitBreaker(t *testing.T) {
+	server, calls := failingServer(t, 100, http.StatusInternalServerError)
+	opts := testOptions()
+	opts.MaxRetries = 0
+	opts.CircuitBreaker = &CircuitBreakerOptions{FailureThreshold: 2, ResetTimeout: time.Hour}
+	client := NewResilientClient(opts)
+
+	for range 2 {
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	_, err := client.Get(server.URL)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestBackoff(t *testing.T) {
+	tr := NewTransport(nil, Options{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}).(*transport)
+	assert.Equal(t, time.Second, tr.backoff(0, nil))
+	assert.Equal(t, 4*time.Second, tr.backoff(2, nil))
+	assert.Equal(t, 5*time.Second, tr.backoff(10, nil))
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"3"}}}
+	assert.Equal(t, 3*time.Second, tr.backoff(0, resp))
+}
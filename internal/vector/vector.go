@@ -0,0 +1,105 @@
+// Package vector provides the embedding math shared by the in-process vector stores,
+// retrievers and memory strategies, so that they all score documents the same way.
+//
+// The loops are unrolled with independent accumulators, which lets the compiler keep
+// them in registers and eliminate bounds checks.
+package vector
+
+import "math"
+
+// Float is the element type of a vector
+type Float interface {
+	~float32 | ~float64
+}
+
+// DotProduct returns the dot product of a and b, or 0 if their lengths differ
+func DotProduct[T Float](a, b []T) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	b = b[:len(a)]
+
+	var s0, s1, s2, s3 float64
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += float64(a[i]) * float64(b[i])
+		s1 += float64(a[i+1]) * float64(b[i+1])
+		s2 += float64(a[i+2]) * float64(b[i+2])
+		s3 += float64(a[i+3]) * float64(b[i+3])
+	}
+	for ; i < len(a); i++ {
+		s0 += float64(a[i]) * float64(b[i])
+	}
+	return s0 + s1 + s2 + s3
+}
+
+// Cosine returns the cosine similarity of a and b, or 0 if their lengths differ or either
+// of them is a zero vector
+func Cosine[T Float](a, b []T) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	b = b[:len(a)]
+
+	var dot0, dot1, normA0, normA1, normB0, normB1 float64
+	i := 0
+	for ; i+2 <= len(a); i += 2 {
+		x0, y0 := float64(a[i]), float64(b[i])
+		x1, y1 := float64(a[i+1]), float64(b[i+1])
+		dot0 += x0 * y0
+		dot1 += x1 * y1
+		normA0 += x0 * x0
+		normA1 += x1 * x1
+		normB0 += y0 * y0
+		normB1 += y1 * y1
+	}
+	for ; i < len(a); i++ {
+		x, y := float64(a[i]), float64(b[i])
+		dot0 += x * y
+		normA0 += x * x
+		normB0 += y * y
+	}
+
+	normA, normB := normA0+normA1, normB0+normB1
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return (dot0 + dot1) / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// Euclidean returns the Euclidean distance between a and b, or +Inf if their lengths differ
+func Euclidean[T Float](a, b []T) float64 {
+	if len(a) != len(b) {
+		return math.Inf(1)
+	}
+	b = b[:len(a)]
+
+	var s0, s1, s2, s3 float64
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		d0 := float64(a[i]) - float64(b[i])
+		d1 := float64(a[i+1]) - float64(b[i+1])
+		d2 := float64(a[i+2]) - float64(b[i+2])
+		d3 := float64(a[i+3]) - float64(b[i+3])
+		s0 += d0 * d0
+		s1 += d1 * d1
+		s2 += d2 * d2
+		s3 += d3 * d3
+	}
+	for ; i < len(a); i++ {
+		d := float64(a[i]) - float64(b[i])
+		s0 += d * d
+	}
+	return math.Sqrt(s0 + s1 + s2 + s3)
+}
+
+// Normalize scales v in place to unit length. Zero vectors are left unchanged.
+func Normalize[T Float](v []T) {
+	norm := math.Sqrt(DotProduct(v, v))
+	if norm == 0 {
+		return
+	}
+	for i := range v {
+		v[i] = T(float64(v[i]) / norm)
+	}
+}
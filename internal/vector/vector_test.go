@@ -0,0 +1,74 @@
+package vector
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDotProduct(t *testing.T) {
+	assert.Equal(t, 32.0, DotProduct([]float32{1, 2, 3}, []float32{4, 5, 6}))
+	assert.Equal(t, 60.0, DotProduct([]float64{1, 2, 3, 4, 5}, []float64{0, 1, 2, 3, 8}))
+	assert.Equal(t, 0.0, DotProduct([]float32{1, 2}, []float32{1}))
+}
+
+func TestCosine(t *testing.T) {
+	assert.InDelta(t, 1.0, Cosine([]float32{1, 2, 3}, []float32{2, 4, 6}), 1e-9)
+	assert.InDelta(t, 0.0, Cosine([]float32{1, 0}, []float32{0, 1}), 1e-9)
+	assert.InDelta(t, -1.0, Cosine([]float64{1, 1, 1}, []float64{-1, -1, -1}), 1e-9)
+	assert.InDelta(t, 32/(math.Sqrt(14)*math.Sqrt(77)), Cosine([]float32{1, 2, 3}, []float32{4, 5, 6}), 1e-6)
+
+	assert.Equal(t, 0.0, Cosine([]float32{1, 2}, []float32{1, 2, 3}))
+	assert.Equal(t, 0.0, Cosine([]float32{0, 0}, []float32{1, 2}))
+	assert.Equal(t, 0.0, Cosine([]float32{0}, []float32{0}))
+}
+
+func TestEuclidean(t *testing.T) {
+	assert.Equal(t, 5.0, Euclidean([]float32{0, 0}, []float32{3, 4}))
+	assert.InDelta(t, math.Sqrt(5), Euclidean([]float64{1, 1, 1, 1, 1}, []float64{2, 2, 2, 2, 2}), 1e-9)
+	assert.True(t, math.IsInf(Euclidean([]float32{1}, []float32{1, 2}), 1))
+}
+
+func TestNormalize(t *testing.T) {
+	v := []float32{3, 4}
+	Normalize(v)
+	assert.InDeltaSlice(t, []float32{0.6, 0.8}, v, 1e-6)
+
+	zero := []float64{0, 0}
+	Normalize(zero)
+	assert.Equal(t, []float64{0, 0}, zero)
+}
+
+func randomVector(n int) []float32 {
+	v := make([]float32, n)
+	for i := range v {
+		v[i] = rand.Float32()
+	}
+	return v
+}
+
+func BenchmarkCosine(b *testing.B) {
+	x, y := randomVector(1536), randomVector(1536)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Cosine(x, y)
+	}
+}
+
+func BenchmarkDotProduct(b *testing.B) {
+	x, y := randomVector(1536), randomVector(1536)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		DotProduct(x, y)
+	}
+}
+
+func BenchmarkEuclidean(b *testing.B) {
+	x, y := randomVector(1536), randomVector(1536)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Euclidean(x, y)
+	}
+}
@@ -1,15 +1,18 @@
 package qwen
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/smallnest/langgraphgo/httputil"
+	"github.com/smallnest/langgraphgo/rag"
 	"github.com/tmc/langchaingo/embeddings"
 )
 
@@ -27,9 +30,11 @@ func NewEmbedder(baseURL, apiKey, model string) *Embedder {
 
 // Embedder is a custom embedder that supports encoding_format for Qwen models.
 type Embedder struct {
-	baseURL string
-	apiKey  string
-	model   string
+	baseURL    string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+	clientOnce sync.Once
 }
 
 // EmbedQuery embeds a single query text.
@@ -50,7 +55,7 @@ func (e *Embedder) EmbedDocument(ctx context.Context, text string) ([]float32, e
 	return emb[0], nil
 }
 
-// EmbedDocuments embeds multiple documents with retry logic for rate limiting.
+// EmbedDocuments embeds multiple documents. Rate limiting and server errors are retried.
 func (e *Embedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
 	if len(texts) == 0 {
 		return nil, nil
@@ -72,80 +77,56 @@ func (e *Embedder) EmbedDocuments(ctx context.Context, texts []string) ([][]floa
 	baseURL := strings.TrimSuffix(e.baseURL, "/")
 	url := baseURL + "/embeddings"
 
-	// Retry logic for rate limiting
-	maxRetries := 5
-	retryDelay := 2 * time.Second
-
-	var lastErr error
-	for attempt := range maxRetries {
-		if attempt > 0 {
-			log.Printf("Retry attempt %d/%d after %v delay", attempt, maxRetries, retryDelay)
-			select {
-			case <-time.After(retryDelay):
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			}
-			// Exponential backoff
-			retryDelay *= 2
-		}
-
-		// Create request
-		req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(payloadBytes)))
-		if err != nil {
-			return nil, fmt.Errorf("create request: %w", err)
-		}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payloadBytes))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
 
-		// Set headers
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	// Rate limiting (429) and server errors (5xx) are retried by the client
+	resp, err := e.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", rag.NewConnectionError("Qwen API", err))
+	}
+	defer resp.Body.Close()
 
-		// Send request
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		if err != nil {
-			lastErr = fmt.Errorf("send request: %w", err)
-			continue
-		}
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
 
-		// Read response body for error details
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
-
-		if resp.StatusCode == http.StatusOK {
-			// Parse response
-			var result embeddingResponse
-			if err := json.Unmarshal(bodyBytes, &result); err != nil {
-				return nil, fmt.Errorf("decode response: %w", err)
-			}
-
-			// Extract embeddings
-			emb := make([][]float32, len(result.Data))
-			for i, item := range result.Data {
-				emb[i] = item.Embedding
-			}
-			return emb, nil
-		}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &rag.StatusError{Backend: "Qwen API", StatusCode: resp.StatusCode, Body: string(bodyBytes)}
+	}
 
-		// Handle rate limiting (429) and server errors (5xx)
-		if resp.StatusCode == 429 || (resp.StatusCode >= 500 && resp.StatusCode < 600) {
-			// Try to parse error response for logging
-			var errResp map[string]any
-			if json.Unmarshal(bodyBytes, &errResp) == nil {
-				log.Printf("API returned status %d (will retry): %v", resp.StatusCode, errResp)
-			}
-			lastErr = fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(bodyBytes))
-			continue
-		}
+	var result embeddingResponse
+	if err := json.Unmarshal(bodyBytes, &result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
 
-		// For other errors, don't retry
-		var errResp map[string]any
-		if json.Unmarshal(bodyBytes, &errResp) == nil {
-			return nil, fmt.Errorf("API returned status %d: %v", resp.StatusCode, errResp)
-		}
-		return nil, fmt.Errorf("API returned unexpected status code: %d, body: %s", resp.StatusCode, string(bodyBytes))
+	emb := make([][]float32, len(result.Data))
+	for i, item := range result.Data {
+		emb[i] = item.Embedding
 	}
+	return emb, nil
+}
 
-	return nil, fmt.Errorf("max retries exceeded: %w", lastErr)
+// client returns the HTTP client of the embedder. The default client retries up to 4 times,
+// starting with a 2s backoff, as the ModelScope API rate limits aggressively.
+func (e *Embedder) client() *http.Client {
+	e.clientOnce.Do(func() {
+		if e.httpClient != nil {
+			return
+		}
+		opts := httputil.DefaultOptions()
+		opts.Timeout = 0 // bounded by the context
+		opts.MaxRetries = 4
+		opts.InitialBackoff = 2 * time.Second
+		opts.MaxBackoff = 30 * time.Second
+		e.httpClient = httputil.NewResilientClient(opts)
+	})
+	return e.httpClient
 }
 
 // GetDimension returns the dimension of the embeddings.
@@ -2,8 +2,14 @@ package qwen
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/smallnest/langgraphgo/httputil"
 )
 
 func TestEmbedder(t *testing.T) {
@@ -77,6 +83,35 @@ func TestEmbedder(t *testing.T) {
 			t.Errorf("expected nil result, got %v", result)
 		}
 	})
+
+	t.Run("EmbedDocuments retries rate limiting", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":[{"embedding":[0.5,0.25],"index":0}]}`))
+		}))
+		defer server.Close()
+
+		opts := httputil.DefaultOptions()
+		opts.InitialBackoff = time.Millisecond
+		embedder := NewEmbedderWithOptions(
+			WithBaseURL(server.URL),
+			WithHTTPClient(httputil.NewResilientClient(opts)),
+		)
+		emb, err := embedder.EmbedDocument(context.Background(), "hello")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(emb) != 2 || emb[0] != 0.5 {
+			t.Errorf("unexpected embedding: %v", emb)
+		}
+		if calls.Load() != 2 {
+			t.Errorf("expected 2 calls, got %d", calls.Load())
+		}
+	})
 }
 
 // Integration test - run with MODELSCOPE_API_KEY set
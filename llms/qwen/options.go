@@ -1,5 +1,7 @@
 package qwen
 
+import "net/http"
+
 // Option is a function that configures an Embedder.
 type Option func(*Embedder)
 
@@ -24,6 +26,14 @@ func WithModel(model string) Option {
 	}
 }
 
+// WithHTTPClient sets the HTTP client used to call the Qwen API, overriding the default
+// client that retries rate limiting and server errors.
+func WithHTTPClient(client *http.Client) Option {
+	return func(e *Embedder) {
+		e.httpClient = client
+	}
+}
+
 // NewEmbedderWithOptions creates a new Qwen embedder with the given options.
 func NewEmbedderWithOptions(opts ...Option) *Embedder {
 	e := &Embedder{
@@ -3,9 +3,10 @@ package memory
 import (
 	"context"
 	"fmt"
-	"math"
 	"sort"
 	"sync"
+
+	"github.com/smallnest/langgraphgo/internal/vector"
 )
 
 // RetrievalMemory uses vector embeddings to retrieve relevant past messages
@@ -94,7 +95,7 @@ func (r *RetrievalMemory) GetContext(ctx context.Context, query string) ([]*Mess
 	scores := make([]scoredMessage, 0, len(r.messages))
 	for _, msg := range r.messages {
 		msgEmbedding := r.embeddings[msg.ID]
-		similarity := cosineSimilarity(queryEmbedding, msgEmbedding)
+		similarity := vector.Cosine(queryEmbedding, msgEmbedding)
 		scores = append(scores, scoredMessage{
 			message: msg,
 			score:   similarity,
@@ -165,26 +166,6 @@ func (r *RetrievalMemory) SetTopK(k int) {
 	}
 }
 
-// cosineSimilarity calculates cosine similarity between two vectors
-func cosineSimilarity(a, b []float64) float64 {
-	if len(a) != len(b) {
-		return 0.0
-	}
-
-	var dotProduct, normA, normB float64
-	for i := range a {
-		dotProduct += a[i] * b[i]
-		normA += a[i] * a[i]
-		normB += b[i] * b[i]
-	}
-
-	if normA == 0 || normB == 0 {
-		return 0.0
-	}
-
-	return dotProduct / (math.Sqrt(normA) * math.Sqrt(normB))
-}
-
 // defaultEmbeddingFunc provides a simple embedding function
 // In production, use a proper embedding model (e.g., OpenAI embeddings)
 func defaultEmbeddingFunc(ctx context.Context, text string) ([]float64, error) {
@@ -208,18 +189,7 @@ func defaultEmbeddingFunc(ctx context.Context, text string) ([]float64, error) {
 		embedding[hash] += float64(count)
 	}
 
-	// Normalize
-	var norm float64
-	for _, val := range embedding {
-		norm += val * val
-	}
-	norm = math.Sqrt(norm)
-
-	if norm > 0 {
-		for i := range embedding {
-			embedding[i] /= norm
-		}
-	}
+	vector.Normalize(embedding)
 
 	return embedding, nil
 }
@@ -21,8 +21,14 @@ type CreateAgentOptions struct {
 	StateModifier          func(messages []llms.MessageContent) []llms.MessageContent
 	MaxIterations          int
 	DisableModelInvocation bool
+	// StopCondition is evaluated on the state after each round of tool calls; returning true
+	// ends the run. It is used by CreateAgentMap.
+	StopCondition func(state map[string]any) (bool, error)
 }
 
+// AgentStoppedStateKey is set to true in the state of an agent run ended by its StopCondition
+const AgentStoppedStateKey = "stopped"
+
 type CreateAgentOption func(*CreateAgentOptions)
 
 func WithSystemMessage(message string) CreateAgentOption {
@@ -49,6 +55,13 @@ func WithDisableModelInvocation(disable bool) CreateAgentOption {
 	return func(o *CreateAgentOptions) { o.DisableModelInvocation = disable }
 }
 
+// WithStopCondition ends the agent loop when cond returns true for the state after a round of
+// tool calls, e.g. when a tool returned a sentinel or a budget is exhausted. The run then ends
+// with AgentStoppedStateKey set; an error from cond fails the run.
+func WithStopCondition(cond func(state map[string]any) (bool, error)) CreateAgentOption {
+	return func(o *CreateAgentOptions) { o.StopCondition = cond }
+}
+
 // CreateAgentMap creates a new agent graph with map[string]any state
 func CreateAgentMap(model llms.Model, inputTools []tools.Tool, maxIterations int, opts ...CreateAgentOption) (*graph.StateRunnable[map[string]any], error) {
 	options := &CreateAgentOptions{}
@@ -271,7 +284,25 @@ func CreateAgentMap(model llms.Model, inputTools []tools.Tool, maxIterations int
 		}
 		return graph.END
 	})
-	workflow.AddEdge("tools", "agent")
+
+	if options.StopCondition != nil {
+		workflow.AddNode("stop_check", "Stop condition check", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			stop, err := options.StopCondition(state)
+			if err != nil {
+				return nil, fmt.Errorf("stop condition: %w", err)
+			}
+			return map[string]any{AgentStoppedStateKey: stop}, nil
+		})
+		workflow.AddEdge("tools", "stop_check")
+		workflow.AddConditionalEdge("stop_check", func(ctx context.Context, state map[string]any) string {
+			if stop, _ := state[AgentStoppedStateKey].(bool); stop {
+				return graph.END
+			}
+			return "agent"
+		})
+	} else {
+		workflow.AddEdge("tools", "agent")
+	}
 
 	return workflow.Compile()
 }
@@ -2,6 +2,8 @@ package prebuilt
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -276,6 +278,64 @@ func TestCreateAgentWithToolCalls(t *testing.T) {
 	})
 }
 
+func TestCreateAgentMap_StopCondition(t *testing.T) {
+	budgetTool := &MockToolWithResponse{name: "test_tool", description: "A test tool", response: "budget exhausted"}
+	toolRounds := func(state map[string]any) int {
+		rounds := 0
+		for _, msg := range state["messages"].([]llms.MessageContent) {
+			if msg.Role == llms.ChatMessageTypeTool {
+				rounds++
+			}
+		}
+		return rounds
+	}
+
+	t.Run("Stops after the round satisfying the condition", func(t *testing.T) {
+		llm := &loopingToolLLM{}
+		agent, err := CreateAgentMap(llm, []tools.Tool{budgetTool}, 10, WithStopCondition(func(state map[string]any) (bool, error) {
+			return toolRounds(state) >= 2, nil
+		}))
+		assert.NoError(t, err)
+
+		result, err := agent.Invoke(context.Background(), map[string]any{
+			"messages": []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "Spend")},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, true, result[AgentStoppedStateKey])
+		assert.Equal(t, 2, llm.calls)
+		assert.Equal(t, 2, toolRounds(result))
+	})
+
+	t.Run("Condition errors fail the run", func(t *testing.T) {
+		agent, err := CreateAgentMap(&loopingToolLLM{}, []tools.Tool{budgetTool}, 10, WithStopCondition(func(state map[string]any) (bool, error) {
+			return false, errors.New("budget service unavailable")
+		}))
+		assert.NoError(t, err)
+
+		_, err = agent.Invoke(context.Background(), map[string]any{
+			"messages": []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "Spend")},
+		})
+		assert.ErrorContains(t, err, "budget service unavailable")
+	})
+}
+
+// loopingToolLLM calls test_tool on every turn
+type loopingToolLLM struct {
+	llms.Model
+	calls int
+}
+
+func (m *loopingToolLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	m.calls++
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{
+		ToolCalls: []llms.ToolCall{{
+			ID:           fmt.Sprintf("call_%d", m.calls),
+			Type:         "function",
+			FunctionCall: &llms.FunctionCall{Name: "test_tool", Arguments: `{"input":"spend"}`},
+		}},
+	}}}, nil
+}
+
 // Mock structures for testing
 type MockLLM struct {
 	llms.Model
@@ -26,7 +26,7 @@ func CreateReactAgentMap(model llms.Model, inputTools []tools.Tool, maxIteration
 
 	// Define the state schema
 	agentSchema := graph.NewMapSchema()
-	agentSchema.RegisterReducer("messages", graph.AppendReducer)
+	agentSchema.RegisterReducer("messages", graph.MessageReducer)
 	workflow.SetSchema(agentSchema)
 
 	// Define the agent node
@@ -17,6 +17,9 @@ type ReflectionAgentConfig struct {
 	SystemMessage    string
 	ReflectionPrompt string
 	Verbose          bool
+	// MaxVerificationRounds limits tool-calling rounds per reflection
+	// (only used by CreateReflectionAgentWithTools)
+	MaxVerificationRounds int
 }
 
 // CreateReflectionAgentMap creates a new Reflection Agent with map[string]any state
@@ -40,7 +43,7 @@ func CreateReflectionAgentMap(config ReflectionAgentConfig) (*graph.StateRunnabl
 
 	workflow := graph.NewStateGraph[map[string]any]()
 	agentSchema := graph.NewMapSchema()
-	agentSchema.RegisterReducer("messages", graph.AppendReducer)
+	agentSchema.RegisterReducer("messages", graph.MessageReducer)
 	workflow.SetSchema(agentSchema)
 
 	workflow.AddNode("generate", "Generate or revise response", func(ctx context.Context, state map[string]any) (map[string]any, error) {
@@ -0,0 +1,266 @@
+package prebuilt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// DefaultMaxVerificationRounds is the default number of tool-calling rounds the
+// reflection step may use to fact-check a single draft.
+const DefaultMaxVerificationRounds = 3
+
+// CreateReflectionAgentWithTools creates a reflection agent whose reflection step can
+// call tools (e.g. retrieval or web search) to fact-check claims made in the draft.
+// Tool results are fed back into the revision so corrections are grounded in evidence.
+//
+// The state tracks:
+//   - "draft": the current response
+//   - "reflection": the latest critique
+//   - "verified_claims" / "unverified_claims": claims reported by the reflection step
+//   - "evidence": tool results gathered while verifying the latest draft
+//
+// The agent stops when the reflection is satisfactory and no unverified claims remain,
+// or when MaxIterations drafts have been generated.
+func CreateReflectionAgentWithTools(config ReflectionAgentConfig, inputTools []tools.Tool) (*graph.StateRunnable[map[string]any], error) {
+	if config.Model == nil {
+		return nil, fmt.Errorf("model is required")
+	}
+	if config.MaxIterations == 0 {
+		config.MaxIterations = 3
+	}
+	if config.MaxVerificationRounds == 0 {
+		config.MaxVerificationRounds = DefaultMaxVerificationRounds
+	}
+	reflectionModel := config.ReflectionModel
+	if reflectionModel == nil {
+		reflectionModel = config.Model
+	}
+	if config.SystemMessage == "" {
+		config.SystemMessage = "You are a helpful assistant. Generate a high-quality response to the user's request."
+	}
+	if config.ReflectionPrompt == "" {
+		config.ReflectionPrompt = buildDefaultVerificationPrompt()
+	}
+	reflectionPrompt := config.ReflectionPrompt + "\n\n" + claimFormatInstructions
+
+	toolExecutor := NewToolExecutor(inputTools)
+	toolDefs := BuildToolDefinitions(inputTools, getToolSchema)
+
+	workflow := graph.NewStateGraph[map[string]any]()
+	workflow.SetSchema(CreateStandardAgentSchema())
+
+	workflow.AddNode("generate", "Generate or revise response", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		iteration, _ := state["iteration"].(int)
+		messages, ok := state["messages"].([]llms.MessageContent)
+		if !ok || len(messages) == 0 {
+			return nil, fmt.Errorf("no messages found")
+		}
+
+		var promptMessages []llms.MessageContent
+		if iteration == 0 {
+			promptMessages = append([]llms.MessageContent{{Role: llms.ChatMessageTypeSystem, Parts: []llms.ContentPart{llms.TextPart(config.SystemMessage)}}}, messages...)
+		} else {
+			reflection, _ := state["reflection"].(string)
+			draft, _ := state["draft"].(string)
+			evidence, _ := state["evidence"].([]string)
+			unverified, _ := state["unverified_claims"].([]string)
+			revisionPrompt := fmt.Sprintf("Revise based on reflection:\nRequest: %s\nDraft: %s\nReflection: %s", getOriginalRequest(messages), draft, reflection)
+			if len(unverified) > 0 {
+				revisionPrompt += "\nUnverified claims (correct or remove them):\n- " + strings.Join(unverified, "\n- ")
+			}
+			if len(evidence) > 0 {
+				revisionPrompt += "\nEvidence gathered while fact-checking:\n" + strings.Join(evidence, "\n")
+			}
+			promptMessages = []llms.MessageContent{
+				{Role: llms.ChatMessageTypeSystem, Parts: []llms.ContentPart{llms.TextPart(config.SystemMessage)}},
+				{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{llms.TextPart(revisionPrompt)}},
+			}
+		}
+
+		resp, err := config.Model.GenerateContent(ctx, promptMessages)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Choices) == 0 {
+			return nil, fmt.Errorf("no response from LLM")
+		}
+		draft := resp.Choices[0].Content
+		return map[string]any{
+			"messages":            []llms.MessageContent{{Role: llms.ChatMessageTypeAI, Parts: []llms.ContentPart{llms.TextPart(draft)}}},
+			"draft":               draft,
+			"iteration":           iteration + 1,
+			"reflection_messages": []llms.MessageContent{},
+			"verification_rounds": 0,
+		}, nil
+	})
+
+	workflow.AddNode("reflect", "Reflect on response and fact-check claims", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		reflectionMessages, _ := state["reflection_messages"].([]llms.MessageContent)
+		if len(reflectionMessages) == 0 {
+			draft, _ := state["draft"].(string)
+			messages, _ := state["messages"].([]llms.MessageContent)
+			reflectionMessages = []llms.MessageContent{
+				{Role: llms.ChatMessageTypeSystem, Parts: []llms.ContentPart{llms.TextPart(reflectionPrompt)}},
+				{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{llms.TextPart(fmt.Sprintf("Request: %s\nResponse: %s", getOriginalRequest(messages), draft))}},
+			}
+		}
+
+		// Once the verification budget is spent, force a final critique without tools
+		var opts []llms.CallOption
+		rounds, _ := state["verification_rounds"].(int)
+		if rounds < config.MaxVerificationRounds && len(toolDefs) > 0 {
+			opts = append(opts, llms.WithTools(toolDefs))
+		}
+
+		resp, err := reflectionModel.GenerateContent(ctx, reflectionMessages, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(resp.Choices) == 0 {
+			return nil, fmt.Errorf("no response from reflection LLM")
+		}
+		choice := resp.Choices[0]
+		aiMsg := llms.MessageContent{Role: llms.ChatMessageTypeAI}
+		if choice.Content != "" {
+			aiMsg.Parts = append(aiMsg.Parts, llms.TextPart(choice.Content))
+		}
+		for _, tc := range choice.ToolCalls {
+			aiMsg.Parts = append(aiMsg.Parts, tc)
+		}
+		reflectionMessages = append(reflectionMessages, aiMsg)
+
+		update := map[string]any{"reflection_messages": reflectionMessages}
+		if len(choice.ToolCalls) == 0 {
+			verified, unverified := parseClaims(choice.Content)
+			update["reflection"] = choice.Content
+			update["verified_claims"] = verified
+			update["unverified_claims"] = unverified
+			update["evidence"] = collectEvidence(reflectionMessages)
+		}
+		return update, nil
+	})
+
+	workflow.AddNode("verify", "Execute fact-checking tool calls", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		reflectionMessages, _ := state["reflection_messages"].([]llms.MessageContent)
+		if len(reflectionMessages) == 0 {
+			return nil, fmt.Errorf("no reflection messages found")
+		}
+		lastMsg := reflectionMessages[len(reflectionMessages)-1]
+
+		for _, part := range lastMsg.Parts {
+			if tc, ok := part.(llms.ToolCall); ok {
+				reflectionMessages = append(reflectionMessages, executeToolCall(ctx, toolExecutor, tc))
+			}
+		}
+
+		rounds, _ := state["verification_rounds"].(int)
+		return map[string]any{
+			"reflection_messages": reflectionMessages,
+			"verification_rounds": rounds + 1,
+		}, nil
+	})
+
+	workflow.SetEntryPoint("generate")
+	workflow.AddConditionalEdge("generate", func(ctx context.Context, state map[string]any) string {
+		iteration, _ := state["iteration"].(int)
+		if iteration >= config.MaxIterations {
+			return graph.END
+		}
+		return "reflect"
+	})
+	workflow.AddConditionalEdge("reflect", func(ctx context.Context, state map[string]any) string {
+		reflectionMessages, _ := state["reflection_messages"].([]llms.MessageContent)
+		if HasToolCallsInLastMessage(reflectionMessages) {
+			return "verify"
+		}
+		reflection, _ := state["reflection"].(string)
+		unverified, _ := state["unverified_claims"].([]string)
+		if isResponseSatisfactory(reflection) && len(unverified) == 0 {
+			return graph.END
+		}
+		return "generate"
+	})
+	workflow.AddEdge("verify", "reflect")
+
+	return workflow.Compile()
+}
+
+// executeToolCall runs a single tool call and wraps the result in a tool message.
+// Tool errors are reported in the message content rather than failing the graph.
+func executeToolCall(ctx context.Context, executor *ToolExecutor, tc llms.ToolCall) llms.MessageContent {
+	inputVal := tc.FunctionCall.Arguments
+	if tool, ok := executor.Tools[tc.FunctionCall.Name]; ok {
+		if _, hasCustomSchema := tool.(ToolWithSchema); !hasCustomSchema {
+			var args map[string]any
+			_ = json.Unmarshal([]byte(tc.FunctionCall.Arguments), &args)
+			if val, ok := args["input"].(string); ok {
+				inputVal = val
+			}
+		}
+	}
+
+	res, err := executor.Execute(ctx, ToolInvocation{
+		Tool:      tc.FunctionCall.Name,
+		ToolInput: inputVal,
+	})
+	if err != nil {
+		res = fmt.Sprintf("Error: %v", err)
+	}
+
+	return llms.MessageContent{
+		Role: llms.ChatMessageTypeTool,
+		Parts: []llms.ContentPart{
+			llms.ToolCallResponse{
+				ToolCallID: tc.ID,
+				Name:       tc.FunctionCall.Name,
+				Content:    res,
+			},
+		},
+	}
+}
+
+// parseClaims extracts claims prefixed with VERIFIED: / UNVERIFIED: from a reflection
+func parseClaims(reflection string) (verified, unverified []string) {
+	verified = []string{}
+	unverified = []string{}
+	for line := range strings.SplitSeq(reflection, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*"))
+		upper := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(upper, "UNVERIFIED:"):
+			unverified = append(unverified, strings.TrimSpace(line[len("UNVERIFIED:"):]))
+		case strings.HasPrefix(upper, "VERIFIED:"):
+			verified = append(verified, strings.TrimSpace(line[len("VERIFIED:"):]))
+		}
+	}
+	return verified, unverified
+}
+
+// collectEvidence returns the tool results gathered during reflection
+func collectEvidence(messages []llms.MessageContent) []string {
+	evidence := []string{}
+	for _, msg := range messages {
+		if msg.Role != llms.ChatMessageTypeTool {
+			continue
+		}
+		for _, part := range msg.Parts {
+			if resp, ok := part.(llms.ToolCallResponse); ok {
+				evidence = append(evidence, fmt.Sprintf("[%s] %s", resp.Name, resp.Content))
+			}
+		}
+	}
+	return evidence
+}
+
+const claimFormatInstructions = `After verifying, list every factual claim of the response on its own line, prefixed with "VERIFIED:" if the evidence supports it or "UNVERIFIED:" otherwise. Then give your critique.`
+
+func buildDefaultVerificationPrompt() string {
+	return `You are a critical fact-checker. Identify the factual claims in the response and use the available tools to verify any claim you are not certain about. Do not accept claims without evidence.`
+}
@@ -0,0 +1,80 @@
+package prebuilt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// scriptedLLM returns a fixed sequence of choices, with no choices for a nil entry
+type scriptedLLM struct {
+	llms.Model
+	choices []*llms.ContentChoice
+	calls   int
+}
+
+func (m *scriptedLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	choice := m.choices[m.calls%len(m.choices)]
+	m.calls++
+	if choice == nil {
+		return &llms.ContentResponse{}, nil
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{choice}}, nil
+}
+
+func TestCreateReflectionAgentWithTools(t *testing.T) {
+	model := &scriptedLLM{choices: []*llms.ContentChoice{
+		{Content: "Paris has 50 million inhabitants."},
+		{ToolCalls: []llms.ToolCall{{
+			ID:           "call_1",
+			Type:         "function",
+			FunctionCall: &llms.FunctionCall{Name: "search", Arguments: `{"input":"Paris population"}`},
+		}}},
+		{Content: "UNVERIFIED: Paris has 50 million inhabitants\nThe figure is wrong."},
+		{Content: "Paris has about 2 million inhabitants."},
+		{Content: "VERIFIED: Paris has about 2 million inhabitants\nExcellent."},
+	}}
+	search := &MockToolWithResponse{name: "search", description: "web search", response: "Paris population: 2.1 million"}
+
+	agent, err := CreateReflectionAgentWithTools(ReflectionAgentConfig{Model: model, MaxIterations: 3}, []tools.Tool{search})
+	require.NoError(t, err)
+
+	res, err := agent.Invoke(context.Background(), map[string]any{
+		"messages": []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "How many people live in Paris?")},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Paris has about 2 million inhabitants.", res["draft"])
+	assert.Equal(t, []string{"Paris has about 2 million inhabitants"}, res["verified_claims"])
+	assert.Empty(t, res["unverified_claims"])
+	assert.Equal(t, 2, res["iteration"])
+	assert.Equal(t, 5, model.calls)
+}
+
+func TestCreateReflectionAgentWithTools_NoChoices(t *testing.T) {
+	scripts := map[string][]*llms.ContentChoice{
+		"Generate": {nil},
+		"Reflect":  {{Content: "Paris is in France."}, nil},
+	}
+	for name, choices := range scripts {
+		t.Run(name, func(t *testing.T) {
+			agent, err := CreateReflectionAgentWithTools(ReflectionAgentConfig{Model: &scriptedLLM{choices: choices}}, nil)
+			require.NoError(t, err)
+
+			_, err = agent.Invoke(context.Background(), map[string]any{
+				"messages": []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "Where is Paris?")},
+			})
+			assert.ErrorContains(t, err, "no response from")
+		})
+	}
+}
+
+func TestParseClaims(t *testing.T) {
+	verified, unverified := parseClaims("- VERIFIED: a\n* unverified: b\nnoise")
+	assert.Equal(t, []string{"a"}, verified)
+	assert.Equal(t, []string{"b"}, unverified)
+}
@@ -0,0 +1,153 @@
+package prebuilt
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+const (
+	// RouteStateKey holds the route chosen by a router node
+	RouteStateKey = "route"
+	// RouteConfidenceStateKey holds the model's confidence in the chosen route, between 0 and 1
+	RouteConfidenceStateKey = "route_confidence"
+	// RouteReasoningStateKey holds the model's explanation of the choice
+	RouteReasoningStateKey = "route_reasoning"
+)
+
+// RouteSpec describes a route a router node can choose
+type RouteSpec struct {
+	Name        string
+	Description string
+}
+
+// RouterOptions configures a router node
+type RouterOptions struct {
+	// DefaultRoute is chosen when the model picks an unknown route or is not confident
+	// enough. It defaults to the first route.
+	DefaultRoute string
+	// MinConfidence is the confidence below which the default route is chosen
+	MinConfidence float64
+}
+
+type RouterOption func(*RouterOptions)
+
+func WithDefaultRoute(route string) RouterOption {
+	return func(o *RouterOptions) { o.DefaultRoute = route }
+}
+
+func WithMinConfidence(confidence float64) RouterOption {
+	return func(o *RouterOptions) { o.MinConfidence = confidence }
+}
+
+// NewRouterNode creates a node that asks the model to classify the input stored under
+// inputFrom into one of the described routes. The input may be a string or messages, of
+// which the last one is classified. The node returns the state with the choice under
+// RouteStateKey, RouteConfidenceStateKey and RouteReasoningStateKey; RouteFromState
+// reads it back for a conditional edge:
+//
+//	g.AddNode("router", "Classify the request", prebuilt.NewRouterNode(model, routes, "input"))
+//	g.AddConditionalEdge("router", prebuilt.RouteFromState)
+func NewRouterNode(llm llms.Model, routes []RouteSpec, inputFrom string, opts ...RouterOption) func(context.Context, map[string]any) (map[string]any, error) {
+	options := &RouterOptions{}
+	if len(routes) > 0 {
+		options.DefaultRoute = routes[0].Name
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	known := make(map[string]string, len(routes))
+	var sb strings.Builder
+	sb.WriteString("Classify the user input into exactly one of these routes:\n")
+	for _, route := range routes {
+		known[strings.ToLower(route.Name)] = route.Name
+		fmt.Fprintf(&sb, "- %s: %s\n", route.Name, route.Description)
+	}
+	sb.WriteString(`
+Respond in exactly this format:
+ROUTE: <route name>
+CONFIDENCE: <number between 0.0 and 1.0>
+REASONING: <one sentence>`)
+	systemPrompt := sb.String()
+
+	return func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		if len(routes) == 0 {
+			return nil, fmt.Errorf("router has no routes")
+		}
+
+		input, err := routerInput(state[inputFrom])
+		if err != nil {
+			return nil, fmt.Errorf("invalid router input %q: %w", inputFrom, err)
+		}
+
+		resp, err := llm.GenerateContent(ctx, []llms.MessageContent{
+			llms.TextParts(llms.ChatMessageTypeSystem, systemPrompt),
+			llms.TextParts(llms.ChatMessageTypeHuman, input),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("router classification failed: %w", err)
+		}
+		if len(resp.Choices) == 0 {
+			return nil, fmt.Errorf("no response from LLM")
+		}
+
+		fields := make(map[string]string)
+		for line := range strings.SplitSeq(resp.Choices[0].Content, "\n") {
+			if name, value, ok := strings.Cut(line, ":"); ok {
+				fields[strings.ToUpper(strings.TrimSpace(name))] = strings.TrimSpace(value)
+			}
+		}
+
+		route, ok := known[strings.ToLower(strings.Trim(fields["ROUTE"], "\"'` "))]
+		confidence, err := strconv.ParseFloat(fields["CONFIDENCE"], 64)
+		if err != nil {
+			confidence = 0
+		}
+		confidence = min(max(confidence, 0), 1)
+		if !ok || confidence < options.MinConfidence {
+			route = options.DefaultRoute
+		}
+
+		result := make(map[string]any, len(state)+3)
+		maps.Copy(result, state)
+		result[RouteStateKey] = route
+		result[RouteConfidenceStateKey] = confidence
+		result[RouteReasoningStateKey] = fields["REASONING"]
+		return result, nil
+	}
+}
+
+// RouteFromState is a condition for AddConditionalEdge that returns the route chosen
+// by a router node
+func RouteFromState(_ context.Context, state map[string]any) string {
+	route, _ := state[RouteStateKey].(string)
+	return route
+}
+
+// routerInput extracts the text to classify from a state value
+func routerInput(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []llms.MessageContent:
+		if len(v) == 0 {
+			return "", fmt.Errorf("no messages")
+		}
+		var parts []string
+		for _, part := range v[len(v)-1].Parts {
+			if text, ok := part.(llms.TextContent); ok {
+				parts = append(parts, text.Text)
+			}
+		}
+		return strings.Join(parts, "\n"), nil
+	case nil:
+		return "", fmt.Errorf("not found in state")
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
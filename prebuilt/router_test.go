@@ -0,0 +1,89 @@
+package prebuilt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// routerLLM answers every classification with a fixed response
+type routerLLM struct {
+	llms.Model
+	response string
+	input    string
+}
+
+func (m *routerLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	m.input = messages[len(messages)-1].Parts[0].(llms.TextContent).Text
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: m.response}}}, nil
+}
+
+func TestRouterNode(t *testing.T) {
+	routes := []RouteSpec{
+		{Name: "billing", Description: "Payments, invoices and refunds"},
+		{Name: "technical", Description: "Bugs and outages"},
+	}
+
+	tests := []struct {
+		name           string
+		response       string
+		opts           []RouterOption
+		wantRoute      string
+		wantConfidence float64
+	}{
+		{"chosen route", "ROUTE: Technical\nCONFIDENCE: 0.9\nREASONING: the site is down", nil, "technical", 0.9},
+		{"unknown route falls back", "ROUTE: sales\nCONFIDENCE: 0.8", []RouterOption{WithDefaultRoute("technical")}, "technical", 0.8},
+		{"low confidence falls back", "ROUTE: technical\nCONFIDENCE: 0.3", []RouterOption{WithMinConfidence(0.5)}, "billing", 0.3},
+		{"unparsable confidence", "ROUTE: billing", []RouterOption{WithMinConfidence(0.5)}, "billing", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := NewRouterNode(&routerLLM{response: tt.response}, routes, "input", tt.opts...)
+			result, err := node(context.Background(), map[string]any{"input": "The site is down"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRoute, result[RouteStateKey])
+			assert.Equal(t, tt.wantConfidence, result[RouteConfidenceStateKey])
+		})
+	}
+}
+
+func TestRouterNode_Graph(t *testing.T) {
+	llm := &routerLLM{response: "ROUTE: billing\nCONFIDENCE: 0.95\nREASONING: refund request"}
+	routes := []RouteSpec{
+		{Name: "billing", Description: "Payments, invoices and refunds"},
+		{Name: "technical", Description: "Bugs and outages"},
+	}
+
+	g := graph.NewStateGraph[map[string]any]()
+	g.SetSchema(graph.NewMapSchema())
+	g.AddNode("router", "Classify the request", NewRouterNode(llm, routes, "messages"))
+	for _, route := range routes {
+		g.AddNode(route.Name, route.Description, func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			return map[string]any{"handled_by": route.Name}, nil
+		})
+		g.AddEdge(route.Name, graph.END)
+	}
+	g.AddConditionalEdge("router", RouteFromState)
+	g.SetEntryPoint("router")
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	result, err := runnable.Invoke(context.Background(), map[string]any{
+		"messages": []llms.MessageContent{
+			llms.TextParts(llms.ChatMessageTypeHuman, "Hi"),
+			llms.TextParts(llms.ChatMessageTypeHuman, "I want a refund"),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "billing", result["handled_by"])
+	assert.Equal(t, "refund request", result[RouteReasoningStateKey])
+	assert.Equal(t, "I want a refund", llm.input)
+
+	_, err = NewRouterNode(llm, routes, "missing")(context.Background(), map[string]any{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,137 @@
+package prebuilt
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// Environment is a simulated environment for simulator-in-the-loop ("mental loop") agents.
+// The agent forks the real environment with Copy, runs proposed actions forward in the
+// copies and only executes the refined action in the real environment.
+type Environment[A any] interface {
+	// Copy returns an independent deep copy of the environment
+	Copy() Environment[A]
+	// Step applies an action and advances the environment by one step
+	Step(action A)
+	// Value returns the quantity the agent optimizes (e.g. portfolio value)
+	Value() float64
+}
+
+// SeedableEnvironment is implemented by environments with their own random source.
+// Simulations seed every copy, so rollouts are reproducible and independent of the
+// randomness of the real environment.
+type SeedableEnvironment interface {
+	Seed(seed int64)
+}
+
+// SimulationConfig configures the rollouts of Simulate and SimulatorNode
+type SimulationConfig[A any] struct {
+	// Rollouts is the number of simulations to run (default 5)
+	Rollouts int
+	// Horizon is the number of steps per rollout; the first step applies the proposed action (default 1)
+	Horizon int
+	// Seed is the base seed; rollout i seeds its copy with Seed+i
+	Seed int64
+	// NextAction chooses the actions after the first step. If nil, the proposed action is repeated.
+	NextAction func(env Environment[A], step int) A
+}
+
+// SimulationStats aggregates the outcome of the rollouts of an action
+type SimulationStats struct {
+	// InitialValue is the environment value before the action
+	InitialValue float64
+	// FinalValues holds the final value of each rollout
+	FinalValues []float64
+	// Mean, StdDev, Min and Max summarize FinalValues
+	Mean   float64
+	StdDev float64
+	Min    float64
+	Max    float64
+	// MeanReturn is the mean relative change of the value, (Mean-InitialValue)/InitialValue
+	MeanReturn float64
+}
+
+// Simulate runs seeded rollouts of action on copies of env and aggregates the final values.
+// env itself is never stepped.
+func Simulate[A any](ctx context.Context, env Environment[A], action A, config SimulationConfig[A]) (*SimulationStats, error) {
+	if config.Rollouts <= 0 {
+		config.Rollouts = 5
+	}
+	if config.Horizon <= 0 {
+		config.Horizon = 1
+	}
+
+	stats := &SimulationStats{
+		InitialValue: env.Value(),
+		FinalValues:  make([]float64, config.Rollouts),
+	}
+	for i := range config.Rollouts {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("simulation cancelled after %d rollouts: %w", i, err)
+		}
+
+		sim := env.Copy()
+		if seedable, ok := sim.(SeedableEnvironment); ok {
+			seedable.Seed(config.Seed + int64(i))
+		}
+
+		sim.Step(action)
+		for step := 1; step < config.Horizon; step++ {
+			next := action
+			if config.NextAction != nil {
+				next = config.NextAction(sim, step)
+			}
+			sim.Step(next)
+		}
+		stats.FinalValues[i] = sim.Value()
+	}
+
+	stats.summarize()
+	return stats, nil
+}
+
+// summarize computes the aggregate statistics of FinalValues
+func (s *SimulationStats) summarize() {
+	s.Min, s.Max = math.Inf(1), math.Inf(-1)
+	var sum float64
+	for _, v := range s.FinalValues {
+		sum += v
+		s.Min = math.Min(s.Min, v)
+		s.Max = math.Max(s.Max, v)
+	}
+	s.Mean = sum / float64(len(s.FinalValues))
+
+	var variance float64
+	for _, v := range s.FinalValues {
+		variance += (v - s.Mean) * (v - s.Mean)
+	}
+	s.StdDev = math.Sqrt(variance / float64(len(s.FinalValues)))
+
+	if s.InitialValue != 0 {
+		s.MeanReturn = (s.Mean - s.InitialValue) / s.InitialValue
+	}
+}
+
+// SimulatorNode is a reusable node for the SIMULATE step of a mental loop agent.
+// It reads the real environment and the proposed action from the state, runs seeded
+// rollouts with Simulate and stores the aggregated statistics with setStats.
+func SimulatorNode[S, A any](
+	getEnv func(S) Environment[A],
+	getAction func(S) A,
+	setStats func(S, *SimulationStats) S,
+	config SimulationConfig[A],
+) func(context.Context, S) (S, error) {
+	return func(ctx context.Context, state S) (S, error) {
+		env := getEnv(state)
+		if env == nil {
+			return state, fmt.Errorf("no environment found in state")
+		}
+
+		stats, err := Simulate(ctx, env, getAction(state), config)
+		if err != nil {
+			return state, err
+		}
+		return setStats(state, stats), nil
+	}
+}
@@ -0,0 +1,88 @@
+package prebuilt
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// walkEnv is a random walk where the action is added to the value before a random move
+type walkEnv struct {
+	value float64
+	steps int
+	rng   *rand.Rand
+}
+
+func (e *walkEnv) Copy() Environment[float64] {
+	return &walkEnv{value: e.value, steps: e.steps, rng: e.rng}
+}
+
+func (e *walkEnv) Seed(seed int64) { e.rng = rand.New(rand.NewSource(seed)) }
+
+func (e *walkEnv) Step(action float64) {
+	e.value += action + e.rng.NormFloat64()
+	e.steps++
+}
+
+func (e *walkEnv) Value() float64 { return e.value }
+
+func TestSimulate(t *testing.T) {
+	ctx := context.Background()
+	real := &walkEnv{value: 100, rng: rand.New(rand.NewSource(1))}
+	config := SimulationConfig[float64]{
+		Rollouts: 20,
+		Horizon:  3,
+		Seed:     42,
+		NextAction: func(env Environment[float64], step int) float64 {
+			return 0 // hold after the initial action
+		},
+	}
+
+	stats, err := Simulate(ctx, real, 10, config)
+	require.NoError(t, err)
+	assert.Len(t, stats.FinalValues, 20)
+	assert.Equal(t, 100.0, stats.InitialValue)
+	assert.InDelta(t, 110, stats.Mean, 2)
+	assert.InDelta(t, (stats.Mean-100)/100, stats.MeanReturn, 1e-9)
+	assert.LessOrEqual(t, stats.Min, stats.Mean)
+	assert.GreaterOrEqual(t, stats.Max, stats.Mean)
+	assert.Positive(t, stats.StdDev)
+
+	// The real environment is untouched and seeded rollouts are reproducible
+	assert.Equal(t, 0, real.steps)
+	again, err := Simulate(ctx, real, 10, config)
+	require.NoError(t, err)
+	assert.Equal(t, stats.FinalValues, again.FinalValues)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = Simulate(cancelled, real, 10, config)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestSimulatorNode(t *testing.T) {
+	type state struct {
+		Market   *walkEnv
+		Proposal float64
+		Stats    *SimulationStats
+	}
+
+	node := SimulatorNode(
+		func(s state) Environment[float64] { return s.Market },
+		func(s state) float64 { return s.Proposal },
+		func(s state, stats *SimulationStats) state {
+			s.Stats = stats
+			return s
+		},
+		SimulationConfig[float64]{Rollouts: 3, Seed: 7},
+	)
+
+	result, err := node(context.Background(), state{Market: &walkEnv{value: 50}, Proposal: -5})
+	require.NoError(t, err)
+	require.NotNil(t, result.Stats)
+	assert.Len(t, result.Stats.FinalValues, 3)
+	assert.Equal(t, 50.0, result.Stats.InitialValue)
+}
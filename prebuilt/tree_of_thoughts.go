@@ -3,6 +3,9 @@ package prebuilt
 import (
 	"context"
 	"fmt"
+	"math"
+	"math/rand"
+	"sort"
 
 	"github.com/smallnest/langgraphgo/graph"
 )
@@ -27,6 +30,17 @@ type SearchPath struct {
 	Score  float64
 }
 
+// SamplingStrategy selects the paths kept after each evaluation
+type SamplingStrategy string
+
+const (
+	// SamplingGreedy keeps the MaxPaths highest-scored paths
+	SamplingGreedy SamplingStrategy = "greedy"
+	// SamplingSoftmax samples MaxPaths paths without replacement, weighting each by
+	// exp(score/Temperature), so lower-scored paths are occasionally explored
+	SamplingSoftmax SamplingStrategy = "softmax"
+)
+
 type TreeOfThoughtsConfig struct {
 	Generator    ThoughtGenerator
 	Evaluator    ThoughtEvaluator
@@ -34,6 +48,64 @@ type TreeOfThoughtsConfig struct {
 	MaxPaths     int
 	Verbose      bool
 	InitialState ThoughtState
+
+	// SamplingStrategy selects the paths to expand (default SamplingGreedy)
+	SamplingStrategy SamplingStrategy
+	// Temperature controls the exploration of SamplingSoftmax: higher values flatten the
+	// score distribution. A non-positive temperature selects greedily.
+	Temperature float64
+	// Seed seeds the sampling; runs with the same seed explore the same paths
+	Seed int64
+}
+
+// selectPaths returns the indices of the paths to keep, given their scores
+func selectPaths(scores []float64, config TreeOfThoughtsConfig, iteration int) []int {
+	indices := make([]int, len(scores))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(a, b int) bool { return scores[indices[a]] > scores[indices[b]] })
+	if len(indices) <= config.MaxPaths {
+		return indices
+	}
+	if config.SamplingStrategy != SamplingSoftmax || config.Temperature <= 0 {
+		return indices[:config.MaxPaths]
+	}
+
+	// Seeding per iteration keeps runs reproducible across invocations
+	rng := rand.New(rand.NewSource(config.Seed + int64(iteration)))
+	best := scores[indices[0]]
+	weights := make([]float64, len(indices))
+	for i, idx := range indices {
+		weights[i] = math.Exp((scores[idx] - best) / config.Temperature)
+	}
+
+	selected := make([]int, 0, config.MaxPaths)
+	taken := make([]bool, len(indices))
+	for len(selected) < config.MaxPaths {
+		var total float64
+		for i, w := range weights {
+			if !taken[i] {
+				total += w
+			}
+		}
+		// When the remaining weights underflow to zero, the best remaining path is taken
+		pick := -1
+		r := rng.Float64() * total
+		for i, w := range weights {
+			if taken[i] {
+				continue
+			}
+			pick = i
+			if total == 0 || r < w {
+				break
+			}
+			r -= w
+		}
+		selected = append(selected, indices[pick])
+		taken[pick] = true
+	}
+	return selected
 }
 
 // CreateTreeOfThoughtsAgentMap creates a ToT agent with map[string]any state
@@ -100,16 +172,19 @@ func CreateTreeOfThoughtsAgentMap(config TreeOfThoughtsConfig) (*graph.StateRunn
 			score, _ := config.Evaluator.Evaluate(ctx, last, len(activePaths[i].States))
 			activePaths[i].Score = score
 		}
-		// Sort and prune (simple implementation)
-		var pruned []SearchPath
+		// Prune invalid paths, then keep MaxPaths according to the sampling strategy
+		var candidates []SearchPath
+		var scores []float64
 		for _, p := range activePaths {
 			if p.Score >= 0 {
-				pruned = append(pruned, p)
+				candidates = append(candidates, p)
+				scores = append(scores, p.Score)
 			}
 		}
-		// Keep top MaxPaths (simplified)
-		if len(pruned) > config.MaxPaths {
-			pruned = pruned[:config.MaxPaths]
+		iteration, _ := state["iteration"].(int)
+		var pruned []SearchPath
+		for _, i := range selectPaths(scores, config, iteration) {
+			pruned = append(pruned, candidates[i])
 		}
 		return map[string]any{"active_paths": pruned}, nil
 	})
@@ -207,13 +282,25 @@ func CreateTreeOfThoughtsAgent[S any](
 
 	workflow.AddNode("evaluate", "Evaluate paths", func(ctx context.Context, state S) (S, error) {
 		activePaths := getActivePaths(state)
-		for _, path := range activePaths {
+		ids := make([]string, 0, len(activePaths))
+		for id, path := range activePaths {
 			last := path.States[len(path.States)-1]
 			score, _ := config.Evaluator.Evaluate(ctx, last, len(path.States))
 			path.Score = score
+			ids = append(ids, id)
+		}
+		// Sort the IDs so that sampling does not depend on map iteration order
+		sort.Strings(ids)
+		scores := make([]float64, len(ids))
+		for i, id := range ids {
+			scores[i] = activePaths[id].Score
+		}
+
+		kept := make(map[string]*SearchPath)
+		for _, i := range selectPaths(scores, config, getIteration(state)) {
+			kept[ids[i]] = activePaths[ids[i]]
 		}
-		// Simplified pruning and top-k
-		state = setActivePaths(state, activePaths) // Update state
+		state = setActivePaths(state, kept)
 		return state, nil
 	})
 
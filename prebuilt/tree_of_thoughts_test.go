@@ -530,3 +530,33 @@ func TestTreeOfThoughtsConfig(t *testing.T) {
 		assert.NotNil(t, config.InitialState)
 	})
 }
+
+func TestSelectPaths(t *testing.T) {
+	scores := []float64{0.1, 0.9, 0.5, 0.7, 0.3}
+
+	t.Run("greedy keeps the highest scores", func(t *testing.T) {
+		config := TreeOfThoughtsConfig{MaxPaths: 2}
+		assert.Equal(t, []int{1, 3}, selectPaths(scores, config, 0))
+	})
+
+	t.Run("softmax sampling is reproducible and explores", func(t *testing.T) {
+		config := TreeOfThoughtsConfig{MaxPaths: 2, SamplingStrategy: SamplingSoftmax, Temperature: 1, Seed: 7}
+		first := selectPaths(scores, config, 0)
+		assert.Len(t, first, 2)
+		assert.NotEqual(t, first[0], first[1])
+		assert.Equal(t, first, selectPaths(scores, config, 0))
+
+		explored := map[int]bool{}
+		for iteration := range 50 {
+			for _, i := range selectPaths(scores, config, iteration) {
+				explored[i] = true
+			}
+		}
+		assert.Len(t, explored, len(scores), "lower-scored paths are sampled too")
+	})
+
+	t.Run("underflowing weights fall back to the best paths", func(t *testing.T) {
+		config := TreeOfThoughtsConfig{MaxPaths: 3, SamplingStrategy: SamplingSoftmax, Temperature: 1e-6}
+		assert.ElementsMatch(t, []int{1, 3, 2}, selectPaths(scores, config, 0))
+	})
+}
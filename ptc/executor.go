@@ -43,11 +43,22 @@ const (
 
 // CodeExecutor handles the execution of programmatic tool calling code
 type CodeExecutor struct {
-	Language   ExecutionLanguage
-	Tools      []tools.Tool
-	Timeout    time.Duration
-	WorkDir    string
-	Mode       ExecutionMode
+	Language ExecutionLanguage
+	Tools    []tools.Tool
+	Timeout  time.Duration
+	WorkDir  string
+	Mode     ExecutionMode
+	// AllowedImports lists the standard-library modules the code may use; nil does not
+	// restrict the code. For Python, the code is parsed before it runs and rejected if it
+	// imports another module, imports dynamically (__import__, importlib), runs code with
+	// exec or eval, or uses the system modules of the execution template. For Go, the listed
+	// packages are imported when the code refers to them, and os, os/exec and net/http are
+	// not available. This keeps generated code within its scope but is not a sandbox:
+	// run untrusted code in an isolated environment.
+	AllowedImports []string
+	// Globals are injected into the execution scope. Values are decoded from JSON, except
+	// Code values which are inserted as source.
+	Globals    map[string]any
 	toolServer *ToolServer
 }
 
@@ -57,6 +68,9 @@ type ExecutionResult struct {
 	Error  error
 	Stdout string
 	Stderr string
+	// ToolCalls lists the tools the code called through the tool server, in execution order.
+	// Tools embedded in direct mode (shell, Python and file tools) are not included.
+	ToolCalls []ToolCall
 }
 
 // NewCodeExecutor creates a new code executor for PTC
@@ -117,20 +131,37 @@ func (ce *CodeExecutor) Execute(ctx context.Context, code string) (*ExecutionRes
 	log.Debug("Executing code in %s mode with language %s", ce.Mode, ce.Language)
 	log.Debug("Code length: %d bytes", len(code))
 
+	if err := ValidateImports(ce.Language, ce.AllowedImports); err != nil {
+		return nil, fmt.Errorf("invalid allowed imports: %w", err)
+	}
+	if err := ValidateGlobals(ce.Globals); err != nil {
+		return nil, fmt.Errorf("invalid globals: %w", err)
+	}
+
 	var result *ExecutionResult
 	var err error
 
+	// Record the tool calls the code makes through the tool server
+	executionID := fmt.Sprintf("exec_%d", time.Now().UnixNano())
+	ce.toolServer.beginRecording(executionID)
+
 	switch ce.Language {
 	case LanguagePython:
-		result, err = ce.executePython(ctx, code)
+		result, err = ce.executePython(ctx, code, executionID)
 	case LanguageGo:
-		result, err = ce.executeGo(ctx, code)
+		result, err = ce.executeGo(ctx, code, executionID)
 	default:
+		ce.toolServer.endRecording(executionID)
 		err = fmt.Errorf("unsupported language: %s", ce.Language)
 		log.Error("Unsupported language: %s", ce.Language)
 		return nil, err
 	}
 
+	calls := ce.toolServer.endRecording(executionID)
+	if result != nil {
+		result.ToolCalls = calls
+	}
+
 	if err != nil {
 		log.Error("Code execution failed: %v", err)
 	} else {
@@ -141,17 +172,24 @@ func (ce *CodeExecutor) Execute(ctx context.Context, code string) (*ExecutionRes
 }
 
 // executePython executes Python code with tool bindings
-func (ce *CodeExecutor) executePython(ctx context.Context, code string) (*ExecutionResult, error) {
+func (ce *CodeExecutor) executePython(ctx context.Context, code, executionID string) (*ExecutionResult, error) {
 	// Create a temporary Python script
 	scriptPath := filepath.Join(ce.WorkDir, fmt.Sprintf("ptc_script_%d.py", time.Now().UnixNano()))
 	defer os.Remove(scriptPath)
 
+	if ce.AllowedImports != nil {
+		if err := checkPythonCode(ctx, code, ce.AllowedImports); err != nil {
+			// Reported like a runtime error so that the model can rewrite the code
+			return &ExecutionResult{Output: err.Error(), Stderr: err.Error(), Error: err}, nil
+		}
+	}
+
 	// Generate Python tool wrapper functions based on execution mode
 	var toolWrappers string
 	if ce.Mode == ModeServer {
-		toolWrappers = ce.generatePythonToolWrappersServer()
+		toolWrappers = ce.generatePythonToolWrappersServer(executionID)
 	} else {
-		toolWrappers = ce.generatePythonToolWrappersDirect()
+		toolWrappers = ce.generatePythonToolWrappersDirect(executionID)
 	}
 
 	// Combine tool wrappers and user code
@@ -162,9 +200,12 @@ import sys
 # Tool wrapper functions
 %s
 
+# Allowed imports and globals
+%s
+
 # User code
 %s
-`, toolWrappers, code)
+`, toolWrappers, pythonScope(ce.AllowedImports, ce.Globals), code)
 
 	if err := os.WriteFile(scriptPath, []byte(fullScript), 0600); err != nil {
 		return nil, fmt.Errorf("failed to write script: %w", err)
@@ -190,7 +231,7 @@ import sys
 }
 
 // executeGo executes Go code with tool bindings
-func (ce *CodeExecutor) executeGo(ctx context.Context, code string) (*ExecutionResult, error) {
+func (ce *CodeExecutor) executeGo(ctx context.Context, code, executionID string) (*ExecutionResult, error) {
 	// Create a temporary Go file
 	scriptPath := filepath.Join(ce.WorkDir, fmt.Sprintf("ptc_script_%d.go", time.Now().UnixNano()))
 	defer os.Remove(scriptPath)
@@ -198,9 +239,21 @@ func (ce *CodeExecutor) executeGo(ctx context.Context, code string) (*ExecutionR
 	// Generate Go tool wrapper functions based on execution mode
 	var toolWrappers string
 	if ce.Mode == ModeServer {
-		toolWrappers = ce.generateGoToolWrappersServer()
+		toolWrappers = ce.generateGoToolWrappersServer(executionID)
 	} else {
-		toolWrappers = ce.generateGoToolWrappersDirect()
+		toolWrappers = ce.generateGoToolWrappersDirect(executionID)
+	}
+
+	if ce.AllowedImports != nil {
+		if err := checkGoCode(code); err != nil {
+			// Reported like a compile error so that the model can rewrite the code
+			return &ExecutionResult{Output: err.Error(), Stderr: err.Error(), Error: err}, nil
+		}
+	}
+
+	var extraImports string
+	for _, imp := range goExtraImports(code, ce.AllowedImports) {
+		extraImports += fmt.Sprintf("\t%q\n", imp)
 	}
 
 	// Combine tool wrappers and user code
@@ -213,28 +266,33 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
-	"os"
-	"os/exec"
+	ptchttp "net/http"
+	ptcos "os"
+	ptcexec "os/exec"
 	"strings"
-)
+%s)
 
 // Prevent unused import errors
 var _ = json.Marshal
 var _ = fmt.Println
 var _ = strings.Contains
 var _ = bytes.NewBuffer
-var _ = http.Client{}
+var _ = ptchttp.Client{}
 var _ = io.ReadAll
+var _ = ptcos.Exit
+var _ = ptcexec.Command
 
 // Tool wrapper functions
 %s
 
+// Globals
+%s
+
 func main() {
 	ctx := context.Background()
 	%s
 }
-`, toolWrappers, code)
+`, extraImports, toolWrappers, goGlobals(ce.Globals), code)
 
 	if err := os.WriteFile(scriptPath, []byte(fullScript), 0600); err != nil {
 		return nil, fmt.Errorf("failed to write script: %w", err)
@@ -260,7 +318,7 @@ func main() {
 }
 
 // generatePythonToolWrappersServer creates Python wrapper functions for tools (server mode)
-func (ce *CodeExecutor) generatePythonToolWrappersServer() string {
+func (ce *CodeExecutor) generatePythonToolWrappersServer(executionID string) string {
 	var wrappers []string
 
 	serverURL := ce.toolServer.GetBaseURL()
@@ -283,6 +341,7 @@ except ImportError:
     import urllib2 as urllib
 
 TOOL_SERVER_URL = "%s"
+EXECUTION_ID = "%s"
 
 def call_tool(tool_name, tool_input):
     """Call a tool through the HTTP tool server"""
@@ -290,7 +349,8 @@ def call_tool(tool_name, tool_input):
         url = TOOL_SERVER_URL + "/call"
         data = json.dumps({
             "tool_name": tool_name,
-            "input": tool_input
+            "input": tool_input,
+            "execution_id": EXECUTION_ID
         }).encode('utf-8')
 
         req = urllib.request.Request(url, data=data, headers={'Content-Type': 'application/json'})
@@ -303,7 +363,7 @@ def call_tool(tool_name, tool_input):
             return f"Error calling tool {tool_name}: {result.get('error', 'Unknown error')}"
     except Exception as e:
         return f"Error calling tool {tool_name}: {str(e)}"
-`, string(toolsJSON), serverURL)
+`, string(toolsJSON), serverURL, executionID)
 
 	wrappers = append(wrappers, wrapper)
 
@@ -324,7 +384,7 @@ def %s(input_data):
 
 // generatePythonToolWrappersDirect creates Python wrapper functions for tools (direct mode)
 // In direct mode, shell/python/file tools are embedded; generic tools use internal server
-func (ce *CodeExecutor) generatePythonToolWrappersDirect() string {
+func (ce *CodeExecutor) generatePythonToolWrappersDirect(executionID string) string {
 	var wrappers []string
 
 	serverURL := ce.toolServer.GetBaseURL()
@@ -343,6 +403,7 @@ except ImportError:
     import urllib2 as urllib
 
 INTERNAL_TOOL_SERVER = "%s"
+EXECUTION_ID = "%s"
 
 # Helper function to call generic tools via internal server
 def _call_generic_tool(tool_name, tool_input):
@@ -351,7 +412,8 @@ def _call_generic_tool(tool_name, tool_input):
         url = INTERNAL_TOOL_SERVER + "/call"
         data = json.dumps({
             "tool_name": tool_name,
-            "input": tool_input
+            "input": tool_input,
+            "execution_id": EXECUTION_ID
         }).encode('utf-8')
 
         req = urllib.request.Request(url, data=data, headers={'Content-Type': 'application/json'})
@@ -422,7 +484,7 @@ def _write_file(file_path, content):
         return f"Successfully wrote to {file_path}"
     except Exception as e:
         return f"File write error: {str(e)}"
-`, serverURL)
+`, serverURL, executionID)
 	wrappers = append(wrappers, wrapper)
 
 	// Generate embedded tool functions based on tool name patterns
@@ -547,27 +609,23 @@ def %s(input_data):
 }
 
 // generateGoToolWrappersServer creates Go wrapper functions for tools (server mode)
-func (ce *CodeExecutor) generateGoToolWrappersServer() string {
+func (ce *CodeExecutor) generateGoToolWrappersServer(executionID string) string {
 	var wrappers []string
 
 	serverURL := ce.toolServer.GetBaseURL()
 
 	// Create the call_tool function
 	wrapper := fmt.Sprintf(`
-import (
-	"bytes"
-	"io"
-	"net/http"
-	"os"
-)
-
 const toolServerURL = "%s"
 
+const executionID = "%s"
+
 // callTool calls a tool through the HTTP tool server
 func callTool(ctx context.Context, toolName string, toolInput any) (string, error) {
 	requestBody := map[string]any{
-		"tool_name": toolName,
-		"input":     toolInput,
+		"tool_name":    toolName,
+		"input":        toolInput,
+		"execution_id": executionID,
 	}
 
 	jsonData, err := json.Marshal(requestBody)
@@ -575,13 +633,13 @@ func callTool(ctx context.Context, toolName string, toolInput any) (string, erro
 		return "", fmt.Errorf("failed to marshal request: %%w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", toolServerURL+"/call", bytes.NewBuffer(jsonData))
+	req, err := ptchttp.NewRequestWithContext(ctx, "POST", toolServerURL+"/call", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %%w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
+	client := &ptchttp.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to call tool: %%w", err)
@@ -610,7 +668,7 @@ func callTool(ctx context.Context, toolName string, toolInput any) (string, erro
 	}
 	return "", fmt.Errorf("tool execution failed: %%s", errorMsg)
 }
-`, serverURL)
+`, serverURL, executionID)
 	wrappers = append(wrappers, wrapper)
 
 	// Generate individual tool functions
@@ -629,7 +687,7 @@ func %s(ctx context.Context, input string) (string, error) {
 
 // generateGoToolWrappersDirect creates Go wrapper functions for tools (direct mode)
 // In direct mode, shell/python/file tools are embedded; generic tools use internal server
-func (ce *CodeExecutor) generateGoToolWrappersDirect() string {
+func (ce *CodeExecutor) generateGoToolWrappersDirect(executionID string) string {
 	var wrappers []string
 
 	serverURL := ce.toolServer.GetBaseURL()
@@ -640,11 +698,15 @@ func (ce *CodeExecutor) generateGoToolWrappersDirect() string {
 // Internal tool server URL for generic tools
 const internalToolServer = "%s"
 
+// Execution ID reported with tool calls so they can be recorded
+const executionID = "%s"
+
 // Helper function to call generic tools via internal server
 func callGenericTool(ctx context.Context, toolName string, input string) (string, error) {
 	requestBody := map[string]any{
-		"tool_name": toolName,
-		"input":     input,
+		"tool_name":    toolName,
+		"input":        input,
+		"execution_id": executionID,
 	}
 
 	jsonData, err := json.Marshal(requestBody)
@@ -652,13 +714,13 @@ func callGenericTool(ctx context.Context, toolName string, input string) (string
 		return "", fmt.Errorf("failed to marshal request: %%w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", internalToolServer+"/call", bytes.NewBuffer(jsonData))
+	req, err := ptchttp.NewRequestWithContext(ctx, "POST", internalToolServer+"/call", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %%w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
+	client := &ptchttp.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to call tool: %%w", err)
@@ -690,11 +752,11 @@ func callGenericTool(ctx context.Context, toolName string, input string) (string
 
 // Helper function to run shell commands
 func runShell(ctx context.Context, code string, args []string) (string, error) {
-	tmpfile, err := os.CreateTemp("", "shell-*.sh")
+	tmpfile, err := ptcos.CreateTemp("", "shell-*.sh")
 	if err != nil {
 		return "", err
 	}
-	defer os.Remove(tmpfile.Name())
+	defer ptcos.Remove(tmpfile.Name())
 
 	if _, err := tmpfile.Write([]byte(code)); err != nil {
 		return "", err
@@ -703,18 +765,18 @@ func runShell(ctx context.Context, code string, args []string) (string, error) {
 		return "", err
 	}
 
-	cmd := exec.CommandContext(ctx, "bash", append([]string{tmpfile.Name()}, args...)...)
+	cmd := ptcexec.CommandContext(ctx, "bash", append([]string{tmpfile.Name()}, args...)...)
 	output, err := cmd.CombinedOutput()
 	return string(output), err
 }
 
 // Helper function to run Python scripts
 func runPython(ctx context.Context, code string, args []string) (string, error) {
-	tmpfile, err := os.CreateTemp("", "python-*.py")
+	tmpfile, err := ptcos.CreateTemp("", "python-*.py")
 	if err != nil {
 		return "", err
 	}
-	defer os.Remove(tmpfile.Name())
+	defer ptcos.Remove(tmpfile.Name())
 
 	if _, err := tmpfile.Write([]byte(code)); err != nil {
 		return "", err
@@ -724,18 +786,18 @@ func runPython(ctx context.Context, code string, args []string) (string, error)
 	}
 
 	pythonCmd := "python3"
-	if _, err := exec.LookPath("python3"); err != nil {
+	if _, err := ptcexec.LookPath("python3"); err != nil {
 		pythonCmd = "python"
 	}
 
-	cmd := exec.CommandContext(ctx, pythonCmd, append([]string{tmpfile.Name()}, args...)...)
+	cmd := ptcexec.CommandContext(ctx, pythonCmd, append([]string{tmpfile.Name()}, args...)...)
 	output, err := cmd.CombinedOutput()
 	return string(output), err
 }
 
 // Helper function to read files
 func readFile(filePath string) (string, error) {
-	data, err := os.ReadFile(filePath)
+	data, err := ptcos.ReadFile(filePath)
 	if err != nil {
 		return "", err
 	}
@@ -744,13 +806,13 @@ func readFile(filePath string) (string, error) {
 
 // Helper function to write files
 func writeFile(filePath string, content string) (string, error) {
-	err := os.WriteFile(filePath, []byte(content), 0600)
+	err := ptcos.WriteFile(filePath, []byte(content), 0600)
 	if err != nil {
 		return "", err
 	}
 	return fmt.Sprintf("Successfully wrote to %%s", filePath), nil
 }
-`, serverURL)
+`, serverURL, executionID)
 	wrappers = append(wrappers, wrapper)
 
 	// Generate embedded tool functions based on tool name patterns
@@ -206,6 +206,183 @@ print(f"Results: {a}, {b}, {c}")
 	}
 }
 
+// TestToolCallRecording tests that tool calls made by the code are recorded in order
+func TestToolCallRecording(t *testing.T) {
+	for _, mode := range []ptc.ExecutionMode{ptc.ModeDirect, ptc.ModeServer} {
+		t.Run(string(mode), func(t *testing.T) {
+			tools := []tools.Tool{
+				MockTool{name: "get_budget", description: "Gets a budget", response: "1000"},
+				MockTool{name: "get_expenses", description: "Gets expenses", response: "250"},
+			}
+
+			executor := ptc.NewCodeExecutorWithMode(ptc.LanguagePython, tools, mode)
+			ctx := context.Background()
+			if err := executor.Start(ctx); err != nil {
+				t.Fatalf("Failed to start executor: %v", err)
+			}
+			defer executor.Stop(ctx)
+
+			code := `
+budget = get_budget("alice")
+expenses = get_expenses("alice")
+missing = call_tool("unknown", "x") if "call_tool" in globals() else _call_generic_tool("unknown", "x")
+print(budget, expenses)
+`
+			result, err := executor.Execute(ctx, code)
+			if err != nil {
+				t.Fatalf("Failed to execute code: %v", err)
+			}
+
+			if len(result.ToolCalls) != 3 {
+				t.Fatalf("Expected 3 tool calls, got %d: %+v", len(result.ToolCalls), result.ToolCalls)
+			}
+			if call := result.ToolCalls[0]; call.Tool != "get_budget" || call.Input != "alice" || call.Result != "1000" {
+				t.Errorf("Unexpected first tool call: %+v", call)
+			}
+			if call := result.ToolCalls[1]; call.Tool != "get_expenses" || call.Result != "250" {
+				t.Errorf("Unexpected second tool call: %+v", call)
+			}
+			if call := result.ToolCalls[2]; call.Tool != "unknown" || call.Error == "" {
+				t.Errorf("Expected failed call to be recorded with an error: %+v", call)
+			}
+
+			// Calls are recorded per execution
+			result, err = executor.Execute(ctx, `print("no tools")`)
+			if err != nil {
+				t.Fatalf("Failed to execute code: %v", err)
+			}
+			if len(result.ToolCalls) != 0 {
+				t.Errorf("Expected no tool calls, got %+v", result.ToolCalls)
+			}
+		})
+	}
+}
+
+// TestAllowedImportsAndGlobals tests that the execution scope can be extended
+func TestAllowedImportsAndGlobals(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Python", func(t *testing.T) {
+		executor := ptc.NewCodeExecutor(ptc.LanguagePython, []tools.Tool{})
+		executor.AllowedImports = ptc.DefaultAllowedImports(ptc.LanguagePython)
+		executor.Globals = map[string]any{
+			"BUDGET":      1000,
+			"CATEGORIES":  []string{"travel", "meals"},
+			"over_budget": ptc.Code("def over_budget(amount):\n    return amount > BUDGET"),
+		}
+		if err := executor.Start(ctx); err != nil {
+			t.Fatalf("Failed to start executor: %v", err)
+		}
+		defer executor.Stop(ctx)
+
+		result, err := executor.Execute(ctx, `
+print(statistics.mean([BUDGET, 500]), CATEGORIES[1], over_budget(1200))
+`)
+		if err != nil {
+			t.Fatalf("Failed to execute code: %v", err)
+		}
+		if !strings.Contains(result.Output, "750 meals True") {
+			t.Errorf("Expected output to contain '750 meals True', got: %s", result.Output)
+		}
+
+		result, err = executor.Execute(ctx, "import subprocess\nprint('ran')")
+		if err != nil {
+			t.Fatalf("Failed to execute code: %v", err)
+		}
+		if result.Error == nil || strings.Contains(result.Output, "ran") {
+			t.Errorf("Expected disallowed import to be rejected, got: %s", result.Output)
+		}
+	})
+
+	t.Run("Go", func(t *testing.T) {
+		executor := ptc.NewCodeExecutor(ptc.LanguageGo, []tools.Tool{})
+		executor.AllowedImports = ptc.DefaultAllowedImports(ptc.LanguageGo)
+		executor.Globals = map[string]any{"BUDGET": 1000}
+		if err := executor.Start(ctx); err != nil {
+			t.Fatalf("Failed to start executor: %v", err)
+		}
+		defer executor.Stop(ctx)
+
+		result, err := executor.Execute(ctx, "_ = ctx\nfmt.Println(strconv.Itoa(BUDGET), math.Sqrt(16))")
+		if err != nil {
+			t.Fatalf("Failed to execute Go code: %v", err)
+		}
+		if !strings.Contains(result.Output, "1000 4") {
+			t.Errorf("Expected output to contain '1000 4', got: %s", result.Output)
+		}
+	})
+
+	t.Run("Python bypasses", func(t *testing.T) {
+		executor := ptc.NewCodeExecutor(ptc.LanguagePython, []tools.Tool{})
+		executor.AllowedImports = ptc.DefaultAllowedImports(ptc.LanguagePython)
+		if err := executor.Start(ctx); err != nil {
+			t.Fatalf("Failed to start executor: %v", err)
+		}
+		defer executor.Stop(ctx)
+
+		bypasses := []string{
+			"import math; import os\nos.system('echo ran')",
+			"if True: import subprocess\nprint('ran')",
+			"__import__('os').system('echo ran')",
+			"sys.modules['os'].system('echo ran')",
+			"os.system('echo ran')",
+			"exec(\"print('ran')\")",
+			"import importlib\nprint('ran')",
+			"print(().__class__.__bases__[0].__subclasses__(), 'ran')",
+		}
+		for _, code := range bypasses {
+			result, err := executor.Execute(ctx, code)
+			if err != nil {
+				t.Fatalf("Failed to execute code: %v", err)
+			}
+			if result.Error == nil || strings.Contains(result.Output, "ran") {
+				t.Errorf("Expected %q to be rejected, got: %s", code, result.Output)
+			}
+		}
+
+		result, err := executor.Execute(ctx, "import math; from collections import Counter\nprint(math.sqrt(16), Counter('aa')['a'])")
+		if err != nil {
+			t.Fatalf("Failed to execute code: %v", err)
+		}
+		if result.Error != nil || !strings.Contains(result.Output, "4.0 2") {
+			t.Errorf("Expected allowed imports to run, got: %s", result.Output)
+		}
+	})
+
+	t.Run("Go bypasses", func(t *testing.T) {
+		executor := ptc.NewCodeExecutor(ptc.LanguageGo, []tools.Tool{})
+		executor.AllowedImports = ptc.DefaultAllowedImports(ptc.LanguageGo)
+		if err := executor.Start(ctx); err != nil {
+			t.Fatalf("Failed to start executor: %v", err)
+		}
+		defer executor.Stop(ctx)
+
+		bypasses := []string{
+			`out, _ := exec.Command("echo", "ran").Output(); fmt.Println(string(out))`,
+			`out, _ := ptcexec.Command("echo", "ran").Output(); fmt.Println(string(out))`,
+			`out, _ := runShell(ctx, "echo ran", nil); fmt.Println(out)`,
+			`fmt.Println(os.Getenv("HOME"), "ran")`,
+		}
+		for _, code := range bypasses {
+			result, err := executor.Execute(ctx, code)
+			if err != nil {
+				t.Fatalf("Failed to execute Go code: %v", err)
+			}
+			if result.Error == nil || strings.Contains(result.Output, "ran\n") {
+				t.Errorf("Expected %q to be rejected, got: %s", code, result.Output)
+			}
+		}
+	})
+
+	t.Run("Denied import", func(t *testing.T) {
+		executor := ptc.NewCodeExecutor(ptc.LanguagePython, []tools.Tool{})
+		executor.AllowedImports = []string{"subprocess"}
+		if _, err := executor.Execute(ctx, "print('hi')"); err == nil {
+			t.Error("Expected error for a denied import")
+		}
+	})
+}
+
 // TestErrorHandling tests error handling in tool execution
 func TestErrorHandling(t *testing.T) {
 	tools := []tools.Tool{
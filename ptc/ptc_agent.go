@@ -31,8 +31,31 @@ type PTCAgentConfig struct {
 
 	// MaxIterations is the maximum number of iterations (default: 10)
 	MaxIterations int
+
+	// AllowedImports lists the standard-library modules generated code may import (default:
+	// DefaultAllowedImports for the language). Modules giving access to the system, processes
+	// or the network cannot be allowed, and code using them is rejected before it runs (see
+	// CodeExecutor.AllowedImports). This is not a sandbox: run untrusted code in isolation.
+	AllowedImports []string
+
+	// Globals are constants and helpers injected into the execution scope of generated code.
+	// Values are passed as JSON; Code values are inserted as source, e.g. helper functions.
+	Globals map[string]any
+
+	// CodeExtractor extracts the code to execute from the model's responses (default:
+	// ExtractCode). A response without code is taken as the final answer, unless it contains
+	// a code fence: then the model is asked to reply with only code, up to twice.
+	CodeExtractor CodeExtractor
 }
 
+// maxCodeExtractionRetries is how often the model is re-prompted for a response whose code
+// could not be extracted
+const maxCodeExtractionRetries = 2
+
+// codeOnlyPrompt asks the model to repeat its last response as code only
+const codeOnlyPrompt = "No executable code could be found in your last response. " +
+	"Reply with only the code, in a single fenced code block, and no explanation."
+
 // CreatePTCAgent creates a new agent that uses programmatic tool calling
 // This agent generates code to call tools programmatically rather than
 // using traditional tool calling with round-trips
@@ -57,8 +80,25 @@ func CreatePTCAgent(config PTCAgentConfig) (*graph.Runnable, error) {
 		config.MaxIterations = 20
 	}
 
+	if config.CodeExtractor == nil {
+		config.CodeExtractor = ExtractCode
+	}
+
+	if config.AllowedImports == nil {
+		config.AllowedImports = DefaultAllowedImports(config.Language)
+	}
+	if err := ValidateImports(config.Language, config.AllowedImports); err != nil {
+		return nil, fmt.Errorf("invalid allowed imports: %w", err)
+	}
+	if err := ValidateGlobals(config.Globals); err != nil {
+		return nil, fmt.Errorf("invalid globals: %w", err)
+	}
+
 	// Create PTC tool node with execution mode
 	ptcNode := NewPTCToolNodeWithMode(config.Language, config.Tools, config.ExecutionMode)
+	ptcNode.CodeExtractor = config.CodeExtractor
+	ptcNode.Executor.AllowedImports = config.AllowedImports
+	ptcNode.Executor.Globals = config.Globals
 
 	// Start the tool server
 	if err := ptcNode.Executor.Start(context.Background()); err != nil {
@@ -73,12 +113,12 @@ func CreatePTCAgent(config PTCAgentConfig) (*graph.Runnable, error) {
 
 	// Define the state schema
 	schema := graph.NewMapSchema()
-	schema.RegisterReducer("messages", graph.AddMessages)
+	schema.RegisterReducer("messages", graph.MessageReducer)
 	workflow.SetSchema(schema)
 
 	// Add agent node
 	workflow.AddNode("agent", "LLM agent that generates code for tool calling", func(ctx context.Context, state map[string]any) (map[string]any, error) {
-		return agentNode(ctx, state, config.Model, systemPrompt, config.MaxIterations)
+		return agentNode(ctx, state, config.Model, systemPrompt, config.MaxIterations, config.CodeExtractor)
 	})
 
 	// Add PTC execution node
@@ -107,8 +147,10 @@ func CreatePTCAgent(config PTCAgentConfig) (*graph.Runnable, error) {
 		lastMsg := messages[len(messages)-1]
 
 		// Check if the message contains code to execute
-		if lastMsg.Role == llms.ChatMessageTypeAI && ContainsCode(lastMsg) {
-			return "execute_code"
+		if lastMsg.Role == llms.ChatMessageTypeAI {
+			if _, ok := config.CodeExtractor(messageText(lastMsg)); ok {
+				return "execute_code"
+			}
 		}
 
 		// Otherwise, we're done
@@ -128,7 +170,7 @@ func CreatePTCAgent(config PTCAgentConfig) (*graph.Runnable, error) {
 }
 
 // agentNode is the main agent logic node
-func agentNode(ctx context.Context, state map[string]any, model llms.Model, systemPrompt string, maxIterations int) (map[string]any, error) {
+func agentNode(ctx context.Context, state map[string]any, model llms.Model, systemPrompt string, maxIterations int, extract CodeExtractor) (map[string]any, error) {
 	messages := state["messages"].([]llms.MessageContent)
 
 	// Check iteration count
@@ -164,29 +206,41 @@ func agentNode(ctx context.Context, state map[string]any, model llms.Model, syst
 		}, messages...)
 	}
 
-	// Call the model
-	resp, err := model.GenerateContent(ctx, messages)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate content: %w", err)
-	}
+	var aiMsg llms.MessageContent
+	for retry := 0; ; retry++ {
+		// Call the model
+		resp, err := model.GenerateContent(ctx, messages)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate content: %w", err)
+		}
 
-	// Extract response
-	var responseContent []llms.ContentPart
-	for _, choice := range resp.Choices {
-		if choice.Content != "" {
-			responseContent = append(responseContent, llms.TextPart(choice.Content))
+		// Extract response
+		var responseContent []llms.ContentPart
+		for _, choice := range resp.Choices {
+			if choice.Content != "" {
+				responseContent = append(responseContent, llms.TextPart(choice.Content))
+			}
+		}
+
+		if len(responseContent) == 0 {
+			return nil, fmt.Errorf("empty response from model")
 		}
-	}
 
-	if len(responseContent) == 0 {
-		return nil, fmt.Errorf("empty response from model")
+		aiMsg = llms.MessageContent{
+			Role:  llms.ChatMessageTypeAI,
+			Parts: responseContent,
+		}
+
+		// A response with a code fence whose code cannot be extracted is a failed attempt
+		// at code rather than a final answer, so ask for the code alone
+		text := messageText(aiMsg)
+		if _, ok := extract(text); ok || !strings.Contains(text, "```") || retry == maxCodeExtractionRetries {
+			break
+		}
+		messages = append(messages, aiMsg, llms.TextParts(llms.ChatMessageTypeHuman, codeOnlyPrompt))
 	}
 
 	// Add AI response to messages
-	aiMsg := llms.MessageContent{
-		Role:  llms.ChatMessageTypeAI,
-		Parts: responseContent,
-	}
 	state["messages"] = append(state["messages"].([]llms.MessageContent), aiMsg)
 
 	return state, nil
@@ -223,6 +277,10 @@ Format your code in markdown code blocks:
 # Your code here
 `+"```", langName, langName, toolDefs, langName)
 
+	if scope := describeScope(language, executor); scope != "" {
+		basePrompt += "\n\n" + scope
+	}
+
 	if userPrompt != "" {
 		return userPrompt + "\n\n" + basePrompt
 	}
@@ -230,6 +288,22 @@ Format your code in markdown code blocks:
 	return basePrompt
 }
 
+// describeScope tells the model which imports and globals its code can use
+func describeScope(language ExecutionLanguage, executor *CodeExecutor) string {
+	var lines []string
+	if executor.AllowedImports != nil {
+		if language == LanguageGo {
+			lines = append(lines, "You may use these packages (they are imported for you): "+strings.Join(executor.AllowedImports, ", "))
+		} else {
+			lines = append(lines, "You may import only these modules: "+strings.Join(executor.AllowedImports, ", "))
+		}
+	}
+	if len(executor.Globals) > 0 {
+		lines = append(lines, "These globals are already defined: "+strings.Join(sortedGlobalNames(executor.Globals), ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // containsCode checks if a message contains code to execute
 // ContainsCode checks if a message contains code to execute
 func ContainsCode(msg llms.MessageContent) bool {
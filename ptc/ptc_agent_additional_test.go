@@ -153,7 +153,7 @@ func TestAgentNodeMaxIterations(t *testing.T) {
 		"iteration_count": maxIterations,
 	}
 
-	_, err := agentNode(context.Background(), initialState, mockLLM, "system prompt", maxIterations)
+	_, err := agentNode(context.Background(), initialState, mockLLM, "system prompt", maxIterations, ExtractCode)
 	require.NoError(t, err)
 
 	finalState := initialState
@@ -196,3 +196,117 @@ func TestCreatePTCAgent(t *testing.T) {
 	assert.Error(t, err)
 	config.Tools = []tools.Tool{tool}
 }
+
+func TestExtractCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		resp     string
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "Python fence with explanation",
+			resp:     "Let me check the budgets.\n```python\nprint(get_budget('alice'))\n```\nThis prints the budget.",
+			expected: "print(get_budget('alice'))",
+			ok:       true,
+		},
+		{
+			name:     "Multiple blocks are concatenated",
+			resp:     "First:\n```python\na = 1\n```\nThen:\n```py\nprint(a)\n```",
+			expected: "a = 1\n\nprint(a)",
+			ok:       true,
+		},
+		{
+			name:     "Non-code blocks are skipped",
+			resp:     "Input:\n```json\n{\"a\": 1}\n```\n```go\nfmt.Println(1)\n```",
+			expected: "fmt.Println(1)",
+			ok:       true,
+		},
+		{
+			name:     "Unterminated fence",
+			resp:     "```python\nprint(1)",
+			expected: "print(1)",
+			ok:       true,
+		},
+		{
+			name:     "JSON code field",
+			resp:     `{"code": "print(2)"}`,
+			expected: "print(2)",
+			ok:       true,
+		},
+		{
+			name:     "Bare code",
+			resp:     "import json\nprint(json.dumps({}))\n",
+			expected: "import json\nprint(json.dumps({}))",
+			ok:       true,
+		},
+		{
+			name: "Final answer",
+			resp: "The team is 1,250 USD over budget.",
+		},
+		{
+			name: "Only a shell block",
+			resp: "```bash\nls\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, ok := ExtractCode(tt.resp)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, code)
+		})
+	}
+}
+
+// sequenceLLM replies with the given responses in order and records the prompts
+type sequenceLLM struct {
+	responses []string
+	calls     [][]llms.MessageContent
+}
+
+func (m *sequenceLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	m.calls = append(m.calls, messages)
+	response := m.responses[0]
+	m.responses = m.responses[1:]
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: response}}}, nil
+}
+
+func (m *sequenceLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return "", nil
+}
+
+func TestAgentNodeRepromptsForCode(t *testing.T) {
+	t.Run("Re-prompts when code cannot be extracted", func(t *testing.T) {
+		llm := &sequenceLLM{responses: []string{"```bash\nls\n```", "```python\nprint(1)\n```"}}
+		state := map[string]any{"messages": []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "q")}}
+
+		result, err := agentNode(context.Background(), state, llm, "system prompt", 5, ExtractCode)
+		require.NoError(t, err)
+		require.Len(t, llm.calls, 2)
+		lastPrompt := llm.calls[1][len(llm.calls[1])-1]
+		assert.Equal(t, codeOnlyPrompt, messageText(lastPrompt))
+
+		messages := result["messages"].([]llms.MessageContent)
+		assert.Len(t, messages, 2)
+		assert.Equal(t, "```python\nprint(1)\n```", messageText(messages[1]))
+	})
+
+	t.Run("Gives up after the retries", func(t *testing.T) {
+		llm := &sequenceLLM{responses: []string{"```bash\nls\n```", "```bash\nls\n```", "```bash\nls\n```"}}
+		state := map[string]any{"messages": []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "q")}}
+
+		_, err := agentNode(context.Background(), state, llm, "system prompt", 5, ExtractCode)
+		require.NoError(t, err)
+		assert.Len(t, llm.calls, maxCodeExtractionRetries+1)
+	})
+
+	t.Run("Final answers are not re-prompted", func(t *testing.T) {
+		llm := &sequenceLLM{responses: []string{"The answer is 42."}}
+		state := map[string]any{"messages": []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "q")}}
+
+		_, err := agentNode(context.Background(), state, llm, "system prompt", 5, ExtractCode)
+		require.NoError(t, err)
+		assert.Len(t, llm.calls, 1)
+	})
+}
@@ -80,6 +80,15 @@ func TestPTCToolNode(t *testing.T) {
 	if lastMsg.Role != llms.ChatMessageTypeHuman {
 		t.Errorf("Expected last message to be Human, got %s", lastMsg.Role)
 	}
+
+	// Check that the tool call was recorded
+	toolCalls, ok := newState.(map[string]any)[ptc.ToolCallsKey].([]ptc.ToolCall)
+	if !ok || len(toolCalls) != 1 {
+		t.Fatalf("Expected 1 recorded tool call, got %v", newState.(map[string]any)[ptc.ToolCallsKey])
+	}
+	if toolCalls[0].Tool != "calculator" || toolCalls[0].Input != "2+2" || toolCalls[0].Result != "42" {
+		t.Errorf("Unexpected tool call: %+v", toolCalls[0])
+	}
 }
 
 // TestPTCToolNodeWithGoCode tests PTCToolNode with Go code
@@ -229,6 +238,26 @@ func TestPTCAgentConfig(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error when tools are not provided")
 	}
+
+	// Test with a dangerous import
+	_, err = ptc.CreatePTCAgent(ptc.PTCAgentConfig{
+		Model:          &MockLLM{response: "test"},
+		Tools:          tools,
+		AllowedImports: []string{"statistics", "os.path"},
+	})
+	if err == nil {
+		t.Error("Expected error when a dangerous import is allowed")
+	}
+
+	// Test with an invalid global name
+	_, err = ptc.CreatePTCAgent(ptc.PTCAgentConfig{
+		Model:   &MockLLM{response: "test"},
+		Tools:   tools,
+		Globals: map[string]any{"not-a-name": 1},
+	})
+	if err == nil {
+		t.Error("Expected error when a global name is not an identifier")
+	}
 }
 
 // TestPTCAgentDefaultConfig tests default configuration
@@ -4,15 +4,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/tools"
 )
 
+// ToolCallsKey is the state key under which PTCToolNode accumulates the []ToolCall made by
+// the executed code, in execution order across all executions
+const ToolCallsKey = "tool_calls"
+
 // PTCToolNode is a graph node that handles programmatic tool calling
 // It receives code from the LLM and executes it with tool access
 type PTCToolNode struct {
 	Executor *CodeExecutor
+
+	// CodeExtractor extracts the code from the AI message (default: ExtractCode)
+	CodeExtractor CodeExtractor
 }
 
 // NewPTCToolNode creates a new PTC tool node with default execution mode (direct)
@@ -56,15 +64,23 @@ func (node *PTCToolNode) Invoke(ctx context.Context, state any) (any, error) {
 	}
 
 	// Extract code from the message
-	code, err := extractCodeFromMessage(lastMsg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract code: %w", err)
+	extract := node.CodeExtractor
+	if extract == nil {
+		extract = ExtractCode
+	}
+	code, ok := extract(messageText(lastMsg))
+	if !ok {
+		return nil, fmt.Errorf("failed to extract code: no code found in message")
 	}
 
 	// Note: Tool server is already started in CreatePTCAgent, no need to start again
 
 	// Execute the code
 	result, err := node.Executor.Execute(ctx, code)
+	if result != nil {
+		toolCalls, _ := mState[ToolCallsKey].([]ToolCall)
+		mState[ToolCallsKey] = append(toolCalls, result.ToolCalls...)
+	}
 	if err != nil {
 		// Create error message as system message
 		errorMsg := llms.MessageContent{
@@ -89,68 +105,94 @@ func (node *PTCToolNode) Invoke(ctx context.Context, state any) (any, error) {
 	return mState, nil
 }
 
-// extractCodeFromMessage extracts code from an AI message
-// Supports multiple formats:
-// 1. Code in markdown code blocks (```language\ncode\n```)
-// 2. Plain text code
-// 3. JSON with "code" field
-func extractCodeFromMessage(msg llms.MessageContent) (string, error) {
-	for _, part := range msg.Parts {
-		switch p := part.(type) {
-		case llms.TextContent:
-			code := p.Text
-
-			// Try to extract code from markdown code blocks
-			if extracted := extractFromCodeBlock(code); extracted != "" {
-				return extracted, nil
+// CodeExtractor extracts the code to execute from a model response, reporting false when
+// the response contains no code
+type CodeExtractor func(resp string) (code string, ok bool)
+
+// ExtractCode is the default CodeExtractor. It concatenates the fenced code blocks of the
+// response that are untagged or tagged as Python or Go, in order; an unterminated last block
+// runs to the end of the response. Without such blocks it accepts a JSON object with a "code"
+// field, or a response that is bare code (starting with e.g. import, def or func).
+func ExtractCode(resp string) (string, bool) {
+	var blocks []string
+	var block []string
+	inBlock, keep := false, false
+	for _, line := range strings.Split(resp, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "```") {
+			if inBlock && keep {
+				block = append(block, line)
 			}
-
-			// Try to parse as JSON
-			var jsonData map[string]any
-			if err := json.Unmarshal([]byte(code), &jsonData); err == nil {
-				if codeField, ok := jsonData["code"].(string); ok {
-					return codeField, nil
-				}
+			continue
+		}
+		if inBlock {
+			if keep {
+				blocks = append(blocks, strings.Join(block, "\n"))
 			}
+			inBlock = false
+			continue
+		}
+		lang := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(trimmed, "```")))
+		inBlock, keep, block = true, isCodeLanguage(lang), nil
+	}
+	if inBlock && keep && len(block) > 0 {
+		blocks = append(blocks, strings.Join(block, "\n"))
+	}
+	if code := strings.TrimSpace(strings.Join(blocks, "\n\n")); code != "" {
+		return code, true
+	}
 
-			// Return as is
-			return code, nil
+	var jsonData map[string]any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(resp)), &jsonData); err == nil {
+		if code, ok := jsonData["code"].(string); ok && strings.TrimSpace(code) != "" {
+			return code, true
 		}
 	}
 
-	return "", fmt.Errorf("no code found in message")
+	if !strings.Contains(resp, "```") && looksLikeCode(resp) {
+		return strings.TrimSpace(resp), true
+	}
+	return "", false
+}
+
+// isCodeLanguage reports whether a code fence tag marks code PTC can execute
+func isCodeLanguage(lang string) bool {
+	switch lang {
+	case "", "python", "python3", "py", "go", "golang":
+		return true
+	}
+	return false
 }
 
-// extractFromCodeBlock extracts code from markdown code blocks
-func extractFromCodeBlock(text string) string {
-	// Look for code blocks: ```language\ncode\n```
-	start := -1
-	end := -1
-
-	// Find first ```
-	for i := 0; i < len(text)-2; i++ {
-		if text[i:i+3] == "```" {
-			if start == -1 {
-				// Find the end of the first line (language specifier)
-				lineEnd := i + 3
-				for lineEnd < len(text) && text[lineEnd] != '\n' {
-					lineEnd++
-				}
-				if lineEnd < len(text) {
-					start = lineEnd + 1
-				}
-			} else {
-				end = i
-				break
+// bareCodePrefixes are the line starts that identify a response without fences as code
+var bareCodePrefixes = []string{"import ", "from ", "def ", "class ", "print(", "package ", "func "}
+
+// looksLikeCode reports whether the first non-blank line of text starts like code
+func looksLikeCode(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		for _, prefix := range bareCodePrefixes {
+			if strings.HasPrefix(line, prefix) {
+				return true
 			}
 		}
+		return false
 	}
+	return false
+}
 
-	if start != -1 && end != -1 && end > start {
-		return text[start:end]
+// messageText returns the text parts of a message joined by newlines
+func messageText(msg llms.MessageContent) string {
+	var texts []string
+	for _, part := range msg.Parts {
+		if textPart, ok := part.(llms.TextContent); ok {
+			texts = append(texts, textPart.Text)
+		}
 	}
-
-	return ""
+	return strings.Join(texts, "\n")
 }
 
 // Close stops the tool server
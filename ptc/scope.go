@@ -0,0 +1,273 @@
+package ptc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os/exec"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Code is a Globals value that is inserted verbatim into the execution scope, e.g. the
+// source of a helper function
+type Code string
+
+// DefaultPythonImports are the standard-library modules generated Python code may import
+// when PTCAgentConfig.AllowedImports is not set
+var DefaultPythonImports = []string{
+	"json", "math", "re", "statistics", "datetime", "decimal", "collections", "itertools", "functools",
+}
+
+// DefaultGoImports are the standard-library packages generated Go code may use when
+// PTCAgentConfig.AllowedImports is not set
+var DefaultGoImports = []string{
+	"encoding/json", "fmt", "math", "sort", "strconv", "strings", "time",
+}
+
+// deniedPythonImports are modules that give access to the system, processes or the network
+var deniedPythonImports = []string{
+	"os", "sys", "subprocess", "shutil", "socket", "ctypes", "importlib", "builtins", "pickle",
+	"marshal", "multiprocessing", "threading", "signal", "pty", "pathlib", "tempfile", "urllib",
+	"http", "requests", "code", "codeop", "runpy",
+}
+
+// deniedGoImports are packages that give access to the system, processes or the network
+var deniedGoImports = []string{
+	"os", "os/exec", "os/signal", "syscall", "unsafe", "plugin", "net", "net/http", "runtime/debug",
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// DefaultAllowedImports returns the default allowed imports for language
+func DefaultAllowedImports(language ExecutionLanguage) []string {
+	if language == LanguageGo {
+		return slices.Clone(DefaultGoImports)
+	}
+	return slices.Clone(DefaultPythonImports)
+}
+
+// ValidateImports returns an error if imports contains a module that must not be allowed
+// for language, such as os or subprocess
+func ValidateImports(language ExecutionLanguage, imports []string) error {
+	denied := deniedPythonImports
+	if language == LanguageGo {
+		denied = deniedGoImports
+	}
+	for _, imp := range imports {
+		name := imp
+		if language != LanguageGo {
+			// Submodules are denied with their top-level module
+			name, _, _ = strings.Cut(imp, ".")
+		}
+		if imp == "" || slices.Contains(denied, name) {
+			return fmt.Errorf("import %q is not allowed", imp)
+		}
+	}
+	return nil
+}
+
+// ValidateGlobals returns an error if a Globals name is not a valid identifier or a value
+// cannot be encoded as JSON
+func ValidateGlobals(globals map[string]any) error {
+	for name, value := range globals {
+		if !identifierPattern.MatchString(name) {
+			return fmt.Errorf("global %q is not a valid identifier", name)
+		}
+		if _, ok := value.(Code); ok {
+			continue
+		}
+		if _, err := json.Marshal(value); err != nil {
+			return fmt.Errorf("global %q cannot be encoded: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// sortedGlobalNames returns the names of globals in a stable order
+func sortedGlobalNames(globals map[string]any) []string {
+	names := make([]string, 0, len(globals))
+	for name := range globals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pythonScope returns the Python source importing the allowed modules and defining the globals
+func pythonScope(imports []string, globals map[string]any) string {
+	var lines []string
+	for _, imp := range imports {
+		lines = append(lines, "import "+imp)
+	}
+	for _, name := range sortedGlobalNames(globals) {
+		if code, ok := globals[name].(Code); ok {
+			lines = append(lines, string(code))
+			continue
+		}
+		data, _ := json.Marshal(globals[name])
+		// A JSON string literal is also a valid Python string literal
+		literal, _ := json.Marshal(string(data))
+		lines = append(lines, fmt.Sprintf("%s = json.loads(%s)", name, literal))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pythonTemplateNames are the names the Python execution template binds that generated code
+// must not use when imports are restricted: modules giving access to the system, processes or
+// the network, and the helpers running shell commands and Python scripts
+var pythonTemplateNames = []string{"os", "sys", "subprocess", "tempfile", "urllib", "_run_shell", "_run_python"}
+
+// deniedPythonBuiltins are builtins that import modules, run code or reach objects by name,
+// which would bypass the import check
+var deniedPythonBuiltins = []string{
+	"__import__", "exec", "eval", "compile", "getattr", "setattr", "delattr", "globals", "locals",
+	"vars", "breakpoint", "__builtins__", "importlib",
+}
+
+// pythonCheckScript parses the code read from stdin with ast and prints why it is rejected:
+// an import of a module that is not allowed (argv[1]), a use of a denied name (argv[2]) or
+// access to a dunder attribute such as __globals__ or __subclasses__
+const pythonCheckScript = `
+import ast, json, sys
+
+allowed = json.loads(sys.argv[1])
+denied = set(json.loads(sys.argv[2]))
+safe_dunders = {"__name__", "__init__", "__doc__"}
+
+def reject(message):
+    print(message)
+    sys.exit(1)
+
+try:
+    tree = ast.parse(sys.stdin.read())
+except SyntaxError as e:
+    reject("syntax error: " + str(e))
+
+for node in ast.walk(tree):
+    modules = []
+    if isinstance(node, ast.Import):
+        modules = [alias.name for alias in node.names]
+    elif isinstance(node, ast.ImportFrom):
+        modules = ["." * node.level + (node.module or "")]
+    for module in modules:
+        if module not in allowed and module.split(".")[0] not in allowed:
+            reject("import of module " + json.dumps(module) + " is not allowed")
+    if isinstance(node, ast.Name) and node.id in denied:
+        reject("use of " + json.dumps(node.id) + " is not allowed")
+    if isinstance(node, ast.Attribute) and node.attr.startswith("__") and node.attr.endswith("__") and node.attr not in safe_dunders:
+        reject("access to attribute " + json.dumps(node.attr) + " is not allowed")
+`
+
+// checkPythonCode returns an error describing why code may not run with the allowed imports:
+// it imports a module that is not allowed, imports dynamically, runs code with exec or eval,
+// uses a system module of the execution template or accesses a dunder attribute.
+// The code is parsed with python3's ast module.
+func checkPythonCode(ctx context.Context, code string, allowed []string) error {
+	allowedJSON, _ := json.Marshal(allowed)
+	deniedJSON, _ := json.Marshal(slices.Concat(pythonTemplateNames, deniedPythonBuiltins))
+
+	cmd := exec.CommandContext(ctx, "python3", "-c", pythonCheckScript, string(allowedJSON), string(deniedJSON))
+	cmd.Stdin = strings.NewReader(code)
+	output, err := cmd.Output()
+	if err == nil {
+		return nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(bytes.TrimSpace(output)) > 0 {
+		return errors.New(string(bytes.TrimSpace(output)))
+	}
+	return fmt.Errorf("failed to check code: %w", err)
+}
+
+// goTemplateImports are the packages the Go execution template always imports under their
+// own names
+var goTemplateImports = []string{
+	"bytes", "context", "encoding/json", "fmt", "io", "strings",
+}
+
+// goSystemImports are the packages available to Go code when imports are not restricted.
+// The template imports them under the names in goTemplateNames for the tool wrappers.
+var goSystemImports = []string{"net/http", "os", "os/exec"}
+
+// goTemplateNames are the names the Go execution template declares that generated code must
+// not use when imports are restricted: the aliases of the system packages, and the helpers
+// running shell commands and Python scripts and accessing files
+var goTemplateNames = []string{
+	"ptchttp", "ptcos", "ptcexec", "runShell", "runPython", "readFile", "writeFile",
+}
+
+// checkGoCode returns an error if code uses a name of the Go execution template that gives
+// access to the system, processes or the network. Code that does not parse is left to the
+// compiler.
+func checkGoCode(code string) error {
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package main\nfunc _() {\n"+code+"\n}", 0)
+	if err != nil {
+		return nil
+	}
+	var denied string
+	ast.Inspect(file, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && denied == "" && slices.Contains(goTemplateNames, ident.Name) {
+			denied = ident.Name
+		}
+		return denied == ""
+	})
+	if denied != "" {
+		return fmt.Errorf("use of %q is not allowed", denied)
+	}
+	return nil
+}
+
+// goExtraImports returns the allowed packages code refers to that the template does not import.
+// When allowed is nil, the system packages code refers to are imported.
+func goExtraImports(code string, allowed []string) []string {
+	if allowed == nil {
+		allowed = goSystemImports
+	}
+	var imports []string
+	for _, imp := range allowed {
+		if slices.Contains(goTemplateImports, imp) {
+			continue
+		}
+		name := imp[strings.LastIndex(imp, "/")+1:]
+		if regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\.`).MatchString(code) {
+			imports = append(imports, imp)
+		}
+	}
+	return imports
+}
+
+// goGlobals returns the Go declarations of the globals
+func goGlobals(globals map[string]any) string {
+	var decls []string
+	for _, name := range sortedGlobalNames(globals) {
+		switch v := globals[name].(type) {
+		case Code:
+			decls = append(decls, string(v))
+		case string, bool, int, int64, float64:
+			decls = append(decls, fmt.Sprintf("var %s = %#v", name, v))
+		default:
+			data, _ := json.Marshal(v)
+			decls = append(decls, fmt.Sprintf("var %s = ptcGlobal(%q)", name, data))
+		}
+	}
+	if len(decls) == 0 {
+		return ""
+	}
+	return `
+// ptcGlobal decodes the JSON value of an injected global
+func ptcGlobal(data string) any {
+	var v any
+	_ = json.Unmarshal([]byte(data), &v)
+	return v
+}
+
+` + strings.Join(decls, "\n")
+}
@@ -21,12 +21,30 @@ type ToolServer struct {
 	port    int
 	mu      sync.RWMutex
 	started bool
+
+	// recordings holds the tool calls of running executions by execution ID
+	recordings   map[string][]ToolCall
+	recordingsMu sync.Mutex
 }
 
 // ToolRequest represents a tool execution request
 type ToolRequest struct {
 	ToolName string `json:"tool_name"`
 	Input    any    `json:"input"`
+	// ExecutionID identifies the code execution making the call, see ExecutionResult.ToolCalls
+	ExecutionID string `json:"execution_id,omitempty"`
+}
+
+// ToolCall records a tool called by executed code
+type ToolCall struct {
+	// Tool is the name of the tool
+	Tool string `json:"tool"`
+	// Input is the input the code passed to the tool
+	Input any `json:"input"`
+	// Result is the tool output, empty if the call failed
+	Result string `json:"result,omitempty"`
+	// Error describes why the call failed
+	Error string `json:"error,omitempty"`
 }
 
 // ToolResponse represents a tool execution response
@@ -46,9 +64,35 @@ func NewToolServer(toolList []tools.Tool) *ToolServer {
 	}
 
 	return &ToolServer{
-		tools:   toolMap,
-		port:    0, // Will be assigned automatically
-		started: false,
+		tools:      toolMap,
+		port:       0, // Will be assigned automatically
+		started:    false,
+		recordings: make(map[string][]ToolCall),
+	}
+}
+
+// beginRecording starts recording the tool calls made with the given execution ID
+func (ts *ToolServer) beginRecording(executionID string) {
+	ts.recordingsMu.Lock()
+	defer ts.recordingsMu.Unlock()
+	ts.recordings[executionID] = []ToolCall{}
+}
+
+// endRecording stops recording the tool calls of executionID and returns them
+func (ts *ToolServer) endRecording(executionID string) []ToolCall {
+	ts.recordingsMu.Lock()
+	defer ts.recordingsMu.Unlock()
+	calls := ts.recordings[executionID]
+	delete(ts.recordings, executionID)
+	return calls
+}
+
+// record adds a tool call to the recording of its execution, if one is in progress
+func (ts *ToolServer) record(executionID string, call ToolCall) {
+	ts.recordingsMu.Lock()
+	defer ts.recordingsMu.Unlock()
+	if calls, ok := ts.recordings[executionID]; ok {
+		ts.recordings[executionID] = append(calls, call)
 	}
 }
 
@@ -183,7 +227,9 @@ func (ts *ToolServer) handleCallTool(w http.ResponseWriter, r *http.Request) {
 
 	if !exists {
 		log.Warn("Tool not found: %s", req.ToolName)
-		ts.sendErrorResponse(w, req.ToolName, req.Input, fmt.Sprintf("Tool not found: %s", req.ToolName))
+		errorMsg := fmt.Sprintf("Tool not found: %s", req.ToolName)
+		ts.record(req.ExecutionID, ToolCall{Tool: req.ToolName, Input: req.Input, Error: errorMsg})
+		ts.sendErrorResponse(w, req.ToolName, req.Input, errorMsg)
 		return
 	}
 
@@ -209,9 +255,12 @@ func (ts *ToolServer) handleCallTool(w http.ResponseWriter, r *http.Request) {
 	result, err := tool.Call(ctx, inputStr)
 	if err != nil {
 		log.Error("Tool %s execution failed: %v", req.ToolName, err)
-		ts.sendErrorResponse(w, req.ToolName, req.Input, fmt.Sprintf("Tool execution failed: %v", err))
+		errorMsg := fmt.Sprintf("Tool execution failed: %v", err)
+		ts.record(req.ExecutionID, ToolCall{Tool: req.ToolName, Input: req.Input, Error: errorMsg})
+		ts.sendErrorResponse(w, req.ToolName, req.Input, errorMsg)
 		return
 	}
+	ts.record(req.ExecutionID, ToolCall{Tool: req.ToolName, Input: req.Input, Result: result})
 
 	log.Info("Tool %s executed successfully, result length: %d bytes", req.ToolName, len(result))
 	ts.sendSuccessResponse(w, req.ToolName, req.Input, result)
@@ -95,7 +95,7 @@ func convertSchemaDocuments(schemaDocs []schema.Document) []Document {
 		if source, ok := schemaDoc.Metadata["source"]; ok {
 			docs[i].ID = fmt.Sprintf("%v", source)
 		} else {
-			docs[i].ID = fmt.Sprintf("doc_%d", i)
+			docs[i].ID = DocumentID(docs[i])
 		}
 	}
 	return docs
@@ -377,6 +377,11 @@ func (l *LangChainVectorStore) Update(ctx context.Context, docs []Document) erro
 	return l.Add(ctx, docs)
 }
 
+// Clear is not supported, as LangChain vector stores have no standard way to remove documents
+func (l *LangChainVectorStore) Clear(ctx context.Context) error {
+	return fmt.Errorf("clear is not supported by LangChain vector stores")
+}
+
 // GetStats returns vector store statistics
 func (l *LangChainVectorStore) GetStats(ctx context.Context) (*VectorStoreStats, error) {
 	// LangChain vector stores don't typically provide statistics
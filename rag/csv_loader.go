@@ -0,0 +1,150 @@
+package rag
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// CSVOptions configures how LoadCSV turns rows into documents
+type CSVOptions struct {
+	// HasHeader indicates that the first row contains column names.
+	// Without a header, columns are named "column_0", "column_1", ...
+	HasHeader bool
+
+	// Delimiter is the field delimiter (defaults to ',')
+	Delimiter rune
+
+	// ContentColumns are concatenated into Document.Content.
+	// If empty, all columns are used as content.
+	ContentColumns []string
+
+	// IDColumn sets Document.ID from the given column.
+	// If empty, IDs are generated from the file path and row number.
+	IDColumn string
+
+	// ContentSeparator joins content columns (defaults to "\n")
+	ContentSeparator string
+}
+
+// DefaultCSVOptions returns the default CSV options (comma-delimited with a header row)
+func DefaultCSVOptions() *CSVOptions {
+	return &CSVOptions{
+		HasHeader:        true,
+		Delimiter:        ',',
+		ContentSeparator: "\n",
+	}
+}
+
+// LoadCSV loads a CSV file producing one document per row.
+// Content columns are concatenated into the document content as "column: value"
+// lines and every other column is stored in the document metadata.
+func LoadCSV(path string, opts *CSVOptions) ([]Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	docs, err := ReadCSV(file, path, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load CSV %s: %w", path, err)
+	}
+	return docs, nil
+}
+
+// ReadCSV reads CSV data from r producing one document per row.
+// source is recorded in the "source" metadata and used for generated IDs.
+func ReadCSV(r io.Reader, source string, opts *CSVOptions) ([]Document, error) {
+	if opts == nil {
+		opts = DefaultCSVOptions()
+	}
+
+	reader := csv.NewReader(r)
+	if opts.Delimiter != 0 {
+		reader.Comma = opts.Delimiter
+	}
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return []Document{}, nil
+	}
+
+	var header []string
+	if opts.HasHeader {
+		header = records[0]
+		records = records[1:]
+	} else {
+		for i := range records[0] {
+			header = append(header, fmt.Sprintf("column_%d", i))
+		}
+	}
+
+	for _, col := range opts.ContentColumns {
+		if !slices.Contains(header, col) {
+			return nil, fmt.Errorf("content column %q not found", col)
+		}
+	}
+	if opts.IDColumn != "" && !slices.Contains(header, opts.IDColumn) {
+		return nil, fmt.Errorf("id column %q not found", opts.IDColumn)
+	}
+
+	contentColumns := opts.ContentColumns
+	if len(contentColumns) == 0 {
+		contentColumns = header
+	}
+
+	separator := opts.ContentSeparator
+	if separator == "" {
+		separator = "\n"
+	}
+
+	docs := make([]Document, 0, len(records))
+	for rowIdx, record := range records {
+		values := make(map[string]string, len(header))
+		for i, col := range header {
+			if i < len(record) {
+				values[col] = record[i]
+			}
+		}
+
+		parts := make([]string, 0, len(contentColumns))
+		for _, col := range contentColumns {
+			if len(contentColumns) == 1 {
+				parts = append(parts, values[col])
+			} else {
+				parts = append(parts, fmt.Sprintf("%s: %s", col, values[col]))
+			}
+		}
+
+		metadata := map[string]any{
+			"source": source,
+			"row":    rowIdx,
+		}
+		for _, col := range header {
+			if !slices.Contains(contentColumns, col) {
+				metadata[col] = values[col]
+			}
+		}
+
+		id := fmt.Sprintf("csv_%s_%d", source, rowIdx)
+		if opts.IDColumn != "" && values[opts.IDColumn] != "" {
+			id = values[opts.IDColumn]
+		}
+
+		docs = append(docs, Document{
+			ID:       id,
+			Content:  strings.Join(parts, separator),
+			Metadata: metadata,
+		})
+	}
+
+	return docs, nil
+}
@@ -0,0 +1,55 @@
+package rag
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCSV(t *testing.T) {
+	data := "sku,question,answer,category\n" +
+		"A1,How to reset?,Hold the button.,device\n" +
+		"B2,Is it waterproof?,No.,hardware\n"
+	path := filepath.Join(t.TempDir(), "faq.csv")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	t.Run("Content and ID columns", func(t *testing.T) {
+		opts := DefaultCSVOptions()
+		opts.ContentColumns = []string{"question", "answer"}
+		opts.IDColumn = "sku"
+
+		docs, err := LoadCSV(path, opts)
+		require.NoError(t, err)
+		require.Len(t, docs, 2)
+
+		assert.Equal(t, "A1", docs[0].ID)
+		assert.Equal(t, "question: How to reset?\nanswer: Hold the button.", docs[0].Content)
+		assert.Equal(t, "device", docs[0].Metadata["category"])
+		assert.Equal(t, "A1", docs[0].Metadata["sku"])
+		assert.NotContains(t, docs[0].Metadata, "question")
+		assert.Equal(t, 1, docs[1].Metadata["row"])
+	})
+
+	t.Run("No header", func(t *testing.T) {
+		docs, err := ReadCSV(strings.NewReader("x;y\nz;w\n"), "inline", &CSVOptions{
+			Delimiter:      ';',
+			ContentColumns: []string{"column_1"},
+		})
+		require.NoError(t, err)
+		require.Len(t, docs, 2)
+		assert.Equal(t, "y", docs[0].Content)
+		assert.Equal(t, "x", docs[0].Metadata["column_0"])
+		assert.Equal(t, "csv_inline_1", docs[1].ID)
+	})
+
+	t.Run("Unknown column", func(t *testing.T) {
+		opts := DefaultCSVOptions()
+		opts.ContentColumns = []string{"missing"}
+		_, err := LoadCSV(path, opts)
+		assert.Error(t, err)
+	})
+}
@@ -66,4 +66,5 @@ func (m *mockVectorStore) Update(ctx context.Context, docs []rag.Document) error
 func (m *mockVectorStore) GetStats(ctx context.Context) (*rag.VectorStoreStats, error) {
 	return &rag.VectorStoreStats{}, nil
 }
-func (m *mockVectorStore) Close() error { return nil }
+func (m *mockVectorStore) Clear(ctx context.Context) error { m.docs = nil; return nil }
+func (m *mockVectorStore) Close() error                    { return nil }
@@ -3,6 +3,7 @@ package engine
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -26,6 +27,9 @@ func NewGraphRAGEngine(config rag.GraphRAGConfig, llm rag.LLMInterface, embedder
 	if kg == nil {
 		return nil, fmt.Errorf("knowledge graph is required")
 	}
+	if config.VectorStore != nil && embedder == nil {
+		return nil, fmt.Errorf("an embedder is required to use a vector store")
+	}
 
 	// Set default extraction prompt if not provided
 	if config.ExtractionPrompt == "" {
@@ -42,6 +46,11 @@ func NewGraphRAGEngine(config rag.GraphRAGConfig, llm rag.LLMInterface, embedder
 		config.MaxDepth = 3
 	}
 
+	// Set default query timeout if not provided
+	if config.QueryTimeout == 0 {
+		config.QueryTimeout = DefaultGraphQueryTimeout
+	}
+
 	baseEngine := rag.NewBaseEngine(nil, embedder, &rag.Config{
 		GraphRAG: &config,
 	})
@@ -66,15 +75,24 @@ func (g *GraphRAGEngine) Query(ctx context.Context, query string) (*rag.QueryRes
 	})
 }
 
-// QueryWithConfig performs a GraphRAG query with custom configuration
+// QueryWithConfig performs a GraphRAG query with custom configuration.
+// The query honors ctx and GraphRAGConfig.QueryTimeout; when either ends it, the returned
+// error wraps context.DeadlineExceeded or context.Canceled.
 func (g *GraphRAGEngine) QueryWithConfig(ctx context.Context, query string, config *rag.RetrievalConfig) (*rag.QueryResult, error) {
 	startTime := time.Now()
 
+	if g.config.QueryTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.config.QueryTimeout)
+		defer cancel()
+	}
+
 	// Extract entities from the query
 	queryEntities, err := g.extractEntities(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract entities from query: %w", err)
+	if err = queryError(ctx, "extract entities from query", err); err != nil {
+		return nil, err
 	}
+	extractionTime := time.Since(startTime)
 
 	// Build graph query
 	graphQuery := rag.GraphQuery{
@@ -88,9 +106,10 @@ func (g *GraphRAGEngine) QueryWithConfig(ctx context.Context, query string, conf
 	}
 
 	// Perform graph search
+	searchStart := time.Now()
 	graphResult, err := g.knowledgeGraph.Query(ctx, &graphQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to perform graph search: %w", err)
+	if err = queryError(ctx, "perform graph search", err); err != nil {
+		return nil, err
 	}
 
 	// Convert graph results to documents
@@ -99,39 +118,137 @@ func (g *GraphRAGEngine) QueryWithConfig(ctx context.Context, query string, conf
 	// If no entities were found, fall back to entity search
 	if len(docs) == 0 && len(queryEntities) > 0 {
 		docs, err = g.entityBasedSearch(ctx, queryEntities, config.K)
-		if err != nil {
-			return nil, fmt.Errorf("failed entity-based search: %w", err)
+		if err = queryError(ctx, "perform entity-based search", err); err != nil {
+			return nil, err
 		}
 	}
 
 	// Build context from graph results
 	contextStr := g.buildGraphContext(graphResult, queryEntities)
 
-	// Calculate confidence based on entity matches and relationships
-	confidence := g.calculateGraphConfidence(graphResult, queryEntities)
+	searchTime := time.Since(searchStart)
+
+	// Add the most similar chunks when the engine is hybrid
+	graphSources := len(docs)
+	var passages []rag.DocumentSearchResult
+	var vectorSearchTime time.Duration
+	if g.config.VectorStore != nil {
+		vectorStart := time.Now()
+		passages, err = g.vectorSearch(ctx, query, config)
+		if err = queryError(ctx, "perform vector search", err); err != nil {
+			return nil, err
+		}
+		vectorSearchTime = time.Since(vectorStart)
+		docs = appendPassages(docs, passages)
+		contextStr += buildPassageContext(passages)
+	}
+
+	// Calculate confidence based on entity matches, relationships and retrieval scores
+	signals := graphConfidenceSignals(graphResult, queryEntities)
+	confidence := g.confidence(signals)
 
+	// The response time covers the whole query, including LLM entity extraction
 	responseTime := time.Since(startTime)
 
+	metadata := map[string]any{
+		"engine_type":                         "graph_rag",
+		"entities_found":                      len(graphResult.Entities),
+		"relationships":                       len(graphResult.Relationships),
+		"paths_found":                         len(graphResult.Paths),
+		"graph_query":                         graphQuery,
+		"extraction_time":                     extractionTime,
+		"search_time":                         searchTime,
+		rag.GraphConfidenceSignalsMetadataKey: signals,
+	}
+	if g.config.VectorStore != nil {
+		metadata["engine_type"] = "hybrid_graph_rag"
+		metadata["graph_sources"] = graphSources
+		metadata["vector_sources"] = len(docs) - graphSources
+		metadata["vector_results"] = len(passages)
+		metadata["vector_search_time"] = vectorSearchTime
+	}
+
 	return &rag.QueryResult{
 		Query:        query,
 		Sources:      docs,
 		Context:      contextStr,
 		Confidence:   confidence,
 		ResponseTime: responseTime,
-		Metadata: map[string]any{
-			"engine_type":     "graph_rag",
-			"entities_found":  len(graphResult.Entities),
-			"relationships":   len(graphResult.Relationships),
-			"paths_found":     len(graphResult.Paths),
-			"graph_query":     graphQuery,
-			"extraction_time": responseTime,
-		},
+		Metadata:     metadata,
 	}, nil
 }
 
+// vectorSearch returns the chunks most similar to query that pass the score threshold
+func (g *GraphRAGEngine) vectorSearch(ctx context.Context, query string, config *rag.RetrievalConfig) ([]rag.DocumentSearchResult, error) {
+	embedding, err := g.embedder.EmbedDocument(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	results, err := rag.SearchVectorStore(ctx, g.config.VectorStore, embedding, config.K, config.Filter, false)
+	if err != nil {
+		return nil, err
+	}
+
+	passages := make([]rag.DocumentSearchResult, 0, len(results))
+	for _, result := range results {
+		if result.Score >= config.ScoreThreshold {
+			passages = append(passages, result)
+		}
+	}
+	return passages, nil
+}
+
+// appendPassages appends the documents of passages that are not already in docs
+func appendPassages(docs []rag.Document, passages []rag.DocumentSearchResult) []rag.Document {
+	seen := make(map[string]bool, len(docs))
+	for _, doc := range docs {
+		seen[doc.ID] = true
+	}
+	for _, passage := range passages {
+		if passage.Document.ID != "" && seen[passage.Document.ID] {
+			continue
+		}
+		seen[passage.Document.ID] = true
+		docs = append(docs, passage.Document)
+	}
+	return docs
+}
+
+// buildPassageContext formats the chunks found by vector search for the context
+func buildPassageContext(passages []rag.DocumentSearchResult) string {
+	if len(passages) == 0 {
+		return ""
+	}
+
+	var contextStr strings.Builder
+	contextStr.WriteString("\n\nRelevant Passages:\n")
+	for i, passage := range passages {
+		contextStr.WriteString(fmt.Sprintf("[%d] (score %.2f) %s\n", i+1, passage.Score, passage.Document.Content))
+	}
+	return contextStr.String()
+}
+
+// queryError wraps the error of a query stage. A stage that ended because the query was
+// cancelled or timed out reports the context error, even if it returned no error itself
+// (e.g. entity extraction falling back to manual extraction).
+func queryError(ctx context.Context, stage string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		if errors.Is(ctxErr, context.DeadlineExceeded) {
+			return fmt.Errorf("graph rag query timed out: failed to %s: %w", stage, ctxErr)
+		}
+		return fmt.Errorf("graph rag query cancelled: failed to %s: %w", stage, ctxErr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", stage, err)
+	}
+	return nil
+}
+
 // AddDocuments adds documents to the knowledge graph
 func (g *GraphRAGEngine) AddDocuments(ctx context.Context, docs []rag.Document) error {
 	startTime := time.Now()
+	rag.EnsureIDs(docs)
 
 	for _, doc := range docs {
 		// Extract entities from the document
@@ -161,6 +278,24 @@ func (g *GraphRAGEngine) AddDocuments(ctx context.Context, docs []rag.Document)
 		}
 	}
 
+	// Index the chunks for vector search when the engine is hybrid
+	if g.config.VectorStore != nil {
+		chunks := make([]rag.Document, len(docs))
+		for i, doc := range docs {
+			if len(doc.Embedding) == 0 {
+				embedding, err := g.embedder.EmbedDocument(ctx, doc.Content)
+				if err != nil {
+					return fmt.Errorf("failed to embed document %s: %w", doc.ID, err)
+				}
+				doc.Embedding = embedding
+			}
+			chunks[i] = doc
+		}
+		if err := g.config.VectorStore.Add(ctx, chunks); err != nil {
+			return fmt.Errorf("failed to add documents to vector store: %w", err)
+		}
+	}
+
 	g.metrics.IndexingLatency = time.Since(startTime)
 	g.metrics.TotalDocuments += int64(len(docs))
 
@@ -400,42 +535,34 @@ func (g *GraphRAGEngine) buildGraphContext(result *rag.GraphQueryResult, queryEn
 
 // calculateGraphConfidence calculates confidence based on graph results
 func (g *GraphRAGEngine) calculateGraphConfidence(result *rag.GraphQueryResult, queryEntities []*rag.Entity) float64 {
-	if len(result.Entities) == 0 {
-		return 0.0
-	}
+	return g.confidence(graphConfidenceSignals(result, queryEntities))
+}
 
-	// Base confidence from number of entities found
-	entityConfidence := float64(len(result.Entities)) / 10.0
-	if entityConfidence > 1.0 {
-		entityConfidence = 1.0
+// confidence applies the configured confidence function, DefaultGraphConfidence by default
+func (g *GraphRAGEngine) confidence(signals rag.GraphConfidenceSignals) float64 {
+	if g.config.ConfidenceFunc != nil {
+		return g.config.ConfidenceFunc(signals)
 	}
+	return rag.DefaultGraphConfidence(signals)
+}
 
-	// Boost confidence if query entities were matched
-	if len(queryEntities) > 0 {
-		matchedEntities := 0
-		for _, queryEntity := range queryEntities {
-			for _, foundEntity := range result.Entities {
-				if queryEntity.ID == foundEntity.ID || queryEntity.Name == foundEntity.Name {
-					matchedEntities++
-					break
-				}
+// graphConfidenceSignals measures a graph result against the entities extracted from the query
+func graphConfidenceSignals(result *rag.GraphQueryResult, queryEntities []*rag.Entity) rag.GraphConfidenceSignals {
+	signals := rag.GraphConfidenceSignals{
+		EntitiesFound:      len(result.Entities),
+		RelationshipsFound: len(result.Relationships),
+		QueryEntities:      len(queryEntities),
+		RetrievalScores:    result.Scores,
+	}
+	for _, queryEntity := range queryEntities {
+		for _, foundEntity := range result.Entities {
+			if queryEntity.ID == foundEntity.ID || queryEntity.Name == foundEntity.Name {
+				signals.MatchedEntities++
+				break
 			}
 		}
-		entityConfidence += float64(matchedEntities) / float64(len(queryEntities)) * 0.3
-	}
-
-	// Consider relationships count (since store.Relationship doesn't have confidence)
-	relConfidence := 0.0
-	if len(result.Relationships) > 0 {
-		relConfidence = float64(len(result.Relationships)) * 0.1 // Give some weight for having relationships
 	}
-
-	totalConfidence := entityConfidence + relConfidence
-	if totalConfidence > 1.0 {
-		totalConfidence = 1.0
-	}
-
-	return totalConfidence
+	return signals
 }
 
 // manualEntityExtraction provides a fallback for entity extraction
@@ -541,6 +668,9 @@ Entities: %s
 `
 )
 
+// DefaultGraphQueryTimeout is the GraphRAGConfig.QueryTimeout used when none is set
+const DefaultGraphQueryTimeout = 2 * time.Minute
+
 // DefaultEntityTypes contains commonly used entity types
 var DefaultEntityTypes = []string{
 	"PERSON",
@@ -3,9 +3,12 @@ package engine
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/smallnest/langgraphgo/rag"
+	"github.com/smallnest/langgraphgo/rag/store"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockKG struct {
@@ -27,6 +30,16 @@ func (m *mockKG) GetEntity(ctx context.Context, id string) (*rag.Entity, error)
 	return nil, nil
 }
 
+// slowKG blocks queries until the context is done, like an unresponsive database
+type slowKG struct {
+	mockKG
+}
+
+func (m *slowKG) Query(ctx context.Context, q *rag.GraphQuery) (*rag.GraphQueryResult, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
 func TestGraphRAGEngine(t *testing.T) {
 	ctx := context.Background()
 	llm := &mockLLM{}
@@ -67,3 +80,91 @@ func TestGraphRAGEngine(t *testing.T) {
 		assert.Greater(t, conf, 0.0)
 	})
 }
+
+func TestGraphRAGEngine_ConfidenceFunc(t *testing.T) {
+	ctx := context.Background()
+	kg := &mockKG{entities: []*rag.Entity{{ID: "e1", Name: "e1", Type: "person"}, {ID: "e2", Name: "e2", Type: "person"}}}
+
+	var got rag.GraphConfidenceSignals
+	config := rag.GraphRAGConfig{ConfidenceFunc: func(s rag.GraphConfidenceSignals) float64 {
+		got = s
+		return 0.42
+	}}
+	e, err := NewGraphRAGEngine(config, &mockLLM{}, &mockEmbedder{}, kg)
+	assert.NoError(t, err)
+
+	res, err := e.Query(ctx, "e1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.42, res.Confidence)
+	assert.Equal(t, 2, got.EntitiesFound)
+	assert.Equal(t, got, res.Metadata[rag.GraphConfidenceSignalsMetadataKey])
+	assert.GreaterOrEqual(t, res.ResponseTime, res.Metadata["extraction_time"])
+}
+
+func TestDefaultGraphConfidence(t *testing.T) {
+	assert.Equal(t, 0.0, rag.DefaultGraphConfidence(rag.GraphConfidenceSignals{QueryEntities: 1}))
+
+	signals := rag.GraphConfidenceSignals{EntitiesFound: 2, RelationshipsFound: 1, QueryEntities: 2, MatchedEntities: 1}
+	assert.InDelta(t, 0.2+0.15+0.1, rag.DefaultGraphConfidence(signals), 1e-9)
+
+	signals.RelationshipsFound = 20
+	assert.Equal(t, 1.0, rag.DefaultGraphConfidence(signals), "capped at 1")
+
+	signals.RetrievalScores = []float64{0.2, 0.4}
+	assert.InDelta(t, (1.0+0.3)/2, rag.DefaultGraphConfidence(signals), 1e-9)
+}
+
+func TestGraphRAGEngine_QueryTimeout(t *testing.T) {
+	e, err := NewGraphRAGEngine(rag.GraphRAGConfig{}, &mockLLM{}, &mockEmbedder{}, &slowKG{})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultGraphQueryTimeout, e.config.QueryTimeout)
+
+	e, err = NewGraphRAGEngine(rag.GraphRAGConfig{QueryTimeout: 20 * time.Millisecond}, &mockLLM{}, &mockEmbedder{}, &slowKG{})
+	assert.NoError(t, err)
+
+	start := time.Now()
+	_, err = e.Query(context.Background(), "e1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "timed out: failed to perform graph search")
+	assert.Less(t, time.Since(start), time.Second)
+
+	// Cancellation by the caller is reported as such
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = e.Query(ctx, "e1")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestGraphRAGEngine_HybridVectorSearch(t *testing.T) {
+	ctx := context.Background()
+	kg := &mockKG{entities: []*rag.Entity{{ID: "e1", Name: "e1", Type: "person"}}}
+	vs := store.NewInMemoryVectorStore(nil)
+
+	_, err := NewGraphRAGEngine(rag.GraphRAGConfig{VectorStore: vs}, &mockLLM{}, nil, kg)
+	assert.Error(t, err, "a vector store needs an embedder")
+
+	e, err := NewGraphRAGEngine(rag.GraphRAGConfig{VectorStore: vs}, &mockLLM{}, &mockEmbedder{}, kg)
+	require.NoError(t, err)
+	require.NoError(t, e.AddDocuments(ctx, []rag.Document{
+		{ID: "d1", Content: "e1 founded the company"},
+		{ID: "e1", Content: "duplicate of the graph entity"},
+	}))
+
+	stats, err := vs.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.TotalDocuments)
+
+	res, err := e.Query(ctx, "who founded the company?")
+	require.NoError(t, err)
+	ids := make([]string, len(res.Sources))
+	for i, doc := range res.Sources {
+		ids[i] = doc.ID
+	}
+	assert.Equal(t, []string{"e1", "d1"}, ids, "graph results come first and duplicates are dropped")
+	assert.Contains(t, res.Context, "Knowledge Graph Information")
+	assert.Contains(t, res.Context, "Relevant Passages:\n[1] (score 1.00) e1 founded the company")
+	assert.Equal(t, "hybrid_graph_rag", res.Metadata["engine_type"])
+	assert.Equal(t, 1, res.Metadata["graph_sources"])
+	assert.Equal(t, 1, res.Metadata["vector_sources"])
+	assert.Equal(t, 2, res.Metadata["vector_results"])
+}
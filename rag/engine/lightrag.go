@@ -364,6 +364,7 @@ func (l *LightRAGEngine) hybridRetrieval(ctx context.Context, query string, conf
 // AddDocuments adds documents to the LightRAG system
 func (l *LightRAGEngine) AddDocuments(ctx context.Context, docs []rag.Document) error {
 	startTime := time.Now()
+	rag.EnsureIDs(docs)
 
 	for _, doc := range docs {
 		// Split document into chunks
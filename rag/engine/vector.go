@@ -3,10 +3,10 @@ package engine
 import (
 	"context"
 	"fmt"
-	"math"
 	"strings"
 	"time"
 
+	"github.com/smallnest/langgraphgo/internal/vector"
 	"github.com/smallnest/langgraphgo/rag"
 	"github.com/smallnest/langgraphgo/rag/splitter"
 )
@@ -240,13 +240,10 @@ func (v *VectorRAGEngine) QueryWithConfig(ctx context.Context, query string, con
 
 	startTime := time.Now()
 
-	// Perform similarity search with custom config
-	searchResults, err := v.vectorStore.SearchWithFilter(
-		ctx,
-		v.embedQuery(ctx, query),
-		config.K,
-		config.Filter,
-	)
+	// Perform similarity search with custom config. MMR compares the stored embeddings of
+	// the results, so they are fetched for it even when the caller did not ask for them.
+	includeEmbeddings := config.IncludeEmbeddings || config.SearchType == "mmr"
+	searchResults, err := rag.SearchVectorStore(ctx, v.vectorStore, v.embedQuery(ctx, query), config.K, config.Filter, includeEmbeddings)
 	if err != nil {
 		return nil, fmt.Errorf("vector search failed: %w", err)
 	}
@@ -284,15 +281,16 @@ func (v *VectorRAGEngine) QueryWithConfig(ctx context.Context, query string, con
 			nil
 	}
 
-	// Extract documents from search results
+	// Build context from retrieved documents
+	contextStr := v.buildContext(filteredResults)
+
+	// Extract documents from search results, keeping only the requested metadata
+	rag.ProjectMetadata(filteredResults, config.MetadataFields)
 	docs := make([]rag.Document, len(filteredResults))
 	for i, result := range filteredResults {
 		docs[i] = result.Document
 	}
 
-	// Build context from retrieved documents
-	contextStr := v.buildContext(filteredResults)
-
 	// Calculate confidence based on search scores
 	confidence := v.calculateConfidence(filteredResults)
 
@@ -319,6 +317,7 @@ func (v *VectorRAGEngine) QueryWithConfig(ctx context.Context, query string, con
 // AddDocuments adds documents to the vector store
 func (v *VectorRAGEngine) AddDocuments(ctx context.Context, docs []rag.Document) error {
 	startTime := time.Now()
+	rag.EnsureIDs(docs)
 
 	// Process documents: split into chunks if needed
 	processedDocs := make([]rag.Document, 0)
@@ -475,7 +474,7 @@ func (v *VectorRAGEngine) applyMMR(results []rag.DocumentSearchResult, k int) []
 			// Calculate maximal similarity to already selected documents
 			maxSimilarity := 0.0
 			for _, selectedDoc := range selected {
-				similarity := v.calculateSimilarity(candidate.Document, selectedDoc.Document)
+				similarity := v.calculateSimilarity(candidate, selectedDoc)
 				if similarity > maxSimilarity {
 					maxSimilarity = similarity
 				}
@@ -500,35 +499,15 @@ func (v *VectorRAGEngine) applyMMR(results []rag.DocumentSearchResult, k int) []
 	return selected
 }
 
-// calculateSimilarity calculates similarity between two documents
-func (v *VectorRAGEngine) calculateSimilarity(doc1, doc2 rag.Document) float64 {
+// calculateSimilarity calculates similarity between two search results
+func (v *VectorRAGEngine) calculateSimilarity(result1, result2 rag.DocumentSearchResult) float64 {
 	// Simple cosine similarity if embeddings are available
-	if len(doc1.Embedding) > 0 && len(doc2.Embedding) > 0 {
-		return cosineSimilarity(doc1.Embedding, doc2.Embedding)
+	if len(result1.Embedding) > 0 && len(result2.Embedding) > 0 {
+		return vector.Cosine(result1.Embedding, result2.Embedding)
 	}
 
 	// Fallback to Jaccard similarity on content
-	return jaccardSimilarity(doc1.Content, doc2.Content)
-}
-
-// cosineSimilarity calculates cosine similarity between two embeddings
-func cosineSimilarity(a, b []float32) float64 {
-	if len(a) != len(b) {
-		return 0.0
-	}
-
-	var dotProduct, normA, normB float32
-	for i := range a {
-		dotProduct += a[i] * b[i]
-		normA += a[i] * a[i]
-		normB += b[i] * b[i]
-	}
-
-	if normA == 0 || normB == 0 {
-		return 0.0
-	}
-
-	return float64(dotProduct / (float32(math.Sqrt(float64(normA))) * float32(math.Sqrt(float64(normB)))))
+	return jaccardSimilarity(result1.Document.Content, result2.Document.Content)
 }
 
 // jaccardSimilarity calculates Jaccard similarity between two texts
@@ -59,9 +59,9 @@ func TestVectorRAGEngine(t *testing.T) {
 	})
 
 	t.Run("Calculate Similarity", func(t *testing.T) {
-		d1 := rag.Document{Content: "word1 word2"}
-		d2 := rag.Document{Content: "word1 word3"}
-		sim := e.calculateSimilarity(d1, d2)
+		r1 := rag.DocumentSearchResult{Document: rag.Document{Content: "word1 word2"}}
+		r2 := rag.DocumentSearchResult{Document: rag.Document{Content: "word1 word3"}}
+		sim := e.calculateSimilarity(r1, r2)
 		assert.Greater(t, sim, 0.0)
 	})
 }
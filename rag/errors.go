@@ -0,0 +1,171 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// ErrEndpointNotFound is reported for a relationship whose source or target entity does not exist
+var ErrEndpointNotFound = errors.New("relationship endpoint not found")
+
+// ErrEmptyDocument is returned when a document to ingest has no content besides whitespace
+var ErrEmptyDocument = errors.New("document is empty")
+
+// ErrCancelled is reported by RAGPipeline.Stream when the run is cancelled before it completes.
+// The error also wraps the cause, such as context.Canceled.
+var ErrCancelled = errors.New("pipeline run cancelled")
+
+// Categories of failures returned by stores, retrievers, rerankers and embedders, so callers
+// can react with errors.Is, e.g. retry ErrConnection and ErrRateLimited but not ErrInvalidQuery
+var (
+	// ErrConnection is a backend that cannot be reached or is temporarily unavailable
+	ErrConnection = errors.New("backend unavailable")
+	// ErrNotFound is a document, entity, relationship or collection that does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrRateLimited is a backend rejecting requests because of rate limits or quotas
+	ErrRateLimited = errors.New("rate limited")
+	// ErrInvalidQuery is a request rejected by the backend as malformed
+	ErrInvalidQuery = errors.New("invalid query")
+	// ErrDimensionMismatch is an embedding whose dimension does not match the store, see
+	// DimensionMismatchError
+	ErrDimensionMismatch = errors.New("embedding dimension mismatch")
+)
+
+// ErrDocumentNotFound is returned by DocStore.Get for an ID that does not exist. It matches ErrNotFound.
+var ErrDocumentNotFound = fmt.Errorf("document %w", ErrNotFound)
+
+// StatusError is returned when a backend API responds with an error status. It matches
+// ErrRateLimited (429), ErrNotFound (404), ErrInvalidQuery (400, 422) or ErrConnection
+// (500, 502, 503, 504) with errors.Is.
+type StatusError struct {
+	// Backend names the API, e.g. "Cohere API"
+	Backend string
+	// StatusCode is the HTTP status of the response
+	StatusCode int
+	// Body is the response body
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("%s returned status %d", e.Backend, e.StatusCode)
+	}
+	return fmt.Sprintf("%s returned status %d: %s", e.Backend, e.StatusCode, e.Body)
+}
+
+// Is reports whether target is the category of the status
+func (e *StatusError) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests:
+		return target == ErrRateLimited
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return target == ErrInvalidQuery
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return target == ErrConnection
+	}
+	return false
+}
+
+// ConnectionError is returned when a backend cannot be reached. It matches ErrConnection,
+// and unwraps to the underlying error.
+type ConnectionError struct {
+	// Backend names the backend, e.g. "FalkorDB"
+	Backend string
+	// Err is the underlying error
+	Err error
+}
+
+func (e *ConnectionError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Backend, e.Err)
+}
+
+// Is reports whether target is ErrConnection
+func (e *ConnectionError) Is(target error) bool {
+	return target == ErrConnection
+}
+
+// Unwrap returns the underlying error
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+// NewConnectionError wraps err in a *ConnectionError for backend. Cancellation by the caller
+// is not a connection failure and is returned unchanged.
+func NewConnectionError(backend string, err error) error {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return err
+	}
+	return &ConnectionError{Backend: backend, Err: err}
+}
+
+// DimensionMismatchError is returned when an embedding does not match the dimension
+// of the vector store it is added to or searched in
+type DimensionMismatchError struct {
+	// Expected is the dimension of the vector store
+	Expected int
+	// Actual is the dimension of the offending embedding
+	Actual int
+	// DocumentID is the ID of the offending document, empty for queries
+	DocumentID string
+}
+
+func (e *DimensionMismatchError) Error() string {
+	if e.DocumentID == "" {
+		return fmt.Sprintf("embedding dimension mismatch: store expects %d, query has %d", e.Expected, e.Actual)
+	}
+	return fmt.Sprintf("embedding dimension mismatch: store expects %d, document %s has %d", e.Expected, e.DocumentID, e.Actual)
+}
+
+// Is reports whether target is ErrDimensionMismatch
+func (e *DimensionMismatchError) Is(target error) bool {
+	return target == ErrDimensionMismatch
+}
+
+// CheckDimension returns a *DimensionMismatchError if embedding does not have the
+// expected dimension. An expected dimension of 0 accepts any embedding.
+func CheckDimension(expected int, embedding []float32, documentID string) error {
+	if expected > 0 && len(embedding) != expected {
+		return &DimensionMismatchError{Expected: expected, Actual: len(embedding), DocumentID: documentID}
+	}
+	return nil
+}
+
+// BatchWriteError is returned by batch writes when some items could not be written.
+// Items that are not listed were written.
+type BatchWriteError struct {
+	// Errors maps the index of each failed item in the input slice to its error
+	Errors map[int]error
+}
+
+func (e *BatchWriteError) Error() string {
+	indices := e.Indices()
+	if len(indices) == 0 {
+		return "batch write failed"
+	}
+	first := indices[0]
+	return fmt.Sprintf("batch write failed for %d item(s), first at index %d: %v", len(indices), first, e.Errors[first])
+}
+
+// Unwrap returns the item errors, so errors.Is matches any of them
+func (e *BatchWriteError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, i := range e.Indices() {
+		errs = append(errs, e.Errors[i])
+	}
+	return errs
+}
+
+// Indices returns the indices of the failed items in ascending order
+func (e *BatchWriteError) Indices() []int {
+	indices := make([]int, 0, len(e.Errors))
+	for i := range e.Errors {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	return indices
+}
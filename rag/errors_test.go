@@ -0,0 +1,53 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		status   int
+		category error
+	}{
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusBadRequest, ErrInvalidQuery},
+		{http.StatusUnprocessableEntity, ErrInvalidQuery},
+		{http.StatusServiceUnavailable, ErrConnection},
+	}
+	for _, tt := range tests {
+		err := fmt.Errorf("failed to search: %w", &StatusError{Backend: "Chroma", StatusCode: tt.status})
+		assert.ErrorIs(t, err, tt.category, "status %d", tt.status)
+		for _, other := range []error{ErrRateLimited, ErrNotFound, ErrInvalidQuery, ErrConnection} {
+			if other != tt.category {
+				assert.NotErrorIs(t, err, other, "status %d", tt.status)
+			}
+		}
+	}
+
+	assert.NotErrorIs(t, &StatusError{StatusCode: http.StatusUnauthorized}, ErrInvalidQuery)
+	assert.Equal(t, "Cohere API returned status 429: slow down", (&StatusError{Backend: "Cohere API", StatusCode: 429, Body: "slow down"}).Error())
+}
+
+func TestConnectionError(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := NewConnectionError("FalkorDB", cause)
+	assert.ErrorIs(t, err, ErrConnection)
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "FalkorDB: connection refused", err.Error())
+
+	assert.Equal(t, context.Canceled, NewConnectionError("FalkorDB", context.Canceled))
+	assert.Nil(t, NewConnectionError("FalkorDB", nil))
+}
+
+func TestErrorCategories(t *testing.T) {
+	assert.ErrorIs(t, CheckDimension(3, []float32{1}, "doc"), ErrDimensionMismatch)
+	assert.ErrorIs(t, ErrDocumentNotFound, ErrNotFound)
+	assert.Equal(t, "document not found", ErrDocumentNotFound.Error())
+}
@@ -0,0 +1,198 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/smallnest/langgraphgo/rag"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Pipeline answers questions, e.g. a *rag.RAGPipeline
+type Pipeline interface {
+	Query(ctx context.Context, query string) (*rag.Result, error)
+}
+
+// QAPair is a question with its reference answer
+type QAPair struct {
+	Question        string
+	ReferenceAnswer string
+}
+
+// AnswerScores are LLM judge scores between 0 and 1
+type AnswerScores struct {
+	// Faithfulness is how well the answer is supported by the retrieved context
+	Faithfulness float64
+	// Relevance is how well the answer addresses the question
+	Relevance float64
+	// Correctness is how well the answer agrees with the reference answer
+	Correctness float64
+}
+
+// AnswerEvaluation is the evaluation of the answer to one question
+type AnswerEvaluation struct {
+	QAPair
+	Answer  string
+	Context string
+	Scores  AnswerScores
+	// Grounded is false when the judge found claims not supported by the context
+	Grounded  bool
+	Reasoning string
+	// Err is set when the pipeline or the judge failed for this question
+	Err error
+}
+
+// AnswerReport aggregates the evaluations of a dataset
+type AnswerReport struct {
+	Evaluations []AnswerEvaluation
+	// Mean holds the mean scores of the successful evaluations
+	Mean AnswerScores
+	// Ungrounded is the number of answers flagged as not supported by their context
+	Ungrounded int
+	// Failed is the number of questions whose evaluation failed
+	Failed int
+}
+
+const answerJudgePrompt = `You are an impartial judge of question answering systems. Score the answer between 0.0 and 1.0 on:
+- FAITHFULNESS: every claim in the answer is supported by the context
+- RELEVANCE: the answer addresses the question
+- CORRECTNESS: the answer agrees with the reference answer
+Set GROUNDED to no if the answer contains any claim that is not supported by the context.
+
+Respond in exactly this format:
+FAITHFULNESS: <score>
+RELEVANCE: <score>
+CORRECTNESS: <score>
+GROUNDED: <yes|no>
+REASONING: <one sentence>`
+
+// EvaluateAnswers runs each question of the dataset through the pipeline and scores
+// the answers with an LLM judge. Questions whose pipeline run or judgement fails are
+// recorded with Err and counted as Failed; only a cancelled context aborts the run.
+func EvaluateAnswers(ctx context.Context, pipeline Pipeline, dataset []QAPair, judge llms.Model) (*AnswerReport, error) {
+	report := &AnswerReport{Evaluations: make([]AnswerEvaluation, 0, len(dataset))}
+
+	var sum AnswerScores
+	for _, pair := range dataset {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		evaluation := evaluateAnswer(ctx, pipeline, pair, judge)
+		report.Evaluations = append(report.Evaluations, evaluation)
+		if evaluation.Err != nil {
+			report.Failed++
+			continue
+		}
+
+		sum.Faithfulness += evaluation.Scores.Faithfulness
+		sum.Relevance += evaluation.Scores.Relevance
+		sum.Correctness += evaluation.Scores.Correctness
+		if !evaluation.Grounded {
+			report.Ungrounded++
+		}
+	}
+
+	if n := float64(len(dataset) - report.Failed); n > 0 {
+		report.Mean = AnswerScores{
+			Faithfulness: sum.Faithfulness / n,
+			Relevance:    sum.Relevance / n,
+			Correctness:  sum.Correctness / n,
+		}
+	}
+	return report, nil
+}
+
+func evaluateAnswer(ctx context.Context, pipeline Pipeline, pair QAPair, judge llms.Model) AnswerEvaluation {
+	evaluation := AnswerEvaluation{QAPair: pair}
+
+	result, err := pipeline.Query(ctx, pair.Question)
+	if err != nil {
+		evaluation.Err = fmt.Errorf("pipeline failed: %w", err)
+		return evaluation
+	}
+	evaluation.Answer = result.Answer
+	evaluation.Context = result.Context
+
+	prompt := fmt.Sprintf("Question: %s\n\nReference answer: %s\n\nContext:\n%s\n\nAnswer: %s",
+		pair.Question, pair.ReferenceAnswer, result.Context, result.Answer)
+	fields, err := askJudge(ctx, judge, answerJudgePrompt, prompt)
+	if err != nil {
+		evaluation.Err = err
+		return evaluation
+	}
+
+	scores := []struct {
+		name  string
+		score *float64
+	}{
+		{"FAITHFULNESS", &evaluation.Scores.Faithfulness},
+		{"RELEVANCE", &evaluation.Scores.Relevance},
+		{"CORRECTNESS", &evaluation.Scores.Correctness},
+	}
+	for _, s := range scores {
+		if *s.score, err = parseScore(fields, s.name); err != nil {
+			evaluation.Err = err
+			return evaluation
+		}
+	}
+	evaluation.Grounded = !strings.HasPrefix(strings.ToLower(fields["GROUNDED"]), "no")
+	evaluation.Reasoning = fields["REASONING"]
+	return evaluation
+}
+
+const groundednessPrompt = `You check whether an answer is grounded in its context.
+Respond NO if the answer contains any claim that is not supported by the context, otherwise YES.
+
+Respond in exactly this format:
+GROUNDED: <yes|no>
+REASONING: <the unsupported claims, or one sentence>`
+
+// GroundednessCheck asks an LLM judge whether every claim of answer is supported by the
+// retrieved context. It returns false with the judge's reasoning for ungrounded answers.
+func GroundednessCheck(ctx context.Context, judge llms.Model, answer, retrievedContext string) (bool, string, error) {
+	fields, err := askJudge(ctx, judge, groundednessPrompt, fmt.Sprintf("Context:\n%s\n\nAnswer: %s", retrievedContext, answer))
+	if err != nil {
+		return false, "", err
+	}
+
+	verdict, ok := fields["GROUNDED"]
+	if !ok {
+		return false, "", fmt.Errorf("judge response has no GROUNDED verdict")
+	}
+	return !strings.HasPrefix(strings.ToLower(verdict), "no"), fields["REASONING"], nil
+}
+
+// askJudge sends a prompt to the judge and parses its "NAME: value" response lines
+func askJudge(ctx context.Context, judge llms.Model, system, prompt string) (map[string]string, error) {
+	response, err := judge.GenerateContent(ctx, []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, system),
+		llms.TextParts(llms.ChatMessageTypeHuman, prompt),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("judge failed: %w", err)
+	}
+	if len(response.Choices) == 0 {
+		return nil, fmt.Errorf("judge returned no choices")
+	}
+
+	fields := make(map[string]string)
+	for line := range strings.SplitSeq(response.Choices[0].Content, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if ok {
+			fields[strings.ToUpper(strings.TrimSpace(name))] = strings.TrimSpace(value)
+		}
+	}
+	return fields, nil
+}
+
+// parseScore parses a judge score, clamped to [0, 1]
+func parseScore(fields map[string]string, name string) (float64, error) {
+	score, err := strconv.ParseFloat(fields[name], 64)
+	if err != nil {
+		return 0, fmt.Errorf("judge response has no valid %s score: %q", name, fields[name])
+	}
+	return min(max(score, 0), 1), nil
+}
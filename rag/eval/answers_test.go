@@ -0,0 +1,100 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/rag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+type mapPipeline map[string]string
+
+func (p mapPipeline) Query(ctx context.Context, query string) (*rag.Result, error) {
+	answer, ok := p[query]
+	if !ok {
+		return nil, errors.New("no answer")
+	}
+	return &rag.Result{Query: query, Answer: answer, Context: "Paris is the capital of France."}, nil
+}
+
+// scriptedJudge answers with the response whose key ends the prompt
+type scriptedJudge map[string]string
+
+func (j scriptedJudge) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	prompt := messages[len(messages)-1].Parts[0].(llms.TextContent).Text
+	for key, response := range j {
+		if strings.HasSuffix(prompt, key) {
+			return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: response}}}, nil
+		}
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: "I cannot judge this"}}}, nil
+}
+
+func (j scriptedJudge) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func TestEvaluateAnswers(t *testing.T) {
+	pipeline := mapPipeline{
+		"capital of France?": "Paris",
+		"population?":        "Paris has 40 million people",
+		"unjudged?":          "something",
+	}
+	judge := scriptedJudge{
+		"Answer: Paris":     "FAITHFULNESS: 1.0\nRELEVANCE: 1\nCORRECTNESS: 0.8\nGROUNDED: yes\nREASONING: supported",
+		"40 million people": "FAITHFULNESS: 0.2\nRELEVANCE: 1.5\nCORRECTNESS: 0\nGROUNDED: no\nREASONING: population not in context",
+	}
+	dataset := []QAPair{
+		{Question: "capital of France?", ReferenceAnswer: "Paris"},
+		{Question: "population?", ReferenceAnswer: "about 2 million"},
+		{Question: "unjudged?"},
+		{Question: "unknown?"},
+	}
+
+	report, err := EvaluateAnswers(context.Background(), pipeline, dataset, judge)
+	require.NoError(t, err)
+	require.Len(t, report.Evaluations, 4)
+
+	assert.Equal(t, 2, report.Failed)
+	assert.Equal(t, 1, report.Ungrounded)
+	assert.InDelta(t, 0.6, report.Mean.Faithfulness, 1e-9)
+	assert.InDelta(t, 1.0, report.Mean.Relevance, 1e-9, "scores are clamped to 1")
+	assert.InDelta(t, 0.4, report.Mean.Correctness, 1e-9)
+
+	assert.True(t, report.Evaluations[0].Grounded)
+	assert.Equal(t, "Paris", report.Evaluations[0].Answer)
+	assert.False(t, report.Evaluations[1].Grounded)
+	assert.Equal(t, "population not in context", report.Evaluations[1].Reasoning)
+	assert.ErrorContains(t, report.Evaluations[2].Err, "FAITHFULNESS")
+	assert.ErrorContains(t, report.Evaluations[3].Err, "pipeline failed")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = EvaluateAnswers(ctx, pipeline, dataset, judge)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestGroundednessCheck(t *testing.T) {
+	judge := scriptedJudge{
+		"Answer: Paris":  "GROUNDED: YES\nREASONING: stated in context",
+		"Answer: Berlin": "GROUNDED: NO\nREASONING: Berlin is not mentioned",
+	}
+	ctx := context.Background()
+
+	grounded, _, err := GroundednessCheck(ctx, judge, "Paris", "Paris is the capital of France.")
+	require.NoError(t, err)
+	assert.True(t, grounded)
+
+	grounded, reason, err := GroundednessCheck(ctx, judge, "Berlin", "Paris is the capital of France.")
+	require.NoError(t, err)
+	assert.False(t, grounded)
+	assert.Equal(t, "Berlin is not mentioned", reason)
+
+	_, _, err = GroundednessCheck(ctx, judge, "Madrid", "Paris is the capital of France.")
+	assert.Error(t, err)
+}
@@ -0,0 +1,110 @@
+// Package eval provides ranking metrics for comparing retrievers and rerankers
+// against ground-truth relevance judgments, and an LLM-judged harness for
+// evaluating end-to-end answer quality (EvaluateAnswers).
+//
+// Judgments map a document ID to its graded relevance; documents that are not
+// in the map, or have a relevance of 0 or less, are not relevant. When several
+// results share a document ID (e.g. chunks of the same document), only the
+// first one counts.
+package eval
+
+import (
+	"math"
+	"sort"
+
+	"github.com/smallnest/langgraphgo/rag"
+)
+
+// NDCG returns the normalized discounted cumulative gain of the top k results,
+// using the gain 2^rel - 1. It is 1 for an ideal ranking and 0 when no relevant
+// document was retrieved or there are no relevant judgments. k <= 0 uses all results.
+func NDCG(results []rag.DocumentSearchResult, judgments map[string]float64, k int) float64 {
+	var dcg float64
+	for i, rel := range gains(results, judgments, k) {
+		dcg += (math.Pow(2, rel) - 1) / math.Log2(float64(i+2))
+	}
+
+	ideal := make([]float64, 0, len(judgments))
+	for _, rel := range judgments {
+		if rel > 0 {
+			ideal = append(ideal, rel)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(ideal)))
+	if k > 0 && k < len(ideal) {
+		ideal = ideal[:k]
+	}
+
+	var idcg float64
+	for i, rel := range ideal {
+		idcg += (math.Pow(2, rel) - 1) / math.Log2(float64(i+2))
+	}
+	if idcg == 0 {
+		return 0
+	}
+	return dcg / idcg
+}
+
+// MRR returns the reciprocal rank of the first relevant result, or 0 if none is
+// relevant. Averaged over a set of queries this is the mean reciprocal rank.
+func MRR(results []rag.DocumentSearchResult, judgments map[string]float64) float64 {
+	for i, rel := range gains(results, judgments, 0) {
+		if rel > 0 {
+			return 1 / float64(i+1)
+		}
+	}
+	return 0
+}
+
+// RecallAtK returns the fraction of relevant documents found in the top k results.
+// It is 0 when there are no relevant judgments. k <= 0 uses all results.
+func RecallAtK(results []rag.DocumentSearchResult, judgments map[string]float64, k int) float64 {
+	var relevant, found int
+	for _, rel := range judgments {
+		if rel > 0 {
+			relevant++
+		}
+	}
+	if relevant == 0 {
+		return 0
+	}
+
+	for _, rel := range gains(results, judgments, k) {
+		if rel > 0 {
+			found++
+		}
+	}
+	return float64(found) / float64(relevant)
+}
+
+// Mean returns the average of per-query metric values, e.g. to compute MRR over a query set
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// gains returns the relevance of each of the top k results, counting a document ID only
+// at its first occurrence
+func gains(results []rag.DocumentSearchResult, judgments map[string]float64, k int) []float64 {
+	if k <= 0 || k > len(results) {
+		k = len(results)
+	}
+
+	seen := make(map[string]bool, k)
+	rels := make([]float64, k)
+	for i, result := range results[:k] {
+		id := result.Document.ID
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		rels[i] = math.Max(judgments[id], 0)
+	}
+	return rels
+}
@@ -0,0 +1,52 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/smallnest/langgraphgo/rag"
+	"github.com/stretchr/testify/assert"
+)
+
+func ranked(ids ...string) []rag.DocumentSearchResult {
+	results := make([]rag.DocumentSearchResult, len(ids))
+	for i, id := range ids {
+		results[i] = rag.DocumentSearchResult{Document: rag.Document{ID: id}}
+	}
+	return results
+}
+
+func TestNDCG(t *testing.T) {
+	judgments := map[string]float64{"a": 3, "b": 2, "c": 1}
+
+	assert.InDelta(t, 1.0, NDCG(ranked("a", "b", "c"), judgments, 3), 1e-9)
+	assert.InDelta(t, 1.0, NDCG(ranked("a", "x"), judgments, 1), 1e-9)
+	assert.Less(t, NDCG(ranked("c", "b", "a"), judgments, 3), 1.0)
+	assert.Zero(t, NDCG(ranked("x", "y"), judgments, 2))
+	assert.Zero(t, NDCG(ranked("a"), nil, 1))
+
+	// Expected value: DCG = 1/log2(2) + 7/log2(3), IDCG = 7/log2(2) + 3/log2(3)
+	expected := (1 + 7/1.584962500721156) / (7 + 3/1.584962500721156)
+	assert.InDelta(t, expected, NDCG(ranked("c", "a"), judgments, 2), 1e-9)
+
+	// Duplicate chunks of the same document only count once
+	assert.InDelta(t, NDCG(ranked("a", "x"), judgments, 2), NDCG(ranked("a", "a"), judgments, 2), 1e-9)
+}
+
+func TestMRR(t *testing.T) {
+	judgments := map[string]float64{"b": 1}
+
+	assert.Equal(t, 1.0, MRR(ranked("b", "a"), judgments))
+	assert.Equal(t, 1.0/3, MRR(ranked("a", "c", "b"), judgments))
+	assert.Zero(t, MRR(ranked("a", "c"), judgments))
+	assert.InDelta(t, 2.0/3, Mean([]float64{1, 1.0 / 3}), 1e-9)
+	assert.Zero(t, Mean(nil))
+}
+
+func TestRecallAtK(t *testing.T) {
+	judgments := map[string]float64{"a": 1, "b": 1, "x": 0}
+
+	assert.Equal(t, 0.5, RecallAtK(ranked("a", "c", "b"), judgments, 2))
+	assert.Equal(t, 1.0, RecallAtK(ranked("a", "c", "b"), judgments, 0))
+	assert.Equal(t, 0.5, RecallAtK(ranked("a", "a"), judgments, 2))
+	assert.Zero(t, RecallAtK(ranked("a"), map[string]float64{"x": 0}, 1))
+}
@@ -0,0 +1,60 @@
+package rag
+
+// GraphConfidenceSignals are the measurements GraphRAG confidence is computed from. They are
+// reported in the query result metadata under GraphConfidenceSignalsMetadataKey.
+type GraphConfidenceSignals struct {
+	// EntitiesFound is the number of entities returned by the graph query
+	EntitiesFound int `json:"entities_found"`
+	// RelationshipsFound is the number of relationships returned by the graph query
+	RelationshipsFound int `json:"relationships_found"`
+	// QueryEntities is the number of entities extracted from the query
+	QueryEntities int `json:"query_entities"`
+	// MatchedEntities is the number of query entities found by ID or name in the graph results
+	MatchedEntities int `json:"matched_entities"`
+	// RetrievalScores are the scores reported by the graph query, if any
+	RetrievalScores []float64 `json:"retrieval_scores,omitempty"`
+}
+
+// GraphConfidenceSignalsMetadataKey is the QueryResult metadata key holding the
+// GraphConfidenceSignals of a GraphRAG query
+const GraphConfidenceSignalsMetadataKey = "confidence_signals"
+
+// EntityMatchRatio returns the fraction of query entities found in the graph, 0 without query entities
+func (s GraphConfidenceSignals) EntityMatchRatio() float64 {
+	if s.QueryEntities == 0 {
+		return 0
+	}
+	return float64(s.MatchedEntities) / float64(s.QueryEntities)
+}
+
+// MeanRetrievalScore returns the mean of the retrieval scores, 0 without scores
+func (s GraphConfidenceSignals) MeanRetrievalScore() float64 {
+	if len(s.RetrievalScores) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, score := range s.RetrievalScores {
+		total += score
+	}
+	return total / float64(len(s.RetrievalScores))
+}
+
+// DefaultGraphConfidence is the default GraphRAG confidence, in [0, 1]. It is 0 when no entity
+// was found, and otherwise
+//
+//	min(1, 0.1·min(entities, 10) + 0.3·entityMatchRatio + 0.1·relationships)
+//
+// When the graph query reports retrieval scores, the result is averaged with their mean.
+func DefaultGraphConfidence(s GraphConfidenceSignals) float64 {
+	if s.EntitiesFound == 0 {
+		return 0
+	}
+
+	confidence := 0.1*float64(min(s.EntitiesFound, 10)) + 0.3*s.EntityMatchRatio() + 0.1*float64(s.RelationshipsFound)
+	confidence = min(confidence, 1)
+
+	if len(s.RetrievalScores) > 0 {
+		confidence = (confidence + s.MeanRetrievalScore()) / 2
+	}
+	return confidence
+}
@@ -0,0 +1,30 @@
+package rag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// DocumentID returns the deterministic ID of a document: the first 32 hex digits of the
+// SHA-256 hash of its content and its "source" metadata. Ingesting the same document twice
+// yields the same ID, so it can be deduplicated, updated and deleted without an explicit ID.
+func DocumentID(doc Document) string {
+	h := sha256.New()
+	h.Write([]byte(doc.Content))
+	if source, ok := doc.Metadata["source"]; ok {
+		h.Write([]byte{0})
+		fmt.Fprint(h, source)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// EnsureIDs assigns DocumentID to the documents that have no ID, in place, and returns docs
+func EnsureIDs(docs []Document) []Document {
+	for i := range docs {
+		if docs[i].ID == "" {
+			docs[i].ID = DocumentID(docs[i])
+		}
+	}
+	return docs
+}
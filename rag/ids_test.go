@@ -0,0 +1,24 @@
+package rag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnsureIDs(t *testing.T) {
+	docs := EnsureIDs([]Document{
+		{Content: "hello"},
+		{Content: "hello"},
+		{Content: "hello", Metadata: map[string]any{"source": "a.txt"}},
+		{Content: "hello", Metadata: map[string]any{"source": "b.txt"}},
+		{ID: "explicit", Content: "hello"},
+	})
+
+	assert.Len(t, docs[0].ID, 32)
+	assert.Equal(t, docs[0].ID, docs[1].ID, "identical documents get the same ID")
+	assert.NotEqual(t, docs[0].ID, docs[2].ID, "the source is part of the ID")
+	assert.NotEqual(t, docs[2].ID, docs[3].ID)
+	assert.Equal(t, "explicit", docs[4].ID)
+	assert.Equal(t, DocumentID(Document{Content: "hello", Metadata: map[string]any{"source": "a.txt"}}), docs[2].ID)
+}
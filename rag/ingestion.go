@@ -0,0 +1,337 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IngestionEventType identifies the kind of an ingestion progress event
+type IngestionEventType string
+
+const (
+	// IngestionEventLoaded is emitted once the loader has returned all documents
+	IngestionEventLoaded IngestionEventType = "loaded"
+	// IngestionEventSkipped is emitted for documents that were already ingested
+	IngestionEventSkipped IngestionEventType = "skipped"
+	// IngestionEventEmptySkipped is emitted for empty documents skipped with SkipEmpty
+	IngestionEventEmptySkipped IngestionEventType = "empty_skipped"
+	// IngestionEventBatchStored is emitted after a batch of chunks was embedded and stored
+	IngestionEventBatchStored IngestionEventType = "batch_stored"
+	// IngestionEventDocumentIngested is emitted when all chunks of a document are stored
+	IngestionEventDocumentIngested IngestionEventType = "document_ingested"
+	// IngestionEventError is emitted when the pipeline stops because of an error
+	IngestionEventError IngestionEventType = "error"
+	// IngestionEventCompleted is emitted when all documents have been processed
+	IngestionEventCompleted IngestionEventType = "completed"
+)
+
+// IngestionEvent reports the progress of an ingestion run
+type IngestionEvent struct {
+	Type IngestionEventType
+	// DocumentID is the document the event refers to, if any
+	DocumentID string
+	// Chunks is the number of chunks stored by the batch or document
+	Chunks int
+	// Processed is the number of documents ingested or skipped so far
+	Processed int
+	// Total is the number of documents returned by the loader
+	Total int
+	// EmptySkipped is the number of empty documents skipped so far
+	EmptySkipped int
+	// Err is set for IngestionEventError
+	Err error
+}
+
+// IngestionTracker records which documents have been ingested so an interrupted
+// run can resume instead of restarting
+type IngestionTracker interface {
+	IsIngested(ctx context.Context, documentID string) (bool, error)
+	MarkIngested(ctx context.Context, documentID string) error
+}
+
+// IngestionOptions configures an IngestionPipeline
+type IngestionOptions struct {
+	// BatchSize is the number of chunks embedded per embedding call (default 32)
+	BatchSize int
+
+	// MinBatchInterval is the minimum time between embedding calls, to respect rate limits
+	MinBatchInterval time.Duration
+
+	// Tracker records ingested documents. Defaults to an in-memory tracker,
+	// use NewFileIngestionTracker to resume across process restarts.
+	Tracker IngestionTracker
+
+	// SkipEmpty skips documents and chunks whose content is empty or whitespace only,
+	// reporting each skipped document with an IngestionEventEmptySkipped event. Without it,
+	// an empty document stops the run with ErrEmptyDocument.
+	SkipEmpty bool
+}
+
+// IngestionPipeline loads, splits, embeds and stores documents
+type IngestionPipeline struct {
+	loader   DocumentLoader
+	splitter TextSplitter
+	embedder Embedder
+	store    VectorStore
+	opts     IngestionOptions
+}
+
+// NewIngestionPipeline creates a new ingestion pipeline.
+// splitter may be nil, in which case documents are stored as single chunks.
+func NewIngestionPipeline(loader DocumentLoader, splitter TextSplitter, embedder Embedder, store VectorStore, opts IngestionOptions) *IngestionPipeline {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 32
+	}
+	if opts.Tracker == nil {
+		opts.Tracker = NewMemoryIngestionTracker()
+	}
+	return &IngestionPipeline{
+		loader:   loader,
+		splitter: splitter,
+		embedder: embedder,
+		store:    store,
+		opts:     opts,
+	}
+}
+
+// Run ingests all documents from the loader and streams progress events.
+// The channel is closed after an IngestionEventCompleted or IngestionEventError event.
+// A document is marked as ingested only after all of its chunks are stored, so
+// running the pipeline again with the same tracker resumes where it stopped. Chunks have
+// deterministic IDs (those of the splitter, or the document ID and chunk index), and the
+// chunks of a document are deleted from the store before it is ingested, so a document
+// that failed midway is not stored twice.
+func (p *IngestionPipeline) Run(ctx context.Context) <-chan IngestionEvent {
+	events := make(chan IngestionEvent)
+	go func() {
+		defer close(events)
+		if err := p.run(ctx, events); err != nil {
+			emitIngestionEvent(ctx, events, IngestionEvent{Type: IngestionEventError, Err: err})
+		}
+	}()
+	return events
+}
+
+func (p *IngestionPipeline) run(ctx context.Context, events chan<- IngestionEvent) error {
+	docs, err := p.loader.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load documents: %w", err)
+	}
+	EnsureIDs(docs)
+	total := len(docs)
+	if !emitIngestionEvent(ctx, events, IngestionEvent{Type: IngestionEventLoaded, Total: total}) {
+		return ctx.Err()
+	}
+
+	var lastEmbed time.Time
+	emptySkipped := 0
+	for i, doc := range docs {
+		docID := doc.ID
+
+		ingested, err := p.opts.Tracker.IsIngested(ctx, docID)
+		if err != nil {
+			return fmt.Errorf("failed to check document %s: %w", docID, err)
+		}
+		if ingested {
+			if !emitIngestionEvent(ctx, events, IngestionEvent{Type: IngestionEventSkipped, DocumentID: docID, Processed: i + 1, Total: total}) {
+				return ctx.Err()
+			}
+			continue
+		}
+
+		if isBlank(doc.Content) {
+			if !p.opts.SkipEmpty {
+				return fmt.Errorf("failed to ingest document %s: %w", docID, ErrEmptyDocument)
+			}
+			emptySkipped++
+			if !emitIngestionEvent(ctx, events, IngestionEvent{Type: IngestionEventEmptySkipped, DocumentID: docID, Processed: i + 1, Total: total, EmptySkipped: emptySkipped}) {
+				return ctx.Err()
+			}
+			continue
+		}
+
+		chunks := []Document{doc}
+		if p.splitter != nil {
+			chunks = p.splitter.SplitDocuments(chunks)
+		}
+		chunkIDs := make([]string, len(chunks))
+		for j := range chunks {
+			if chunks[j].ID == "" {
+				chunks[j].ID = fmt.Sprintf("%s_chunk_%d", docID, j)
+			}
+			chunkIDs[j] = chunks[j].ID
+		}
+		if p.opts.SkipEmpty {
+			chunks = slices.DeleteFunc(chunks, func(chunk Document) bool { return isBlank(chunk.Content) })
+		}
+
+		// Remove the chunks stored by an earlier run that stopped inside this document
+		if err := p.store.Delete(ctx, chunkIDs); err != nil {
+			return fmt.Errorf("failed to remove stale chunks of document %s: %w", docID, err)
+		}
+
+		for start := 0; start < len(chunks); start += p.opts.BatchSize {
+			batch := chunks[start:min(start+p.opts.BatchSize, len(chunks))]
+
+			if wait := p.opts.MinBatchInterval - time.Since(lastEmbed); !lastEmbed.IsZero() && wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			if err := p.embedAndStore(ctx, batch); err != nil {
+				return fmt.Errorf("failed to ingest document %s: %w", docID, err)
+			}
+			lastEmbed = time.Now()
+
+			if !emitIngestionEvent(ctx, events, IngestionEvent{Type: IngestionEventBatchStored, DocumentID: docID, Chunks: len(batch), Processed: i, Total: total}) {
+				return ctx.Err()
+			}
+		}
+
+		if err := p.opts.Tracker.MarkIngested(ctx, docID); err != nil {
+			return fmt.Errorf("failed to mark document %s as ingested: %w", docID, err)
+		}
+		if !emitIngestionEvent(ctx, events, IngestionEvent{Type: IngestionEventDocumentIngested, DocumentID: docID, Chunks: len(chunks), Processed: i + 1, Total: total}) {
+			return ctx.Err()
+		}
+	}
+
+	emitIngestionEvent(ctx, events, IngestionEvent{Type: IngestionEventCompleted, Processed: total, Total: total, EmptySkipped: emptySkipped})
+	return nil
+}
+
+// isBlank reports whether content is empty or whitespace only
+func isBlank(content string) bool {
+	return strings.TrimSpace(content) == ""
+}
+
+// embedAndStore embeds a batch of chunks and adds them to the store
+func (p *IngestionPipeline) embedAndStore(ctx context.Context, batch []Document) error {
+	texts := make([]string, len(batch))
+	for i, chunk := range batch {
+		texts[i] = chunk.Content
+	}
+	embeddings, err := p.embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed chunks: %w", err)
+	}
+	if len(embeddings) != len(batch) {
+		return fmt.Errorf("embedder returned %d embeddings for %d chunks", len(embeddings), len(batch))
+	}
+
+	docs := make([]Document, len(batch))
+	for i, chunk := range batch {
+		chunk.Embedding = embeddings[i]
+		docs[i] = chunk
+	}
+	if err := p.store.Add(ctx, docs); err != nil {
+		return fmt.Errorf("failed to store chunks: %w", err)
+	}
+	return nil
+}
+
+// emitIngestionEvent sends an event, returning false if the context was cancelled
+func emitIngestionEvent(ctx context.Context, events chan<- IngestionEvent, event IngestionEvent) bool {
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// MemoryIngestionTracker tracks ingested documents in memory
+type MemoryIngestionTracker struct {
+	mu  sync.RWMutex
+	ids map[string]bool
+}
+
+// NewMemoryIngestionTracker creates a new MemoryIngestionTracker
+func NewMemoryIngestionTracker() *MemoryIngestionTracker {
+	return &MemoryIngestionTracker{ids: make(map[string]bool)}
+}
+
+// IsIngested reports whether the document has been ingested
+func (t *MemoryIngestionTracker) IsIngested(ctx context.Context, documentID string) (bool, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.ids[documentID], nil
+}
+
+// MarkIngested records the document as ingested
+func (t *MemoryIngestionTracker) MarkIngested(ctx context.Context, documentID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ids[documentID] = true
+	return nil
+}
+
+// FileIngestionTracker tracks ingested documents in a JSON file
+type FileIngestionTracker struct {
+	path string
+	mem  *MemoryIngestionTracker
+}
+
+// NewFileIngestionTracker creates a tracker backed by the JSON file at path,
+// loading previously ingested document IDs if the file exists
+func NewFileIngestionTracker(path string) (*FileIngestionTracker, error) {
+	t := &FileIngestionTracker{path: path, mem: NewMemoryIngestionTracker()}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ingestion tracker: %w", err)
+	}
+
+	var ids []string
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return nil, fmt.Errorf("failed to parse ingestion tracker: %w", err)
+	}
+	for _, id := range ids {
+		t.mem.ids[id] = true
+	}
+	return t, nil
+}
+
+// IsIngested reports whether the document has been ingested
+func (t *FileIngestionTracker) IsIngested(ctx context.Context, documentID string) (bool, error) {
+	return t.mem.IsIngested(ctx, documentID)
+}
+
+// MarkIngested records the document as ingested and persists the tracker file
+func (t *FileIngestionTracker) MarkIngested(ctx context.Context, documentID string) error {
+	t.mem.mu.Lock()
+	defer t.mem.mu.Unlock()
+	t.mem.ids[documentID] = true
+
+	ids := make([]string, 0, len(t.mem.ids))
+	for id := range t.mem.ids {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return fmt.Errorf("failed to encode ingestion tracker: %w", err)
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write ingestion tracker: %w", err)
+	}
+	if err := os.Rename(tmp, t.path); err != nil {
+		return fmt.Errorf("failed to write ingestion tracker: %w", err)
+	}
+	return nil
+}
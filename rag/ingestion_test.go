@@ -0,0 +1,196 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticLoader []Document
+
+func (l staticLoader) Load(ctx context.Context) ([]Document, error) {
+	return l, nil
+}
+
+type sentenceSplitter struct{}
+
+func (sentenceSplitter) SplitText(text string) []string {
+	return strings.Split(text, ". ")
+}
+
+func (s sentenceSplitter) SplitDocuments(documents []Document) []Document {
+	var chunks []Document
+	for _, doc := range documents {
+		for i, text := range s.SplitText(doc.Content) {
+			chunks = append(chunks, Document{ID: doc.ID + "_" + string(rune('a'+i)), Content: text})
+		}
+	}
+	return chunks
+}
+
+func (sentenceSplitter) JoinText(chunks []string) string {
+	return strings.Join(chunks, ". ")
+}
+
+type failingEmbedder struct {
+	failOn string
+	calls  int
+}
+
+func (e *failingEmbedder) EmbedDocument(ctx context.Context, text string) ([]float32, error) {
+	return []float32{1, 0}, nil
+}
+
+func (e *failingEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	result := make([][]float32, len(texts))
+	for i, text := range texts {
+		if e.failOn != "" && text == e.failOn {
+			return nil, errors.New("rate limited")
+		}
+		result[i] = []float32{1, 0}
+	}
+	return result, nil
+}
+
+func (e *failingEmbedder) GetDimension() int { return 2 }
+
+type recordingStore struct {
+	VectorStore
+	added []Document
+}
+
+func (s *recordingStore) Add(ctx context.Context, documents []Document) error {
+	s.added = append(s.added, documents...)
+	return nil
+}
+
+func (s *recordingStore) Delete(ctx context.Context, ids []string) error {
+	s.added = slices.DeleteFunc(s.added, func(doc Document) bool { return slices.Contains(ids, doc.ID) })
+	return nil
+}
+
+func collectIngestionEvents(ch <-chan IngestionEvent) []IngestionEvent {
+	var events []IngestionEvent
+	for e := range ch {
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestIngestionPipeline(t *testing.T) {
+	ctx := context.Background()
+	loader := staticLoader{
+		{ID: "d1", Content: "One. Two. Three"},
+		{ID: "d2", Content: "Four. Boom"},
+		{ID: "d3", Content: "Five"},
+	}
+	tracker, err := NewFileIngestionTracker(filepath.Join(t.TempDir(), "ingested.json"))
+	require.NoError(t, err)
+
+	// First run fails on the second document
+	embedder := &failingEmbedder{failOn: "Boom"}
+	store := &recordingStore{}
+	events := collectIngestionEvents(NewIngestionPipeline(loader, sentenceSplitter{}, embedder, store, IngestionOptions{BatchSize: 2, Tracker: tracker}).Run(ctx))
+
+	last := events[len(events)-1]
+	assert.Equal(t, IngestionEventError, last.Type)
+	assert.ErrorContains(t, last.Err, "d2")
+	assert.Equal(t, IngestionEventLoaded, events[0].Type)
+	assert.Equal(t, 3, events[0].Total)
+	assert.Len(t, store.added, 3)
+	assert.Equal(t, []float32{1, 0}, store.added[0].Embedding)
+	assert.Equal(t, 3, embedder.calls, "two batches for d1 and the failed batch for d2")
+
+	// Resume with a fresh tracker loaded from the same file
+	tracker, err = NewFileIngestionTracker(tracker.path)
+	require.NoError(t, err)
+	embedder.failOn = ""
+	store = &recordingStore{}
+	events = collectIngestionEvents(NewIngestionPipeline(loader, sentenceSplitter{}, embedder, store, IngestionOptions{BatchSize: 2, Tracker: tracker}).Run(ctx))
+
+	var types []IngestionEventType
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	assert.Equal(t, []IngestionEventType{
+		IngestionEventLoaded,
+		IngestionEventSkipped,
+		IngestionEventBatchStored, IngestionEventDocumentIngested,
+		IngestionEventBatchStored, IngestionEventDocumentIngested,
+		IngestionEventCompleted,
+	}, types)
+	assert.Len(t, store.added, 3)
+	assert.Equal(t, 3, events[len(events)-1].Processed)
+}
+
+func TestIngestionPipeline_ResumeMidDocument(t *testing.T) {
+	ctx := context.Background()
+	loader := staticLoader{{ID: "d1", Content: "One. Two. Three"}}
+	tracker := NewMemoryIngestionTracker()
+	store := &recordingStore{}
+
+	// The first batch of d1 is stored before the second one fails
+	embedder := &failingEmbedder{failOn: "Two"}
+	events := collectIngestionEvents(NewIngestionPipeline(loader, nil, embedder, store, IngestionOptions{}).Run(ctx))
+	require.Equal(t, IngestionEventCompleted, events[len(events)-1].Type)
+	store.added = nil
+
+	events = collectIngestionEvents(NewIngestionPipeline(loader, sentenceSplitter{}, embedder, store, IngestionOptions{BatchSize: 1, Tracker: tracker}).Run(ctx))
+	require.Equal(t, IngestionEventError, events[len(events)-1].Type)
+	require.Len(t, store.added, 1)
+
+	embedder.failOn = ""
+	events = collectIngestionEvents(NewIngestionPipeline(loader, sentenceSplitter{}, embedder, store, IngestionOptions{BatchSize: 1, Tracker: tracker}).Run(ctx))
+	require.Equal(t, IngestionEventCompleted, events[len(events)-1].Type)
+
+	var ids []string
+	for _, doc := range store.added {
+		ids = append(ids, doc.ID)
+	}
+	assert.Equal(t, []string{"d1_a", "d1_b", "d1_c"}, ids)
+}
+
+func TestIngestionPipeline_EmptyDocuments(t *testing.T) {
+	ctx := context.Background()
+	loader := staticLoader{
+		{ID: "d1", Content: "One. Two"},
+		{ID: "blank", Content: " \n\t"},
+		{ID: "d2", Content: "Three.  "},
+	}
+
+	// Empty documents stop the run by default
+	store := &recordingStore{}
+	events := collectIngestionEvents(NewIngestionPipeline(loader, nil, &failingEmbedder{}, store, IngestionOptions{}).Run(ctx))
+	last := events[len(events)-1]
+	assert.Equal(t, IngestionEventError, last.Type)
+	assert.ErrorIs(t, last.Err, ErrEmptyDocument)
+	assert.ErrorContains(t, last.Err, "blank")
+
+	// With SkipEmpty they are reported and counted, and blank chunks are dropped
+	store = &recordingStore{}
+	events = collectIngestionEvents(NewIngestionPipeline(loader, sentenceSplitter{}, &failingEmbedder{}, store, IngestionOptions{SkipEmpty: true}).Run(ctx))
+	last = events[len(events)-1]
+	assert.Equal(t, IngestionEventCompleted, last.Type)
+	assert.Equal(t, 1, last.EmptySkipped)
+
+	var skipped []string
+	for _, e := range events {
+		if e.Type == IngestionEventEmptySkipped {
+			skipped = append(skipped, e.DocumentID)
+		}
+	}
+	assert.Equal(t, []string{"blank"}, skipped)
+
+	var contents []string
+	for _, doc := range store.added {
+		contents = append(contents, doc.Content)
+	}
+	assert.Equal(t, []string{"One", "Two", "Three"}, contents)
+}
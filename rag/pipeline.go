@@ -6,6 +6,7 @@ import (
 	"maps"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/smallnest/langgraphgo/graph"
 	"github.com/tmc/langchaingo/llms"
@@ -25,18 +26,34 @@ type RAGState struct {
 
 // PipelineConfig configures a RAG pipeline
 type PipelineConfig struct {
-	// Retrieval configuration
+	// Retrieval configuration. TopK, ScoreThreshold and UseReranking can be overridden for
+	// a single query with the TopKKey, ScoreThresholdKey and UseRerankingKey input state keys.
 	TopK           int     // Number of documents to retrieve
 	ScoreThreshold float64 // Minimum relevance score
 	UseReranking   bool    // Whether to use reranking
 	UseFallback    bool    // Whether to use fallback search
 
+	// Filter restricts retrieval to documents whose metadata match, e.g.
+	// {"category": "Framework"}. It is passed to the retriever's RetrieveWithConfig;
+	// the vector store and graph retrievers apply it, others (such as BM25 and hybrid)
+	// ignore it. A "filter" map in the input state overrides it for a single query.
+	Filter map[string]any
+
 	// Generation configuration
 	SystemPrompt     string
 	IncludeCitations bool
 	MaxTokens        int
 	Temperature      float64
 
+	// MaxContextChars caps the length of the context passed to the LLM (0 means no limit).
+	// Documents are kept in rank order; the first one that does not fit is truncated and
+	// the rest are dropped, as reported under ContextTrimMetadataKey in the result metadata.
+	MaxContextChars int
+
+	// PostProcessors are applied in order to the generated answer, e.g. to redact PII,
+	// enforce a maximum length or convert to Markdown
+	PostProcessors []AnswerPostProcessor
+
 	// Components
 	Loader      RAGDocumentLoader
 	Splitter    RAGTextSplitter
@@ -47,6 +64,33 @@ type PipelineConfig struct {
 	LLM         llms.Model
 }
 
+// Input state keys overriding the PipelineConfig for a single query, e.g.
+// map[string]any{"query": q, TopKKey: 10, UseRerankingKey: true}
+const (
+	// TopKKey overrides PipelineConfig.TopK (an int)
+	TopKKey = "top_k"
+	// ScoreThresholdKey overrides PipelineConfig.ScoreThreshold (a float64)
+	ScoreThresholdKey = "score_threshold"
+	// UseRerankingKey overrides PipelineConfig.UseReranking (a bool). It only takes effect
+	// in pipelines built with a Reranker.
+	UseRerankingKey = "use_reranking"
+)
+
+// ContextTrimMetadataKey is the result metadata key holding a *ContextTrim when the
+// context was cut to PipelineConfig.MaxContextChars
+const ContextTrimMetadataKey = "context_trimmed"
+
+// ContextTrim reports how the generation context was cut to fit MaxContextChars.
+// Documents are identified by their 1-based citation number.
+type ContextTrim struct {
+	MaxChars  int
+	Truncated []int
+	Dropped   []int
+}
+
+// AnswerPostProcessor transforms a generated answer before it is returned
+type AnswerPostProcessor func(ctx context.Context, answer string) (string, error)
+
 // DefaultPipelineConfig returns a default RAG configuration
 func DefaultPipelineConfig() *PipelineConfig {
 	return &PipelineConfig{
@@ -104,7 +148,9 @@ func (s *ragStateSchema) Update(current, new map[string]any) (map[string]any, er
 	return result, nil
 }
 
-// BuildBasicRAG builds a basic RAG pipeline: Retrieve -> Generate
+// BuildBasicRAG builds a basic RAG pipeline: Retrieve -> Generate.
+// Like the other builders, it makes Compile reject cycles (see graph.StateGraph.RequireAcyclic),
+// including ones added later through GetGraph.
 func (p *RAGPipeline) BuildBasicRAG() error {
 	if p.config.Retriever == nil {
 		return fmt.Errorf("retriever is required for basic RAG")
@@ -121,6 +167,7 @@ func (p *RAGPipeline) BuildBasicRAG() error {
 
 	// Build pipeline
 	p.graph.SetEntryPoint("retrieve")
+	p.graph.RequireAcyclic()
 	p.graph.AddEdge("retrieve", "generate")
 	p.graph.AddEdge("generate", graph.END)
 
@@ -139,8 +186,8 @@ func (p *RAGPipeline) BuildAdvancedRAG() error {
 	// Add retrieval node
 	p.graph.AddNode("retrieve", "Document retrieval node", p.retrieveNode)
 
-	// Add reranking node if enabled
-	if p.config.UseReranking && p.config.Reranker != nil {
+	// Add reranking node if a reranker is set; it runs when reranking is enabled for the query
+	if p.config.Reranker != nil {
 		p.graph.AddNode("rerank", "Document reranking node", p.rerankNode)
 	}
 
@@ -154,9 +201,16 @@ func (p *RAGPipeline) BuildAdvancedRAG() error {
 
 	// Build pipeline
 	p.graph.SetEntryPoint("retrieve")
+	p.graph.RequireAcyclic()
 
-	if p.config.UseReranking && p.config.Reranker != nil {
-		p.graph.AddEdge("retrieve", "rerank")
+	if p.config.Reranker != nil {
+		p.graph.AddConditionalEdge("retrieve", func(ctx context.Context, state map[string]any) string {
+			if p.useReranking(state) {
+				return "rerank"
+			}
+			return "generate"
+		})
+		p.graph.SetConditionalTargets("retrieve", "rerank", "generate")
 		p.graph.AddEdge("rerank", "generate")
 	} else {
 		p.graph.AddEdge("retrieve", "generate")
@@ -202,12 +256,13 @@ func (p *RAGPipeline) BuildConditionalRAG() error {
 
 	// Build pipeline with conditional routing
 	p.graph.SetEntryPoint("retrieve")
+	p.graph.RequireAcyclic()
 	p.graph.AddEdge("retrieve", "rerank")
 
 	// Conditional edge based on relevance score
 	p.graph.AddConditionalEdge("rerank", func(ctx context.Context, state map[string]any) string {
 		rankedDocs, _ := state["ranked_documents"].([]DocumentSearchResult)
-		if len(rankedDocs) > 0 && rankedDocs[0].Score >= p.config.ScoreThreshold {
+		if len(rankedDocs) > 0 && rankedDocs[0].Score >= p.scoreThreshold(state) {
 			return "generate"
 		}
 		if p.config.UseFallback {
@@ -215,6 +270,11 @@ func (p *RAGPipeline) BuildConditionalRAG() error {
 		}
 		return "generate"
 	})
+	if p.config.UseFallback {
+		p.graph.SetConditionalTargets("rerank", "generate", "fallback_search")
+	} else {
+		p.graph.SetConditionalTargets("rerank", "generate")
+	}
 
 	if p.config.UseFallback {
 		p.graph.AddEdge("fallback_search", "generate")
@@ -245,9 +305,33 @@ func (p *RAGPipeline) GetGraph() *graph.StateGraph[map[string]any] {
 func (p *RAGPipeline) retrieveNode(ctx context.Context, state map[string]any) (map[string]any, error) {
 	query, _ := state["query"].(string)
 
-	docs, err := p.config.Retriever.Retrieve(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("retrieval failed: %w", err)
+	filter := p.config.Filter
+	if f, ok := state["filter"].(map[string]any); ok {
+		filter = f
+	}
+
+	_, topKOverridden := state[TopKKey]
+
+	var docs []Document
+	if len(filter) > 0 || topKOverridden {
+		results, err := p.config.Retriever.RetrieveWithConfig(ctx, query, &RetrievalConfig{
+			K:          p.topK(state),
+			SearchType: "similarity",
+			Filter:     filter,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("retrieval failed: %w", err)
+		}
+		docs = make([]Document, len(results))
+		for i, result := range results {
+			docs[i] = result.Document
+		}
+	} else {
+		var err error
+		docs, err = p.config.Retriever.Retrieve(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("retrieval failed: %w", err)
+		}
 	}
 
 	state["retrieved_documents"] = convertToRAGDocuments(docs)
@@ -256,6 +340,36 @@ func (p *RAGPipeline) retrieveNode(ctx context.Context, state map[string]any) (m
 	return state, nil
 }
 
+// topK returns the number of documents to retrieve for the query in state
+func (p *RAGPipeline) topK(state map[string]any) int {
+	switch v := state[TopKKey].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return p.config.TopK
+}
+
+// scoreThreshold returns the minimum relevance score for the query in state
+func (p *RAGPipeline) scoreThreshold(state map[string]any) float64 {
+	switch v := state[ScoreThresholdKey].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+	return p.config.ScoreThreshold
+}
+
+// useReranking reports whether to rerank the documents for the query in state
+func (p *RAGPipeline) useReranking(state map[string]any) bool {
+	if v, ok := state[UseRerankingKey].(bool); ok {
+		return v
+	}
+	return p.config.UseReranking
+}
+
 func (p *RAGPipeline) rerankNode(ctx context.Context, state map[string]any) (map[string]any, error) {
 	query, _ := state["query"].(string)
 	retrievedDocs, _ := state["retrieved_documents"].([]RAGDocument)
@@ -322,7 +436,15 @@ func (p *RAGPipeline) generateNode(ctx context.Context, state map[string]any) (m
 		}
 		contextParts = append(contextParts, fmt.Sprintf("[%d] Source: %s\nContent: %s", i+1, source, doc.Content))
 	}
-	contextStr := strings.Join(contextParts, "\n\n")
+	contextStr, trim := fitContext(contextParts, p.config.MaxContextChars)
+	if trim != nil {
+		metadata := make(map[string]any)
+		if existing, ok := state["metadata"].(map[string]any); ok {
+			maps.Copy(metadata, existing)
+		}
+		metadata[ContextTrimMetadataKey] = trim
+		state["metadata"] = metadata
+	}
 
 	// Build prompt
 	prompt := fmt.Sprintf("Context:\n%s\n\nQuestion: %s\n\nAnswer:", contextStr, query)
@@ -332,20 +454,68 @@ func (p *RAGPipeline) generateNode(ctx context.Context, state map[string]any) (m
 		llms.TextParts("human", prompt),
 	}
 
-	// Generate answer
-	response, err := p.config.LLM.GenerateContent(ctx, messages)
+	// Generate answer, streaming the chunks when the pipeline runs through Stream
+	var options []llms.CallOption
+	if sink, ok := ctx.Value(answerChunkSinkKey{}).(answerChunkSink); ok {
+		options = append(options, llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+			graph.ReportTokens(ctx, chunk)
+			return sink(ctx, chunk)
+		}))
+	}
+	response, err := p.config.LLM.GenerateContent(ctx, messages, options...)
 	if err != nil {
 		return nil, fmt.Errorf("generation failed: %w", err)
 	}
 
 	if len(response.Choices) > 0 {
-		state["answer"] = response.Choices[0].Content
+		answer := response.Choices[0].Content
+		for i, process := range p.config.PostProcessors {
+			if answer, err = process(ctx, answer); err != nil {
+				return nil, fmt.Errorf("answer post-processor %d failed: %w", i, err)
+			}
+		}
+		state["answer"] = answer
 	}
 	state["context"] = contextStr
 
 	return state, nil
 }
 
+// fitContext joins the context parts in order, cutting them to at most maxChars characters.
+// It returns a nil trim when everything fits.
+func fitContext(parts []string, maxChars int) (string, *ContextTrim) {
+	const separator = "\n\n"
+	joined := strings.Join(parts, separator)
+	if maxChars <= 0 || utf8.RuneCountInString(joined) <= maxChars {
+		return joined, nil
+	}
+
+	trim := &ContextTrim{MaxChars: maxChars}
+	var sb strings.Builder
+	remaining := maxChars
+	for i, part := range parts {
+		if i > 0 {
+			remaining -= len(separator)
+		}
+		if remaining <= 0 {
+			trim.Dropped = append(trim.Dropped, i+1)
+			continue
+		}
+		if i > 0 {
+			sb.WriteString(separator)
+		}
+
+		runes := []rune(part)
+		if len(runes) > remaining {
+			runes = runes[:remaining]
+			trim.Truncated = append(trim.Truncated, i+1)
+		}
+		sb.WriteString(string(runes))
+		remaining -= len(runes)
+	}
+	return sb.String(), trim
+}
+
 func (p *RAGPipeline) formatCitationsNode(ctx context.Context, state map[string]any) (map[string]any, error) {
 	documents, _ := state["documents"].([]RAGDocument)
 
@@ -2,9 +2,15 @@ package rag
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/smallnest/langgraphgo/graph"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tmc/langchaingo/llms"
 )
 
@@ -86,6 +92,57 @@ func TestRAGPipelineNodes(t *testing.T) {
 		assert.Equal(t, "Mock Answer", answer)
 	})
 
+	t.Run("Generate Node Post-Processors", func(t *testing.T) {
+		config := DefaultPipelineConfig()
+		config.LLM = llm
+		config.PostProcessors = []AnswerPostProcessor{
+			func(ctx context.Context, answer string) (string, error) {
+				return strings.ReplaceAll(answer, "Mock", "[REDACTED]"), nil
+			},
+			func(ctx context.Context, answer string) (string, error) {
+				return "**" + answer + "**", nil
+			},
+		}
+		state := map[string]any{"query": "test", "documents": []RAGDocument{}}
+
+		res, err := NewRAGPipeline(config).generateNode(ctx, state)
+		require.NoError(t, err)
+		assert.Equal(t, "**[REDACTED] Answer**", res["answer"])
+
+		config.PostProcessors = append(config.PostProcessors, func(ctx context.Context, answer string) (string, error) {
+			return "", assert.AnError
+		})
+		_, err = NewRAGPipeline(config).generateNode(ctx, state)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.ErrorContains(t, err, "post-processor 2")
+	})
+
+	t.Run("Generate Node Max Context", func(t *testing.T) {
+		config := DefaultPipelineConfig()
+		config.LLM = llm
+		config.MaxContextChars = 60
+		state := map[string]any{
+			"query": "test",
+			"documents": []RAGDocument{
+				{Content: "best match", Metadata: map[string]any{"source": "a"}},
+				{Content: strings.Repeat("long chunk ", 10), Metadata: map[string]any{"source": "b"}},
+				{Content: "third", Metadata: map[string]any{"source": "c"}},
+			},
+			"metadata": map[string]any{"fallback_used": false},
+		}
+
+		res, err := NewRAGPipeline(config).generateNode(ctx, state)
+		require.NoError(t, err)
+		contextStr := res["context"].(string)
+		assert.Len(t, contextStr, 60)
+		assert.Contains(t, contextStr, "best match")
+		assert.NotContains(t, contextStr, "third")
+
+		metadata := res["metadata"].(map[string]any)
+		assert.Equal(t, false, metadata["fallback_used"])
+		assert.Equal(t, &ContextTrim{MaxChars: 60, Truncated: []int{2}, Dropped: []int{3}}, metadata[ContextTrimMetadataKey])
+	})
+
 	t.Run("Format Citations Node", func(t *testing.T) {
 		state := map[string]any{
 			"documents": []RAGDocument{{Metadata: map[string]any{"source": "src1"}}},
@@ -185,3 +242,267 @@ func TestCompositeEngine(t *testing.T) {
 		assert.Equal(t, 0.5, wAgg.Confidence)
 	})
 }
+
+func TestRAGPipelineQuery(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.LLM = &mockLLM{}
+	config.Retriever = &mockRetriever{
+		docs: []Document{{Content: "Context doc 1", Metadata: map[string]any{"source": "src1"}}},
+	}
+	p := NewRAGPipeline(config)
+	require.NoError(t, p.BuildAdvancedRAG())
+
+	result, err := p.Query(context.Background(), "test")
+	require.NoError(t, err)
+	assert.Equal(t, "test", result.Query)
+	assert.Equal(t, "Mock Answer", result.Answer)
+	require.Len(t, result.Documents, 1)
+	assert.Equal(t, "Context doc 1", result.Documents[0].Content)
+	require.Len(t, result.Citations, 1)
+	assert.Contains(t, result.Citations[0], "src1")
+}
+
+type recordingRetriever struct {
+	mockRetriever
+	k int
+}
+
+func (r *recordingRetriever) RetrieveWithConfig(ctx context.Context, query string, config *RetrievalConfig) ([]DocumentSearchResult, error) {
+	r.k = config.K
+	return r.mockRetriever.RetrieveWithConfig(ctx, query, config)
+}
+
+type countingReranker struct {
+	calls int
+}
+
+func (r *countingReranker) Rerank(ctx context.Context, query string, documents []DocumentSearchResult) ([]DocumentSearchResult, error) {
+	r.calls++
+	return documents, nil
+}
+
+func TestRAGPipelineQueryWithOverrides(t *testing.T) {
+	retriever := &recordingRetriever{mockRetriever: mockRetriever{docs: []Document{{Content: "doc"}}}}
+	reranker := &countingReranker{}
+	config := DefaultPipelineConfig()
+	config.LLM = &mockLLM{}
+	config.Retriever = retriever
+	config.Reranker = reranker
+	p := NewRAGPipeline(config)
+	require.NoError(t, p.BuildAdvancedRAG())
+
+	_, err := p.Query(context.Background(), "test")
+	require.NoError(t, err)
+	assert.Equal(t, 0, retriever.k)
+	assert.Equal(t, 0, reranker.calls)
+
+	_, err = p.QueryWithOverrides(context.Background(), "test", map[string]any{TopKKey: 10, UseRerankingKey: true})
+	require.NoError(t, err)
+	assert.Equal(t, 10, retriever.k)
+	assert.Equal(t, 1, reranker.calls)
+}
+
+func TestRAGPipelineScoreThresholdOverride(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.LLM = &mockLLM{}
+	config.Retriever = &mockRetriever{docs: []Document{{Content: "doc"}}}
+	config.UseFallback = true
+	config.ScoreThreshold = 0.5
+	p := NewRAGPipeline(config)
+	require.NoError(t, p.BuildConditionalRAG())
+
+	// The first document gets a score of 1.0 without a reranker
+	result, err := p.Query(context.Background(), "test")
+	require.NoError(t, err)
+	assert.Nil(t, result.Metadata["fallback_used"])
+
+	result, err = p.QueryWithOverrides(context.Background(), "test", map[string]any{ScoreThresholdKey: 1.5})
+	require.NoError(t, err)
+	assert.Equal(t, true, result.Metadata["fallback_used"])
+}
+
+func TestRAGPipelineStream(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.LLM = &mockLLM{}
+	config.Retriever = &mockRetriever{docs: []Document{{ID: "1", Content: "LangGraph docs"}}}
+	p := NewRAGPipeline(config)
+	require.NoError(t, p.BuildBasicRAG())
+
+	var streamable graph.Streamable[map[string]any] = p
+	res := streamable.Stream(context.Background(), map[string]any{"query": "What is LangGraph?"})
+
+	var nodes []string
+	for event := range res.Events {
+		nodes = append(nodes, event.NodeName)
+	}
+	assert.Equal(t, []string{"retrieve", "generate"}, nodes)
+	assert.Equal(t, "Mock Answer", ResultFromState(<-res.Result).Answer)
+
+	// A pipeline without nodes fails to compile and reports it on the error channel
+	res = NewRAGPipeline(config).Stream(context.Background(), map[string]any{"query": "q"})
+	assert.Error(t, <-res.Errors)
+	<-res.Done
+}
+
+// streamingLLM streams "chunk " every millisecond up to max times, stopping when the
+// streaming function fails
+type streamingLLM struct {
+	max     int
+	emitted atomic.Int32
+	stopped chan error
+}
+
+func (m *streamingLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	var answer strings.Builder
+	for range m.max {
+		if opts.StreamingFunc != nil {
+			if err := opts.StreamingFunc(ctx, []byte("chunk ")); err != nil {
+				m.stopped <- err
+				return nil, err
+			}
+		}
+		m.emitted.Add(1)
+		answer.WriteString("chunk ")
+		time.Sleep(time.Millisecond)
+	}
+	m.stopped <- nil
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: answer.String()}}}, nil
+}
+
+func (m *streamingLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return "", nil
+}
+
+func TestRAGPipelineStreamAnswer(t *testing.T) {
+	newPipeline := func(llm llms.Model) *RAGPipeline {
+		config := DefaultPipelineConfig()
+		config.LLM = llm
+		config.Retriever = &mockRetriever{docs: []Document{{ID: "1", Content: "LangGraph docs"}}}
+		p := NewRAGPipeline(config)
+		require.NoError(t, p.BuildBasicRAG())
+		return p
+	}
+
+	t.Run("Chunks then completion", func(t *testing.T) {
+		llm := &streamingLLM{max: 3, stopped: make(chan error, 1)}
+		res := newPipeline(llm).Stream(context.Background(), map[string]any{"query": "q"})
+
+		var events []string
+		for event := range res.Events {
+			if event.Event == graph.EventToken {
+				events = append(events, event.Metadata[AnswerChunkMetadataKey].(string))
+			} else {
+				events = append(events, event.NodeName)
+			}
+		}
+		assert.Equal(t, []string{"retrieve", "chunk ", "chunk ", "chunk ", "generate"}, events)
+		assert.Equal(t, "chunk chunk chunk ", ResultFromState(<-res.Result).Answer)
+		assert.NoError(t, <-res.Errors)
+	})
+
+	t.Run("Cancel mid-stream", func(t *testing.T) {
+		llm := &streamingLLM{max: 1000, stopped: make(chan error, 1)}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		res := newPipeline(llm).Stream(ctx, map[string]any{"query": "q"})
+
+		chunks := 0
+		for event := range res.Events {
+			if event.Event != graph.EventToken {
+				continue
+			}
+			chunks++
+			if chunks == 3 {
+				cancel()
+				break
+			}
+		}
+
+		select {
+		case err := <-llm.stopped:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(time.Second):
+			t.Fatal("generation was not aborted")
+		}
+		assert.Less(t, int(llm.emitted.Load()), llm.max)
+
+		<-res.Done
+		for event := range res.Events {
+			assert.NotEqual(t, graph.EventToken, event.Event, "no chunks may arrive after cancellation")
+		}
+		err := <-res.Errors
+		assert.True(t, errors.Is(err, ErrCancelled), "got %v", err)
+		assert.ErrorIs(t, err, context.Canceled)
+		_, ok := <-res.Result
+		assert.False(t, ok)
+	})
+}
+
+func TestFitContext(t *testing.T) {
+	parts := []string{"héllo", "world"}
+
+	joined, trim := fitContext(parts, 0)
+	assert.Equal(t, "héllo\n\nworld", joined)
+	assert.Nil(t, trim)
+
+	joined, trim = fitContext(parts, 12)
+	assert.Equal(t, "héllo\n\nworld", joined)
+	assert.Nil(t, trim)
+
+	joined, trim = fitContext(parts, 3)
+	assert.Equal(t, "hél", joined)
+	assert.Equal(t, &ContextTrim{MaxChars: 3, Truncated: []int{1}, Dropped: []int{2}}, trim)
+}
+
+// filterRetriever records the retrieval config and keeps only documents matching the filter
+type filterRetriever struct {
+	mockRetriever
+	config *RetrievalConfig
+}
+
+func (f *filterRetriever) RetrieveWithConfig(ctx context.Context, query string, config *RetrievalConfig) ([]DocumentSearchResult, error) {
+	f.config = config
+	var results []DocumentSearchResult
+	for _, doc := range f.docs {
+		if doc.Metadata["category"] == config.Filter["category"] {
+			results = append(results, DocumentSearchResult{Document: doc, Score: 0.9})
+		}
+	}
+	return results, nil
+}
+
+func TestRAGPipelineFilter(t *testing.T) {
+	retriever := &filterRetriever{mockRetriever: mockRetriever{docs: []Document{
+		{Content: "LangGraph", Metadata: map[string]any{"category": "Framework"}},
+		{Content: "RAG", Metadata: map[string]any{"category": "Technique"}},
+	}}}
+	config := DefaultPipelineConfig()
+	config.LLM = &mockLLM{}
+	config.Retriever = retriever
+	config.Filter = map[string]any{"category": "Technique"}
+	p := NewRAGPipeline(config)
+	require.NoError(t, p.BuildBasicRAG())
+
+	result, err := p.Query(context.Background(), "q")
+	require.NoError(t, err)
+	require.Len(t, result.Documents, 1)
+	assert.Equal(t, "RAG", result.Documents[0].Content)
+	assert.Equal(t, config.TopK, retriever.config.K)
+
+	result, err = p.QueryWithFilter(context.Background(), "q", map[string]any{"category": "Framework"})
+	require.NoError(t, err)
+	require.Len(t, result.Documents, 1)
+	assert.Equal(t, "LangGraph", result.Documents[0].Content)
+
+	// Without a filter the plain Retrieve path is used
+	config.Filter = nil
+	retriever.config = nil
+	result, err = p.Query(context.Background(), "q")
+	require.NoError(t, err)
+	assert.Len(t, result.Documents, 2)
+	assert.Nil(t, retriever.config)
+}
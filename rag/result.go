@@ -0,0 +1,247 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+// Result is the typed result of a RAG pipeline run
+type Result struct {
+	Query           string
+	Answer          string
+	Context         string
+	Documents       []RAGDocument
+	RankedDocuments []DocumentSearchResult
+	Citations       []string
+	Metadata        map[string]any
+}
+
+// ResultFromState decodes a RAG pipeline state into a Result.
+// Missing or mistyped keys are left empty.
+func ResultFromState(state map[string]any) *Result {
+	result := &Result{}
+	result.Query, _ = state["query"].(string)
+	result.Answer, _ = state["answer"].(string)
+	result.Context, _ = state["context"].(string)
+	result.Citations, _ = state["citations"].([]string)
+	result.Metadata, _ = state["metadata"].(map[string]any)
+
+	switch docs := state["documents"].(type) {
+	case []RAGDocument:
+		result.Documents = docs
+	case []Document:
+		result.Documents = convertToRAGDocuments(docs)
+	}
+
+	switch ranked := state["ranked_documents"].(type) {
+	case []DocumentSearchResult:
+		result.RankedDocuments = ranked
+	case []Document:
+		result.RankedDocuments = make([]DocumentSearchResult, len(ranked))
+		for i, doc := range ranked {
+			result.RankedDocuments[i] = DocumentSearchResult{Document: doc}
+		}
+	}
+
+	return result
+}
+
+// Query runs the pipeline for a single query and returns a typed result.
+// Use Compile and Invoke directly when the map state is needed for graph interop.
+func (p *RAGPipeline) Query(ctx context.Context, query string) (*Result, error) {
+	runnable, err := p.Compile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile pipeline: %w", err)
+	}
+
+	state, err := runnable.Invoke(ctx, map[string]any{"query": query})
+	if err != nil {
+		return nil, err
+	}
+	return ResultFromState(state), nil
+}
+
+// QueryWithFilter is Query restricted to documents whose metadata match filter,
+// overriding PipelineConfig.Filter
+func (p *RAGPipeline) QueryWithFilter(ctx context.Context, query string, filter map[string]any) (*Result, error) {
+	runnable, err := p.Compile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile pipeline: %w", err)
+	}
+
+	state, err := runnable.Invoke(ctx, map[string]any{"query": query, "filter": filter})
+	if err != nil {
+		return nil, err
+	}
+	return ResultFromState(state), nil
+}
+
+// QueryWithOverrides is Query with PipelineConfig settings overridden for this query by
+// the input state keys TopKKey, ScoreThresholdKey, UseRerankingKey and "filter"
+func (p *RAGPipeline) QueryWithOverrides(ctx context.Context, query string, overrides map[string]any) (*Result, error) {
+	runnable, err := p.Compile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile pipeline: %w", err)
+	}
+
+	input := maps.Clone(overrides)
+	if input == nil {
+		input = make(map[string]any)
+	}
+	input["query"] = query
+
+	state, err := runnable.Invoke(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return ResultFromState(state), nil
+}
+
+// AnswerChunkMetadataKey is the metadata key holding the text of a graph.EventToken event
+// streamed by the generate node
+const AnswerChunkMetadataKey = "chunk"
+
+type answerChunkSinkKey struct{}
+
+// answerChunkSink receives the answer chunks streamed by the generate node
+type answerChunkSink func(ctx context.Context, chunk []byte) error
+
+// Stream runs the pipeline in the background and streams an event after each node, so the
+// pipeline can be consumed like any graph.Streamable. The input state holds the query under
+// "query"; a pipeline that fails to compile reports the error on the Errors channel.
+//
+// The answer is streamed as it is generated: each chunk is a graph.EventToken event from the
+// "generate" node with the text under AnswerChunkMetadataKey, before post-processing. If ctx
+// is cancelled or Cancel is called before the run completes, the in-flight LLM call is
+// aborted, events not yet read are discarded, and Errors reports an error matching
+// ErrCancelled instead of a value on Result.
+func (p *RAGPipeline) Stream(ctx context.Context, input map[string]any) *graph.StreamResult[map[string]any] {
+	runnable, err := p.Compile()
+	if err != nil {
+		events := make(chan graph.StreamEvent[map[string]any])
+		results := make(chan map[string]any)
+		errs := make(chan error, 1)
+		done := make(chan struct{})
+		errs <- fmt.Errorf("failed to compile pipeline: %w", err)
+		close(events)
+		close(results)
+		close(errs)
+		close(done)
+		return &graph.StreamResult[map[string]any]{
+			Events: events,
+			Result: results,
+			Errors: errs,
+			Done:   done,
+			Cancel: func() {},
+		}
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	// The generate node waits on forwarded until its chunk is queued, so the chunk precedes
+	// the node's completion event
+	chunks := make(chan string)
+	forwarded := make(chan struct{})
+	sink := answerChunkSink(func(ctx context.Context, chunk []byte) error {
+		select {
+		case chunks <- string(chunk):
+		case <-streamCtx.Done():
+			return streamCtx.Err()
+		}
+		select {
+		case <-forwarded:
+			return nil
+		case <-streamCtx.Done():
+			return streamCtx.Err()
+		}
+	})
+	inner := runnable.Stream(context.WithValue(streamCtx, answerChunkSinkKey{}, sink), input)
+
+	events := make(chan graph.StreamEvent[map[string]any], graph.DefaultStreamConfig().BufferSize)
+	results := make(chan map[string]any, 1)
+	errs := make(chan error, 1)
+	done := make(chan struct{})
+
+	go func() {
+		defer func() {
+			close(events)
+			close(results)
+			close(errs)
+			close(done)
+		}()
+
+		send := func(event graph.StreamEvent[map[string]any]) {
+			if streamCtx.Err() != nil {
+				return
+			}
+			select {
+			case events <- event:
+			case <-streamCtx.Done():
+			}
+		}
+
+		for innerEvents := inner.Events; innerEvents != nil; {
+			select {
+			case event, ok := <-innerEvents:
+				if !ok {
+					innerEvents = nil
+					continue
+				}
+				send(event)
+			case chunk := <-chunks:
+				// Node events are emitted before the next node starts, so any that are
+				// buffered precede this chunk
+				for drained := false; !drained; {
+					select {
+					case event, ok := <-innerEvents:
+						if !ok {
+							innerEvents = nil
+							drained = true
+							continue
+						}
+						send(event)
+					default:
+						drained = true
+					}
+				}
+				send(graph.StreamEvent[map[string]any]{
+					Timestamp: time.Now(),
+					NodeName:  "generate",
+					Event:     graph.EventToken,
+					Metadata:  map[string]any{AnswerChunkMetadataKey: chunk},
+				})
+				select {
+				case forwarded <- struct{}{}:
+				case <-streamCtx.Done():
+				}
+			}
+		}
+
+		if err, ok := <-inner.Errors; ok {
+			if streamCtx.Err() != nil {
+				// Discard the events the consumer has not read yet
+				for len(events) > 0 {
+					select {
+					case <-events:
+					default:
+					}
+				}
+				err = fmt.Errorf("%w: %w", ErrCancelled, err)
+			}
+			errs <- err
+		} else if result, ok := <-inner.Result; ok {
+			results <- result
+		}
+	}()
+
+	return &graph.StreamResult[map[string]any]{
+		Events: events,
+		Result: results,
+		Errors: errs,
+		Done:   done,
+		Cancel: cancel,
+	}
+}
@@ -99,6 +99,7 @@ func NewBM25Retriever(documents []rag.Document, config BM25Config) (*BM25Retriev
 }
 
 // NewBM25RetrieverWithTokenizer creates a BM25 retriever with custom tokenizer
+// (e.g. NewEnglishTokenizer for stop-word removal and stemming)
 func NewBM25RetrieverWithTokenizer(documents []rag.Document, config BM25Config, tok tokenizer.Tokenizer) (*BM25Retriever, error) {
 	if config.K1 == 0 {
 		config.K1 = 1.5
@@ -19,12 +19,21 @@ type CohereRerankerConfig struct {
 	// Model is the Cohere rerank model to use
 	// Options: "rerank-v3.5", "rerank-english-v3.0", "rerank-multilingual-v3.0"
 	Model string
-	// TopK is the number of documents to return
+	// TopK is the number of documents to return (sent as top_n)
 	TopK int
+	// MaxDocuments caps the number of candidates sent in a single request. Larger candidate
+	// sets are reranked in batches whose results are merged by score. 0 means no limit.
+	MaxDocuments int
+	// MinCandidates makes Rerank return the candidates unchanged, without calling the API,
+	// when there are fewer of them. 0 always reranks.
+	MinCandidates int
 	// APIBase is the custom API base URL (optional)
 	APIBase string
-	// Timeout is the HTTP request timeout
+	// Timeout is the HTTP request timeout, including retries
 	Timeout time.Duration
+	// HTTPClient overrides the default client, which retries transient failures
+	// (see httputil.NewResilientClient)
+	HTTPClient *http.Client
 }
 
 // DefaultCohereRerankerConfig returns the default configuration for Cohere reranker
@@ -51,7 +60,9 @@ func NewCohereReranker(apiKey string, config CohereRerankerConfig) *CohereRerank
 		apiKey = os.Getenv("COHERE_API_KEY")
 	}
 	if config.Model == "" {
+		client := config.HTTPClient
 		config = DefaultCohereRerankerConfig()
+		config.HTTPClient = client
 	}
 	if config.Timeout == 0 {
 		config.Timeout = 30 * time.Second
@@ -62,9 +73,7 @@ func NewCohereReranker(apiKey string, config CohereRerankerConfig) *CohereRerank
 
 	return &CohereReranker{
 		apiKey: apiKey,
-		client: &http.Client{
-			Timeout: config.Timeout,
-		},
+		client: resilientClient(config.HTTPClient, config.Timeout),
 		config: config,
 	}
 }
@@ -102,18 +111,46 @@ type cohereMeta struct {
 	APIVersion struct {
 		Version string `json:"version"`
 	} `json:"api_version"`
+	BilledUnits struct {
+		SearchUnits  int `json:"search_units"`
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"billed_units"`
+}
+
+// Model returns the rerank model used by the reranker
+func (r *CohereReranker) Model() string {
+	return r.config.Model
 }
 
 // Rerank reranks documents based on query relevance using Cohere's Rerank API
 func (r *CohereReranker) Rerank(ctx context.Context, query string, documents []rag.DocumentSearchResult) ([]rag.DocumentSearchResult, error) {
+	results, _, err := r.RerankWithUsage(ctx, query, documents)
+	return results, err
+}
+
+// RerankWithUsage reranks documents like Rerank and also returns the usage billed for the call
+func (r *CohereReranker) RerankWithUsage(ctx context.Context, query string, documents []rag.DocumentSearchResult) ([]rag.DocumentSearchResult, *RerankUsage, error) {
+	usage := &RerankUsage{Model: r.config.Model}
 	if len(documents) == 0 {
-		return []rag.DocumentSearchResult{}, nil
+		return []rag.DocumentSearchResult{}, usage, nil
 	}
 
 	if r.apiKey == "" {
-		return nil, fmt.Errorf("cohere API key is required. Set COHERE_API_KEY environment variable or pass apiKey parameter")
+		return nil, nil, fmt.Errorf("cohere API key is required. Set COHERE_API_KEY environment variable or pass apiKey parameter")
+	}
+
+	if len(documents) < r.config.MinCandidates {
+		return documents, usage, nil
 	}
 
+	return rerankInBatches(ctx, query, documents, r.config.MaxDocuments, r.config.TopK, r.rerankBatch)
+}
+
+// rerankBatch reranks documents with a single API request
+func (r *CohereReranker) rerankBatch(ctx context.Context, query string, documents []rag.DocumentSearchResult) ([]rag.DocumentSearchResult, *RerankUsage, error) {
+	usage := &RerankUsage{Model: r.config.Model}
+
 	// Prepare request body
 	reqDocs := make([]cohereDocument, len(documents))
 	for i, doc := range documents {
@@ -136,13 +173,13 @@ func (r *CohereReranker) Rerank(ctx context.Context, query string, documents []r
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	// Create HTTP request
 	req, err := http.NewRequestWithContext(ctx, "POST", r.config.APIBase, bytes.NewReader(jsonBody))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -152,27 +189,30 @@ func (r *CohereReranker) Rerank(ctx context.Context, query string, documents []r
 	// Send request
 	resp, err := r.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, nil, fmt.Errorf("failed to send request: %w", rag.NewConnectionError("Cohere API", err))
 	}
 	defer resp.Body.Close()
 
 	// Read response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Cohere API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, nil, &rag.StatusError{Backend: "Cohere API", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	// Parse response
 	var rerankResp cohereRerankResponse
 	if err := json.Unmarshal(body, &rerankResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	usage.SearchUnits = rerankResp.Meta.BilledUnits.SearchUnits
+	usage.TotalTokens = rerankResp.Meta.BilledUnits.InputTokens + rerankResp.Meta.BilledUnits.OutputTokens
+
 	// Map results back to documents
 	results := make([]rag.DocumentSearchResult, len(rerankResp.Results))
 	for i, result := range rerankResp.Results {
@@ -190,7 +230,7 @@ func (r *CohereReranker) Rerank(ctx context.Context, query string, documents []r
 		}
 	}
 
-	return results, nil
+	return results, usage, nil
 }
 
 // mergeMetadata merges two metadata maps
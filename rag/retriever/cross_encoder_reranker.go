@@ -27,8 +27,14 @@ type CrossEncoderRerankerConfig struct {
 	// This can be a local service (e.g., http://localhost:8000/rerank)
 	// or a remote service
 	APIBase string
-	// Timeout is the HTTP request timeout
+	// Timeout is the HTTP request timeout, including retries
 	Timeout time.Duration
+	// HTTPClient overrides the default client, which retries transient failures
+	// (see httputil.NewResilientClient)
+	HTTPClient *http.Client
+	// MinCandidates makes Rerank return the candidates unchanged, without calling the cross-encoder service,
+	// when there are fewer of them. 0 always reranks.
+	MinCandidates int
 }
 
 // DefaultCrossEncoderRerankerConfig returns the default configuration
@@ -69,7 +75,9 @@ type CrossEncoderReranker struct {
 // NewCrossEncoderReranker creates a new cross-encoder reranker
 func NewCrossEncoderReranker(config CrossEncoderRerankerConfig) *CrossEncoderReranker {
 	if config.ModelName == "" {
+		client := config.HTTPClient
 		config = DefaultCrossEncoderRerankerConfig()
+		config.HTTPClient = client
 	}
 	if config.Timeout == 0 {
 		config.Timeout = 30 * time.Second
@@ -79,9 +87,7 @@ func NewCrossEncoderReranker(config CrossEncoderRerankerConfig) *CrossEncoderRer
 	}
 
 	return &CrossEncoderReranker{
-		client: &http.Client{
-			Timeout: config.Timeout,
-		},
+		client: resilientClient(config.HTTPClient, config.Timeout),
 		config: config,
 	}
 }
@@ -105,6 +111,9 @@ func (r *CrossEncoderReranker) Rerank(ctx context.Context, query string, documen
 	if len(documents) == 0 {
 		return []rag.DocumentSearchResult{}, nil
 	}
+	if len(documents) < r.config.MinCandidates {
+		return documents, nil
+	}
 
 	// Prepare request body
 	docTexts := make([]string, len(documents))
@@ -135,7 +144,7 @@ func (r *CrossEncoderReranker) Rerank(ctx context.Context, query string, documen
 	// Send request
 	resp, err := r.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", rag.NewConnectionError("cross-encoder service", err))
 	}
 	defer resp.Body.Close()
 
@@ -147,7 +156,7 @@ func (r *CrossEncoderReranker) Rerank(ctx context.Context, query string, documen
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("cross-encoder service returned status %d: %s", resp.StatusCode, string(body))
+		return nil, &rag.StatusError{Backend: "cross-encoder service", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	// Parse response
@@ -0,0 +1,186 @@
+package retriever
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/smallnest/langgraphgo/rag"
+)
+
+// ExpansionRetriever expands query terms with synonyms or abbreviations from a
+// dictionary (e.g. "ML" → "machine learning"), runs the base retriever on every
+// expanded query and fuses the deduplicated results.
+// Expansion is rule-based and requires no LLM call.
+type ExpansionRetriever struct {
+	base       rag.Retriever
+	dictionary map[string][]string
+	keys       []string
+	maxQueries int
+	config     rag.RetrievalConfig
+}
+
+// NewExpansionRetriever creates a new ExpansionRetriever.
+// Dictionary keys are matched case-insensitively as whole words or phrases.
+func NewExpansionRetriever(base rag.Retriever, dictionary map[string][]string) *ExpansionRetriever {
+	keys := make([]string, 0, len(dictionary))
+	for key := range dictionary {
+		if strings.TrimSpace(key) != "" {
+			keys = append(keys, key)
+		}
+	}
+	// Match longer phrases first so "machine learning" wins over "learning"
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	return &ExpansionRetriever{
+		base:       base,
+		dictionary: dictionary,
+		keys:       keys,
+		maxQueries: 5,
+		config: rag.RetrievalConfig{
+			K:          4,
+			SearchType: "similarity",
+		},
+	}
+}
+
+// SetMaxQueries sets the maximum number of queries (including the original) sent to the base retriever
+func (r *ExpansionRetriever) SetMaxQueries(n int) {
+	if n > 0 {
+		r.maxQueries = n
+	}
+}
+
+// ExpandQuery returns the original query followed by its expansions.
+// Each expansion replaces one matched term with one of its synonyms.
+func (r *ExpansionRetriever) ExpandQuery(query string) []string {
+	queries := []string{query}
+	seen := map[string]bool{asciiLower(query): true}
+	lowerQuery := asciiLower(query)
+
+	for _, key := range r.keys {
+		pos := findTerm(lowerQuery, asciiLower(key))
+		if pos < 0 {
+			continue
+		}
+		for _, synonym := range r.dictionary[key] {
+			expanded := query[:pos] + synonym + query[pos+len(key):]
+			if seen[asciiLower(expanded)] {
+				continue
+			}
+			seen[asciiLower(expanded)] = true
+			queries = append(queries, expanded)
+			if len(queries) >= r.maxQueries {
+				return queries
+			}
+		}
+	}
+	return queries
+}
+
+// Retrieve retrieves documents based on a query
+func (r *ExpansionRetriever) Retrieve(ctx context.Context, query string) ([]rag.Document, error) {
+	return r.RetrieveWithK(ctx, query, r.config.K)
+}
+
+// RetrieveWithK retrieves exactly k documents
+func (r *ExpansionRetriever) RetrieveWithK(ctx context.Context, query string, k int) ([]rag.Document, error) {
+	config := r.config
+	config.K = k
+	results, err := r.RetrieveWithConfig(ctx, query, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]rag.Document, len(results))
+	for i, result := range results {
+		docs[i] = result.Document
+	}
+
+	return docs, nil
+}
+
+// RetrieveWithConfig retrieves documents with custom configuration.
+// Results of all expanded queries are fused with reciprocal rank fusion.
+func (r *ExpansionRetriever) RetrieveWithConfig(ctx context.Context, query string, config *rag.RetrievalConfig) ([]rag.DocumentSearchResult, error) {
+	if config == nil {
+		config = &r.config
+	}
+
+	queries := r.ExpandQuery(query)
+	lists := make([][]rag.DocumentSearchResult, 0, len(queries))
+	for _, q := range queries {
+		results, err := r.base.RetrieveWithConfig(ctx, q, config)
+		if err != nil {
+			return nil, fmt.Errorf("retrieval failed for expanded query %q: %w", q, err)
+		}
+
+		tagged := make([]rag.DocumentSearchResult, len(results))
+		for i, result := range results {
+			metadata := make(map[string]any, len(result.Metadata)+1)
+			maps.Copy(metadata, result.Metadata)
+			metadata["expanded_query"] = q
+			result.Metadata = metadata
+			tagged[i] = result
+		}
+		lists = append(lists, tagged)
+	}
+
+	return fuseResults(config.K, lists...), nil
+}
+
+// asciiLower lowercases ASCII letters only, so byte offsets match the original string
+func asciiLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= 'A' && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
+	}
+	return string(b)
+}
+
+// findTerm returns the byte offset of the first whole-word occurrence of term in text, or -1.
+// Terms adjacent to CJK characters match without word boundaries.
+func findTerm(text, term string) int {
+	for offset := 0; offset <= len(text)-len(term); {
+		idx := strings.Index(text[offset:], term)
+		if idx < 0 {
+			return -1
+		}
+		start := offset + idx
+		end := start + len(term)
+		if isTermBoundary(text, start, end) {
+			return start
+		}
+		offset = start + 1
+	}
+	return -1
+}
+
+// isTermBoundary reports whether text[start:end] is not glued to neighbouring word characters
+func isTermBoundary(text string, start, end int) bool {
+	first, _ := utf8.DecodeRuneInString(text[start:end])
+	last, _ := utf8.DecodeLastRuneInString(text[start:end])
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isWordRune(before) && isWordRune(first) {
+		return false
+	}
+	if after, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWordRune(after) && isWordRune(last) {
+		return false
+	}
+	return true
+}
+
+// isWordRune reports whether r is part of a space-delimited word
+func isWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !unicode.Is(unicode.Han, r)
+}
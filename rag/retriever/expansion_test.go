@@ -0,0 +1,71 @@
+package retriever
+
+import (
+	"context"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/rag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type queryRecordingRetriever struct {
+	queries []string
+	byQuery map[string][]rag.Document
+}
+
+func (m *queryRecordingRetriever) Retrieve(ctx context.Context, query string) ([]rag.Document, error) {
+	return m.RetrieveWithK(ctx, query, 4)
+}
+
+func (m *queryRecordingRetriever) RetrieveWithK(ctx context.Context, query string, k int) ([]rag.Document, error) {
+	return m.byQuery[query], nil
+}
+
+func (m *queryRecordingRetriever) RetrieveWithConfig(ctx context.Context, query string, config *rag.RetrievalConfig) ([]rag.DocumentSearchResult, error) {
+	m.queries = append(m.queries, query)
+	var res []rag.DocumentSearchResult
+	for _, d := range m.byQuery[query] {
+		res = append(res, rag.DocumentSearchResult{Document: d, Score: 0.5})
+	}
+	return res, nil
+}
+
+func TestExpansionRetriever_ExpandQuery(t *testing.T) {
+	r := NewExpansionRetriever(&queryRecordingRetriever{}, map[string][]string{
+		"ML":   {"machine learning"},
+		"RAG":  {"retrieval augmented generation", "retrieval-augmented generation"},
+		"检索":   {"搜索"},
+		"HTML": {"markup"},
+	})
+
+	assert.Equal(t, []string{"What is ml?", "What is machine learning?"}, r.ExpandQuery("What is ml?"))
+	assert.Equal(t, []string{"XMLSchema"}, r.ExpandQuery("XMLSchema"), "partial words must not match")
+	assert.Equal(t, []string{"向量检索", "向量搜索"}, r.ExpandQuery("向量检索"))
+	assert.Equal(t, []string{
+		"RAG with ML",
+		"retrieval augmented generation with ML",
+		"retrieval-augmented generation with ML",
+		"RAG with machine learning",
+	}, r.ExpandQuery("RAG with ML"))
+
+	r.SetMaxQueries(2)
+	assert.Len(t, r.ExpandQuery("RAG with ML"), 2)
+}
+
+func TestExpansionRetriever_RetrieveDedupes(t *testing.T) {
+	shared := rag.Document{ID: "shared", Content: "machine learning basics"}
+	base := &queryRecordingRetriever{byQuery: map[string][]rag.Document{
+		"ML intro":               {shared},
+		"machine learning intro": {shared, {ID: "extra", Content: "intro to machine learning"}},
+	}}
+	r := NewExpansionRetriever(base, map[string][]string{"ML": {"machine learning"}})
+
+	results, err := r.RetrieveWithConfig(context.Background(), "ML intro", &rag.RetrievalConfig{K: 5})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ML intro", "machine learning intro"}, base.queries)
+	require.Len(t, results, 2)
+	assert.Equal(t, "shared", results[0].Document.ID)
+	assert.Equal(t, "ML intro", results[0].Metadata["expanded_query"])
+	assert.Equal(t, "extra", results[1].Document.ID)
+}
@@ -126,12 +126,21 @@ func (r *GraphRetriever) extractEntitiesFromQuery(ctx context.Context, query str
 	// This is a placeholder - actual implementation would be more sophisticated
 	words := r.extractWords(query)
 
+	candidates := make([]string, 0, len(words))
 	for _, word := range words {
 		// Skip very short words or common words
 		if len(word) < 3 || r.isCommonWord(word) {
 			continue
 		}
+		candidates = append(candidates, word)
+	}
+
+	// Look up all candidates in one round-trip when the knowledge graph supports it
+	if batch, ok := r.knowledgeGraph.(rag.BatchKnowledgeGraph); ok && len(candidates) > 0 {
+		return batch.GetEntities(ctx, candidates)
+	}
 
+	for _, word := range candidates {
 		// Try to find this entity in the knowledge graph
 		entity, err := r.knowledgeGraph.GetEntity(ctx, word)
 		if err == nil && entity != nil {
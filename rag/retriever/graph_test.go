@@ -48,3 +48,33 @@ func TestGraphRetriever(t *testing.T) {
 		assert.NotEmpty(t, docs)
 	})
 }
+
+type batchKG struct {
+	mockKG
+	batchCalls int
+}
+
+func (m *batchKG) GetEntities(ctx context.Context, ids []string) ([]*rag.Entity, error) {
+	m.batchCalls++
+	var found []*rag.Entity
+	for _, id := range ids {
+		if e, _ := m.GetEntity(ctx, id); e != nil {
+			found = append(found, e)
+		}
+	}
+	return found, nil
+}
+
+func (m *batchKG) GetRelationships(ctx context.Context, ids []string) ([]*rag.Relationship, error) {
+	return nil, nil
+}
+
+func TestGraphRetriever_BatchLookup(t *testing.T) {
+	kg := &batchKG{mockKG: mockKG{entities: []*rag.Entity{{ID: "e1", Name: "entity1", Type: "person"}}}}
+	r := NewGraphRetriever(kg, &mockEmbedder{}, rag.RetrievalConfig{K: 1})
+
+	entities, err := r.extractEntitiesFromQuery(context.Background(), "entity1 meets entity2")
+	assert.NoError(t, err)
+	assert.Len(t, entities, 1)
+	assert.Equal(t, 1, kg.batchCalls)
+}
@@ -0,0 +1,187 @@
+package retriever
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/smallnest/langgraphgo/rag"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// DefaultCondensePrompt is the system prompt used to rewrite a follow-up message into a
+// standalone search query
+const DefaultCondensePrompt = "Given a conversation and a follow-up message, rewrite the follow-up " +
+	"as a standalone search query that can be understood without the conversation. Resolve " +
+	"pronouns and references such as \"it\" or \"that\" using the conversation. If the message " +
+	"is already standalone, return it unchanged. Reply with the query only, without explanation or quotes."
+
+type chatHistoryKey struct{}
+
+// WithChatHistory returns a context carrying the conversation history used by
+// HistoryAwareRetriever when it is called through the rag.Retriever methods
+func WithChatHistory(ctx context.Context, history []llms.MessageContent) context.Context {
+	return context.WithValue(ctx, chatHistoryKey{}, history)
+}
+
+// ChatHistoryFromContext returns the conversation history set by WithChatHistory
+func ChatHistoryFromContext(ctx context.Context) []llms.MessageContent {
+	history, _ := ctx.Value(chatHistoryKey{}).([]llms.MessageContent)
+	return history
+}
+
+// HistoryAwareRetriever makes follow-up messages in a multi-turn conversation retrievable.
+// It asks an LLM to condense the conversation history and the new message into a standalone
+// query (e.g. "what about its competitors?" → "competitors of Acme Corp") and retrieves with
+// that query from the base retriever. Without history the message is used as is, with no LLM call.
+type HistoryAwareRetriever struct {
+	base     rag.Retriever
+	llm      llms.Model
+	prompt   string
+	maxTurns int
+	config   rag.RetrievalConfig
+}
+
+// NewHistoryAwareRetriever creates a new HistoryAwareRetriever
+func NewHistoryAwareRetriever(base rag.Retriever, llm llms.Model) *HistoryAwareRetriever {
+	return &HistoryAwareRetriever{
+		base:     base,
+		llm:      llm,
+		prompt:   DefaultCondensePrompt,
+		maxTurns: 10,
+		config: rag.RetrievalConfig{
+			K:          4,
+			SearchType: "similarity",
+		},
+	}
+}
+
+// SetPrompt sets the system prompt used to condense the conversation into a standalone query
+func (r *HistoryAwareRetriever) SetPrompt(prompt string) {
+	if prompt != "" {
+		r.prompt = prompt
+	}
+}
+
+// SetMaxTurns sets how many of the most recent history messages are sent to the LLM
+func (r *HistoryAwareRetriever) SetMaxTurns(n int) {
+	if n > 0 {
+		r.maxTurns = n
+	}
+}
+
+// CondenseQuery returns a standalone search query for message given the conversation history.
+// The message is returned unchanged if the history is empty or the LLM returns no query.
+func (r *HistoryAwareRetriever) CondenseQuery(ctx context.Context, history []llms.MessageContent, message string) (string, error) {
+	transcript := formatChatHistory(history, r.maxTurns)
+	if transcript == "" {
+		return message, nil
+	}
+
+	prompt := fmt.Sprintf("Conversation:\n%s\nFollow-up message: %s\n\nStandalone query:", transcript, message)
+	messages := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, r.prompt),
+		llms.TextParts(llms.ChatMessageTypeHuman, prompt),
+	}
+
+	response, err := r.llm.GenerateContent(ctx, messages)
+	if err != nil {
+		return "", fmt.Errorf("LLM generation failed: %w", err)
+	}
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("no response from LLM")
+	}
+
+	query := strings.Trim(strings.TrimSpace(response.Choices[0].Content), "\"'`")
+	if query == "" {
+		return message, nil
+	}
+	return query, nil
+}
+
+// Retrieve retrieves documents for a message, using the history from WithChatHistory
+func (r *HistoryAwareRetriever) Retrieve(ctx context.Context, query string) ([]rag.Document, error) {
+	return r.RetrieveWithK(ctx, query, r.config.K)
+}
+
+// RetrieveWithK retrieves exactly k documents
+func (r *HistoryAwareRetriever) RetrieveWithK(ctx context.Context, query string, k int) ([]rag.Document, error) {
+	config := r.config
+	config.K = k
+	results, err := r.RetrieveWithConfig(ctx, query, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]rag.Document, len(results))
+	for i, result := range results {
+		docs[i] = result.Document
+	}
+
+	return docs, nil
+}
+
+// RetrieveWithConfig retrieves documents with custom configuration, using the history from
+// WithChatHistory
+func (r *HistoryAwareRetriever) RetrieveWithConfig(ctx context.Context, query string, config *rag.RetrievalConfig) ([]rag.DocumentSearchResult, error) {
+	return r.RetrieveWithHistory(ctx, ChatHistoryFromContext(ctx), query, config)
+}
+
+// RetrieveWithHistory condenses history and message into a standalone query and retrieves
+// with it. The query used is reported in each result's metadata under "standalone_query".
+func (r *HistoryAwareRetriever) RetrieveWithHistory(ctx context.Context, history []llms.MessageContent, message string, config *rag.RetrievalConfig) ([]rag.DocumentSearchResult, error) {
+	if config == nil {
+		config = &r.config
+	}
+
+	query, err := r.CondenseQuery(ctx, history, message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to condense query: %w", err)
+	}
+
+	results, err := r.base.RetrieveWithConfig(ctx, query, config)
+	if err != nil {
+		return nil, fmt.Errorf("retrieval failed for standalone query %q: %w", query, err)
+	}
+
+	for i, result := range results {
+		metadata := make(map[string]any, len(result.Metadata)+1)
+		maps.Copy(metadata, result.Metadata)
+		metadata["standalone_query"] = query
+		results[i].Metadata = metadata
+	}
+	return results, nil
+}
+
+// formatChatHistory renders the text of the last maxTurns messages as "Role: text" lines
+func formatChatHistory(history []llms.MessageContent, maxTurns int) string {
+	if len(history) > maxTurns {
+		history = history[len(history)-maxTurns:]
+	}
+
+	var sb strings.Builder
+	for _, msg := range history {
+		var texts []string
+		for _, part := range msg.Parts {
+			if text, ok := part.(llms.TextContent); ok && strings.TrimSpace(text.Text) != "" {
+				texts = append(texts, strings.TrimSpace(text.Text))
+			}
+		}
+		if len(texts) == 0 {
+			continue
+		}
+
+		role := "User"
+		switch msg.Role {
+		case llms.ChatMessageTypeAI:
+			role = "Assistant"
+		case llms.ChatMessageTypeSystem:
+			role = "System"
+		case llms.ChatMessageTypeTool, llms.ChatMessageTypeFunction:
+			role = "Tool"
+		}
+		fmt.Fprintf(&sb, "%s: %s\n", role, strings.Join(texts, " "))
+	}
+	return sb.String()
+}
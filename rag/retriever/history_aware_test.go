@@ -0,0 +1,84 @@
+package retriever
+
+import (
+	"context"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/rag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// condensingLLM answers every request with a fixed query and records the prompt it received
+type condensingLLM struct {
+	answer  string
+	prompts []string
+}
+
+func (m *condensingLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	m.prompts = append(m.prompts, messages[len(messages)-1].Parts[0].(llms.TextContent).Text)
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: m.answer}}}, nil
+}
+
+func (m *condensingLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return m.answer, nil
+}
+
+func TestHistoryAwareRetriever(t *testing.T) {
+	ctx := context.Background()
+	history := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeHuman, "Tell me about Acme Corp"),
+		llms.TextParts(llms.ChatMessageTypeAI, "Acme Corp makes rockets."),
+	}
+
+	t.Run("Condenses follow-up with history", func(t *testing.T) {
+		base := &queryRecordingRetriever{byQuery: map[string][]rag.Document{
+			"competitors of Acme Corp": {{ID: "competitors"}},
+		}}
+		llm := &condensingLLM{answer: " \"competitors of Acme Corp\"\n"}
+		r := NewHistoryAwareRetriever(base, llm)
+
+		results, err := r.RetrieveWithHistory(ctx, history, "what about its competitors?", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"competitors of Acme Corp"}, base.queries)
+		require.Len(t, results, 1)
+		assert.Equal(t, "competitors", results[0].Document.ID)
+		assert.Equal(t, "competitors of Acme Corp", results[0].Metadata["standalone_query"])
+
+		require.Len(t, llm.prompts, 1)
+		assert.Contains(t, llm.prompts[0], "User: Tell me about Acme Corp\nAssistant: Acme Corp makes rockets.\n")
+		assert.Contains(t, llm.prompts[0], "Follow-up message: what about its competitors?")
+	})
+
+	t.Run("History from context", func(t *testing.T) {
+		base := &queryRecordingRetriever{byQuery: map[string][]rag.Document{}}
+		r := NewHistoryAwareRetriever(base, &condensingLLM{answer: "competitors of Acme Corp"})
+
+		_, err := r.Retrieve(WithChatHistory(ctx, history), "what about its competitors?")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"competitors of Acme Corp"}, base.queries)
+	})
+
+	t.Run("No history skips the LLM", func(t *testing.T) {
+		base := &queryRecordingRetriever{byQuery: map[string][]rag.Document{}}
+		llm := &condensingLLM{answer: "unused"}
+		r := NewHistoryAwareRetriever(base, llm)
+
+		_, err := r.Retrieve(ctx, "what is Acme Corp?")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"what is Acme Corp?"}, base.queries)
+		assert.Empty(t, llm.prompts)
+	})
+
+	t.Run("MaxTurns", func(t *testing.T) {
+		llm := &condensingLLM{answer: "q"}
+		r := NewHistoryAwareRetriever(&queryRecordingRetriever{}, llm)
+		r.SetMaxTurns(1)
+
+		_, err := r.CondenseQuery(ctx, history, "and then?")
+		require.NoError(t, err)
+		assert.NotContains(t, llm.prompts[0], "Tell me about Acme Corp")
+		assert.Contains(t, llm.prompts[0], "Assistant: Acme Corp makes rockets.")
+	})
+}
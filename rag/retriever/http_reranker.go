@@ -0,0 +1,261 @@
+package retriever
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/smallnest/langgraphgo/rag"
+)
+
+// HTTPRerankFormat is the request and response shape spoken by an HTTPReranker
+type HTTPRerankFormat string
+
+const (
+	// HTTPRerankFormatCohere sends {"model", "query", "documents", "top_n"} and reads
+	// {"results": [{"index", "relevance_score"}]}, as served by vLLM, Infinity and other
+	// Cohere/Jina-compatible rerank endpoints
+	HTTPRerankFormatCohere HTTPRerankFormat = "cohere"
+	// HTTPRerankFormatTEI sends {"query", "texts"} and reads [{"index", "score"}], as served
+	// by HuggingFace Text Embeddings Inference
+	HTTPRerankFormatTEI HTTPRerankFormat = "tei"
+)
+
+// HTTPRerankerConfig configures a reranker served over HTTP
+type HTTPRerankerConfig struct {
+	// Format is the API shape of the endpoint (default HTTPRerankFormatCohere)
+	Format HTTPRerankFormat
+	// APIKey is sent as a bearer token when set
+	APIKey string
+	// TopK is the number of documents to return (0 returns all)
+	TopK int
+	// MaxDocuments caps the number of candidates sent in a single request. Larger candidate
+	// sets are reranked in batches whose results are merged by score. 0 means no limit.
+	MaxDocuments int
+	// MinCandidates makes Rerank return the candidates unchanged, without calling the API,
+	// when there are fewer of them. 0 always reranks.
+	MinCandidates int
+	// Timeout is the HTTP request timeout, including retries
+	Timeout time.Duration
+	// HTTPClient overrides the default client, which retries transient failures
+	// (see httputil.NewResilientClient)
+	HTTPClient *http.Client
+}
+
+// DefaultHTTPRerankerConfig returns the default configuration for HTTP rerankers
+func DefaultHTTPRerankerConfig() HTTPRerankerConfig {
+	return HTTPRerankerConfig{
+		Format:  HTTPRerankFormatCohere,
+		TopK:    5,
+		Timeout: 30 * time.Second,
+	}
+}
+
+// HTTPReranker reranks documents with a self-hosted rerank endpoint, such as HuggingFace
+// TEI, vLLM or Infinity, using the common "query and documents in, scored indices out" API
+type HTTPReranker struct {
+	url    string
+	model  string
+	client *http.Client
+	config HTTPRerankerConfig
+}
+
+// NewHTTPReranker creates a reranker for the endpoint at baseURL. "/rerank" is appended
+// unless the URL path already ends with "rerank", e.g. "http://localhost:8080" (TEI) or
+// "http://localhost:8000/v1/rerank" (vLLM). model is sent with the Cohere format and may
+// be empty for servers hosting a single model.
+func NewHTTPReranker(baseURL, model string, config HTTPRerankerConfig) *HTTPReranker {
+	if config.Format == "" {
+		config.Format = HTTPRerankFormatCohere
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	url := strings.TrimRight(baseURL, "/")
+	if !strings.HasSuffix(url, "rerank") {
+		url += "/rerank"
+	}
+
+	return &HTTPReranker{
+		url:    url,
+		model:  model,
+		client: resilientClient(config.HTTPClient, config.Timeout),
+		config: config,
+	}
+}
+
+// httpRerankRequest is the request body of both formats; unused fields are omitted
+type httpRerankRequest struct {
+	Model     string   `json:"model,omitempty"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents,omitempty"`
+	Texts     []string `json:"texts,omitempty"`
+	TopN      int      `json:"top_n,omitempty"`
+	Truncate  bool     `json:"truncate,omitempty"`
+}
+
+// httpRerankResult is a scored document index in either format
+type httpRerankResult struct {
+	Index          int      `json:"index"`
+	RelevanceScore *float64 `json:"relevance_score"`
+	Score          *float64 `json:"score"`
+}
+
+func (r httpRerankResult) score() float64 {
+	if r.RelevanceScore != nil {
+		return *r.RelevanceScore
+	}
+	if r.Score != nil {
+		return *r.Score
+	}
+	return 0
+}
+
+// httpRerankResponse is the Cohere-format response
+type httpRerankResponse struct {
+	Model   string             `json:"model"`
+	Results []httpRerankResult `json:"results"`
+	Usage   struct {
+		TotalTokens int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// Model returns the rerank model used by the reranker
+func (r *HTTPReranker) Model() string {
+	return r.model
+}
+
+// Rerank reranks documents based on query relevance using the rerank endpoint
+func (r *HTTPReranker) Rerank(ctx context.Context, query string, documents []rag.DocumentSearchResult) ([]rag.DocumentSearchResult, error) {
+	results, _, err := r.RerankWithUsage(ctx, query, documents)
+	return results, err
+}
+
+// RerankWithUsage reranks documents like Rerank and also returns the usage reported by the
+// endpoint, if any
+func (r *HTTPReranker) RerankWithUsage(ctx context.Context, query string, documents []rag.DocumentSearchResult) ([]rag.DocumentSearchResult, *RerankUsage, error) {
+	usage := &RerankUsage{Model: r.model}
+	if len(documents) == 0 {
+		return []rag.DocumentSearchResult{}, usage, nil
+	}
+
+	if len(documents) < r.config.MinCandidates {
+		return documents, usage, nil
+	}
+
+	return rerankInBatches(ctx, query, documents, r.config.MaxDocuments, r.config.TopK, r.rerankBatch)
+}
+
+// rerankBatch reranks documents with a single request to the endpoint
+func (r *HTTPReranker) rerankBatch(ctx context.Context, query string, documents []rag.DocumentSearchResult) ([]rag.DocumentSearchResult, *RerankUsage, error) {
+	usage := &RerankUsage{Model: r.model}
+
+	texts := make([]string, len(documents))
+	for i, doc := range documents {
+		texts[i] = doc.Document.Content
+	}
+
+	reqBody := httpRerankRequest{Query: query}
+	switch r.config.Format {
+	case HTTPRerankFormatTEI:
+		reqBody.Texts = texts
+		reqBody.Truncate = true
+	case HTTPRerankFormatCohere:
+		reqBody.Model = r.model
+		reqBody.Documents = texts
+		reqBody.TopN = r.config.TopK
+	default:
+		return nil, nil, fmt.Errorf("unknown rerank format %q", r.config.Format)
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", r.url, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if r.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.config.APIKey)
+	}
+
+	// Send request
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to send request: %w", rag.NewConnectionError("rerank endpoint", err))
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Check status code
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, &rag.StatusError{Backend: "rerank endpoint", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	// Parse response: TEI returns a bare array, the Cohere format an object with results
+	var rerankResp httpRerankResponse
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &rerankResp.Results)
+	} else {
+		err = json.Unmarshal(body, &rerankResp)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if rerankResp.Model != "" {
+		usage.Model = rerankResp.Model
+	}
+	usage.TotalTokens = rerankResp.Usage.TotalTokens
+
+	ranked := rerankResp.Results
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score() > ranked[j].score()
+	})
+	if r.config.TopK > 0 && len(ranked) > r.config.TopK {
+		ranked = ranked[:r.config.TopK]
+	}
+
+	// Map results back to documents
+	results := make([]rag.DocumentSearchResult, 0, len(ranked))
+	for _, result := range ranked {
+		if result.Index < 0 || result.Index >= len(documents) {
+			return nil, nil, fmt.Errorf("rerank endpoint returned index %d for %d documents", result.Index, len(documents))
+		}
+		originalDoc := documents[result.Index]
+		metadata := make(map[string]any, len(originalDoc.Metadata)+5)
+		maps.Copy(metadata, originalDoc.Metadata)
+		maps.Copy(metadata, map[string]any{
+			"rerank_score":     result.score(),
+			"original_score":   originalDoc.Score,
+			"original_index":   result.Index,
+			"reranking_method": "http",
+			"rerank_model":     usage.Model,
+		})
+		results = append(results, rag.DocumentSearchResult{
+			Document: originalDoc.Document,
+			Score:    result.score(),
+			Metadata: metadata,
+		})
+	}
+
+	return results, usage, nil
+}
@@ -7,11 +7,19 @@ import (
 	"github.com/smallnest/langgraphgo/rag"
 )
 
+// ScoreBreakdownMetadataKey is the result metadata key holding a map[string]float64 with the
+// score each retriever gave the document, keyed by component name ("vector", "bm25" or
+// "retriever_<index>"), and the combined score under "fused". Retrievers that did not return
+// the document have no entry. Component scores are unweighted, and normalized when score
+// normalization is enabled.
+const ScoreBreakdownMetadataKey = "score_breakdown"
+
 // HybridRetriever combines multiple retrieval strategies
 type HybridRetriever struct {
-	retrievers []rag.Retriever
-	weights    []float64
-	config     rag.RetrievalConfig
+	retrievers      []rag.Retriever
+	weights         []float64
+	config          rag.RetrievalConfig
+	normalizeScores bool
 }
 
 // NewHybridRetriever creates a new hybrid retriever that combines multiple retrievers
@@ -112,9 +120,14 @@ func (h *HybridRetriever) combineResults(allResults [][]rag.DocumentSearchResult
 	// Create a map to track documents and their scores
 	documentScores := make(map[string]*CombinedDocumentScore)
 
+	names := h.componentNames()
+
 	// Process results from each retriever
 	for retrieverIdx, results := range allResults {
 		weight := h.weights[retrieverIdx]
+		if h.normalizeScores {
+			results = normalizeScores(results)
+		}
 
 		for _, result := range results {
 			docID := result.Document.ID
@@ -124,6 +137,7 @@ func (h *HybridRetriever) combineResults(allResults [][]rag.DocumentSearchResult
 				existing.TotalScore += float64(result.Score) * weight
 				existing.RetrieverCount++
 				existing.Sources = append(existing.Sources, fmt.Sprintf("retriever_%d", retrieverIdx))
+				existing.Breakdown[names[retrieverIdx]] = result.Score
 			} else {
 				// Add new document
 				documentScores[docID] = &CombinedDocumentScore{
@@ -132,6 +146,7 @@ func (h *HybridRetriever) combineResults(allResults [][]rag.DocumentSearchResult
 					RetrieverCount: 1,
 					Sources:        []string{fmt.Sprintf("retriever_%d", retrieverIdx)},
 					Metadata:       result.Metadata,
+					Breakdown:      map[string]float64{names[retrieverIdx]: result.Score},
 				}
 			}
 		}
@@ -153,13 +168,16 @@ func (h *HybridRetriever) combineResults(allResults [][]rag.DocumentSearchResult
 			finalScore = 1.0
 		}
 
+		combined.Breakdown["fused"] = finalScore
+
 		result := rag.DocumentSearchResult{
 			Document: combined.Document,
 			Score:    finalScore,
 			Metadata: map[string]any{
-				"retriever_count":   combined.RetrieverCount,
-				"sources":           combined.Sources,
-				"original_metadata": combined.Metadata,
+				"retriever_count":         combined.RetrieverCount,
+				"sources":                 combined.Sources,
+				"original_metadata":       combined.Metadata,
+				ScoreBreakdownMetadataKey: combined.Breakdown,
 			},
 		}
 
@@ -179,6 +197,49 @@ type CombinedDocumentScore struct {
 	RetrieverCount int
 	Sources        []string
 	Metadata       map[string]any
+	// Breakdown holds the score given by each retriever and the fused score
+	Breakdown map[string]float64
+}
+
+// componentNames returns the score breakdown name of each retriever
+func (h *HybridRetriever) componentNames() []string {
+	names := make([]string, len(h.retrievers))
+	used := make(map[string]bool, len(h.retrievers))
+	for i, r := range h.retrievers {
+		var name string
+		switch r.(type) {
+		case *VectorRetriever, *VectorStoreRetriever:
+			name = "vector"
+		case *BM25Retriever:
+			name = "bm25"
+		}
+		if name == "" || used[name] {
+			name = fmt.Sprintf("retriever_%d", i)
+		}
+		used[name] = true
+		names[i] = name
+	}
+	return names
+}
+
+// normalizeScores returns a copy of results with the scores divided by the top score, so that
+// unbounded scores such as BM25 fall in [0, 1] like cosine similarities. Negative scores become 0.
+func normalizeScores(results []rag.DocumentSearchResult) []rag.DocumentSearchResult {
+	maxScore := 0.0
+	for _, result := range results {
+		maxScore = max(maxScore, result.Score)
+	}
+
+	normalized := make([]rag.DocumentSearchResult, len(results))
+	for i, result := range results {
+		normalized[i] = result
+		if maxScore > 0 {
+			normalized[i].Score = max(result.Score, 0) / maxScore
+		} else {
+			normalized[i].Score = 0
+		}
+	}
+	return normalized
 }
 
 // sortResults sorts results by score in descending order
@@ -217,6 +278,12 @@ func (h *HybridRetriever) SetWeights(weights []float64) error {
 	return nil
 }
 
+// SetNormalizeScores enables dividing each retriever's scores by its top score before they
+// are weighted, so that retrievers with different score ranges contribute comparably
+func (h *HybridRetriever) SetNormalizeScores(enabled bool) {
+	h.normalizeScores = enabled
+}
+
 // AddRetriever adds a new retriever to the hybrid strategy
 func (h *HybridRetriever) AddRetriever(retriever rag.Retriever, weight float64) {
 	h.retrievers = append(h.retrievers, retriever)
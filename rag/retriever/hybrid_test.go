@@ -36,3 +36,32 @@ func TestHybridRetriever(t *testing.T) {
 		assert.Equal(t, 2, h.GetRetrieverCount())
 	})
 }
+
+func TestHybridRetriever_ScoreBreakdown(t *testing.T) {
+	ctx := context.Background()
+	docs := []rag.Document{
+		{ID: "1", Content: "go channels and goroutines for concurrency"},
+		{ID: "2", Content: "python data analysis with pandas"},
+	}
+	bm25, err := NewBM25Retriever(docs, DefaultBM25Config())
+	assert.NoError(t, err)
+	semantic := &mockRetriever{docs: docs}
+
+	h := NewHybridRetriever([]rag.Retriever{bm25, semantic}, []float64{0.5, 0.5}, rag.RetrievalConfig{K: 2})
+	h.SetNormalizeScores(true)
+
+	results, err := h.RetrieveWithConfig(ctx, "go channels", &rag.RetrievalConfig{K: 2})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	top := results[0].Metadata[ScoreBreakdownMetadataKey].(map[string]float64)
+	assert.Equal(t, "1", results[0].Document.ID)
+	assert.InDelta(t, 1.0, top["bm25"], 1e-9, "the best keyword match is normalized to 1")
+	assert.InDelta(t, 1.0, top["retriever_1"], 1e-9)
+	assert.Equal(t, results[0].Score, top["fused"])
+
+	other := results[1].Metadata[ScoreBreakdownMetadataKey].(map[string]float64)
+	assert.NotContains(t, other, "bm25", "documents missed by a retriever have no entry for it")
+	assert.InDelta(t, 1.0, other["retriever_1"], 1e-9)
+	assert.Equal(t, results[1].Score, other["fused"])
+}
@@ -19,12 +19,21 @@ type JinaRerankerConfig struct {
 	// Model is the Jina rerank model to use
 	// Options: "jina-reranker-v1-base-en", "jina-reranker-v2-base-multilingual"
 	Model string
-	// TopK is the number of documents to return
+	// TopK is the number of documents to return (sent as top_n)
 	TopK int
+	// MaxDocuments caps the number of candidates sent in a single request. Larger candidate
+	// sets are reranked in batches whose results are merged by score. 0 means no limit.
+	MaxDocuments int
+	// MinCandidates makes Rerank return the candidates unchanged, without calling the API,
+	// when there are fewer of them. 0 always reranks.
+	MinCandidates int
 	// APIBase is the custom API base URL (optional)
 	APIBase string
-	// Timeout is the HTTP request timeout
+	// Timeout is the HTTP request timeout, including retries
 	Timeout time.Duration
+	// HTTPClient overrides the default client, which retries transient failures
+	// (see httputil.NewResilientClient)
+	HTTPClient *http.Client
 }
 
 // DefaultJinaRerankerConfig returns the default configuration for Jina reranker
@@ -51,7 +60,9 @@ func NewJinaReranker(apiKey string, config JinaRerankerConfig) *JinaReranker {
 		apiKey = os.Getenv("JINA_API_KEY")
 	}
 	if config.Model == "" {
+		client := config.HTTPClient
 		config = DefaultJinaRerankerConfig()
+		config.HTTPClient = client
 	}
 	if config.Timeout == 0 {
 		config.Timeout = 30 * time.Second
@@ -62,9 +73,7 @@ func NewJinaReranker(apiKey string, config JinaRerankerConfig) *JinaReranker {
 
 	return &JinaReranker{
 		apiKey: apiKey,
-		client: &http.Client{
-			Timeout: config.Timeout,
-		},
+		client: resilientClient(config.HTTPClient, config.Timeout),
 		config: config,
 	}
 }
@@ -102,16 +111,39 @@ type jinaUsage struct {
 	TotalTokens int `json:"total_tokens"`
 }
 
+// Model returns the rerank model used by the reranker
+func (r *JinaReranker) Model() string {
+	return r.config.Model
+}
+
 // Rerank reranks documents based on query relevance using Jina's Rerank API
 func (r *JinaReranker) Rerank(ctx context.Context, query string, documents []rag.DocumentSearchResult) ([]rag.DocumentSearchResult, error) {
+	results, _, err := r.RerankWithUsage(ctx, query, documents)
+	return results, err
+}
+
+// RerankWithUsage reranks documents like Rerank and also returns the usage billed for the call
+func (r *JinaReranker) RerankWithUsage(ctx context.Context, query string, documents []rag.DocumentSearchResult) ([]rag.DocumentSearchResult, *RerankUsage, error) {
+	usage := &RerankUsage{Model: r.config.Model}
 	if len(documents) == 0 {
-		return []rag.DocumentSearchResult{}, nil
+		return []rag.DocumentSearchResult{}, usage, nil
 	}
 
 	if r.apiKey == "" {
-		return nil, fmt.Errorf("jina API key is required. Set JINA_API_KEY environment variable or pass apiKey parameter")
+		return nil, nil, fmt.Errorf("jina API key is required. Set JINA_API_KEY environment variable or pass apiKey parameter")
+	}
+
+	if len(documents) < r.config.MinCandidates {
+		return documents, usage, nil
 	}
 
+	return rerankInBatches(ctx, query, documents, r.config.MaxDocuments, r.config.TopK, r.rerankBatch)
+}
+
+// rerankBatch reranks documents with a single API request
+func (r *JinaReranker) rerankBatch(ctx context.Context, query string, documents []rag.DocumentSearchResult) ([]rag.DocumentSearchResult, *RerankUsage, error) {
+	usage := &RerankUsage{Model: r.config.Model}
+
 	// Prepare request body
 	reqDocs := make([]jinaDocument, len(documents))
 	for i, doc := range documents {
@@ -134,13 +166,13 @@ func (r *JinaReranker) Rerank(ctx context.Context, query string, documents []rag
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	// Create HTTP request
 	req, err := http.NewRequestWithContext(ctx, "POST", r.config.APIBase, bytes.NewReader(jsonBody))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -149,26 +181,31 @@ func (r *JinaReranker) Rerank(ctx context.Context, query string, documents []rag
 	// Send request
 	resp, err := r.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, nil, fmt.Errorf("failed to send request: %w", rag.NewConnectionError("Jina API", err))
 	}
 	defer resp.Body.Close()
 
 	// Read response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Jina API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, nil, &rag.StatusError{Backend: "Jina API", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	// Parse response
 	var rerankResp jinaRerankResponse
 	if err := json.Unmarshal(body, &rerankResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if rerankResp.Model != "" {
+		usage.Model = rerankResp.Model
 	}
+	usage.TotalTokens = rerankResp.Usage.TotalTokens
 
 	// Map results back to documents
 	results := make([]rag.DocumentSearchResult, len(rerankResp.Results))
@@ -187,7 +224,7 @@ func (r *JinaReranker) Rerank(ctx context.Context, query string, documents []rag
 		}
 	}
 
-	return results, nil
+	return results, usage, nil
 }
 
 // mergeMetadata merges two metadata maps
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"maps"
 	"strings"
+	"sync"
 
 	"github.com/smallnest/langgraphgo/rag"
 	"github.com/tmc/langchaingo/llms"
@@ -20,6 +21,15 @@ type LLMRerankerConfig struct {
 	SystemPrompt string
 	// BatchSize is the number of documents to score in a single request (for efficiency)
 	BatchSize int
+	// WindowOverlap makes consecutive batches share this many documents (sliding-window).
+	// Documents scored in several windows get the average of their scores, which keeps
+	// scores comparable across batches. Must be smaller than BatchSize.
+	WindowOverlap int
+	// MaxConcurrency is the maximum number of batches scored in parallel (default 1)
+	MaxConcurrency int
+	// MinCandidates makes Rerank return the candidates unchanged, without calling the LLM,
+	// when there are fewer of them. 0 always reranks.
+	MinCandidates int
 }
 
 // DefaultLLMRerankerConfig returns the default configuration for LLM reranker
@@ -48,6 +58,12 @@ func NewLLMReranker(llm llms.Model, config LLMRerankerConfig) *LLMReranker {
 	if config.BatchSize <= 0 {
 		config.BatchSize = 5
 	}
+	if config.WindowOverlap < 0 || config.WindowOverlap >= config.BatchSize {
+		config.WindowOverlap = 0
+	}
+	if config.MaxConcurrency <= 0 {
+		config.MaxConcurrency = 1
+	}
 	return &LLMReranker{
 		llm:    llm,
 		config: config,
@@ -59,27 +75,12 @@ func (r *LLMReranker) Rerank(ctx context.Context, query string, documents []rag.
 	if len(documents) == 0 {
 		return []rag.DocumentSearchResult{}, nil
 	}
-
-	// Score all documents
-	scores := make([]float64, len(documents))
-
-	// Score documents in batches for efficiency
-	for i := 0; i < len(documents); i += r.config.BatchSize {
-		end := min(i+r.config.BatchSize, len(documents))
-		batch := documents[i:end]
-
-		batchScores, err := r.scoreBatch(ctx, query, batch)
-		if err != nil {
-			// If batch scoring fails, use original scores
-			for j := i; j < end; j++ {
-				scores[j] = documents[j].Score
-			}
-			continue
-		}
-
-		copy(scores[i:end], batchScores)
+	if len(documents) < r.config.MinCandidates {
+		return documents, nil
 	}
 
+	scores := r.scoreWindows(ctx, query, documents)
+
 	// Combine original scores with LLM scores (weighted average)
 	type docScore struct {
 		doc   rag.DocumentSearchResult
@@ -142,6 +143,57 @@ func (r *LLMReranker) Rerank(ctx context.Context, query string, documents []rag.
 	return results, nil
 }
 
+// scoreWindows scores documents in (optionally overlapping) batches and averages the
+// scores of documents that appear in several windows. Documents whose batches all
+// failed keep their original score.
+func (r *LLMReranker) scoreWindows(ctx context.Context, query string, documents []rag.DocumentSearchResult) []float64 {
+	step := r.config.BatchSize - r.config.WindowOverlap
+	var starts []int
+	for start := 0; start < len(documents); start += step {
+		starts = append(starts, start)
+		if start+r.config.BatchSize >= len(documents) {
+			break
+		}
+	}
+
+	windowScores := make([][]float64, len(starts))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, r.config.MaxConcurrency)
+	for w, start := range starts {
+		end := min(start+r.config.BatchSize, len(documents))
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(w int, batch []rag.DocumentSearchResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			// Failed batches are skipped; their documents fall back to original scores
+			if batchScores, err := r.scoreBatch(ctx, query, batch); err == nil {
+				windowScores[w] = batchScores
+			}
+		}(w, documents[start:end])
+	}
+	wg.Wait()
+
+	sums := make([]float64, len(documents))
+	counts := make([]int, len(documents))
+	for w, batchScores := range windowScores {
+		for j, score := range batchScores {
+			sums[starts[w]+j] += score
+			counts[starts[w]+j]++
+		}
+	}
+
+	scores := make([]float64, len(documents))
+	for i := range documents {
+		if counts[i] == 0 {
+			scores[i] = documents[i].Score
+		} else {
+			scores[i] = sums[i] / float64(counts[i])
+		}
+	}
+	return scores
+}
+
 // scoreBatch scores a batch of documents using a single LLM call
 func (r *LLMReranker) scoreBatch(ctx context.Context, query string, documents []rag.DocumentSearchResult) ([]float64, error) {
 	// Build prompt with all documents
@@ -0,0 +1,99 @@
+package retriever
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/rag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// scoringLLM scores each "[n] doc-<score>" line of the prompt with the score embedded in the document
+type scoringLLM struct {
+	mu         sync.Mutex
+	batchSizes []int
+}
+
+var docScorePattern = regexp.MustCompile(`\[\d+\] doc-([0-9.]+)`)
+
+func (m *scoringLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	prompt := messages[len(messages)-1].Parts[0].(llms.TextContent).Text
+	matches := docScorePattern.FindAllStringSubmatch(prompt, -1)
+
+	scores := make([]string, len(matches))
+	for i, match := range matches {
+		scores[i] = match[1]
+	}
+
+	m.mu.Lock()
+	m.batchSizes = append(m.batchSizes, len(matches))
+	m.mu.Unlock()
+
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: "[" + strings.Join(scores, ", ") + "]"}}}, nil
+}
+
+func (m *scoringLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return "", nil
+}
+
+func TestLLMReranker_Windows(t *testing.T) {
+	docs := make([]rag.DocumentSearchResult, 12)
+	for i := range docs {
+		docs[i] = rag.DocumentSearchResult{Document: rag.Document{ID: fmt.Sprint(i), Content: fmt.Sprintf("doc-%.2f", float64(i)/12)}}
+	}
+
+	t.Run("Batches", func(t *testing.T) {
+		llm := &scoringLLM{}
+		r := NewLLMReranker(llm, LLMRerankerConfig{TopK: 3, BatchSize: 5, MaxConcurrency: 3})
+
+		results, err := r.Rerank(context.Background(), "q", docs)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []int{5, 5, 2}, llm.batchSizes)
+		require.Len(t, results, 3)
+		assert.Equal(t, "11", results[0].Document.ID)
+		assert.Equal(t, "10", results[1].Document.ID)
+	})
+
+	t.Run("Sliding window", func(t *testing.T) {
+		llm := &scoringLLM{}
+		r := NewLLMReranker(llm, LLMRerankerConfig{TopK: 12, BatchSize: 5, WindowOverlap: 2})
+
+		results, err := r.Rerank(context.Background(), "q", docs)
+		require.NoError(t, err)
+		// Windows start at 0, 3, 6 and 9
+		assert.Equal(t, []int{5, 5, 5, 3}, llm.batchSizes)
+		require.Len(t, results, 12)
+		for _, res := range results {
+			var want float64
+			fmt.Sscanf(res.Document.Content, "doc-%f", &want)
+			assert.InDelta(t, want, res.Metadata["llm_rerank_score"], 1e-9)
+		}
+	})
+}
+
+func TestLLMReranker_MinCandidates(t *testing.T) {
+	docs := []rag.DocumentSearchResult{
+		{Document: rag.Document{ID: "a", Content: "doc-0.1"}, Score: 0.9},
+		{Document: rag.Document{ID: "b", Content: "doc-0.9"}, Score: 0.2},
+	}
+
+	llm := &scoringLLM{}
+	r := NewLLMReranker(llm, LLMRerankerConfig{TopK: 2, BatchSize: 5, MinCandidates: 3})
+	results, err := r.Rerank(context.Background(), "q", docs)
+	require.NoError(t, err)
+	assert.Equal(t, docs, results)
+	assert.Empty(t, llm.batchSizes, "the LLM should not be called below MinCandidates")
+
+	// At the threshold the candidates are reranked
+	docs = append(docs, rag.DocumentSearchResult{Document: rag.Document{ID: "c", Content: "doc-0.5"}})
+	results, err = r.Rerank(context.Background(), "q", docs)
+	require.NoError(t, err)
+	assert.Equal(t, []int{3}, llm.batchSizes)
+	assert.Equal(t, "b", results[0].Document.ID)
+}
@@ -0,0 +1,291 @@
+package retriever
+
+// PorterStemmer implements the Porter stemming algorithm for English.
+// Words containing characters other than lowercase ASCII letters are returned unchanged.
+type PorterStemmer struct{}
+
+// NewPorterStemmer creates a new PorterStemmer
+func NewPorterStemmer() *PorterStemmer {
+	return &PorterStemmer{}
+}
+
+// Stem returns the stem of word
+func (s *PorterStemmer) Stem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	p := &porter{b: []byte(word), k: len(word) - 1}
+	p.step1ab()
+	p.step1c()
+	p.step2()
+	p.step3()
+	p.step4()
+	p.step5()
+	return string(p.b[:p.k+1])
+}
+
+// porter holds the word being stemmed; b[0..k] is the current word and j marks
+// the end of the stem matched by the last call to ends
+type porter struct {
+	b    []byte
+	k, j int
+}
+
+// cons reports whether b[i] is a consonant
+func (p *porter) cons(i int) bool {
+	switch p.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		if i == 0 {
+			return true
+		}
+		return !p.cons(i - 1)
+	}
+	return true
+}
+
+// m measures the number of consonant sequences in b[0..j]
+func (p *porter) m() int {
+	n, i := 0, 0
+	for {
+		if i > p.j {
+			return n
+		}
+		if !p.cons(i) {
+			break
+		}
+		i++
+	}
+	i++
+	for {
+		for {
+			if i > p.j {
+				return n
+			}
+			if p.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+		n++
+		for {
+			if i > p.j {
+				return n
+			}
+			if !p.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+	}
+}
+
+// vowelInStem reports whether b[0..j] contains a vowel
+func (p *porter) vowelInStem() bool {
+	for i := 0; i <= p.j; i++ {
+		if !p.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doublec reports whether b[j-1..j] is a double consonant
+func (p *porter) doublec(j int) bool {
+	if j < 1 || p.b[j] != p.b[j-1] {
+		return false
+	}
+	return p.cons(j)
+}
+
+// cvc reports whether b[i-2..i] is consonant-vowel-consonant and the last
+// consonant is not w, x or y
+func (p *porter) cvc(i int) bool {
+	if i < 2 || !p.cons(i) || p.cons(i-1) || !p.cons(i-2) {
+		return false
+	}
+	switch p.b[i] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+// ends reports whether b[0..k] ends with s, setting j to the end of the stem
+func (p *porter) ends(s string) bool {
+	l := len(s)
+	if l > p.k+1 || string(p.b[p.k-l+1:p.k+1]) != s {
+		return false
+	}
+	p.j = p.k - l
+	return true
+}
+
+// setto replaces b[j+1..k] with s
+func (p *porter) setto(s string) {
+	p.b = append(p.b[:p.j+1], s...)
+	p.k = p.j + len(s)
+}
+
+// r replaces the suffix with s if the stem has at least one consonant sequence
+func (p *porter) r(s string) {
+	if p.m() > 0 {
+		p.setto(s)
+	}
+}
+
+// replaceFirst applies the first matching suffix rule; pairs are suffix, replacement
+func (p *porter) replaceFirst(rules ...string) {
+	for i := 0; i+1 < len(rules); i += 2 {
+		if p.ends(rules[i]) {
+			p.r(rules[i+1])
+			return
+		}
+	}
+}
+
+// step1ab removes plurals and -ed or -ing
+func (p *porter) step1ab() {
+	if p.b[p.k] == 's' {
+		switch {
+		case p.ends("sses"):
+			p.k -= 2
+		case p.ends("ies"):
+			p.setto("i")
+		case p.b[p.k-1] != 's':
+			p.k--
+		}
+	}
+	if p.ends("eed") {
+		if p.m() > 0 {
+			p.k--
+		}
+	} else if (p.ends("ed") || p.ends("ing")) && p.vowelInStem() {
+		p.k = p.j
+		switch {
+		case p.ends("at"):
+			p.setto("ate")
+		case p.ends("bl"):
+			p.setto("ble")
+		case p.ends("iz"):
+			p.setto("ize")
+		case p.doublec(p.k):
+			p.k--
+			switch p.b[p.k] {
+			case 'l', 's', 'z':
+				p.k++
+			}
+		default:
+			p.j = p.k
+			if p.m() == 1 && p.cvc(p.k) {
+				p.setto("e")
+			}
+		}
+	}
+}
+
+// step1c turns terminal y to i when there is another vowel in the stem
+func (p *porter) step1c() {
+	if p.ends("y") && p.vowelInStem() {
+		p.b[p.k] = 'i'
+	}
+}
+
+// step2 maps double suffixes to single ones
+func (p *porter) step2() {
+	if p.k < 1 {
+		return
+	}
+	switch p.b[p.k-1] {
+	case 'a':
+		p.replaceFirst("ational", "ate", "tional", "tion")
+	case 'c':
+		p.replaceFirst("enci", "ence", "anci", "ance")
+	case 'e':
+		p.replaceFirst("izer", "ize")
+	case 'l':
+		p.replaceFirst("bli", "ble", "alli", "al", "entli", "ent", "eli", "e", "ousli", "ous")
+	case 'o':
+		p.replaceFirst("ization", "ize", "ation", "ate", "ator", "ate")
+	case 's':
+		p.replaceFirst("alism", "al", "iveness", "ive", "fulness", "ful", "ousness", "ous")
+	case 't':
+		p.replaceFirst("aliti", "al", "iviti", "ive", "biliti", "ble")
+	case 'g':
+		p.replaceFirst("logi", "log")
+	}
+}
+
+// step3 handles -ic-, -full, -ness etc.
+func (p *porter) step3() {
+	switch p.b[p.k] {
+	case 'e':
+		p.replaceFirst("icate", "ic", "ative", "", "alize", "al")
+	case 'i':
+		p.replaceFirst("iciti", "ic")
+	case 'l':
+		p.replaceFirst("ical", "ic", "ful", "")
+	case 's':
+		p.replaceFirst("ness", "")
+	}
+}
+
+// step4 removes -ant, -ence etc. in context <c>vcvc<v>
+func (p *porter) step4() {
+	if p.k < 1 {
+		return
+	}
+	matched := false
+	switch p.b[p.k-1] {
+	case 'a':
+		matched = p.ends("al")
+	case 'c':
+		matched = p.ends("ance") || p.ends("ence")
+	case 'e':
+		matched = p.ends("er")
+	case 'i':
+		matched = p.ends("ic")
+	case 'l':
+		matched = p.ends("able") || p.ends("ible")
+	case 'n':
+		matched = p.ends("ant") || p.ends("ement") || p.ends("ment") || p.ends("ent")
+	case 'o':
+		matched = (p.ends("ion") && p.j >= 0 && (p.b[p.j] == 's' || p.b[p.j] == 't')) || p.ends("ou")
+	case 's':
+		matched = p.ends("ism")
+	case 't':
+		matched = p.ends("ate") || p.ends("iti")
+	case 'u':
+		matched = p.ends("ous")
+	case 'v':
+		matched = p.ends("ive")
+	case 'z':
+		matched = p.ends("ize")
+	}
+	if matched && p.m() > 1 {
+		p.k = p.j
+	}
+}
+
+// step5 removes a final -e and changes -ll to -l when the stem is long enough
+func (p *porter) step5() {
+	p.j = p.k
+	if p.b[p.k] == 'e' {
+		a := p.m()
+		if a > 1 || (a == 1 && !p.cvc(p.k-1)) {
+			p.k--
+		}
+	}
+	if p.b[p.k] == 'l' && p.doublec(p.k) && p.m() > 1 {
+		p.k--
+	}
+}
@@ -2,24 +2,111 @@ package retriever
 
 import (
 	"context"
-	"strings"
+	"net/http"
+	"sort"
+	"time"
 
+	"github.com/smallnest/langgraphgo/httputil"
 	"github.com/smallnest/langgraphgo/rag"
 )
 
+// RerankUsage reports what an API-backed reranker billed for a single call
+type RerankUsage struct {
+	// Model is the rerank model that served the request
+	Model string
+	// SearchUnits is the number of search units billed (Cohere)
+	SearchUnits int
+	// TotalTokens is the number of tokens billed
+	TotalTokens int
+}
+
+// resilientClient returns client, or a new resilient client with the given timeout if it is nil
+func resilientClient(client *http.Client, timeout time.Duration) *http.Client {
+	if client != nil {
+		return client
+	}
+	opts := httputil.DefaultOptions()
+	opts.Timeout = timeout
+	return httputil.NewResilientClient(opts)
+}
+
+// rerankInBatches reranks documents with rerank in batches of at most maxDocuments (0 sends
+// them in one request) and merges the results by score, keeping the topK best (0 keeps all).
+// The original_index metadata of merged results refers to documents, and the usage is summed.
+func rerankInBatches(
+	ctx context.Context,
+	query string,
+	documents []rag.DocumentSearchResult,
+	maxDocuments, topK int,
+	rerank func(ctx context.Context, query string, batch []rag.DocumentSearchResult) ([]rag.DocumentSearchResult, *RerankUsage, error),
+) ([]rag.DocumentSearchResult, *RerankUsage, error) {
+	if maxDocuments <= 0 || len(documents) <= maxDocuments {
+		return rerank(ctx, query, documents)
+	}
+
+	var results []rag.DocumentSearchResult
+	var usage *RerankUsage
+	for offset := 0; offset < len(documents); offset += maxDocuments {
+		batch := documents[offset:min(offset+maxDocuments, len(documents))]
+		batchResults, batchUsage, err := rerank(ctx, query, batch)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, result := range batchResults {
+			if index, ok := result.Metadata["original_index"].(int); ok {
+				result.Metadata["original_index"] = index + offset
+			}
+		}
+		results = append(results, batchResults...)
+		if usage == nil {
+			usage = batchUsage
+		} else {
+			usage.SearchUnits += batchUsage.SearchUnits
+			usage.TotalTokens += batchUsage.TotalTokens
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if topK > 0 && len(results) > topK {
+		results = results[:topK]
+	}
+	return results, usage, nil
+}
+
 // SimpleReranker is a simple reranker that scores documents based on keyword matching
+// or a custom score function
 type SimpleReranker struct {
-	// Can be extended with more sophisticated reranking logic
+	scorer ScoreFunc
 }
 
 // NewSimpleReranker creates a new SimpleReranker
 func NewSimpleReranker() *SimpleReranker {
-	return &SimpleReranker{}
+	return &SimpleReranker{scorer: KeywordScorer}
+}
+
+// NewSimpleRerankerWithScorer creates a SimpleReranker that scores documents with scorer
+// (e.g. KeywordScorer, JaccardScorer or a function from NewBM25Scorer)
+func NewSimpleRerankerWithScorer(scorer ScoreFunc) *SimpleReranker {
+	if scorer == nil {
+		scorer = KeywordScorer
+	}
+	return &SimpleReranker{scorer: scorer}
+}
+
+// NewSimpleRerankerWithTokenizer creates a SimpleReranker that matches keywords using tok
+// (e.g. NewEnglishTokenizer or NewChineseTokenizer)
+func NewSimpleRerankerWithTokenizer(tok Tokenizer) *SimpleReranker {
+	return NewSimpleRerankerWithScorer(NewKeywordScorer(tok))
 }
 
 // Rerank reranks documents based on query relevance
 func (r *SimpleReranker) Rerank(ctx context.Context, query string, documents []rag.DocumentSearchResult) ([]rag.DocumentSearchResult, error) {
-	queryTerms := strings.Fields(strings.ToLower(query))
+	scorer := r.scorer
+	if scorer == nil {
+		scorer = KeywordScorer
+	}
 
 	type docScore struct {
 		doc   rag.DocumentSearchResult
@@ -28,18 +115,7 @@ func (r *SimpleReranker) Rerank(ctx context.Context, query string, documents []r
 
 	scores := make([]docScore, len(documents))
 	for i, docResult := range documents {
-		content := strings.ToLower(docResult.Document.Content)
-
-		// Simple scoring: count query term occurrences
-		var score float64
-		for _, term := range queryTerms {
-			score += float64(strings.Count(content, term))
-		}
-
-		// Normalize by document length
-		if len(content) > 0 {
-			score = score / float64(len(content)) * 1000
-		}
+		score := scorer(query, docResult.Document)
 
 		// Combine with original score
 		finalScore := 0.7*docResult.Score + 0.3*score
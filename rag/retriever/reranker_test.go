@@ -2,6 +2,9 @@ package retriever
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/smallnest/langgraphgo/rag"
@@ -24,3 +27,214 @@ func TestSimpleReranker(t *testing.T) {
 		assert.Greater(t, res[0].Score, 0.5)
 	})
 }
+
+func TestCohereReranker_UsageAndMaxDocuments(t *testing.T) {
+	scores := map[string]float64{"a": 0.4, "b": 0.9, "c": 0.7}
+	var batches [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var received cohereRerankRequest
+		_ = json.NewDecoder(req.Body).Decode(&received)
+		var batch []string
+		resp := cohereRerankResponse{}
+		resp.Meta.BilledUnits.SearchUnits = 1
+		for i, doc := range received.Documents {
+			batch = append(batch, doc.Text)
+			resp.Results = append(resp.Results, cohereRerankResult{Index: i, RelevanceScore: scores[doc.Text]})
+		}
+		batches = append(batches, batch)
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	config := DefaultCohereRerankerConfig()
+	config.APIBase = server.URL
+	config.MaxDocuments = 2
+	config.TopK = 2
+	r := NewCohereReranker("test-key", config)
+	assert.Equal(t, "rerank-v3.5", r.Model())
+
+	docs := []rag.DocumentSearchResult{
+		{Document: rag.Document{Content: "a"}},
+		{Document: rag.Document{Content: "b"}},
+		{Document: rag.Document{Content: "c"}},
+	}
+	results, usage, err := r.RerankWithUsage(context.Background(), "q", docs)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, batches)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "b", results[0].Document.Content)
+		assert.Equal(t, "c", results[1].Document.Content)
+		assert.Equal(t, 2, results[1].Metadata["original_index"])
+	}
+	assert.Equal(t, 2, usage.SearchUnits)
+	assert.Equal(t, "rerank-v3.5", usage.Model)
+}
+
+func TestJinaReranker_Usage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(`{"model":"jina-test","results":[{"index":0,"relevance_score":0.8}],"usage":{"total_tokens":42}}`))
+	}))
+	defer server.Close()
+
+	config := DefaultJinaRerankerConfig()
+	config.APIBase = server.URL
+	r := NewJinaReranker("test-key", config)
+
+	docs := []rag.DocumentSearchResult{{Document: rag.Document{Content: "a"}}}
+	_, usage, err := r.RerankWithUsage(context.Background(), "q", docs)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, usage.TotalTokens)
+	assert.Equal(t, "jina-test", usage.Model)
+}
+
+func TestSimpleRerankerWithScorer(t *testing.T) {
+	ctx := context.Background()
+	docs := []rag.DocumentSearchResult{
+		{Document: rag.Document{ID: "a", Content: "cats and dogs play in the garden"}, Score: 0.5},
+		{Document: rag.Document{ID: "b", Content: "graph databases store nodes and edges"}, Score: 0.5},
+	}
+
+	t.Run("Custom scorer", func(t *testing.T) {
+		r := NewSimpleRerankerWithScorer(func(query string, doc rag.Document) float64 {
+			if doc.ID == "b" {
+				return 1
+			}
+			return 0
+		})
+		res, err := r.Rerank(ctx, "anything", docs)
+		assert.NoError(t, err)
+		assert.Equal(t, "b", res[0].Document.ID)
+	})
+
+	t.Run("Jaccard", func(t *testing.T) {
+		assert.InDelta(t, 1.0, JaccardScorer("Graph nodes", rag.Document{Content: "nodes, graph"}), 1e-9)
+		assert.InDelta(t, 1.0/3.0, JaccardScorer("graph nodes", rag.Document{Content: "graph edges"}), 1e-9)
+		assert.Zero(t, JaccardScorer("", rag.Document{Content: "graph"}))
+
+		res, err := NewSimpleRerankerWithScorer(JaccardScorer).Rerank(ctx, "graph edges", docs)
+		assert.NoError(t, err)
+		assert.Equal(t, "b", res[0].Document.ID)
+	})
+
+	t.Run("BM25", func(t *testing.T) {
+		corpus := []rag.Document{docs[0].Document, docs[1].Document}
+		scorer := NewBM25Scorer(corpus, DefaultBM25Config())
+		assert.Greater(t, scorer("dogs garden", corpus[0]), scorer("dogs garden", corpus[1]))
+		assert.Zero(t, scorer("unknown", corpus[0]))
+
+		res, err := NewSimpleRerankerWithScorer(scorer).Rerank(ctx, "graph nodes", docs)
+		assert.NoError(t, err)
+		assert.Equal(t, "b", res[0].Document.ID)
+	})
+}
+
+func TestCohereReranker_MinCandidates(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`{"results":[{"index":1,"relevance_score":0.9},{"index":0,"relevance_score":0.4}]}`))
+	}))
+	defer server.Close()
+
+	config := DefaultCohereRerankerConfig()
+	config.APIBase = server.URL
+	config.MinCandidates = 3
+	r := NewCohereReranker("test-key", config)
+
+	docs := []rag.DocumentSearchResult{
+		{Document: rag.Document{Content: "a"}, Score: 0.8},
+		{Document: rag.Document{Content: "b"}, Score: 0.6},
+	}
+	results, err := r.Rerank(context.Background(), "q", docs)
+	assert.NoError(t, err)
+	assert.Equal(t, docs, results)
+	assert.Equal(t, 0, calls)
+}
+
+func TestCohereReranker_TypedErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"message":"too many requests"}`))
+	}))
+
+	config := DefaultCohereRerankerConfig()
+	config.APIBase = server.URL
+	config.HTTPClient = server.Client() // no retries
+	r := NewCohereReranker("test-key", config)
+	docs := []rag.DocumentSearchResult{{Document: rag.Document{Content: "a"}}}
+
+	_, err := r.Rerank(context.Background(), "q", docs)
+	assert.ErrorIs(t, err, rag.ErrRateLimited)
+	var statusErr *rag.StatusError
+	assert.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusTooManyRequests, statusErr.StatusCode)
+
+	server.Close()
+	_, err = r.Rerank(context.Background(), "q", docs)
+	assert.ErrorIs(t, err, rag.ErrConnection)
+}
+
+func TestHTTPReranker(t *testing.T) {
+	docs := []rag.DocumentSearchResult{
+		{Document: rag.Document{ID: "a", Content: "a"}, Score: 0.7},
+		{Document: rag.Document{ID: "b", Content: "b"}, Score: 0.6},
+		{Document: rag.Document{ID: "c", Content: "c"}, Score: 0.5},
+	}
+
+	t.Run("Cohere format", func(t *testing.T) {
+		var path string
+		var received map[string]any
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			path = req.URL.Path
+			_ = json.NewDecoder(req.Body).Decode(&received)
+			_, _ = w.Write([]byte(`{"model":"bge-reranker","results":[{"index":2,"relevance_score":0.9},{"index":0,"relevance_score":0.3}],"usage":{"total_tokens":7}}`))
+		}))
+		defer server.Close()
+
+		config := DefaultHTTPRerankerConfig()
+		config.TopK = 2
+		r := NewHTTPReranker(server.URL+"/v1/rerank", "bge-reranker", config)
+		results, usage, err := r.RerankWithUsage(context.Background(), "q", docs)
+		assert.NoError(t, err)
+		assert.Equal(t, "/v1/rerank", path)
+		assert.Equal(t, map[string]any{"model": "bge-reranker", "query": "q", "documents": []any{"a", "b", "c"}, "top_n": 2.0}, received)
+		assert.Equal(t, 7, usage.TotalTokens)
+		assert.Len(t, results, 2)
+		assert.Equal(t, "c", results[0].Document.ID)
+		assert.Equal(t, 0.9, results[0].Score)
+		assert.Equal(t, 0.5, results[0].Metadata["original_score"])
+	})
+
+	t.Run("TEI format", func(t *testing.T) {
+		var path string
+		var received map[string]any
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			path = req.URL.Path
+			_ = json.NewDecoder(req.Body).Decode(&received)
+			_, _ = w.Write([]byte(`[{"index":0,"score":0.2},{"index":1,"score":0.8},{"index":2,"score":0.5}]`))
+		}))
+		defer server.Close()
+
+		config := DefaultHTTPRerankerConfig()
+		config.Format = HTTPRerankFormatTEI
+		config.TopK = 2
+		r := NewHTTPReranker(server.URL+"/", "", config)
+		results, err := r.Rerank(context.Background(), "q", docs)
+		assert.NoError(t, err)
+		assert.Equal(t, "/rerank", path)
+		assert.Equal(t, map[string]any{"query": "q", "texts": []any{"a", "b", "c"}, "truncate": true}, received)
+		assert.Len(t, results, 2)
+		assert.Equal(t, "b", results[0].Document.ID)
+		assert.Equal(t, "c", results[1].Document.ID)
+	})
+
+	t.Run("Typed errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}))
+		defer server.Close()
+
+		_, err := NewHTTPReranker(server.URL, "", DefaultHTTPRerankerConfig()).Rerank(context.Background(), "q", docs)
+		assert.ErrorIs(t, err, rag.ErrInvalidQuery)
+	})
+}
@@ -0,0 +1,146 @@
+package retriever
+
+import (
+	"math"
+	"strings"
+
+	"github.com/smallnest/langgraphgo/rag"
+	"github.com/smallnest/langgraphgo/rag/tokenizer"
+)
+
+// ScoreFunc scores how relevant a document is to a query; higher is more relevant
+type ScoreFunc func(query string, doc rag.Document) float64
+
+// KeywordScorer counts query term occurrences in the document, normalized by document length.
+// This is the default scoring used by SimpleReranker.
+func KeywordScorer(query string, doc rag.Document) float64 {
+	queryTerms := strings.Fields(strings.ToLower(query))
+	content := strings.ToLower(doc.Content)
+
+	var score float64
+	for _, term := range queryTerms {
+		score += float64(strings.Count(content, term))
+	}
+
+	// Normalize by document length
+	if len(content) > 0 {
+		score = score / float64(len(content)) * 1000
+	}
+	return score
+}
+
+// NewKeywordScorer creates a score function that counts query term occurrences in the
+// document after tokenizing both with tok, normalized by the document token count
+func NewKeywordScorer(tok Tokenizer) ScoreFunc {
+	return func(query string, doc rag.Document) float64 {
+		docTokens := tok.Tokenize(doc.Content)
+		if len(docTokens) == 0 {
+			return 0
+		}
+		queryTerms := termSet(tok.Tokenize(query))
+
+		var matches int
+		for _, token := range docTokens {
+			if queryTerms[token] {
+				matches++
+			}
+		}
+		return float64(matches) / float64(len(docTokens)) * 100
+	}
+}
+
+// JaccardScorer scores a document by the Jaccard similarity of the query and document term sets
+func JaccardScorer(query string, doc rag.Document) float64 {
+	return jaccard(tokenizer.NewSimpleTokenizer(true, true), query, doc)
+}
+
+// NewJaccardScorer creates a Jaccard score function that tokenizes with tok
+func NewJaccardScorer(tok Tokenizer) ScoreFunc {
+	return func(query string, doc rag.Document) float64 {
+		return jaccard(tok, query, doc)
+	}
+}
+
+func jaccard(tok Tokenizer, query string, doc rag.Document) float64 {
+	queryTerms := termSet(tok.Tokenize(query))
+	docTerms := termSet(tok.Tokenize(doc.Content))
+	if len(queryTerms) == 0 || len(docTerms) == 0 {
+		return 0
+	}
+
+	intersection := 0
+	for term := range queryTerms {
+		if docTerms[term] {
+			intersection++
+		}
+	}
+	union := len(queryTerms) + len(docTerms) - intersection
+	return float64(intersection) / float64(union)
+}
+
+// NewBM25Scorer creates a BM25 score function whose document frequencies and average
+// document length are computed from corpus. Documents outside the corpus can still be
+// scored; their terms are weighted using the corpus statistics.
+func NewBM25Scorer(corpus []rag.Document, config BM25Config) ScoreFunc {
+	return NewBM25ScorerWithTokenizer(corpus, config, tokenizer.NewSimpleTokenizer(true, true))
+}
+
+// NewBM25ScorerWithTokenizer creates a BM25 score function with a custom tokenizer
+// (e.g. NewEnglishTokenizer for stop-word removal and stemming)
+func NewBM25ScorerWithTokenizer(corpus []rag.Document, config BM25Config, tok Tokenizer) ScoreFunc {
+	if config.K1 == 0 {
+		config.K1 = 1.5
+	}
+	if config.B == 0 {
+		config.B = 0.75
+	}
+
+	docFreqs := make(map[string]int)
+	totalLength := 0
+	for _, doc := range corpus {
+		tokens := tok.Tokenize(doc.Content)
+		totalLength += len(tokens)
+		for term := range termSet(tokens) {
+			docFreqs[term]++
+		}
+	}
+	numDocs := len(corpus)
+	avgDocLength := 1.0
+	if numDocs > 0 && totalLength > 0 {
+		avgDocLength = float64(totalLength) / float64(numDocs)
+	}
+
+	return func(query string, doc rag.Document) float64 {
+		tokens := tok.Tokenize(doc.Content)
+		if len(tokens) == 0 {
+			return 0
+		}
+		termCounts := make(map[string]int)
+		for _, token := range tokens {
+			termCounts[token]++
+		}
+
+		var score float64
+		for term := range termSet(tok.Tokenize(query)) {
+			freq := termCounts[term]
+			if freq == 0 {
+				continue
+			}
+			docFreq := docFreqs[term]
+			idf := math.Log((float64(numDocs)-float64(docFreq)+0.5)/(float64(docFreq)+0.5) + 1.0)
+			numerator := float64(freq) * (config.K1 + 1.0)
+			denominator := float64(freq) + config.K1*(1.0-config.B+config.B*(float64(len(tokens))/avgDocLength))
+			score += idf * numerator / denominator
+		}
+		return score
+	}
+}
+
+// termSet returns the distinct terms of a token list
+func termSet(tokens []string) map[string]bool {
+	set := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		set[token] = true
+	}
+	return set
+}
@@ -0,0 +1,139 @@
+package retriever
+
+import (
+	"strings"
+
+	"github.com/smallnest/langgraphgo/rag/tokenizer"
+)
+
+// Tokenizer splits text into terms for keyword scoring.
+// It has the same method set as tokenizer.Tokenizer, so segmenters from the
+// rag/tokenizer package (e.g. tokenizer.NewChineseTokenizer) or third-party CJK
+// segmenters can be used directly.
+type Tokenizer interface {
+	Tokenize(text string) []string
+}
+
+// Stemmer reduces a word to its stem
+type Stemmer interface {
+	Stem(word string) string
+}
+
+// TokenizerConfig configures a TextTokenizer
+type TokenizerConfig struct {
+	// Segmenter splits text into raw tokens.
+	// Defaults to splitting on whitespace and punctuation.
+	Segmenter Tokenizer
+
+	// Lowercase converts tokens to lowercase
+	Lowercase bool
+
+	// StopWords are removed after lowercasing (matching is case-insensitive when Lowercase is set)
+	StopWords []string
+
+	// Stemmer, if set, is applied to every remaining token
+	Stemmer Stemmer
+}
+
+// DefaultTokenizerConfig returns a configuration that lowercases and removes English stop words
+func DefaultTokenizerConfig() TokenizerConfig {
+	return TokenizerConfig{
+		Lowercase: true,
+		StopWords: EnglishStopWords,
+	}
+}
+
+// TextTokenizer tokenizes text with optional lowercasing, stop-word removal and stemming
+type TextTokenizer struct {
+	segmenter Tokenizer
+	lowercase bool
+	stopWords map[string]bool
+	stemmer   Stemmer
+}
+
+// NewTokenizer creates a new TextTokenizer
+func NewTokenizer(config TokenizerConfig) *TextTokenizer {
+	segmenter := config.Segmenter
+	if segmenter == nil {
+		segmenter = tokenizer.NewSimpleTokenizer(false, true)
+	}
+
+	stopWords := make(map[string]bool, len(config.StopWords))
+	for _, word := range config.StopWords {
+		if config.Lowercase {
+			word = strings.ToLower(word)
+		}
+		stopWords[word] = true
+	}
+
+	return &TextTokenizer{
+		segmenter: segmenter,
+		lowercase: config.Lowercase,
+		stopWords: stopWords,
+		stemmer:   config.Stemmer,
+	}
+}
+
+// NewEnglishTokenizer creates a tokenizer that lowercases, removes English stop words
+// and applies Porter stemming
+func NewEnglishTokenizer() *TextTokenizer {
+	config := DefaultTokenizerConfig()
+	config.Stemmer = NewPorterStemmer()
+	return NewTokenizer(config)
+}
+
+// NewChineseTokenizer creates a tokenizer that segments Chinese text into characters
+// and removes common Chinese and English stop words
+func NewChineseTokenizer() *TextTokenizer {
+	stopWords := make([]string, 0, len(ChineseStopWords)+len(EnglishStopWords))
+	stopWords = append(stopWords, ChineseStopWords...)
+	stopWords = append(stopWords, EnglishStopWords...)
+	return NewTokenizer(TokenizerConfig{
+		Segmenter: tokenizer.NewChineseTokenizer(),
+		Lowercase: true,
+		StopWords: stopWords,
+	})
+}
+
+// Tokenize splits text into processed terms
+func (t *TextTokenizer) Tokenize(text string) []string {
+	raw := t.segmenter.Tokenize(text)
+	tokens := make([]string, 0, len(raw))
+	for _, token := range raw {
+		if t.lowercase {
+			token = strings.ToLower(token)
+		}
+		if token == "" || t.stopWords[token] {
+			continue
+		}
+		if t.stemmer != nil {
+			token = t.stemmer.Stem(token)
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// EnglishStopWords is a list of common English stop words
+var EnglishStopWords = []string{
+	"a", "about", "above", "after", "again", "against", "all", "am", "an", "and", "any", "are", "as", "at",
+	"be", "because", "been", "before", "being", "below", "between", "both", "but", "by",
+	"can", "could", "did", "do", "does", "doing", "down", "during",
+	"each", "few", "for", "from", "further", "had", "has", "have", "having", "he", "her", "here", "hers",
+	"herself", "him", "himself", "his", "how", "i", "if", "in", "into", "is", "it", "its", "itself",
+	"just", "me", "more", "most", "my", "myself", "no", "nor", "not", "now",
+	"of", "off", "on", "once", "only", "or", "other", "our", "ours", "ourselves", "out", "over", "own",
+	"same", "she", "should", "so", "some", "such",
+	"than", "that", "the", "their", "theirs", "them", "themselves", "then", "there", "these", "they",
+	"this", "those", "through", "to", "too", "under", "until", "up", "very",
+	"was", "we", "were", "what", "when", "where", "which", "while", "who", "whom", "why", "will", "with",
+	"would", "you", "your", "yours", "yourself", "yourselves",
+}
+
+// ChineseStopWords is a list of common Chinese stop words (single characters, matching
+// the character-level segmentation of tokenizer.ChineseTokenizer)
+var ChineseStopWords = []string{
+	"的", "了", "是", "在", "和", "与", "及", "或", "也", "就", "都", "而", "但", "被", "把",
+	"这", "那", "之", "其", "着", "过", "吗", "呢", "吧", "啊", "么", "个", "有", "为", "以",
+	"于", "将", "对", "从", "到", "由", "我", "你", "他", "她", "它", "们",
+}
@@ -0,0 +1,80 @@
+package retriever
+
+import (
+	"context"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/rag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPorterStemmer(t *testing.T) {
+	s := NewPorterStemmer()
+	cases := map[string]string{
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"cats":           "cat",
+		"agreed":         "agre",
+		"running":        "run",
+		"hopping":        "hop",
+		"filing":         "file",
+		"happy":          "happi",
+		"relational":     "relat",
+		"generalization": "gener",
+		"effective":      "effect",
+		"controll":       "control",
+		"as":             "as",
+		"Graph":          "Graph",
+		"检索":             "检索",
+	}
+	for word, want := range cases {
+		assert.Equal(t, want, s.Stem(word), word)
+	}
+}
+
+func TestTextTokenizer(t *testing.T) {
+	t.Run("Default config removes stop words", func(t *testing.T) {
+		tok := NewTokenizer(DefaultTokenizerConfig())
+		assert.Equal(t, []string{"graph", "agents", "running"}, tok.Tokenize("The Graph, and the agents running!"))
+	})
+
+	t.Run("English tokenizer stems", func(t *testing.T) {
+		tok := NewEnglishTokenizer()
+		assert.Equal(t, []string{"graph", "agent", "run"}, tok.Tokenize("The Graph, and the agents running!"))
+	})
+
+	t.Run("Chinese tokenizer", func(t *testing.T) {
+		tok := NewChineseTokenizer()
+		assert.Equal(t, []string{"图", "数", "据", "库"}, tok.Tokenize("的图数据库"))
+	})
+
+	t.Run("Custom segmenter", func(t *testing.T) {
+		tok := NewTokenizer(TokenizerConfig{
+			Segmenter: staticTokenizer{"检索", "的", "增强"},
+			StopWords: ChineseStopWords,
+		})
+		assert.Equal(t, []string{"检索", "增强"}, tok.Tokenize("ignored"))
+	})
+}
+
+func TestSimpleRerankerWithTokenizer(t *testing.T) {
+	docs := []rag.DocumentSearchResult{
+		{Document: rag.Document{ID: "stop", Content: "the the the and of the"}, Score: 0.5},
+		{Document: rag.Document{ID: "match", Content: "agents running graphs"}, Score: 0.5},
+	}
+
+	res, err := NewSimpleRerankerWithTokenizer(NewEnglishTokenizer()).Rerank(context.Background(), "the agent runs a graph", docs)
+	assert.NoError(t, err)
+	assert.Equal(t, "match", res[0].Document.ID)
+	assert.Equal(t, 0.5*0.7, res[1].Score)
+
+	scorer := NewBM25ScorerWithTokenizer([]rag.Document{docs[0].Document, docs[1].Document}, DefaultBM25Config(), NewEnglishTokenizer())
+	assert.Greater(t, scorer("running agent", docs[1].Document), 0.0)
+	assert.Zero(t, scorer("the", docs[0].Document))
+}
+
+type staticTokenizer []string
+
+func (s staticTokenizer) Tokenize(string) []string {
+	return s
+}
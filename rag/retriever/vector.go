@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/smallnest/langgraphgo/internal/vector"
 	"github.com/smallnest/langgraphgo/rag"
 )
 
@@ -67,17 +68,10 @@ func (r *VectorRetriever) RetrieveWithConfig(ctx context.Context, query string,
 		return nil, fmt.Errorf("failed to embed query: %w", err)
 	}
 
-	// Perform vector search
-	var results []rag.DocumentSearchResult
-
-	if len(config.Filter) > 0 {
-		// Search with filters
-		results, err = r.vectorStore.SearchWithFilter(ctx, queryEmbedding, config.K, config.Filter)
-	} else {
-		// Simple search
-		results, err = r.vectorStore.Search(ctx, queryEmbedding, config.K)
-	}
-
+	// Perform vector search. MMR compares the stored embeddings of the results, so they are
+	// fetched for it even when the caller did not ask for them.
+	includeEmbeddings := config.IncludeEmbeddings || config.SearchType == "mmr"
+	results, err := rag.SearchVectorStore(ctx, r.vectorStore, queryEmbedding, config.K, config.Filter, includeEmbeddings)
 	if err != nil {
 		return nil, fmt.Errorf("vector search failed: %w", err)
 	}
@@ -101,6 +95,13 @@ func (r *VectorRetriever) RetrieveWithConfig(ctx context.Context, query string,
 		results = r.applyDiversitySearch(results, config.K)
 	}
 
+	if includeEmbeddings && !config.IncludeEmbeddings {
+		for i := range results {
+			results[i].Embedding = nil
+		}
+	}
+	rag.ProjectMetadata(results, config.MetadataFields)
+
 	return results, nil
 }
 
@@ -127,7 +128,7 @@ func (r *VectorRetriever) applyMMR(results []rag.DocumentSearchResult, k int) []
 			// Calculate maximal similarity to already selected documents
 			maxSimilarity := 0.0
 			for _, selectedDoc := range selected {
-				similarity := r.calculateSimilarity(candidate.Document, selectedDoc.Document)
+				similarity := r.calculateSimilarity(candidate, selectedDoc)
 				if similarity > maxSimilarity {
 					maxSimilarity = similarity
 				}
@@ -210,35 +211,15 @@ func (r *VectorRetriever) applyDiversitySearch(results []rag.DocumentSearchResul
 	return selected
 }
 
-// calculateSimilarity calculates similarity between two documents
-func (r *VectorRetriever) calculateSimilarity(doc1, doc2 rag.Document) float64 {
+// calculateSimilarity calculates similarity between two search results
+func (r *VectorRetriever) calculateSimilarity(result1, result2 rag.DocumentSearchResult) float64 {
 	// Use embeddings if available
-	if len(doc1.Embedding) > 0 && len(doc2.Embedding) > 0 {
-		return cosineSimilarity(doc1.Embedding, doc2.Embedding)
+	if len(result1.Embedding) > 0 && len(result2.Embedding) > 0 {
+		return vector.Cosine(result1.Embedding, result2.Embedding)
 	}
 
 	// Fallback to content similarity
-	return contentSimilarity(doc1.Content, doc2.Content)
-}
-
-// cosineSimilarity calculates cosine similarity between two embeddings
-func cosineSimilarity(a, b []float32) float64 {
-	if len(a) != len(b) {
-		return 0.0
-	}
-
-	var dotProduct, normA, normB float32
-	for i := range a {
-		dotProduct += a[i] * b[i]
-		normA += a[i] * a[i]
-		normB += b[i] * b[i]
-	}
-
-	if normA == 0 || normB == 0 {
-		return 0.0
-	}
-
-	return float64(dotProduct / (normA * normB))
+	return contentSimilarity(result1.Document.Content, result2.Document.Content)
 }
 
 // contentSimilarity calculates similarity between document contents
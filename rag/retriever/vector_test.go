@@ -38,6 +38,11 @@ func (m *mockVectorStore) GetStats(ctx context.Context) (*rag.VectorStoreStats,
 	return nil, nil
 }
 
+func (m *mockVectorStore) Clear(ctx context.Context) error {
+	m.docs = nil
+	return nil
+}
+
 func TestVectorRetriever(t *testing.T) {
 	ctx := context.Background()
 	store := &mockVectorStore{
@@ -97,3 +102,46 @@ func TestContentSimilarity(t *testing.T) {
 	assert.Greater(t, sim, 0.0)
 	assert.Less(t, sim, 1.0)
 }
+
+func TestVectorRetriever_IncludeEmbeddings(t *testing.T) {
+	ctx := context.Background()
+	store := &mockVectorStore{
+		docs: []rag.Document{
+			{ID: "doc1", Content: "content 1", Embedding: []float32{0.1, 0.2}},
+			{ID: "doc2", Content: "content 2", Embedding: []float32{0.2, 0.1}},
+		},
+	}
+	r := NewVectorRetriever(store, &mockEmbedder{}, rag.RetrievalConfig{K: 2})
+
+	results, err := r.RetrieveWithConfig(ctx, "q", &rag.RetrievalConfig{K: 2, IncludeEmbeddings: true})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, []float32{0.2, 0.1}, results[1].Embedding)
+
+	// Embeddings fetched only for MMR are not returned
+	results, err = r.RetrieveWithConfig(ctx, "q", &rag.RetrievalConfig{K: 2, SearchType: "mmr"})
+	assert.NoError(t, err)
+	for _, result := range results {
+		assert.Nil(t, result.Embedding)
+	}
+}
+
+func TestVectorRetriever_MetadataFields(t *testing.T) {
+	ctx := context.Background()
+	metadata := map[string]any{"title": "Doc 1", "source": "a.txt", "raw": "large blob"}
+	store := &mockVectorStore{
+		docs: []rag.Document{{ID: "doc1", Content: "content 1", Metadata: metadata}},
+	}
+	r := NewVectorRetriever(store, &mockEmbedder{}, rag.RetrievalConfig{K: 1})
+
+	results, err := r.RetrieveWithConfig(ctx, "q", &rag.RetrievalConfig{K: 1, MetadataFields: []string{"title", "missing"}})
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, map[string]any{"title": "Doc 1"}, results[0].Document.Metadata)
+	// The stored metadata is left untouched
+	assert.Len(t, metadata, 3)
+
+	results, err = r.RetrieveWithConfig(ctx, "q", &rag.RetrievalConfig{K: 1})
+	assert.NoError(t, err)
+	assert.Len(t, results[0].Document.Metadata, 3)
+}
@@ -0,0 +1,189 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/smallnest/langgraphgo/rag"
+)
+
+// rrfK is the constant used by reciprocal rank fusion
+const rrfK = 60
+
+// ErrWebRetrieval is returned, together with the vector results, when the web retriever
+// of a VectorPlusWebRetriever fails
+var ErrWebRetrieval = errors.New("web retrieval failed")
+
+// VectorPlusWebRetriever runs vector retrieval first and, when the best vector score is
+// below the confidence threshold, augments the results with web search results.
+// Results are fused with reciprocal rank fusion and deduplicated by ID and content.
+type VectorPlusWebRetriever struct {
+	vectorStore         rag.VectorStore
+	embedder            rag.Embedder
+	webRetriever        rag.Retriever
+	confidenceThreshold float64
+	config              rag.RetrievalConfig
+}
+
+// NewVectorPlusWebRetriever creates a new retriever that falls back to web results when
+// the top vector score is below confidenceThreshold
+func NewVectorPlusWebRetriever(vectorStore rag.VectorStore, embedder rag.Embedder, webRetriever rag.Retriever, confidenceThreshold float64) *VectorPlusWebRetriever {
+	return &VectorPlusWebRetriever{
+		vectorStore:         vectorStore,
+		embedder:            embedder,
+		webRetriever:        webRetriever,
+		confidenceThreshold: confidenceThreshold,
+		config: rag.RetrievalConfig{
+			K:          4,
+			SearchType: "similarity",
+		},
+	}
+}
+
+// Retrieve retrieves documents based on a query
+func (r *VectorPlusWebRetriever) Retrieve(ctx context.Context, query string) ([]rag.Document, error) {
+	return r.RetrieveWithK(ctx, query, r.config.K)
+}
+
+// RetrieveWithK retrieves exactly k documents
+func (r *VectorPlusWebRetriever) RetrieveWithK(ctx context.Context, query string, k int) ([]rag.Document, error) {
+	config := r.config
+	config.K = k
+	results, err := r.RetrieveWithConfig(ctx, query, &config)
+	if err != nil && !errors.Is(err, ErrWebRetrieval) {
+		return nil, err
+	}
+
+	docs := make([]rag.Document, len(results))
+	for i, result := range results {
+		docs[i] = result.Document
+	}
+
+	return docs, err
+}
+
+// RetrieveWithConfig retrieves documents with custom configuration.
+// If the web retriever fails, the vector results are returned on their own together with
+// an error wrapping ErrWebRetrieval and the web retriever's error.
+func (r *VectorPlusWebRetriever) RetrieveWithConfig(ctx context.Context, query string, config *rag.RetrievalConfig) ([]rag.DocumentSearchResult, error) {
+	if config == nil {
+		config = &r.config
+	}
+	k := config.K
+	if k <= 0 {
+		k = r.config.K
+	}
+
+	queryEmbedding, err := r.embedder.EmbedDocument(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	var vectorResults []rag.DocumentSearchResult
+	if len(config.Filter) > 0 {
+		vectorResults, err = r.vectorStore.SearchWithFilter(ctx, queryEmbedding, k, config.Filter)
+	} else {
+		vectorResults, err = r.vectorStore.Search(ctx, queryEmbedding, k)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("vector search failed: %w", err)
+	}
+
+	topScore := 0.0
+	for _, result := range vectorResults {
+		topScore = max(topScore, result.Score)
+	}
+
+	vectorResults = tagResults(vectorResults, "vector")
+	if len(vectorResults) > 0 && topScore >= r.confidenceThreshold {
+		return vectorResults, nil
+	}
+
+	if r.webRetriever == nil {
+		return vectorResults, nil
+	}
+
+	webResults, err := r.webRetriever.RetrieveWithConfig(ctx, query, &rag.RetrievalConfig{K: k})
+	if err != nil {
+		return vectorResults, fmt.Errorf("%w: %w", ErrWebRetrieval, err)
+	}
+	webResults = tagResults(webResults, "web")
+
+	return fuseResults(k, vectorResults, webResults), nil
+}
+
+// tagResults records the retrieval source in each result's metadata
+func tagResults(results []rag.DocumentSearchResult, source string) []rag.DocumentSearchResult {
+	tagged := make([]rag.DocumentSearchResult, len(results))
+	for i, result := range results {
+		metadata := make(map[string]any, len(result.Metadata)+1)
+		maps.Copy(metadata, result.Metadata)
+		metadata["retrieval_source"] = source
+		result.Metadata = metadata
+		tagged[i] = result
+	}
+	return tagged
+}
+
+// fuseResults merges ranked result lists with reciprocal rank fusion,
+// deduplicating documents by ID or normalized content, and keeps the top k
+func fuseResults(k int, lists ...[]rag.DocumentSearchResult) []rag.DocumentSearchResult {
+	type fused struct {
+		result rag.DocumentSearchResult
+		score  float64
+		order  int
+	}
+
+	byKey := make(map[string]*fused)
+	var merged []*fused
+	var order int
+	for _, list := range lists {
+		for rank, result := range list {
+			keys := []string{"content:" + strings.Join(strings.Fields(strings.ToLower(result.Document.Content)), " ")}
+			if result.Document.ID != "" {
+				keys = append(keys, "id:"+result.Document.ID)
+			}
+			contribution := 1.0 / float64(rrfK+rank+1)
+
+			var entry *fused
+			for _, key := range keys {
+				if existing, ok := byKey[key]; ok {
+					entry = existing
+					break
+				}
+			}
+			if entry != nil {
+				entry.score += contribution
+			} else {
+				entry = &fused{result: result, score: contribution, order: order}
+				merged = append(merged, entry)
+				order++
+			}
+			for _, key := range keys {
+				byKey[key] = entry
+			}
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].score != merged[j].score {
+			return merged[i].score > merged[j].score
+		}
+		return merged[i].order < merged[j].order
+	})
+
+	if k > 0 && len(merged) > k {
+		merged = merged[:k]
+	}
+
+	results := make([]rag.DocumentSearchResult, len(merged))
+	for i, f := range merged {
+		results[i] = f.result
+		results[i].Metadata["fusion_score"] = f.score
+	}
+	return results
+}
@@ -0,0 +1,80 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/rag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticRetriever struct {
+	results []rag.DocumentSearchResult
+	err     error
+	calls   int
+}
+
+func (s *staticRetriever) Retrieve(ctx context.Context, query string) ([]rag.Document, error) {
+	return s.RetrieveWithK(ctx, query, len(s.results))
+}
+
+func (s *staticRetriever) RetrieveWithK(ctx context.Context, query string, k int) ([]rag.Document, error) {
+	results, _ := s.RetrieveWithConfig(ctx, query, &rag.RetrievalConfig{K: k})
+	docs := make([]rag.Document, len(results))
+	for i, r := range results {
+		docs[i] = r.Document
+	}
+	return docs, nil
+}
+
+func (s *staticRetriever) RetrieveWithConfig(ctx context.Context, query string, config *rag.RetrievalConfig) ([]rag.DocumentSearchResult, error) {
+	s.calls++
+	return s.results, s.err
+}
+
+func TestVectorPlusWebRetriever(t *testing.T) {
+	ctx := context.Background()
+	store := &mockVectorStore{docs: []rag.Document{
+		{ID: "doc1", Content: "Local fact"},
+		{ID: "doc2", Content: "Shared fact"},
+	}}
+	web := &staticRetriever{results: []rag.DocumentSearchResult{
+		{Document: rag.Document{ID: "https://example.com", Content: "shared   FACT"}, Score: 0.7},
+		{Document: rag.Document{ID: "https://example.org", Content: "Web fact"}, Score: 0.6},
+	}}
+
+	t.Run("Confident vector results skip the web", func(t *testing.T) {
+		r := NewVectorPlusWebRetriever(store, &mockEmbedder{}, web, 0.8)
+		results, err := r.RetrieveWithConfig(ctx, "q", &rag.RetrievalConfig{K: 2})
+		require.NoError(t, err)
+		assert.Len(t, results, 2)
+		assert.Equal(t, 0, web.calls)
+		assert.Equal(t, "vector", results[0].Metadata["retrieval_source"])
+	})
+
+	t.Run("Low confidence fuses and dedupes web results", func(t *testing.T) {
+		r := NewVectorPlusWebRetriever(store, &mockEmbedder{}, web, 1.5)
+		results, err := r.RetrieveWithConfig(ctx, "q", &rag.RetrievalConfig{K: 5})
+		require.NoError(t, err)
+		assert.Equal(t, 1, web.calls)
+		require.Len(t, results, 3)
+		// The shared document appears in both lists and is ranked first
+		assert.Equal(t, "doc2", results[0].Document.ID)
+	})
+
+	t.Run("Web failure returns the vector results and the error", func(t *testing.T) {
+		webErr := errors.New("search quota exceeded")
+		r := NewVectorPlusWebRetriever(store, &mockEmbedder{}, &staticRetriever{err: webErr}, 1.5)
+		results, err := r.RetrieveWithConfig(ctx, "q", &rag.RetrievalConfig{K: 2})
+		assert.ErrorIs(t, err, ErrWebRetrieval)
+		assert.ErrorIs(t, err, webErr)
+		require.Len(t, results, 2)
+		assert.Equal(t, "vector", results[0].Metadata["retrieval_source"])
+
+		docs, err := r.RetrieveWithK(ctx, "q", 2)
+		assert.ErrorIs(t, err, webErr)
+		assert.Len(t, docs, 2)
+	})
+}
@@ -0,0 +1,56 @@
+package rag
+
+import "context"
+
+// SearchVectorStore searches store for the k documents most similar to query, restricted by
+// filter when it is not empty. With includeEmbeddings the results carry the stored embeddings:
+// stores that implement EmbeddingVectorStore return them, for other stores Document.Embedding
+// is used when the store returns it.
+func SearchVectorStore(ctx context.Context, store VectorStore, query []float32, k int, filter map[string]any, includeEmbeddings bool) ([]DocumentSearchResult, error) {
+	if includeEmbeddings {
+		if es, ok := store.(EmbeddingVectorStore); ok {
+			return es.SearchWithEmbeddings(ctx, query, k, filter)
+		}
+	}
+
+	var results []DocumentSearchResult
+	var err error
+	if len(filter) > 0 {
+		results, err = store.SearchWithFilter(ctx, query, k, filter)
+	} else {
+		results, err = store.Search(ctx, query, k)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if includeEmbeddings {
+		for i := range results {
+			if len(results[i].Embedding) == 0 {
+				results[i].Embedding = results[i].Document.Embedding
+			}
+		}
+	}
+	return results, nil
+}
+
+// ProjectMetadata keeps only the given keys in the document metadata of results, as
+// requested with RetrievalConfig.MetadataFields. It does nothing when fields is empty.
+func ProjectMetadata(results []DocumentSearchResult, fields []string) {
+	if len(fields) == 0 {
+		return
+	}
+	for i := range results {
+		doc := &results[i].Document
+		if doc.Metadata == nil {
+			continue
+		}
+		projected := make(map[string]any, len(fields))
+		for _, field := range fields {
+			if v, ok := doc.Metadata[field]; ok {
+				projected[field] = v
+			}
+		}
+		doc.Metadata = projected
+	}
+}
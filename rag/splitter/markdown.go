@@ -0,0 +1,225 @@
+package splitter
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/smallnest/langgraphgo/rag"
+)
+
+// MarkdownSplitter splits Markdown text into chunks aligned to heading boundaries.
+// Each chunk records the path of headings it belongs to (e.g. "Intro > Setup")
+// and fenced code blocks are never split across chunks.
+type MarkdownSplitter struct {
+	MaxChunkSize int
+	// HeadingSeparator joins heading titles in the heading_path metadata
+	HeadingSeparator string
+}
+
+// markdownChunk is a chunk of Markdown with the heading path it belongs to
+type markdownChunk struct {
+	content     string
+	headingPath []string
+}
+
+// NewMarkdownSplitter creates a new MarkdownSplitter
+func NewMarkdownSplitter(maxChunkSize int) rag.TextSplitter {
+	return &MarkdownSplitter{
+		MaxChunkSize:     maxChunkSize,
+		HeadingSeparator: " > ",
+	}
+}
+
+// SplitText splits Markdown text into chunks
+func (s *MarkdownSplitter) SplitText(text string) []string {
+	chunks := s.split(text)
+	result := make([]string, len(chunks))
+	for i, c := range chunks {
+		result[i] = c.content
+	}
+	return result
+}
+
+// SplitDocuments splits Markdown documents into chunks with heading_path metadata
+func (s *MarkdownSplitter) SplitDocuments(docs []rag.Document) []rag.Document {
+	result := make([]rag.Document, 0)
+
+	for _, doc := range docs {
+		chunks := s.split(doc.Content)
+		for i, chunk := range chunks {
+			metadata := make(map[string]any)
+			maps.Copy(metadata, doc.Metadata)
+
+			metadata["chunk_index"] = i
+			metadata["chunk_total"] = len(chunks)
+			metadata["parent_id"] = doc.ID
+			metadata["heading_path"] = strings.Join(chunk.headingPath, s.HeadingSeparator)
+
+			result = append(result, rag.Document{
+				ID:        fmt.Sprintf("%s_chunk_%d", doc.ID, i),
+				Content:   chunk.content,
+				Metadata:  metadata,
+				CreatedAt: doc.CreatedAt,
+				UpdatedAt: doc.UpdatedAt,
+			})
+		}
+	}
+
+	return result
+}
+
+// JoinText joins text chunks back together
+func (s *MarkdownSplitter) JoinText(chunks []string) string {
+	return strings.Join(chunks, "\n\n")
+}
+
+// split parses the Markdown into sections and packs each section's blocks into chunks
+func (s *MarkdownSplitter) split(text string) []markdownChunk {
+	var chunks []markdownChunk
+	var headingPath []string
+	var headingLevels []int
+	var blocks []string
+	var current []string
+	var fence string
+
+	flushBlock := func() {
+		if len(current) > 0 {
+			block := strings.TrimSpace(strings.Join(current, "\n"))
+			if block != "" {
+				blocks = append(blocks, block)
+			}
+			current = nil
+		}
+	}
+	flushSection := func() {
+		flushBlock()
+		path := append([]string(nil), headingPath...)
+		for _, content := range s.packBlocks(blocks) {
+			chunks = append(chunks, markdownChunk{content: content, headingPath: path})
+		}
+		blocks = nil
+	}
+
+	for line := range strings.SplitSeq(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		// Inside a fenced code block: everything belongs to the block
+		if fence != "" {
+			current = append(current, line)
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+				flushBlock()
+			}
+			continue
+		}
+
+		if f := codeFence(trimmed); f != "" {
+			flushBlock()
+			fence = f
+			current = append(current, line)
+			continue
+		}
+
+		if level, title := parseHeading(trimmed); level > 0 {
+			flushSection()
+			for len(headingLevels) > 0 && headingLevels[len(headingLevels)-1] >= level {
+				headingLevels = headingLevels[:len(headingLevels)-1]
+				headingPath = headingPath[:len(headingPath)-1]
+			}
+			headingLevels = append(headingLevels, level)
+			headingPath = append(headingPath, title)
+			blocks = append(blocks, trimmed)
+			continue
+		}
+
+		if trimmed == "" {
+			flushBlock()
+			continue
+		}
+		current = append(current, line)
+	}
+	flushSection()
+
+	return chunks
+}
+
+// packBlocks greedily merges blocks into chunks no larger than MaxChunkSize.
+// Code blocks are kept intact even when they exceed the limit.
+func (s *MarkdownSplitter) packBlocks(blocks []string) []string {
+	var chunks []string
+	var buf strings.Builder
+
+	flush := func() {
+		if buf.Len() > 0 {
+			chunks = append(chunks, buf.String())
+			buf.Reset()
+		}
+	}
+
+	for _, block := range blocks {
+		if s.MaxChunkSize > 0 && len(block) > s.MaxChunkSize && codeFence(block) == "" {
+			flush()
+			chunks = append(chunks, s.splitLongBlock(block)...)
+			continue
+		}
+		if s.MaxChunkSize > 0 && buf.Len() > 0 && buf.Len()+2+len(block) > s.MaxChunkSize {
+			flush()
+		}
+		if buf.Len() > 0 {
+			buf.WriteString("\n\n")
+		}
+		buf.WriteString(block)
+	}
+	flush()
+
+	return chunks
+}
+
+// splitLongBlock splits an oversized prose block on whitespace boundaries
+func (s *MarkdownSplitter) splitLongBlock(block string) []string {
+	var chunks []string
+	var buf strings.Builder
+
+	for _, word := range strings.Fields(block) {
+		if buf.Len() > 0 && buf.Len()+1+len(word) > s.MaxChunkSize {
+			chunks = append(chunks, buf.String())
+			buf.Reset()
+		}
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(word)
+	}
+	if buf.Len() > 0 {
+		chunks = append(chunks, buf.String())
+	}
+
+	return chunks
+}
+
+// codeFence returns the fence marker if the line opens a fenced code block
+func codeFence(line string) string {
+	for _, fence := range []string{"```", "~~~"} {
+		if strings.HasPrefix(line, fence) {
+			return fence
+		}
+	}
+	return ""
+}
+
+// parseHeading returns the level and title of an ATX heading, or 0 if the line is not a heading
+func parseHeading(line string) (int, string) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return 0, ""
+	}
+	if level < len(line) && line[level] != ' ' && line[level] != '\t' {
+		return 0, ""
+	}
+	title := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(line[level:]), "#"))
+	return level, title
+}
@@ -260,3 +260,40 @@ func TestSimpleTextSplitter(t *testing.T) {
 		}
 	})
 }
+
+func TestMarkdownSplitter(t *testing.T) {
+	text := "# Guide\n\nIntro text.\n\n## Install\n\nRun this:\n\n```bash\n# not a heading\ngo get example.com/pkg\n```\n\n### Linux\n\nUse apt.\n\n## Usage\n\nCall it."
+
+	t.Run("Heading path metadata", func(t *testing.T) {
+		s := NewMarkdownSplitter(1000)
+		docs := s.SplitDocuments([]rag.Document{{ID: "readme", Content: text}})
+
+		var paths []string
+		for _, d := range docs {
+			paths = append(paths, d.Metadata["heading_path"].(string))
+		}
+		assert.Equal(t, []string{"Guide", "Guide > Install", "Guide > Install > Linux", "Guide > Usage"}, paths)
+		assert.Equal(t, "readme_chunk_1", docs[1].ID)
+		assert.Contains(t, docs[1].Content, "# not a heading\ngo get example.com/pkg\n```")
+	})
+
+	t.Run("Code blocks are kept intact", func(t *testing.T) {
+		s := NewMarkdownSplitter(20)
+		chunks := s.SplitText(text)
+
+		found := false
+		for _, c := range chunks {
+			if strings.HasPrefix(c, "```bash") {
+				found = true
+				assert.True(t, strings.HasSuffix(c, "```"))
+			}
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("Long paragraphs are split", func(t *testing.T) {
+		s := NewMarkdownSplitter(10)
+		chunks := s.SplitText("one two three four five six")
+		assert.Equal(t, []string{"one two", "three four", "five six"}, chunks)
+	})
+}
@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync/atomic"
 	"time"
 
+	"github.com/smallnest/langgraphgo/httputil"
 	"github.com/smallnest/langgraphgo/rag"
 )
 
@@ -26,7 +28,11 @@ type ChromaV2VectorStore struct {
 	collectionID string
 	collection   string
 	embedder     rag.Embedder
+	dimension    dimensionTracker
 	httpClient   *http.Client
+	authToken    string
+	authHeader   string
+	ready        atomic.Bool
 }
 
 // ChromaV2Config contains configuration for ChromaV2VectorStore
@@ -49,8 +55,29 @@ type ChromaV2Config struct {
 	// Embedder is the embedder to use for generating embeddings
 	Embedder rag.Embedder
 
-	// HTTPClient is the HTTP client to use (optional, will create default if not provided)
+	// Dimension rejects embeddings of any other dimension with a *rag.DimensionMismatchError.
+	// When it is 0, the dimension of the first stored embedding is enforced.
+	Dimension int
+
+	// HTTPClient is the HTTP client to use (optional). The default client pools connections
+	// and retries transient failures, see httputil.NewResilientClient. The client is shared
+	// by all requests of the store, so configure its transport for connection pooling when
+	// running under high load.
 	HTTPClient *http.Client
+
+	// Timeout is the request timeout of the default HTTP client (defaults to 30s)
+	Timeout time.Duration
+
+	// MaxIdleConnsPerHost is the number of keep-alive connections the default HTTP client
+	// keeps per host (defaults to 32)
+	MaxIdleConnsPerHost int
+
+	// AuthToken is sent with every request when the Chroma server requires token authentication
+	AuthToken string
+
+	// AuthHeader is the header used for AuthToken (defaults to "Authorization" with a
+	// "Bearer " prefix; set to "X-Chroma-Token" to send the raw token)
+	AuthHeader string
 }
 
 // NewChromaV2VectorStore creates a new ChromaV2VectorStore with the given configuration
@@ -70,11 +97,21 @@ func NewChromaV2VectorStore(config ChromaV2Config) (*ChromaV2VectorStore, error)
 		config.Database = "default_database"
 	}
 
-	// Create HTTP client if not provided
+	// Create a pooled HTTP client if not provided
 	if config.HTTPClient == nil {
-		config.HTTPClient = &http.Client{
-			Timeout: 30 * time.Second,
+		if config.Timeout <= 0 {
+			config.Timeout = 30 * time.Second
+		}
+		if config.MaxIdleConnsPerHost <= 0 {
+			config.MaxIdleConnsPerHost = 32
 		}
+		opts := httputil.DefaultOptions()
+		opts.Timeout = config.Timeout
+		opts.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+		config.HTTPClient = httputil.NewResilientClient(opts)
+	}
+	if config.AuthHeader == "" {
+		config.AuthHeader = "Authorization"
 	}
 
 	store := &ChromaV2VectorStore{
@@ -83,7 +120,10 @@ func NewChromaV2VectorStore(config ChromaV2Config) (*ChromaV2VectorStore, error)
 		database:   config.Database,
 		collection: config.Collection,
 		embedder:   config.Embedder,
+		dimension:  dimensionTracker{dimension: config.Dimension},
 		httpClient: config.HTTPClient,
+		authToken:  config.AuthToken,
+		authHeader: config.AuthHeader,
 	}
 
 	// Initialize collection
@@ -103,6 +143,35 @@ func NewChromaV2VectorStoreSimple(baseURL, collection string, embedder rag.Embed
 	})
 }
 
+// do sends a request with the configured authentication. The response body drains on
+// Close so the underlying connection can be reused.
+func (s *ChromaV2VectorStore) do(req *http.Request) (*http.Response, error) {
+	if s.authToken != "" {
+		if http.CanonicalHeaderKey(s.authHeader) == "Authorization" {
+			req.Header.Set(s.authHeader, "Bearer "+s.authToken)
+		} else {
+			req.Header.Set(s.authHeader, s.authToken)
+		}
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, rag.NewConnectionError("Chroma", err)
+	}
+	resp.Body = drainingBody{resp.Body}
+	return resp, nil
+}
+
+// drainingBody discards unread data on Close so keep-alive connections are returned to the pool
+type drainingBody struct {
+	io.ReadCloser
+}
+
+func (b drainingBody) Close() error {
+	_, _ = io.Copy(io.Discard, b.ReadCloser)
+	return b.ReadCloser.Close()
+}
+
 // initCollection initializes or gets the collection
 func (s *ChromaV2VectorStore) initCollection(ctx context.Context) error {
 	// First try to get existing collection by name
@@ -144,7 +213,7 @@ func (s *ChromaV2VectorStore) createCollection(ctx context.Context) error {
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return err
 	}
@@ -152,7 +221,7 @@ func (s *ChromaV2VectorStore) createCollection(ctx context.Context) error {
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to create collection: status %d: %s", resp.StatusCode, string(respBody))
+		return fmt.Errorf("failed to create collection: %w", &rag.StatusError{Backend: "Chroma", StatusCode: resp.StatusCode, Body: string(respBody)})
 	}
 
 	var result struct {
@@ -174,14 +243,14 @@ func (s *ChromaV2VectorStore) listCollections(ctx context.Context) ([]Collection
 		return nil, err
 	}
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to list collections: status %d", resp.StatusCode)
+		return nil, fmt.Errorf("failed to list collections: %w", &rag.StatusError{Backend: "Chroma", StatusCode: resp.StatusCode})
 	}
 
 	// Try to decode as array first (Chroma v2 returns empty array [])
@@ -247,6 +316,11 @@ func (s *ChromaV2VectorStore) Add(ctx context.Context, documents []rag.Document)
 		}
 	}
 
+	dimension, err := s.dimension.check(embeddedDocs, ids)
+	if err != nil {
+		return err
+	}
+
 	payload := map[string]any{
 		"ids":        ids,
 		"embeddings": embeds,
@@ -276,7 +350,7 @@ func (s *ChromaV2VectorStore) Add(ctx context.Context, documents []rag.Document)
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return err
 	}
@@ -284,16 +358,36 @@ func (s *ChromaV2VectorStore) Add(ctx context.Context, documents []rag.Document)
 
 	if resp.StatusCode != http.StatusCreated {
 		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to add documents: status %d: %s", resp.StatusCode, string(respBody))
+		return fmt.Errorf("failed to add documents: %w", &rag.StatusError{Backend: "Chroma", StatusCode: resp.StatusCode, Body: string(respBody)})
 	}
 
+	s.dimension.adopt(dimension)
 	return nil
 }
 
 // Search performs similarity search in the Chroma v2 vector store
 func (s *ChromaV2VectorStore) Search(ctx context.Context, query []float32, k int) ([]rag.DocumentSearchResult, error) {
+	return s.query(ctx, query, k, nil, false)
+}
+
+// SearchWithFilter performs similarity search with metadata filters
+func (s *ChromaV2VectorStore) SearchWithFilter(ctx context.Context, query []float32, k int, filter map[string]any) ([]rag.DocumentSearchResult, error) {
+	return s.query(ctx, query, k, filter, false)
+}
+
+// SearchWithEmbeddings performs similarity search with metadata filters and returns the
+// embedding of each result
+func (s *ChromaV2VectorStore) SearchWithEmbeddings(ctx context.Context, query []float32, k int, filter map[string]any) ([]rag.DocumentSearchResult, error) {
+	return s.query(ctx, query, k, filter, true)
+}
+
+// query runs a similarity search, restricted by the where clause filter when it is not nil
+func (s *ChromaV2VectorStore) query(ctx context.Context, query []float32, k int, filter map[string]any, includeEmbeddings bool) ([]rag.DocumentSearchResult, error) {
 	if k <= 0 {
-		return nil, fmt.Errorf("k must be positive")
+		return nil, fmt.Errorf("%w: k must be positive", rag.ErrInvalidQuery)
+	}
+	if err := rag.CheckDimension(s.dimension.get(), query, ""); err != nil {
+		return nil, err
 	}
 
 	// Convert query embedding from float32 to float64
@@ -303,10 +397,17 @@ func (s *ChromaV2VectorStore) Search(ctx context.Context, query []float32, k int
 	}
 
 	// Use the query endpoint (not search - search is for distributed mode)
+	include := []string{"metadatas", "documents", "distances"}
+	if includeEmbeddings {
+		include = append(include, "embeddings")
+	}
 	payload := map[string]any{
 		"query_embeddings": [][]float64{queryEmbedding},
 		"n_results":        k,
-		"include":          []string{"metadatas", "documents", "distances"},
+		"include":          include,
+	}
+	if filter != nil {
+		payload["where"] = filter
 	}
 
 	body, err := json.Marshal(payload)
@@ -323,7 +424,7 @@ func (s *ChromaV2VectorStore) Search(ctx context.Context, query []float32, k int
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -331,15 +432,16 @@ func (s *ChromaV2VectorStore) Search(ctx context.Context, query []float32, k int
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to search: status %d: %s", resp.StatusCode, string(respBody))
+		return nil, fmt.Errorf("failed to search: %w", &rag.StatusError{Backend: "Chroma", StatusCode: resp.StatusCode, Body: string(respBody)})
 	}
 
 	// Chroma v2 query response format
 	var result struct {
-		IDs       [][]string         `json:"ids"`
-		Distances [][]float64        `json:"distances"`
-		Documents [][]string         `json:"documents"`
-		Metadatas [][]map[string]any `json:"metadatas"`
+		IDs        [][]string         `json:"ids"`
+		Distances  [][]float64        `json:"distances"`
+		Documents  [][]string         `json:"documents"`
+		Metadatas  [][]map[string]any `json:"metadatas"`
+		Embeddings [][][]float32      `json:"embeddings"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, err
@@ -378,129 +480,83 @@ func (s *ChromaV2VectorStore) Search(ctx context.Context, query []float32, k int
 			},
 			Score: 1.0 - distance, // Convert distance to similarity score
 		}
+		if len(result.Embeddings) > 0 && i < len(result.Embeddings[0]) {
+			searchResults[i].Embedding = result.Embeddings[0][i]
+		}
 	}
 
 	return searchResults, nil
 }
 
-// SearchWithFilter performs similarity search with metadata filters
-func (s *ChromaV2VectorStore) SearchWithFilter(ctx context.Context, query []float32, k int, filter map[string]any) ([]rag.DocumentSearchResult, error) {
-	if k <= 0 {
-		return nil, fmt.Errorf("k must be positive")
-	}
-
-	// Convert query embedding from float32 to float64
-	queryEmbedding := make([]float64, len(query))
-	for i, v := range query {
-		queryEmbedding[i] = float64(v)
+// Delete removes documents by their IDs
+func (s *ChromaV2VectorStore) Delete(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
 	}
 
-	// Use the query endpoint with where clause for filtering
 	payload := map[string]any{
-		"query_embeddings": [][]float64{queryEmbedding},
-		"n_results":        k,
-		"where":            filter,
-		"include":          []string{"metadatas", "documents", "distances"},
+		"ids": ids,
 	}
 
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	url := fmt.Sprintf("%s/api/v2/tenants/%s/databases/%s/collections/%s/query",
+	url := fmt.Sprintf("%s/api/v2/tenants/%s/databases/%s/collections/%s/delete",
 		s.baseURL, s.tenant, s.database, s.collectionID)
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to search with filter: status %d: %s", resp.StatusCode, string(respBody))
-	}
-
-	// Chroma v2 query response format
-	var result struct {
-		IDs       [][]string         `json:"ids"`
-		Distances [][]float64        `json:"distances"`
-		Documents [][]string         `json:"documents"`
-		Metadatas [][]map[string]any `json:"metadatas"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
-	}
-
-	// Convert to our format
-	if len(result.IDs) == 0 {
-		return []rag.DocumentSearchResult{}, nil
-	}
-
-	searchResults := make([]rag.DocumentSearchResult, len(result.IDs[0]))
-	for i := range result.IDs[0] {
-		var metadata map[string]any
-		if len(result.Metadatas) > 0 && i < len(result.Metadatas[0]) {
-			metadata = result.Metadatas[0][i]
-		}
-
-		var content string
-		if len(result.Documents) > 0 && i < len(result.Documents[0]) {
-			content = result.Documents[0][i]
-		}
-
-		var distance float64
-		if len(result.Distances) > 0 && i < len(result.Distances[0]) {
-			distance = result.Distances[0][i]
-		}
-
-		searchResults[i] = rag.DocumentSearchResult{
-			Document: rag.Document{
-				ID:        result.IDs[0][i],
-				Content:   content,
-				Metadata:  metadata,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
-			},
-			Score: 1.0 - distance,
-		}
+		return fmt.Errorf("failed to delete documents: %w", &rag.StatusError{Backend: "Chroma", StatusCode: resp.StatusCode, Body: string(respBody)})
 	}
 
-	return searchResults, nil
+	return nil
 }
 
-// Delete removes documents by their IDs
-func (s *ChromaV2VectorStore) Delete(ctx context.Context, ids []string) error {
-	if len(ids) == 0 {
-		return nil
+// Clear removes all documents by deleting and recreating the collection.
+// The recreated collection has a new ID.
+func (s *ChromaV2VectorStore) Clear(ctx context.Context) error {
+	if err := s.deleteCollection(ctx); err != nil {
+		return err
 	}
-
-	payload := map[string]any{
-		"ids": ids,
+	if err := s.createCollection(ctx); err != nil {
+		return fmt.Errorf("failed to recreate collection: %w", err)
 	}
+	return nil
+}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
+// Drop deletes the collection on the server. The store must not be used afterwards.
+func (s *ChromaV2VectorStore) Drop(ctx context.Context) error {
+	if err := s.deleteCollection(ctx); err != nil {
 		return err
 	}
+	s.collectionID = ""
+	return nil
+}
 
-	url := fmt.Sprintf("%s/api/v2/tenants/%s/databases/%s/collections/%s/delete",
-		s.baseURL, s.tenant, s.database, s.collectionID)
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+// deleteCollection deletes the collection by name
+func (s *ChromaV2VectorStore) deleteCollection(ctx context.Context) error {
+	url := fmt.Sprintf("%s/api/v2/tenants/%s/databases/%s/collections/%s",
+		s.baseURL, s.tenant, s.database, s.collection)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return err
 	}
@@ -508,7 +564,7 @@ func (s *ChromaV2VectorStore) Delete(ctx context.Context, ids []string) error {
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to delete documents: status %d: %s", resp.StatusCode, string(respBody))
+		return fmt.Errorf("failed to delete collection: %w", &rag.StatusError{Backend: "Chroma", StatusCode: resp.StatusCode, Body: string(respBody)})
 	}
 
 	return nil
@@ -553,6 +609,11 @@ func (s *ChromaV2VectorStore) Update(ctx context.Context, documents []rag.Docume
 		}
 	}
 
+	dimension, err := s.dimension.check(embeddedDocs, ids)
+	if err != nil {
+		return err
+	}
+
 	payload := map[string]any{
 		"ids":        ids,
 		"embeddings": embeds,
@@ -574,7 +635,7 @@ func (s *ChromaV2VectorStore) Update(ctx context.Context, documents []rag.Docume
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return err
 	}
@@ -582,12 +643,83 @@ func (s *ChromaV2VectorStore) Update(ctx context.Context, documents []rag.Docume
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to update documents: status %d: %s", resp.StatusCode, string(respBody))
+		return fmt.Errorf("failed to update documents: %w", &rag.StatusError{Backend: "Chroma", StatusCode: resp.StatusCode, Body: string(respBody)})
 	}
 
+	s.dimension.adopt(dimension)
 	return nil
 }
 
+// Sync makes the collection contain exactly docs, re-embedding only new and changed documents
+func (s *ChromaV2VectorStore) Sync(ctx context.Context, docs []rag.Document) (*rag.SyncResult, error) {
+	current, err := s.contentHashes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := rag.PlanSync(current, docs)
+	if err := s.Delete(ctx, plan.Delete); err != nil {
+		return nil, err
+	}
+	// Update upserts, so new and changed documents are written in one request
+	if err := s.Update(ctx, append(plan.Update, plan.Add...)); err != nil {
+		return nil, err
+	}
+	return &plan.Result, nil
+}
+
+// contentHashes returns the content hash of every document in the collection
+func (s *ChromaV2VectorStore) contentHashes(ctx context.Context) (map[string]string, error) {
+	body, err := json.Marshal(map[string]any{
+		"include": []string{"metadatas", "documents"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/api/v2/tenants/%s/databases/%s/collections/%s/get",
+		s.baseURL, s.tenant, s.database, s.collectionID)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get documents: %w", &rag.StatusError{Backend: "Chroma", StatusCode: resp.StatusCode, Body: string(respBody)})
+	}
+
+	var result struct {
+		IDs       []string         `json:"ids"`
+		Documents []string         `json:"documents"`
+		Metadatas []map[string]any `json:"metadatas"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode documents: %w", err)
+	}
+
+	hashes := make(map[string]string, len(result.IDs))
+	for i, id := range result.IDs {
+		var doc rag.Document
+		if i < len(result.Documents) {
+			doc.Content = result.Documents[i]
+		}
+		if i < len(result.Metadatas) {
+			doc.Metadata = result.Metadatas[i]
+		}
+		hashes[id] = rag.StoredContentHash(doc)
+	}
+	return hashes, nil
+}
+
 // GetStats returns statistics about the Chroma v2 vector store
 func (s *ChromaV2VectorStore) GetStats(ctx context.Context) (*rag.VectorStoreStats, error) {
 	url := fmt.Sprintf("%s/api/v2/tenants/%s/databases/%s/collections/%s/count",
@@ -597,14 +729,14 @@ func (s *ChromaV2VectorStore) GetStats(ctx context.Context) (*rag.VectorStoreSta
 		return nil, err
 	}
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get stats: status %d", resp.StatusCode)
+		return nil, fmt.Errorf("failed to get stats: %w", &rag.StatusError{Backend: "Chroma", StatusCode: resp.StatusCode})
 	}
 
 	var count uint32
@@ -622,6 +754,27 @@ func (s *ChromaV2VectorStore) GetStats(ctx context.Context) (*rag.VectorStoreSta
 	}, nil
 }
 
+// Warmup opens a pooled connection to the server and runs a trivial query, which makes
+// Chroma load the collection index that it otherwise loads on the first real query
+func (s *ChromaV2VectorStore) Warmup(ctx context.Context) error {
+	stats, err := s.GetStats(ctx)
+	if err != nil {
+		return fmt.Errorf("warmup failed: %w", err)
+	}
+	if stats.Dimension > 0 && stats.TotalDocuments > 0 {
+		if _, err := s.Search(ctx, warmupQuery(stats.Dimension), 1); err != nil {
+			return fmt.Errorf("warmup query failed: %w", err)
+		}
+	}
+	s.ready.Store(true)
+	return nil
+}
+
+// Ready reports whether Warmup has succeeded
+func (s *ChromaV2VectorStore) Ready() bool {
+	return s.ready.Load()
+}
+
 // Close closes the Chroma v2 vector store and releases resources
 func (s *ChromaV2VectorStore) Close() error {
 	// Nothing to clean up for HTTP client
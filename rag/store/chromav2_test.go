@@ -0,0 +1,205 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/rag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChromaV2VectorStore_AuthAndConnectionReuse(t *testing.T) {
+	var mu sync.Mutex
+	var authHeaders []string
+	conns := map[string]bool{}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authHeaders = append(authHeaders, r.Header.Get("X-Chroma-Token"))
+		conns[r.RemoteAddr] = true
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/collections"):
+			_, _ = w.Write([]byte(`[{"id":"c1","name":"docs"}]`))
+		default:
+			// Unread response bodies must not prevent connection reuse
+			_, _ = w.Write([]byte(`{"ok":true,"padding":"` + strings.Repeat("x", 4096) + `"}`))
+		}
+	}))
+	server.Start()
+	defer server.Close()
+
+	s, err := NewChromaV2VectorStore(ChromaV2Config{
+		BaseURL:    server.URL,
+		Collection: "docs",
+		Embedder:   NewMockEmbedder(3),
+		AuthToken:  "secret",
+		AuthHeader: "X-Chroma-Token",
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for range 3 {
+		require.NoError(t, s.Delete(ctx, []string{"a"}))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, authHeaders, 4)
+	for _, h := range authHeaders {
+		assert.Equal(t, "secret", h)
+	}
+	assert.Len(t, conns, 1, "requests should reuse a single keep-alive connection")
+}
+
+func TestChromaV2VectorStore_Sync(t *testing.T) {
+	var deleted, upserted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			IDs []string `json:"ids"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/collections"):
+			_, _ = w.Write([]byte(`[{"id":"c1","name":"docs"}]`))
+		case strings.HasSuffix(r.URL.Path, "/get"):
+			// "a" was stored with a hash, "b" without one
+			_, _ = w.Write([]byte(`{"ids":["a","b","c"],"documents":["alpha","beta","gamma"],` +
+				`"metadatas":[{"content_hash":"` + rag.ContentHash("alpha") + `"},null,null]}`))
+		case strings.HasSuffix(r.URL.Path, "/delete"):
+			deleted = payload.IDs
+		case strings.HasSuffix(r.URL.Path, "/upsert"):
+			upserted = payload.IDs
+		}
+	}))
+	defer server.Close()
+
+	s, err := NewChromaV2VectorStoreSimple(server.URL, "docs", NewMockEmbedder(3))
+	require.NoError(t, err)
+
+	result, err := s.Sync(context.Background(), []rag.Document{
+		{ID: "a", Content: "alpha"},
+		{ID: "b", Content: "beta v2"},
+		{ID: "d", Content: "delta"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &rag.SyncResult{Added: []string{"d"}, Updated: []string{"b"}, Deleted: []string{"c"}, Unchanged: 1}, result)
+	assert.Equal(t, []string{"c"}, deleted)
+	assert.Equal(t, []string{"b", "d"}, upserted)
+}
+
+func TestChromaV2VectorStore_ClearAndDrop(t *testing.T) {
+	var requests []string
+	nextID := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path[strings.LastIndex(r.URL.Path, "/"):])
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`[{"id":"c1","name":"docs"}]`))
+		case r.Method == http.MethodPost:
+			nextID++
+			_, _ = fmt.Fprintf(w, `{"id":"c%d","name":"docs"}`, nextID)
+		}
+	}))
+	defer server.Close()
+
+	s, err := NewChromaV2VectorStoreSimple(server.URL, "docs", NewMockEmbedder(3))
+	require.NoError(t, err)
+
+	require.NoError(t, s.Clear(context.Background()))
+	assert.Equal(t, "c2", s.GetCollectionID())
+
+	require.NoError(t, s.Drop(context.Background()))
+	assert.Empty(t, s.GetCollectionID())
+	assert.Equal(t, []string{"GET /collections", "DELETE /docs", "POST /collections", "DELETE /docs"}, requests)
+}
+
+func TestChromaV2VectorStore_Warmup(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path[strings.LastIndex(r.URL.Path, "/"):])
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/collections"):
+			_, _ = w.Write([]byte(`[{"id":"c1","name":"docs"}]`))
+		case strings.HasSuffix(r.URL.Path, "/count"):
+			_, _ = w.Write([]byte(`2`))
+		case strings.HasSuffix(r.URL.Path, "/query"):
+			_, _ = w.Write([]byte(`{"ids":[["a"]],"documents":[["alpha"]],"distances":[[0.1]]}`))
+		}
+	}))
+	defer server.Close()
+
+	s, err := NewChromaV2VectorStoreSimple(server.URL, "docs", NewMockEmbedder(3))
+	require.NoError(t, err)
+
+	var warmable rag.WarmableVectorStore = s
+	assert.False(t, warmable.Ready())
+	require.NoError(t, warmable.Warmup(context.Background()))
+	assert.True(t, warmable.Ready())
+	assert.Equal(t, []string{"GET /collections", "GET /count", "POST /query"}, requests)
+}
+
+func TestChromaV2VectorStore_DimensionValidation(t *testing.T) {
+	var writes int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/collections"):
+			_, _ = w.Write([]byte(`[{"id":"c1","name":"docs"}]`))
+		default:
+			writes++
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	t.Run("Dimension from first document", func(t *testing.T) {
+		// The embedder's reported dimension is not trusted
+		s, err := NewChromaV2VectorStoreSimple(server.URL, "docs", misreportingEmbedder{Embedder: NewMockEmbedder(3), dim: 2560})
+		require.NoError(t, err)
+
+		_, err = s.Search(ctx, []float32{1, 2, 3, 4}, 1)
+		require.NotErrorAs(t, err, new(*rag.DimensionMismatchError))
+
+		require.NoError(t, s.Add(ctx, []rag.Document{{ID: "a", Content: "text"}}))
+		_, err = s.Search(ctx, []float32{1, 2, 3, 4}, 1)
+		var dimErr *rag.DimensionMismatchError
+		require.ErrorAs(t, err, &dimErr)
+		assert.Equal(t, 3, dimErr.Expected)
+		assert.Equal(t, 4, dimErr.Actual)
+	})
+
+	t.Run("Declared dimension", func(t *testing.T) {
+		s, err := NewChromaV2VectorStore(ChromaV2Config{
+			BaseURL:    server.URL,
+			Collection: "docs",
+			Embedder:   NewMockEmbedder(4096),
+			Dimension:  128,
+		})
+		require.NoError(t, err)
+
+		writes = 0
+		err = s.Add(ctx, []rag.Document{{ID: "qwen", Content: "text"}})
+		var dimErr *rag.DimensionMismatchError
+		require.ErrorAs(t, err, &dimErr)
+		assert.Equal(t, "qwen", dimErr.DocumentID)
+
+		err = s.Update(ctx, []rag.Document{{ID: "qwen", Content: "text"}})
+		require.ErrorAs(t, err, &dimErr)
+		assert.Zero(t, writes, "mismatched documents must not be sent")
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"time"
 
 	"github.com/philippgille/chromem-go"
@@ -18,6 +19,9 @@ type ChromemVectorStore struct {
 	collection     *chromem.Collection
 	embedder       rag.Embedder
 	collectionName string
+	dimension      dimensionTracker
+	embeddingFunc  chromem.EmbeddingFunc
+	ready          atomic.Bool
 }
 
 // ChromemConfig contains configuration for ChromemVectorStore
@@ -32,6 +36,10 @@ type ChromemConfig struct {
 
 	// Embedder is the embedder to use for generating embeddings
 	Embedder rag.Embedder
+
+	// Dimension rejects embeddings of any other dimension with a *rag.DimensionMismatchError.
+	// When it is 0, the dimension of the first stored embedding is enforced.
+	Dimension int
 }
 
 // NewChromemVectorStore creates a new ChromemVectorStore with the given configuration
@@ -82,6 +90,8 @@ func NewChromemVectorStore(config ChromemConfig) (*ChromemVectorStore, error) {
 		collection:     collection,
 		embedder:       config.Embedder,
 		collectionName: collectionName,
+		dimension:      dimensionTracker{dimension: config.Dimension},
+		embeddingFunc:  embeddingFunc,
 	}, nil
 }
 
@@ -107,6 +117,8 @@ func (s *ChromemVectorStore) Add(ctx context.Context, documents []rag.Document)
 
 	// Prepare documents for chromem
 	chromemDocs := make([]chromem.Document, len(documents))
+	embeddings := make([][]float32, len(documents))
+	ids := make([]string, len(documents))
 	for i, doc := range documents {
 		// Convert metadata to the format expected by chromem
 		metadata := make(map[string]string)
@@ -125,63 +137,53 @@ func (s *ChromemVectorStore) Add(ctx context.Context, documents []rag.Document)
 			return fmt.Errorf("failed to create chromem document for %s: %w", doc.ID, err)
 		}
 		chromemDocs[i] = chromemDoc
+		embeddings[i] = chromemDoc.Embedding
+		ids[i] = doc.ID
+	}
+	dimension, err := s.dimension.check(embeddings, ids)
+	if err != nil {
+		return err
 	}
 
 	// Add documents to the collection in a batch
-	return s.collection.AddDocuments(ctx, chromemDocs, runtimeNumWorkers(len(documents)))
+	if err := s.collection.AddDocuments(ctx, chromemDocs, runtimeNumWorkers(len(documents))); err != nil {
+		return err
+	}
+	s.dimension.adopt(dimension)
+	return nil
 }
 
 // Search performs similarity search in the chromem vector store
 func (s *ChromemVectorStore) Search(ctx context.Context, query []float32, k int) ([]rag.DocumentSearchResult, error) {
-	if k <= 0 {
-		return nil, fmt.Errorf("k must be positive")
-	}
-
-	// Get the count to limit k appropriately
-	count := s.collection.Count()
-	if k > count {
-		k = count
-	}
-
-	// If no documents, return empty results
-	if k == 0 {
-		return []rag.DocumentSearchResult{}, nil
-	}
-
-	// Perform the search with embedding
-	results, err := s.collection.QueryEmbedding(ctx, query, k, nil, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query collection: %w", err)
-	}
-
-	// Convert results to our format
-	searchResults := make([]rag.DocumentSearchResult, len(results))
-	for i, result := range results {
-		searchResults[i] = rag.DocumentSearchResult{
-			Document: rag.Document{
-				ID:        result.ID,
-				Content:   result.Content,
-				Metadata:  convertStringMapToAnyMap(result.Metadata),
-				CreatedAt: time.Now(), // chromem doesn't store creation time
-				UpdatedAt: time.Now(),
-			},
-			Score: float64(result.Similarity),
-		}
-	}
-
-	return searchResults, nil
+	return s.query(ctx, query, k, nil, false)
 }
 
 // SearchWithFilter performs similarity search with metadata filters
 func (s *ChromemVectorStore) SearchWithFilter(ctx context.Context, query []float32, k int, filter map[string]any) ([]rag.DocumentSearchResult, error) {
+	return s.query(ctx, query, k, filter, false)
+}
+
+// SearchWithEmbeddings performs similarity search with metadata filters and returns the
+// embedding of each result
+func (s *ChromemVectorStore) SearchWithEmbeddings(ctx context.Context, query []float32, k int, filter map[string]any) ([]rag.DocumentSearchResult, error) {
+	return s.query(ctx, query, k, filter, true)
+}
+
+// query runs a similarity search, converting filter to chromem's string map
+func (s *ChromemVectorStore) query(ctx context.Context, query []float32, k int, filter map[string]any, includeEmbeddings bool) ([]rag.DocumentSearchResult, error) {
 	if k <= 0 {
-		return nil, fmt.Errorf("k must be positive")
+		return nil, fmt.Errorf("%w: k must be positive", rag.ErrInvalidQuery)
+	}
+	if err := rag.CheckDimension(s.dimension.get(), query, ""); err != nil {
+		return nil, err
 	}
 
-	// Convert filter to chromem format (string map)
-	where := make(map[string]string)
-	for k, v := range filter {
-		where[k] = fmt.Sprint(v)
+	var where map[string]string
+	if len(filter) > 0 {
+		where = make(map[string]string, len(filter))
+		for k, v := range filter {
+			where[k] = fmt.Sprint(v)
+		}
 	}
 
 	// Get the count to limit k appropriately
@@ -195,10 +197,9 @@ func (s *ChromemVectorStore) SearchWithFilter(ctx context.Context, query []float
 		return []rag.DocumentSearchResult{}, nil
 	}
 
-	// Perform the search with filters
 	results, err := s.collection.QueryEmbedding(ctx, query, k, where, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query collection with filter: %w", err)
+		return nil, fmt.Errorf("failed to query collection: %w", err)
 	}
 
 	// Convert results to our format
@@ -209,11 +210,14 @@ func (s *ChromemVectorStore) SearchWithFilter(ctx context.Context, query []float
 				ID:        result.ID,
 				Content:   result.Content,
 				Metadata:  convertStringMapToAnyMap(result.Metadata),
-				CreatedAt: time.Now(),
+				CreatedAt: time.Now(), // chromem doesn't store creation time
 				UpdatedAt: time.Now(),
 			},
 			Score: float64(result.Similarity),
 		}
+		if includeEmbeddings {
+			searchResults[i].Embedding = result.Embedding
+		}
 	}
 
 	return searchResults, nil
@@ -247,6 +251,28 @@ func (s *ChromemVectorStore) Update(ctx context.Context, documents []rag.Documen
 	return s.Add(ctx, documents)
 }
 
+// Clear removes all documents by deleting and recreating the collection
+func (s *ChromemVectorStore) Clear(ctx context.Context) error {
+	if err := s.db.DeleteCollection(s.collectionName); err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+	collection, err := s.db.CreateCollection(s.collectionName, nil, s.embeddingFunc)
+	if err != nil {
+		return fmt.Errorf("failed to recreate collection: %w", err)
+	}
+	s.collection = collection
+	return nil
+}
+
+// Drop deletes the collection, including its persisted data.
+// The store must not be used afterwards.
+func (s *ChromemVectorStore) Drop(ctx context.Context) error {
+	if err := s.db.DeleteCollection(s.collectionName); err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+	return nil
+}
+
 // GetStats returns statistics about the chromem vector store
 func (s *ChromemVectorStore) GetStats(ctx context.Context) (*rag.VectorStoreStats, error) {
 	count := s.collection.Count()
@@ -261,6 +287,27 @@ func (s *ChromemVectorStore) GetStats(ctx context.Context) (*rag.VectorStoreStat
 	}, nil
 }
 
+// Warmup runs a trivial query against the collection. chromem-go loads persisted data
+// when the store is created, so this mainly verifies that the store can serve queries.
+func (s *ChromemVectorStore) Warmup(ctx context.Context) error {
+	dimension := s.dimension.get()
+	if dimension == 0 {
+		dimension = s.embedder.GetDimension()
+	}
+	if dimension > 0 && s.collection.Count() > 0 {
+		if _, err := s.Search(ctx, warmupQuery(dimension), 1); err != nil {
+			return fmt.Errorf("warmup query failed: %w", err)
+		}
+	}
+	s.ready.Store(true)
+	return nil
+}
+
+// Ready reports whether Warmup has succeeded
+func (s *ChromemVectorStore) Ready() bool {
+	return s.ready.Load()
+}
+
 // Close closes the chromem vector store and releases resources
 func (s *ChromemVectorStore) Close() error {
 	// chromem-go doesn't require explicit cleanup for in-memory DB
@@ -286,6 +333,13 @@ func convertStringMapToAnyMap(m map[string]string) map[string]any {
 	return result
 }
 
+// warmupQuery returns a unit query embedding of the given dimension for warm-up searches
+func warmupQuery(dimension int) []float32 {
+	query := make([]float32, dimension)
+	query[0] = 1
+	return query
+}
+
 // runtimeNumWorkers returns the number of workers to use for parallel operations
 // based on the number of documents
 func runtimeNumWorkers(numDocuments int) int {
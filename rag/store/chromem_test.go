@@ -365,3 +365,47 @@ func TestRuntimeNumWorkers(t *testing.T) {
 		})
 	}
 }
+
+func TestChromemVectorStore_ClearAndDrop(t *testing.T) {
+	ctx := context.Background()
+	tempDir := t.TempDir()
+
+	s, err := NewChromemVectorStoreSimple(tempDir, &mockEmbedder{dim: 3})
+	require.NoError(t, err)
+	require.NoError(t, s.Add(ctx, []rag.Document{{ID: "1", Content: "doc", Embedding: []float32{1, 0, 0}}}))
+
+	require.NoError(t, s.Clear(ctx))
+	stats, err := s.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.TotalDocuments)
+
+	// The cleared store is still usable
+	require.NoError(t, s.Add(ctx, []rag.Document{{ID: "2", Content: "doc", Embedding: []float32{0, 1, 0}}}))
+	results, err := s.Search(ctx, []float32{0, 1, 0}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "2", results[0].Document.ID)
+
+	var droppable rag.DroppableVectorStore = s
+	require.NoError(t, droppable.Drop(ctx))
+	reopened, err := NewChromemVectorStoreSimple(tempDir, &mockEmbedder{dim: 3})
+	require.NoError(t, err)
+	stats, err = reopened.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.TotalDocuments)
+}
+
+func TestChromemVectorStore_Warmup(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewChromemVectorStoreSimple(t.TempDir(), &mockEmbedder{dim: 3})
+	require.NoError(t, err)
+
+	// An empty store is ready without a query
+	assert.False(t, s.Ready())
+	require.NoError(t, s.Warmup(ctx))
+	assert.True(t, s.Ready())
+
+	require.NoError(t, s.Add(ctx, []rag.Document{{ID: "1", Content: "doc", Embedding: []float32{0, 1, 0}}}))
+	var warmable rag.WarmableVectorStore = s
+	require.NoError(t, warmable.Warmup(ctx))
+	assert.True(t, warmable.Ready())
+}
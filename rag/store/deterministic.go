@@ -0,0 +1,226 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/smallnest/langgraphgo/rag"
+)
+
+// DeterministicTestStore is a vector store for tests that returns a declared ranking of
+// documents for each query string instead of ranking by similarity.
+//
+// Search receives an embedding rather than the query text, so the store is also the
+// rag.Embedder to use with it: EmbedDocument encodes each distinct text as a one-dimensional
+// vector that Search decodes back to the text. Embeddings from another embedder are rejected
+// with rag.ErrInvalidQuery.
+type DeterministicTestStore struct {
+	mu       sync.Mutex
+	rankings map[string][]string
+	scores   map[string][]float64
+	docs     map[string]rag.Document
+	// texts holds every embedded text; a text's embedding is its index plus one
+	texts   []string
+	textIDs map[string]int
+	queries []string
+}
+
+// NewDeterministicTestStore creates a DeterministicTestStore that returns, for each query
+// string, the documents with the given IDs in the given order.
+//
+// The document at rank i of a ranking of n is scored 1 - i/n, so the first result scores 1.
+// Use SetScores to choose the scores instead, e.g. to test score thresholds. IDs of documents
+// that were not added are skipped, and queries without a ranking return no results.
+func NewDeterministicTestStore(rankings map[string][]string) *DeterministicTestStore {
+	s := &DeterministicTestStore{
+		rankings: make(map[string][]string, len(rankings)),
+		scores:   make(map[string][]float64),
+		docs:     make(map[string]rag.Document),
+		textIDs:  make(map[string]int),
+	}
+	for query, ids := range rankings {
+		s.rankings[query] = slices.Clone(ids)
+	}
+	return s
+}
+
+// SetScores sets the scores of the ranking of query, one per ranked document
+func (s *DeterministicTestStore) SetScores(query string, scores []float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ranking, ok := s.rankings[query]
+	if !ok {
+		return fmt.Errorf("no ranking for query %q", query)
+	}
+	if len(scores) != len(ranking) {
+		return fmt.Errorf("query %q ranks %d documents but %d scores were given", query, len(ranking), len(scores))
+	}
+	s.scores[query] = slices.Clone(scores)
+	return nil
+}
+
+// Queries returns the query texts searched so far, in order
+func (s *DeterministicTestStore) Queries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.queries)
+}
+
+// EmbedDocument returns the embedding that identifies text to Search
+func (s *DeterministicTestStore) EmbedDocument(ctx context.Context, text string) ([]float32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.embed(text), nil
+}
+
+// EmbedDocuments returns the embeddings that identify texts to Search
+func (s *DeterministicTestStore) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i] = s.embed(text)
+	}
+	return embeddings, nil
+}
+
+// GetDimension returns the embedding dimension, which is always 1
+func (s *DeterministicTestStore) GetDimension() int {
+	return 1
+}
+
+func (s *DeterministicTestStore) embed(text string) []float32 {
+	id, ok := s.textIDs[text]
+	if !ok {
+		s.texts = append(s.texts, text)
+		id = len(s.texts)
+		s.textIDs[text] = id
+	}
+	return []float32{float32(id)}
+}
+
+// Add adds documents, replacing documents with the same ID. Embeddings are ignored.
+func (s *DeterministicTestStore) Add(ctx context.Context, documents []rag.Document) error {
+	for _, doc := range documents {
+		if doc.ID == "" {
+			return fmt.Errorf("document has no ID")
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, doc := range documents {
+		s.docs[doc.ID] = doc
+	}
+	return nil
+}
+
+// Search returns the first k documents of the ranking declared for the query
+func (s *DeterministicTestStore) Search(ctx context.Context, query []float32, k int) ([]rag.DocumentSearchResult, error) {
+	return s.SearchWithFilter(ctx, query, k, nil)
+}
+
+// SearchWithFilter returns the first k documents of the ranking declared for the query
+// whose metadata matches filter
+func (s *DeterministicTestStore) SearchWithFilter(ctx context.Context, query []float32, k int, filter map[string]any) ([]rag.DocumentSearchResult, error) {
+	if k <= 0 {
+		return nil, fmt.Errorf("%w: k must be positive", rag.ErrInvalidQuery)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	text, err := s.decode(query)
+	if err != nil {
+		return nil, err
+	}
+	s.queries = append(s.queries, text)
+
+	ranking := s.rankings[text]
+	scores := s.scores[text]
+	results := []rag.DocumentSearchResult{}
+	for i, id := range ranking {
+		if len(results) == k {
+			break
+		}
+		doc, ok := s.docs[id]
+		if !ok || !matchesMetadata(doc, filter) {
+			continue
+		}
+		score := 1 - float64(i)/float64(len(ranking))
+		if scores != nil {
+			score = scores[i]
+		}
+		results = append(results, rag.DocumentSearchResult{Document: doc, Score: score})
+	}
+	return results, nil
+}
+
+// decode returns the text whose embedding is query
+func (s *DeterministicTestStore) decode(query []float32) (string, error) {
+	if len(query) == 1 {
+		id := int(query[0])
+		if float32(id) == query[0] && id >= 1 && id <= len(s.texts) {
+			return s.texts[id-1], nil
+		}
+	}
+	return "", fmt.Errorf("%w: query embedding was not created by the DeterministicTestStore", rag.ErrInvalidQuery)
+}
+
+// Delete removes the documents with the given IDs
+func (s *DeterministicTestStore) Delete(ctx context.Context, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		delete(s.docs, id)
+	}
+	return nil
+}
+
+// Update replaces existing documents
+func (s *DeterministicTestStore) Update(ctx context.Context, documents []rag.Document) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, doc := range documents {
+		if _, ok := s.docs[doc.ID]; !ok {
+			return fmt.Errorf("%w: %s", rag.ErrDocumentNotFound, doc.ID)
+		}
+	}
+	for _, doc := range documents {
+		s.docs[doc.ID] = doc
+	}
+	return nil
+}
+
+// GetStats returns statistics about the store
+func (s *DeterministicTestStore) GetStats(ctx context.Context) (*rag.VectorStoreStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &rag.VectorStoreStats{
+		TotalDocuments: len(s.docs),
+		TotalVectors:   len(s.docs),
+		Dimension:      1,
+		LastUpdated:    time.Now(),
+	}, nil
+}
+
+// Clear removes all documents. Rankings, scores and recorded queries are kept.
+func (s *DeterministicTestStore) Clear(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.docs = make(map[string]rag.Document)
+	return nil
+}
+
+// matchesMetadata reports whether doc has every key-value pair of filter in its metadata
+func matchesMetadata(doc rag.Document, filter map[string]any) bool {
+	for key, value := range filter {
+		docValue, exists := doc.Metadata[key]
+		if !exists || docValue != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/rag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeterministicTestStore(t *testing.T) {
+	ctx := context.Background()
+	newStore := func(t *testing.T) *DeterministicTestStore {
+		s := NewDeterministicTestStore(map[string][]string{
+			"what is rag": {"doc3", "doc1", "missing", "doc2"},
+		})
+		require.NoError(t, s.Add(ctx, []rag.Document{
+			{ID: "doc1", Content: "one", Metadata: map[string]any{"lang": "en"}},
+			{ID: "doc2", Content: "two", Metadata: map[string]any{"lang": "en"}},
+			{ID: "doc3", Content: "three", Metadata: map[string]any{"lang": "fr"}},
+		}))
+		return s
+	}
+	ids := func(results []rag.DocumentSearchResult) []string {
+		out := make([]string, len(results))
+		for i, r := range results {
+			out[i] = r.Document.ID
+		}
+		return out
+	}
+
+	t.Run("Returns the declared ranking", func(t *testing.T) {
+		s := newStore(t)
+		query, err := s.EmbedDocument(ctx, "what is rag")
+		require.NoError(t, err)
+
+		results, err := s.Search(ctx, query, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"doc3", "doc1", "doc2"}, ids(results))
+		assert.Equal(t, []float64{1, 0.75, 0.25}, []float64{results[0].Score, results[1].Score, results[2].Score})
+
+		results, err = s.Search(ctx, query, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"doc3"}, ids(results))
+		assert.Equal(t, []string{"what is rag", "what is rag"}, s.Queries())
+	})
+
+	t.Run("Filter and unknown queries", func(t *testing.T) {
+		s := newStore(t)
+		query, _ := s.EmbedDocument(ctx, "what is rag")
+		results, err := s.SearchWithFilter(ctx, query, 10, map[string]any{"lang": "en"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"doc1", "doc2"}, ids(results))
+
+		other, _ := s.EmbedDocument(ctx, "something else")
+		results, err = s.Search(ctx, other, 10)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+
+		_, err = s.Search(ctx, []float32{0.3}, 10)
+		assert.ErrorIs(t, err, rag.ErrInvalidQuery)
+		_, err = s.Search(ctx, query, 0)
+		assert.ErrorIs(t, err, rag.ErrInvalidQuery)
+	})
+
+	t.Run("SetScores", func(t *testing.T) {
+		s := newStore(t)
+		require.NoError(t, s.SetScores("what is rag", []float64{0.9, 0.6, 0.5, 0.3}))
+		assert.Error(t, s.SetScores("what is rag", []float64{0.9}))
+		assert.Error(t, s.SetScores("unknown", []float64{0.9}))
+
+		query, _ := s.EmbedDocument(ctx, "what is rag")
+		results, err := s.Search(ctx, query, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []float64{0.9, 0.6, 0.3}, []float64{results[0].Score, results[1].Score, results[2].Score})
+	})
+
+	t.Run("Delete, Update and Clear", func(t *testing.T) {
+		s := newStore(t)
+		require.NoError(t, s.Delete(ctx, []string{"doc3"}))
+		require.NoError(t, s.Update(ctx, []rag.Document{{ID: "doc1", Content: "updated"}}))
+		assert.ErrorIs(t, s.Update(ctx, []rag.Document{{ID: "doc3"}}), rag.ErrDocumentNotFound)
+
+		query, _ := s.EmbedDocument(ctx, "what is rag")
+		results, err := s.Search(ctx, query, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"doc1", "doc2"}, ids(results))
+		assert.Equal(t, "updated", results[0].Document.Content)
+
+		stats, err := s.GetStats(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, stats.TotalDocuments)
+
+		require.NoError(t, s.Clear(ctx))
+		results, err = s.Search(ctx, query, 10)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
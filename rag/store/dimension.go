@@ -0,0 +1,49 @@
+package store
+
+import (
+	"sync"
+
+	"github.com/smallnest/langgraphgo/rag"
+)
+
+// batchDimension validates a batch of embeddings against a store's dimension before any of
+// them is stored. A store without a dimension (0) takes that of the first embedding. It
+// returns the store's dimension once the batch is stored.
+func batchDimension(dimension int, embeddings [][]float32, ids []string) (int, error) {
+	for i, embedding := range embeddings {
+		if dimension == 0 {
+			dimension = len(embedding)
+		}
+		if err := rag.CheckDimension(dimension, embedding, ids[i]); err != nil {
+			return 0, err
+		}
+	}
+	return dimension, nil
+}
+
+// dimensionTracker holds the embedding dimension of a store backed by a database: the
+// configured dimension, or else that of the first stored embedding (0 until then)
+type dimensionTracker struct {
+	mu        sync.Mutex
+	dimension int
+}
+
+func (t *dimensionTracker) get() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.dimension
+}
+
+// check validates a batch of embeddings and returns the dimension to adopt once it is stored
+func (t *dimensionTracker) check(embeddings [][]float32, ids []string) (int, error) {
+	return batchDimension(t.get(), embeddings, ids)
+}
+
+// adopt sets the dimension after a batch was stored, unless the store already has one
+func (t *dimensionTracker) adopt(dimension int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.dimension == 0 {
+		t.dimension = dimension
+	}
+}
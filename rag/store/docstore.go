@@ -0,0 +1,173 @@
+package store
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/smallnest/langgraphgo/rag"
+)
+
+// InMemoryDocStore is a rag.DocStore that keeps documents in memory
+type InMemoryDocStore struct {
+	mu   sync.RWMutex
+	docs map[string]rag.Document
+}
+
+// NewInMemoryDocStore creates an empty InMemoryDocStore
+func NewInMemoryDocStore() *InMemoryDocStore {
+	return &InMemoryDocStore{docs: make(map[string]rag.Document)}
+}
+
+// Put adds the documents, replacing documents with the same ID
+func (s *InMemoryDocStore) Put(ctx context.Context, docs []rag.Document) error {
+	for _, doc := range docs {
+		if doc.ID == "" {
+			return fmt.Errorf("document has no ID")
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, doc := range docs {
+		s.docs[doc.ID] = doc
+	}
+	return nil
+}
+
+// Get returns the document with the given ID
+func (s *InMemoryDocStore) Get(ctx context.Context, id string) (rag.Document, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	doc, ok := s.docs[id]
+	if !ok {
+		return rag.Document{}, fmt.Errorf("%w: %s", rag.ErrDocumentNotFound, id)
+	}
+	return doc, nil
+}
+
+// GetMany returns the documents with the given IDs in order, omitting IDs that do not exist
+func (s *InMemoryDocStore) GetMany(ctx context.Context, ids []string) ([]rag.Document, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	docs := make([]rag.Document, 0, len(ids))
+	for _, id := range ids {
+		if doc, ok := s.docs[id]; ok {
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
+// Delete removes the documents with the given IDs
+func (s *InMemoryDocStore) Delete(ctx context.Context, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		delete(s.docs, id)
+	}
+	return nil
+}
+
+// FileDocStore is a rag.DocStore that keeps each document in a JSON file in a directory
+type FileDocStore struct {
+	mu   sync.RWMutex
+	path string
+}
+
+// NewFileDocStore creates a FileDocStore in the directory at path, creating it if needed.
+// Documents already in the directory are kept.
+func NewFileDocStore(path string) (*FileDocStore, error) {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create docstore directory: %w", err)
+	}
+	return &FileDocStore{path: path}, nil
+}
+
+// filename returns the file of the document with the given ID. The ID is hex encoded, so
+// any ID gives a valid file name inside the directory.
+func (s *FileDocStore) filename(id string) string {
+	return filepath.Join(s.path, hex.EncodeToString([]byte(id))+".json")
+}
+
+// Put adds the documents, replacing documents with the same ID
+func (s *FileDocStore) Put(ctx context.Context, docs []rag.Document) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, doc := range docs {
+		if doc.ID == "" {
+			return fmt.Errorf("document has no ID")
+		}
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("failed to marshal document %s: %w", doc.ID, err)
+		}
+		// Write to a temporary file first, so a failed write does not leave a partial document
+		filename := s.filename(doc.ID)
+		if err := os.WriteFile(filename+".tmp", data, 0600); err != nil {
+			return fmt.Errorf("failed to write document %s: %w", doc.ID, err)
+		}
+		if err := os.Rename(filename+".tmp", filename); err != nil {
+			return fmt.Errorf("failed to write document %s: %w", doc.ID, err)
+		}
+	}
+	return nil
+}
+
+// Get returns the document with the given ID
+func (s *FileDocStore) Get(ctx context.Context, id string) (rag.Document, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.read(id)
+}
+
+// read loads the document with the given ID
+func (s *FileDocStore) read(id string) (rag.Document, error) {
+	data, err := os.ReadFile(s.filename(id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return rag.Document{}, fmt.Errorf("%w: %s", rag.ErrDocumentNotFound, id)
+		}
+		return rag.Document{}, fmt.Errorf("failed to read document %s: %w", id, err)
+	}
+	var doc rag.Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return rag.Document{}, fmt.Errorf("failed to unmarshal document %s: %w", id, err)
+	}
+	return doc, nil
+}
+
+// GetMany returns the documents with the given IDs in order, omitting IDs that do not exist
+func (s *FileDocStore) GetMany(ctx context.Context, ids []string) ([]rag.Document, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	docs := make([]rag.Document, 0, len(ids))
+	for _, id := range ids {
+		doc, err := s.read(id)
+		if errors.Is(err, rag.ErrDocumentNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// Delete removes the documents with the given IDs
+func (s *FileDocStore) Delete(ctx context.Context, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		if err := os.Remove(s.filename(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to delete document %s: %w", id, err)
+		}
+	}
+	return nil
+}
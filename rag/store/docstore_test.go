@@ -0,0 +1,70 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/rag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocStores(t *testing.T) {
+	ctx := context.Background()
+	fileStore, err := NewFileDocStore(t.TempDir())
+	require.NoError(t, err)
+
+	stores := map[string]rag.DocStore{
+		"InMemory": NewInMemoryDocStore(),
+		"File":     fileStore,
+	}
+	for name, s := range stores {
+		t.Run(name, func(t *testing.T) {
+			docs := []rag.Document{
+				{ID: "parent/1", Content: "Full text of the first document", Metadata: map[string]any{"source": "a.md"}},
+				{ID: "parent/2", Content: "Full text of the second document"},
+			}
+			require.NoError(t, s.Put(ctx, docs))
+			assert.Error(t, s.Put(ctx, []rag.Document{{Content: "no ID"}}))
+
+			doc, err := s.Get(ctx, "parent/1")
+			require.NoError(t, err)
+			assert.Equal(t, "Full text of the first document", doc.Content)
+			assert.Equal(t, "a.md", doc.Metadata["source"])
+
+			_, err = s.Get(ctx, "missing")
+			assert.ErrorIs(t, err, rag.ErrDocumentNotFound)
+
+			many, err := s.GetMany(ctx, []string{"parent/2", "missing", "parent/1"})
+			require.NoError(t, err)
+			require.Len(t, many, 2)
+			assert.Equal(t, "parent/2", many[0].ID)
+			assert.Equal(t, "parent/1", many[1].ID)
+
+			// Put replaces documents with the same ID
+			require.NoError(t, s.Put(ctx, []rag.Document{{ID: "parent/2", Content: "Updated"}}))
+			doc, err = s.Get(ctx, "parent/2")
+			require.NoError(t, err)
+			assert.Equal(t, "Updated", doc.Content)
+
+			require.NoError(t, s.Delete(ctx, []string{"parent/1", "missing"}))
+			_, err = s.Get(ctx, "parent/1")
+			assert.ErrorIs(t, err, rag.ErrDocumentNotFound)
+		})
+	}
+}
+
+func TestFileDocStore_Persists(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s, err := NewFileDocStore(dir)
+	require.NoError(t, err)
+	require.NoError(t, s.Put(ctx, []rag.Document{{ID: "../escape", Content: "kept"}}))
+
+	reopened, err := NewFileDocStore(dir)
+	require.NoError(t, err)
+	doc, err := reopened.Get(ctx, "../escape")
+	require.NoError(t, err)
+	assert.Equal(t, "kept", doc.Content)
+}
@@ -2,10 +2,13 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"maps"
 	"net/url"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/redis/go-redis/v9"
@@ -16,11 +19,17 @@ import (
 type FalkorDBGraph struct {
 	client    redis.UniversalClient
 	graphName string
+	// vectorEmbeddings stores entity embeddings as native vecf32 vectors instead of lists
+	vectorEmbeddings bool
 }
 
-// NewFalkorDBGraph creates a new FalkorDB knowledge graph
+// NewFalkorDBGraph creates a new FalkorDB knowledge graph.
+//
+// Entity embeddings are stored as lists of numbers by default. Add embeddings=vector to the
+// connection string to store them as native FalkorDB vectors (vecf32, FalkorDB 4.0+), which are
+// compact and can be used in vector indexes. Both keep the float32 values exactly.
 func NewFalkorDBGraph(connectionString string) (rag.KnowledgeGraph, error) {
-	// Format: falkordb://host:port/graph_name
+	// Format: falkordb://host:port/graph_name[?embeddings=list|vector]
 	u, err := url.Parse(connectionString)
 	if err != nil {
 		return nil, fmt.Errorf("invalid connection string: %w", err)
@@ -35,54 +44,175 @@ func NewFalkorDBGraph(connectionString string) (rag.KnowledgeGraph, error) {
 		graphName = "rag"
 	}
 
+	var vectorEmbeddings bool
+	switch mode := u.Query().Get("embeddings"); mode {
+	case "", "list":
+	case "vector":
+		vectorEmbeddings = true
+	default:
+		return nil, fmt.Errorf("invalid connection string: unknown embeddings mode %q", mode)
+	}
+
 	// Create a go-redis client
 	client := redis.NewClient(&redis.Options{
 		Addr: addr,
 	})
 
 	return &FalkorDBGraph{
-		client:    client,
-		graphName: graphName,
+		client:           client,
+		graphName:        graphName,
+		vectorEmbeddings: vectorEmbeddings,
 	}, nil
 }
 
-// AddEntity adds an entity to the graph
+// AddEntity adds an entity to the graph, updating the properties of an existing entity with the same ID
 func (f *FalkorDBGraph) AddEntity(ctx context.Context, entity *rag.Entity) error {
+	_, err := f.UpsertEntity(ctx, entity)
+	return err
+}
+
+// UpsertEntity merges an entity on its ID and sets its properties.
+// It reports whether a new node was created (false means an existing node was updated).
+func (f *FalkorDBGraph) UpsertEntity(ctx context.Context, entity *rag.Entity) (bool, error) {
 	g := NewGraph(f.graphName, f.client)
 
 	label := sanitizeLabel(entity.Type)
-	props := entityToMap(entity)
-	propsStr := propsToString(props)
-
-	// Escape single quotes in entity ID for Cypher compatibility
-	escapedID := strings.ReplaceAll(entity.ID, "'", "\\'")
+	propsStr := propsToString(f.entityProps(entity))
 
 	// Using MERGE to avoid duplicates
-	query := fmt.Sprintf("MERGE (n:%s {id: '%s'}) SET n += %s", label, escapedID, propsStr)
+	query := fmt.Sprintf("MERGE (n:%s {id: %s}) SET n += %s", label, cypherString(entity.ID), propsStr)
 
-	_, err := g.Query(ctx, query)
-	return err
+	qr, err := g.Query(ctx, query)
+	if err != nil {
+		return false, err
+	}
+	return queryStatistic(qr, "Nodes created") > 0, nil
 }
 
-// AddRelationship adds a relationship to the graph
+// AddRelationship adds a relationship to the graph, updating the properties of an existing
+// relationship with the same ID
 func (f *FalkorDBGraph) AddRelationship(ctx context.Context, rel *rag.Relationship) error {
+	_, err := f.UpsertRelationship(ctx, rel)
+	return err
+}
+
+// UpsertRelationship merges a relationship on its ID between its source and target and sets
+// its properties. It reports whether a new relationship was created.
+func (f *FalkorDBGraph) UpsertRelationship(ctx context.Context, rel *rag.Relationship) (bool, error) {
 	g := NewGraph(f.graphName, f.client)
 
 	relType := sanitizeLabel(rel.Type)
 	props := relationshipToMap(rel)
 	propsStr := propsToString(props)
 
-	// Escape single quotes for Cypher compatibility
-	escapedSource := strings.ReplaceAll(rel.Source, "'", "\\'")
-	escapedTarget := strings.ReplaceAll(rel.Target, "'", "\\'")
-	escapedID := strings.ReplaceAll(rel.ID, "'", "\\'")
-
 	// MATCH source and target, then MERGE relationship
-	query := fmt.Sprintf("MATCH (a {id: '%s'}), (b {id: '%s'}) MERGE (a)-[r:%s {id: '%s'}]->(b) SET r += %s",
-		escapedSource, escapedTarget, relType, escapedID, propsStr)
+	query := fmt.Sprintf("MATCH (a {id: %s}), (b {id: %s}) MERGE (a)-[r:%s {id: %s}]->(b) SET r += %s",
+		cypherString(rel.Source), cypherString(rel.Target), relType, cypherString(rel.ID), propsStr)
 
-	_, err := g.Query(ctx, query)
-	return err
+	qr, err := g.Query(ctx, query)
+	if err != nil {
+		return false, err
+	}
+	return queryStatistic(qr, "Relationships created") > 0, nil
+}
+
+// falkorDBWriteBatchSize bounds the number of items sent in a single UNWIND query
+const falkorDBWriteBatchSize = 500
+
+// AddEntities merges several entities like AddEntity, with one UNWIND query per entity type
+// and batch of falkorDBWriteBatchSize (labels cannot be bound from a row). Entities of a
+// failed query are reported in a *rag.BatchWriteError; the others are written.
+func (f *FalkorDBGraph) AddEntities(ctx context.Context, entities []*rag.Entity) error {
+	g := NewGraph(f.graphName, f.client)
+	failed := make(map[int]error)
+
+	var labels []string
+	groups := make(map[string][]int)
+	for i, entity := range entities {
+		if entity == nil {
+			failed[i] = errors.New("nil entity")
+			continue
+		}
+		label := sanitizeLabel(entity.Type)
+		if _, ok := groups[label]; !ok {
+			labels = append(labels, label)
+		}
+		groups[label] = append(groups[label], i)
+	}
+
+	for _, label := range labels {
+		for batch := range slices.Chunk(groups[label], falkorDBWriteBatchSize) {
+			rows := make([]string, len(batch))
+			for j, i := range batch {
+				rows[j] = fmt.Sprintf("{id: %s, props: %s}", cypherString(entities[i].ID), propsToString(f.entityProps(entities[i])))
+			}
+			query := fmt.Sprintf("UNWIND [%s] AS row MERGE (n:%s {id: row.id}) SET n += row.props",
+				strings.Join(rows, ", "), label)
+			if _, err := g.Query(ctx, query); err != nil {
+				for _, i := range batch {
+					failed[i] = err
+				}
+			}
+		}
+	}
+	return newBatchWriteError(failed)
+}
+
+// AddRelationships merges several relationships like AddRelationship, with one UNWIND query
+// per relationship type and batch of falkorDBWriteBatchSize. Unlike AddRelationship, a
+// relationship whose source or target does not exist is reported with rag.ErrEndpointNotFound
+// in the returned *rag.BatchWriteError.
+func (f *FalkorDBGraph) AddRelationships(ctx context.Context, rels []*rag.Relationship) error {
+	g := NewGraph(f.graphName, f.client)
+	failed := make(map[int]error)
+
+	var relTypes []string
+	groups := make(map[string][]int)
+	for i, rel := range rels {
+		if rel == nil {
+			failed[i] = errors.New("nil relationship")
+			continue
+		}
+		relType := sanitizeLabel(rel.Type)
+		if _, ok := groups[relType]; !ok {
+			relTypes = append(relTypes, relType)
+		}
+		groups[relType] = append(groups[relType], i)
+	}
+
+	for _, relType := range relTypes {
+		for batch := range slices.Chunk(groups[relType], falkorDBWriteBatchSize) {
+			rows := make([]string, len(batch))
+			for j, i := range batch {
+				rel := rels[i]
+				rows[j] = fmt.Sprintf("{id: %s, source: %s, target: %s, props: %s}", cypherString(rel.ID),
+					cypherString(rel.Source), cypherString(rel.Target), propsToString(relationshipToMap(rel)))
+			}
+			query := fmt.Sprintf("UNWIND [%s] AS row MATCH (a {id: row.source}), (b {id: row.target}) "+
+				"MERGE (a)-[r:%s {id: row.id}]->(b) SET r += row.props RETURN DISTINCT row.id",
+				strings.Join(rows, ", "), relType)
+			qr, err := g.Query(ctx, query)
+			if err != nil {
+				for _, i := range batch {
+					failed[i] = err
+				}
+				continue
+			}
+
+			written := make(map[string]bool, len(qr.Results))
+			for _, row := range qr.Results {
+				if len(row) > 0 {
+					written[toString(row[0])] = true
+				}
+			}
+			for _, i := range batch {
+				if rel := rels[i]; !written[rel.ID] {
+					failed[i] = fmt.Errorf("%w: %s -> %s", rag.ErrEndpointNotFound, rel.Source, rel.Target)
+				}
+			}
+		}
+	}
+	return newBatchWriteError(failed)
 }
 
 // Query performs a graph query
@@ -181,7 +311,7 @@ func (f *FalkorDBGraph) GetEntity(ctx context.Context, id string) (*rag.Entity,
 		return nil, err
 	}
 	if len(qr.Results) == 0 {
-		return nil, fmt.Errorf("entity not found: %s", id)
+		return nil, fmt.Errorf("entity %w: %s", rag.ErrNotFound, id)
 	}
 
 	row := qr.Results[0]
@@ -206,7 +336,7 @@ func (f *FalkorDBGraph) GetRelationship(ctx context.Context, id string) (*rag.Re
 		return nil, err
 	}
 	if len(qr.Results) == 0 {
-		return nil, fmt.Errorf("relationship not found: %s", id)
+		return nil, fmt.Errorf("relationship %w: %s", rag.ErrNotFound, id)
 	}
 
 	row := qr.Results[0]
@@ -221,6 +351,79 @@ func (f *FalkorDBGraph) GetRelationship(ctx context.Context, id string) (*rag.Re
 	return rel, nil
 }
 
+// GetEntities retrieves several entities by ID with a single query.
+// Results follow the order of ids; unknown IDs are omitted.
+func (f *FalkorDBGraph) GetEntities(ctx context.Context, ids []string) ([]*rag.Entity, error) {
+	if len(ids) == 0 {
+		return []*rag.Entity{}, nil
+	}
+	g := NewGraph(f.graphName, f.client)
+
+	query := fmt.Sprintf("MATCH (n) WHERE n.id IN %s RETURN n", cypherStringList(ids))
+	qr, err := g.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*rag.Entity, len(qr.Results))
+	for _, row := range qr.Results {
+		if len(row) == 0 {
+			continue
+		}
+		if ent := parseNode(row[0]); ent != nil {
+			byID[ent.ID] = ent
+		}
+	}
+
+	entities := make([]*rag.Entity, 0, len(byID))
+	for _, id := range ids {
+		if ent, ok := byID[id]; ok {
+			entities = append(entities, ent)
+			delete(byID, id)
+		}
+	}
+	return entities, nil
+}
+
+// GetRelationships retrieves several relationships by ID with a single query.
+// Results follow the order of ids; unknown IDs are omitted.
+func (f *FalkorDBGraph) GetRelationships(ctx context.Context, ids []string) ([]*rag.Relationship, error) {
+	if len(ids) == 0 {
+		return []*rag.Relationship{}, nil
+	}
+	g := NewGraph(f.graphName, f.client)
+
+	query := fmt.Sprintf("MATCH (a)-[r]->(b) WHERE r.id IN %s RETURN a, r, b", cypherStringList(ids))
+	qr, err := g.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*rag.Relationship, len(qr.Results))
+	for _, row := range qr.Results {
+		if len(row) < 3 {
+			continue
+		}
+		a := parseNode(row[0])
+		b := parseNode(row[2])
+		if a == nil || b == nil {
+			continue
+		}
+		if rel := parseEdge(row[1], a.ID, b.ID); rel != nil {
+			byID[rel.ID] = rel
+		}
+	}
+
+	rels := make([]*rag.Relationship, 0, len(byID))
+	for _, id := range ids {
+		if rel, ok := byID[id]; ok {
+			rels = append(rels, rel)
+			delete(byID, id)
+		}
+	}
+	return rels, nil
+}
+
 // GetRelatedEntities finds entities related to a given entity
 func (f *FalkorDBGraph) GetRelatedEntities(ctx context.Context, entityID string, maxDepth int) ([]*rag.Entity, error) {
 	if maxDepth < 1 {
@@ -289,6 +492,34 @@ func (f *FalkorDBGraph) Close() error {
 
 // Helpers
 
+// cypherStringList renders ids as a Cypher list literal of quoted strings
+func cypherStringList(ids []string) string {
+	quoted := make([]string, len(ids))
+	for i, id := range ids {
+		quoted[i] = cypherString(id)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// cypherString renders s as a single quoted Cypher string literal
+func cypherString(s string) string {
+	return "'" + cypherEscape(s) + "'"
+}
+
+// cypherEscape escapes backslashes and quotes in a single pass, so that a value cannot end
+// the string literal it is written into early
+var cypherEscape = strings.NewReplacer(`\`, `\\`, `'`, `\'`, `"`, `\"`).Replace
+
+// cypherKey renders a property key, quoting it with backticks unless it is a plain identifier
+func cypherKey(k string) string {
+	if identifierRegex.MatchString(k) {
+		return k
+	}
+	return "`" + strings.ReplaceAll(k, "`", "``") + "`"
+}
+
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 var labelRegex = regexp.MustCompile(`[^a-zA-Z0-9_]`)
 
 func sanitizeLabel(l string) string {
@@ -299,6 +530,9 @@ func sanitizeLabel(l string) string {
 	return clean
 }
 
+// cypherVector is an embedding written as a native FalkorDB vector: vecf32([v1, v2, ...])
+type cypherVector []float32
+
 func propsToString(m map[string]any) string {
 	parts := []string{}
 	for k, v := range m {
@@ -306,19 +540,44 @@ func propsToString(m map[string]any) string {
 		switch v := v.(type) {
 		case []float32:
 			// Convert to Cypher list: [v1, v2, ...]
+			val = float32List(v)
+		case cypherVector:
+			val = "vecf32(" + float32List(v) + ")"
+		case []float64:
 			s := make([]string, len(v))
 			for i, f := range v {
-				s[i] = fmt.Sprintf("%f", f)
+				s[i] = strconv.FormatFloat(f, 'g', -1, 64)
 			}
 			val = "[" + strings.Join(s, ",") + "]"
 		default:
 			val = quoteString(v)
 		}
-		parts = append(parts, fmt.Sprintf("%s: %v", k, val))
+		parts = append(parts, fmt.Sprintf("%s: %v", cypherKey(k), val))
 	}
 	return "{" + strings.Join(parts, ", ") + "}"
 }
 
+// float32List formats v as a Cypher list. Each value is written with the fewest digits that
+// parse back to the same float32, so embeddings are not rounded (unlike %f, which keeps six
+// decimals and flushes small components to zero).
+func float32List(v []float32) string {
+	s := make([]string, len(v))
+	for i, f := range v {
+		s[i] = strconv.FormatFloat(float64(f), 'g', -1, 32)
+	}
+	return "[" + strings.Join(s, ",") + "]"
+}
+
+// entityProps returns the properties written for e, with the embedding as a native vector if
+// the graph is configured so
+func (f *FalkorDBGraph) entityProps(e *rag.Entity) map[string]any {
+	m := entityToMap(e)
+	if f.vectorEmbeddings && len(e.Embedding) > 0 {
+		m["embedding"] = cypherVector(e.Embedding)
+	}
+	return m
+}
+
 func entityToMap(e *rag.Entity) map[string]any {
 	m := make(map[string]any)
 	maps.Copy(m, e.Properties)
@@ -3,29 +3,21 @@ package store
 import (
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/redis/go-redis/v9"
+	"github.com/smallnest/langgraphgo/rag"
 )
 
 func quoteString(i any) any {
 	switch x := i.(type) {
 	case string:
-		if len(x) == 0 {
-			return "\"\""
-		}
-		// Escape single quotes for Cypher compatibility
-		x = strings.ReplaceAll(x, "'", "\\'")
-		if x[0] != '"' {
-			x = "\"" + x
-		}
-		if x[len(x)-1] != '"' {
-			x += "\""
-		}
-		return x
+		return "\"" + cypherEscape(x) + "\""
 	default:
 		return i
 	}
@@ -67,7 +59,7 @@ func (n *Node) String() string {
 	if len(n.Properties) > 0 {
 		p := ""
 		for k, v := range n.Properties {
-			p += fmt.Sprintf("%s:%v,", k, quoteString(v))
+			p += fmt.Sprintf("%s:%v,", cypherKey(k), quoteString(v))
 		}
 		p = p[:len(p)-1]
 		s += "{" + p + "}"
@@ -93,7 +85,7 @@ func (e *Edge) String() string {
 	if len(e.Properties) > 0 {
 		p := ""
 		for k, v := range e.Properties {
-			p += fmt.Sprintf("%s:%s,", k, quoteString(v))
+			p += fmt.Sprintf("%s:%s,", cypherKey(k), quoteString(v))
 		}
 		p = p[:len(p)-1]
 		s += "{" + p + "}"
@@ -164,6 +156,21 @@ type QueryResult struct {
 	Statistics []string
 }
 
+// queryStatistic returns the value of a statistic such as "Nodes created: 1", or 0 if absent
+func queryStatistic(qr QueryResult, name string) int {
+	for _, stat := range qr.Statistics {
+		key, value, ok := strings.Cut(stat, ":")
+		if !ok || strings.TrimSpace(key) != name {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
 // Query executes a query against the graph.
 func (g *Graph) Query(ctx context.Context, q string) (QueryResult, error) {
 	qr := QueryResult{}
@@ -171,7 +178,7 @@ func (g *Graph) Query(ctx context.Context, q string) (QueryResult, error) {
 	// go-redis Do returns a Cmd which can be used to get the result
 	res, err := g.Conn.Do(ctx, "GRAPH.QUERY", g.Name, q).Result()
 	if err != nil {
-		return qr, err
+		return qr, queryError(err)
 	}
 
 	r, ok := res.([]any)
@@ -240,6 +247,16 @@ func (g *Graph) Query(ctx context.Context, q string) (QueryResult, error) {
 	return qr, nil
 }
 
+// queryError classifies a failed query: errors reported by the server (e.g. Cypher syntax
+// errors) match rag.ErrInvalidQuery, the others are connection failures
+func queryError(err error) error {
+	var serverErr redis.Error
+	if errors.As(err, &serverErr) {
+		return fmt.Errorf("%w: %w", rag.ErrInvalidQuery, err)
+	}
+	return rag.NewConnectionError("FalkorDB", err)
+}
+
 func (g *Graph) Delete(ctx context.Context) error {
 	return g.Conn.Do(ctx, "GRAPH.DELETE", g.Name).Err()
 }
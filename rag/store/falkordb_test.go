@@ -1,10 +1,12 @@
 package store
 
 import (
+	"context"
 	"testing"
 
 	"github.com/smallnest/langgraphgo/rag"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewFalkorDBGraph(t *testing.T) {
@@ -41,6 +43,18 @@ func TestNewFalkorDBGraph(t *testing.T) {
 		assert.Contains(t, err.Error(), "missing host")
 	})
 
+	t.Run("Embeddings mode", func(t *testing.T) {
+		g, err := NewFalkorDBGraph("falkordb://localhost:6379/graph?embeddings=vector")
+		require.NoError(t, err)
+		fg := g.(*FalkorDBGraph)
+		assert.Equal(t, "graph", fg.graphName)
+		assert.True(t, fg.vectorEmbeddings)
+		fg.Close()
+
+		_, err = NewFalkorDBGraph("falkordb://localhost:6379/graph?embeddings=text")
+		assert.ErrorContains(t, err, "unknown embeddings mode")
+	})
+
 	t.Run("NewKnowledgeGraph factory", func(t *testing.T) {
 		g, err := NewKnowledgeGraph("falkordb://localhost:6379/graph")
 		if err == nil {
@@ -97,6 +111,14 @@ func TestPropsToString(t *testing.T) {
 		assert.Contains(t, s, "]")
 	})
 
+	t.Run("Embeddings keep full float32 precision", func(t *testing.T) {
+		embedding := []float32{0.12345678, -1.5e-7, 3}
+		assert.Equal(t, "{embedding: [0.12345678,-1.5e-07,3]}", propsToString(map[string]any{"embedding": embedding}))
+		assert.Equal(t, "{embedding: vecf32([0.12345678,-1.5e-07,3])}",
+			propsToString(map[string]any{"embedding": cypherVector(embedding)}))
+		assert.Equal(t, "{scores: [0.1234567890123,2]}", propsToString(map[string]any{"scores": []float64{0.1234567890123, 2}}))
+	})
+
 	t.Run("Boolean and numeric values", func(t *testing.T) {
 		props := map[string]any{
 			"active": true,
@@ -716,7 +738,14 @@ func TestInternalHelpers(t *testing.T) {
 	})
 
 	t.Run("quoteString with quoted string", func(t *testing.T) {
-		assert.Equal(t, "\"already\"", quoteString("\"already\""))
+		assert.Equal(t, `"\"already\""`, quoteString(`"already"`))
+	})
+
+	t.Run("quoteString and cypherString with backslashes", func(t *testing.T) {
+		assert.Equal(t, `"end\\"`, quoteString(`end\`))
+		assert.Equal(t, `'end\\'`, cypherString(`end\`))
+		assert.Equal(t, `'a\\\' OR 1=1 //'`, cypherString(`a\' OR 1=1 //`))
+		assert.Equal(t, "{`weird key`: 1}", propsToString(map[string]any{"weird key": 1}))
 	})
 
 	t.Run("quoteString with single quotes", func(t *testing.T) {
@@ -790,3 +819,112 @@ func TestInternalHelpers(t *testing.T) {
 		assert.Contains(t, s, "]->")
 	})
 }
+
+func TestQueryStatistic(t *testing.T) {
+	qr := QueryResult{Statistics: []string{
+		"Nodes created: 1",
+		"Properties set: 4",
+		"Query internal execution time: 0.3 milliseconds",
+	}}
+	assert.Equal(t, 1, queryStatistic(qr, "Nodes created"))
+	assert.Equal(t, 4, queryStatistic(qr, "Properties set"))
+	assert.Equal(t, 0, queryStatistic(qr, "Relationships created"))
+}
+
+func TestFalkorDBGraph_Upsert(t *testing.T) {
+	ctx := context.Background()
+	kg, err := NewFalkorDBGraph("falkordb://localhost:6379/test_upsert")
+	require.NoError(t, err)
+	fg := kg.(*FalkorDBGraph)
+	defer fg.Close()
+
+	raw := NewGraph(fg.graphName, fg.client)
+	if _, err := raw.Query(ctx, "RETURN 1"); err != nil {
+		t.Skipf("FalkorDB not available on localhost:6379, skipping test: %v", err)
+	}
+	defer func() { _ = raw.Delete(ctx) }()
+
+	created, err := fg.UpsertEntity(ctx, &rag.Entity{ID: "alice", Type: "Person", Name: "Alice",
+		Properties: map[string]any{"age": 30}})
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	created, err = fg.UpsertEntity(ctx, &rag.Entity{ID: "alice", Type: "Person", Name: "Alice",
+		Properties: map[string]any{"age": 31, "city": "Paris"}})
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	qr, err := raw.Query(ctx, "MATCH (n {id: 'alice'}) RETURN n.id")
+	require.NoError(t, err)
+	assert.Len(t, qr.Results, 1)
+
+	entity, err := fg.GetEntity(ctx, "alice")
+	require.NoError(t, err)
+	assert.EqualValues(t, 31, entity.Properties["age"])
+	assert.Equal(t, "Paris", entity.Properties["city"])
+
+	entities, err := fg.GetEntities(ctx, []string{"paris", "missing", "alice"})
+	require.NoError(t, err)
+	require.Len(t, entities, 1)
+	assert.Equal(t, "alice", entities[0].ID)
+
+	_, err = fg.UpsertEntity(ctx, &rag.Entity{ID: "paris", Type: "City", Name: "Paris"})
+	require.NoError(t, err)
+	rel := &rag.Relationship{ID: "alice_paris", Source: "alice", Target: "paris", Type: "LIVES_IN"}
+	created, err = fg.UpsertRelationship(ctx, rel)
+	require.NoError(t, err)
+	assert.True(t, created)
+	created, err = fg.UpsertRelationship(ctx, rel)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	rels, err := fg.GetRelationships(ctx, []string{"alice_paris"})
+	require.NoError(t, err)
+	require.Len(t, rels, 1)
+	assert.Equal(t, "paris", rels[0].Target)
+}
+
+func TestFalkorDBGraph_BatchAdd(t *testing.T) {
+	ctx := context.Background()
+	kg, err := NewFalkorDBGraph("falkordb://localhost:6379/test_batch_add")
+	require.NoError(t, err)
+	fg := kg.(*FalkorDBGraph)
+	defer fg.Close()
+
+	raw := NewGraph(fg.graphName, fg.client)
+	if _, err := raw.Query(ctx, "RETURN 1"); err != nil {
+		t.Skipf("FalkorDB not available on localhost:6379, skipping test: %v", err)
+	}
+	defer func() { _ = raw.Delete(ctx) }()
+
+	err = fg.AddEntities(ctx, []*rag.Entity{
+		{ID: "alice", Type: "Person", Name: "Alice", Properties: map[string]any{"age": 30}},
+		{ID: "paris", Type: "City", Name: "Paris"},
+		{ID: "o'brien", Type: "Person", Name: "O'Brien"},
+	})
+	require.NoError(t, err)
+
+	entities, err := fg.GetEntities(ctx, []string{"alice", "paris", "o'brien"})
+	require.NoError(t, err)
+	require.Len(t, entities, 3)
+	assert.EqualValues(t, 30, entities[0].Properties["age"])
+
+	err = fg.AddRelationships(ctx, []*rag.Relationship{
+		{ID: "alice_paris", Source: "alice", Target: "paris", Type: "LIVES_IN"},
+		{ID: "alice_london", Source: "alice", Target: "london", Type: "LIVES_IN"},
+		{ID: "obrien_alice", Source: "o'brien", Target: "alice", Type: "KNOWS"},
+	})
+	var batchErr *rag.BatchWriteError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, []int{1}, batchErr.Indices())
+	assert.ErrorIs(t, err, rag.ErrEndpointNotFound)
+
+	rels, err := fg.GetRelationships(ctx, []string{"alice_paris", "alice_london", "obrien_alice"})
+	require.NoError(t, err)
+	require.Len(t, rels, 2)
+	assert.Equal(t, "paris", rels[0].Target)
+}
+
+func TestCypherStringList(t *testing.T) {
+	assert.Equal(t, `['a', 'it\'s']`, cypherStringList([]string{"a", "it's"}))
+}
@@ -2,8 +2,12 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/smallnest/langgraphgo/rag"
 )
@@ -11,11 +15,7 @@ import (
 // NewKnowledgeGraph creates a new knowledge graph based on the database URL
 func NewKnowledgeGraph(databaseURL string) (rag.KnowledgeGraph, error) {
 	if strings.HasPrefix(databaseURL, "memory://") {
-		return &MemoryGraph{
-			entities:      make(map[string]rag.Entity),
-			relationships: make(map[string]rag.Relationship),
-			entityIndex:   make(map[string][]string),
-		}, nil
+		return NewInMemoryGraph(), nil
 	}
 
 	if strings.HasPrefix(databaseURL, "falkordb://") {
@@ -26,34 +26,84 @@ func NewKnowledgeGraph(databaseURL string) (rag.KnowledgeGraph, error) {
 	return nil, fmt.Errorf("only memory:// and falkordb:// URLs are currently supported")
 }
 
-// MemoryGraph implements an in-memory knowledge graph
+// MemoryGraph implements an in-memory knowledge graph. It needs no external services, which
+// makes it suitable for tests, demos and small offline GraphRAG setups. It is safe for
+// concurrent use.
 type MemoryGraph struct {
+	mu            sync.RWMutex
 	entities      map[string]rag.Entity
 	relationships map[string]rag.Relationship
 	entityIndex   map[string][]string
+	// adjacency lists the IDs of the relationships touching each entity, in insertion order
+	adjacency map[string][]string
 }
 
-// AddEntity adds an entity to the memory graph
-func (m *MemoryGraph) AddEntity(ctx context.Context, entity *rag.Entity) error {
-	m.entities[entity.ID] = *entity
-
-	// Update type index
-	if _, exists := m.entityIndex[entity.Type]; !exists {
-		m.entityIndex[entity.Type] = make([]string, 0)
+// NewInMemoryGraph creates an empty in-memory knowledge graph
+func NewInMemoryGraph() *MemoryGraph {
+	return &MemoryGraph{
+		entities:      make(map[string]rag.Entity),
+		relationships: make(map[string]rag.Relationship),
+		entityIndex:   make(map[string][]string),
+		adjacency:     make(map[string][]string),
 	}
-	m.entityIndex[entity.Type] = append(m.entityIndex[entity.Type], entity.ID)
+}
 
+// AddEntity adds an entity to the memory graph, replacing an existing entity with the same ID
+func (m *MemoryGraph) AddEntity(ctx context.Context, entity *rag.Entity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.putEntity(entity)
 	return nil
 }
 
-// AddRelationship adds a relationship to the memory graph
+// AddRelationship adds a relationship to the memory graph, replacing an existing relationship
+// with the same ID. The source and target entities do not need to exist.
 func (m *MemoryGraph) AddRelationship(ctx context.Context, rel *rag.Relationship) error {
-	m.relationships[rel.ID] = *rel
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.putRelationship(rel)
 	return nil
 }
 
-// Query performs a graph query
+// AddEntities adds several entities to the memory graph
+func (m *MemoryGraph) AddEntities(ctx context.Context, entities []*rag.Entity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	failed := make(map[int]error)
+	for i, entity := range entities {
+		if entity == nil {
+			failed[i] = errors.New("nil entity")
+			continue
+		}
+		m.putEntity(entity)
+	}
+	return newBatchWriteError(failed)
+}
+
+// AddRelationships adds several relationships to the memory graph
+func (m *MemoryGraph) AddRelationships(ctx context.Context, rels []*rag.Relationship) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	failed := make(map[int]error)
+	for i, rel := range rels {
+		if rel == nil {
+			failed[i] = errors.New("nil relationship")
+			continue
+		}
+		m.putRelationship(rel)
+	}
+	return newBatchWriteError(failed)
+}
+
+// Query performs a graph query. With a StartEntity, the candidates are that entity and the
+// entities reachable within MaxDepth hops (default 1), with the traversed relationships.
+// Entities are filtered by EntityTypes or EntityType and by Filters (property equality), and
+// capped by Limit. Relationships are those of the Relationships types when given, otherwise
+// those touching a returned entity.
 func (m *MemoryGraph) Query(ctx context.Context, query *rag.GraphQuery) (*rag.GraphQueryResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	result := &rag.GraphQueryResult{
 		Entities:      make([]*rag.Entity, 0),
 		Relationships: make([]*rag.Relationship, 0),
@@ -61,36 +111,79 @@ func (m *MemoryGraph) Query(ctx context.Context, query *rag.GraphQuery) (*rag.Gr
 		Metadata:      make(map[string]any),
 	}
 
-	// Filter by entity types
-	if len(query.EntityTypes) > 0 {
-		for _, entityType := range query.EntityTypes {
-			if entityIDs, exists := m.entityIndex[entityType]; exists {
-				for _, id := range entityIDs {
-					if entity, exists := m.entities[id]; exists {
-						e := entity
-						result.Entities = append(result.Entities, &e)
+	relTypes := make(map[string]bool, len(query.Relationships))
+	for _, t := range query.Relationships {
+		relTypes[t] = true
+	}
+	entityTypes := make(map[string]bool, len(query.EntityTypes)+1)
+	for _, t := range query.EntityTypes {
+		entityTypes[t] = true
+	}
+	if query.EntityType != "" {
+		entityTypes[query.EntityType] = true
+	}
+
+	var candidates, relCandidates []string
+	if query.StartEntity != "" {
+		if _, exists := m.entities[query.StartEntity]; !exists {
+			return result, nil
+		}
+		depth := query.MaxDepth
+		if depth < 1 {
+			depth = 1
+		}
+		reached, traversed := m.traverse(query.StartEntity, depth, relTypes)
+		candidates = append([]string{query.StartEntity}, reached...)
+		relCandidates = traversed
+	} else {
+		relCandidates = slices.Sorted(maps.Keys(m.relationships))
+	}
+
+	var rels []rag.Relationship
+	for _, id := range relCandidates {
+		if rel := m.relationships[id]; len(relTypes) == 0 || relTypes[rel.Type] {
+			rels = append(rels, rel)
+		}
+	}
+
+	if query.StartEntity == "" {
+		if len(relTypes) > 0 && len(entityTypes) == 0 && len(query.Filters) == 0 {
+			// A relationship query returns the entities taking part in the relationships
+			seen := make(map[string]bool)
+			for _, rel := range rels {
+				for _, endpoint := range []string{rel.Source, rel.Target} {
+					if !seen[endpoint] {
+						seen[endpoint] = true
+						candidates = append(candidates, endpoint)
 					}
 				}
 			}
+		} else {
+			candidates = slices.Sorted(maps.Keys(m.entities))
 		}
 	}
 
-	// Filter by relationship types
-	// Note: GraphQuery in rag/types.go has Relationships []string, checking implementation
-	if len(query.Relationships) > 0 {
-		for _, relType := range query.Relationships {
-			for _, rel := range m.relationships {
-				if rel.Type == relType {
-					r := rel
-					result.Relationships = append(result.Relationships, &r)
-				}
-			}
+	matched := make(map[string]bool)
+	for _, id := range candidates {
+		entity, exists := m.entities[id]
+		if !exists || (len(entityTypes) > 0 && !entityTypes[entity.Type]) || !matchesFilters(entity, query.Filters) {
+			continue
 		}
+		if query.Limit > 0 && len(result.Entities) >= query.Limit {
+			break
+		}
+		e := entity
+		result.Entities = append(result.Entities, &e)
+		matched[id] = true
 	}
 
-	// Apply limit
-	if query.Limit > 0 && len(result.Entities) > query.Limit {
-		result.Entities = result.Entities[:query.Limit]
+	// Relationships of the requested types, or else those touching a returned entity
+	for _, rel := range rels {
+		if len(relTypes) == 0 && !matched[rel.Source] && !matched[rel.Target] {
+			continue
+		}
+		r := rel
+		result.Relationships = append(result.Relationships, &r)
 	}
 
 	return result, nil
@@ -98,125 +191,235 @@ func (m *MemoryGraph) Query(ctx context.Context, query *rag.GraphQuery) (*rag.Gr
 
 // GetEntity retrieves an entity by ID
 func (m *MemoryGraph) GetEntity(ctx context.Context, id string) (*rag.Entity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	entity, exists := m.entities[id]
 	if !exists {
-		return nil, fmt.Errorf("entity not found: %s", id)
+		return nil, fmt.Errorf("entity %w: %s", rag.ErrNotFound, id)
 	}
 	return &entity, nil
 }
 
 // GetRelationship retrieves a relationship by ID
 func (m *MemoryGraph) GetRelationship(ctx context.Context, id string) (*rag.Relationship, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	rel, exists := m.relationships[id]
 	if !exists {
-		return nil, fmt.Errorf("relationship not found: %s", id)
+		return nil, fmt.Errorf("relationship %w: %s", rag.ErrNotFound, id)
 	}
 	return &rel, nil
 }
 
-// GetRelatedEntities finds entities related to a given entity
-func (m *MemoryGraph) GetRelatedEntities(ctx context.Context, entityID string, maxDepth int) ([]*rag.Entity, error) {
-	related := make([]*rag.Entity, 0)
-	visited := make(map[string]bool)
-
-	// Simple implementation for depth 1
-	// For maxDepth > 1, would need BFS
-
-	// Find relationships connected to this entity
-	for _, rel := range m.relationships {
-		// Note: relationshipType filter not in signature anymore, maxDepth added
-
-		if rel.Source == entityID {
-			if !visited[rel.Target] {
-				visited[rel.Target] = true
-				if entity, exists := m.entities[rel.Target]; exists {
-					e := entity
-					related = append(related, &e)
-				}
-			}
-		} else if rel.Target == entityID {
-			if !visited[rel.Source] {
-				visited[rel.Source] = true
-				if entity, exists := m.entities[rel.Source]; exists {
-					e := entity
-					related = append(related, &e)
-				}
-			}
+// GetEntities retrieves several entities by ID, omitting unknown IDs
+func (m *MemoryGraph) GetEntities(ctx context.Context, ids []string) ([]*rag.Entity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entities := make([]*rag.Entity, 0, len(ids))
+	for _, id := range ids {
+		if entity, exists := m.entities[id]; exists {
+			e := entity
+			entities = append(entities, &e)
 		}
 	}
-
-	return related, nil
+	return entities, nil
 }
 
-// DeleteEntity removes an entity from the memory graph
-func (m *MemoryGraph) DeleteEntity(ctx context.Context, id string) error {
-	delete(m.entities, id)
-
-	// Remove from type index
-	for entityType, entityIDs := range m.entityIndex {
-		for i, entityID := range entityIDs {
-			if entityID == id {
-				m.entityIndex[entityType] = append(entityIDs[:i], entityIDs[i+1:]...)
-				break
-			}
+// GetRelationships retrieves several relationships by ID, omitting unknown IDs
+func (m *MemoryGraph) GetRelationships(ctx context.Context, ids []string) ([]*rag.Relationship, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	rels := make([]*rag.Relationship, 0, len(ids))
+	for _, id := range ids {
+		if rel, exists := m.relationships[id]; exists {
+			r := rel
+			rels = append(rels, &r)
 		}
-		if len(m.entityIndex[entityType]) == 0 {
-			delete(m.entityIndex, entityType)
+	}
+	return rels, nil
+}
+
+// GetRelatedEntities returns the entities reachable from entityID within maxDepth hops
+// (at least 1), following relationships in both directions, nearest first
+func (m *MemoryGraph) GetRelatedEntities(ctx context.Context, entityID string, maxDepth int) ([]*rag.Entity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if maxDepth < 1 {
+		maxDepth = 1
+	}
+
+	reached, _ := m.traverse(entityID, maxDepth, nil)
+	related := make([]*rag.Entity, 0, len(reached))
+	for _, id := range reached {
+		if entity, exists := m.entities[id]; exists {
+			e := entity
+			related = append(related, &e)
 		}
 	}
+	return related, nil
+}
+
+// DeleteEntity removes an entity and the relationships touching it from the memory graph
+func (m *MemoryGraph) DeleteEntity(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
+	if entity, exists := m.entities[id]; exists {
+		m.unindexEntity(entity)
+		delete(m.entities, id)
+	}
+	for _, relID := range slices.Clone(m.adjacency[id]) {
+		m.removeRelationship(relID)
+	}
 	return nil
 }
 
 // DeleteRelationship removes a relationship from the memory graph
 func (m *MemoryGraph) DeleteRelationship(ctx context.Context, id string) error {
-	delete(m.relationships, id)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.removeRelationship(id)
 	return nil
 }
 
 // UpdateEntity updates an entity in the memory graph
 func (m *MemoryGraph) UpdateEntity(ctx context.Context, entity *rag.Entity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if _, exists := m.entities[entity.ID]; !exists {
-		return fmt.Errorf("entity not found: %s", entity.ID)
+		return fmt.Errorf("entity %w: %s", rag.ErrNotFound, entity.ID)
 	}
-
-	// Update type index if type changed
-	oldEntity, exists := m.entities[entity.ID]
-	if exists && oldEntity.Type != entity.Type {
-		// Remove from old type index
-		for i, entityID := range m.entityIndex[oldEntity.Type] {
-			if entityID == entity.ID {
-				m.entityIndex[oldEntity.Type] = append(m.entityIndex[oldEntity.Type][:i], m.entityIndex[oldEntity.Type][i+1:]...)
-				break
-			}
-		}
-
-		// Add to new type index
-		if _, exists := m.entityIndex[entity.Type]; !exists {
-			m.entityIndex[entity.Type] = make([]string, 0)
-		}
-		m.entityIndex[entity.Type] = append(m.entityIndex[entity.Type], entity.ID)
-	}
-
-	m.entities[entity.ID] = *entity
+	m.putEntity(entity)
 	return nil
 }
 
 // UpdateRelationship updates a relationship in the memory graph
 func (m *MemoryGraph) UpdateRelationship(ctx context.Context, rel *rag.Relationship) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if _, exists := m.relationships[rel.ID]; !exists {
-		return fmt.Errorf("relationship not found: %s", rel.ID)
+		return fmt.Errorf("relationship %w: %s", rag.ErrNotFound, rel.ID)
 	}
-
-	m.relationships[rel.ID] = *rel
+	m.putRelationship(rel)
 	return nil
 }
 
 // Close closes the memory graph (no-op for in-memory implementation)
 func (m *MemoryGraph) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	// Clear all data
 	m.entities = make(map[string]rag.Entity)
 	m.relationships = make(map[string]rag.Relationship)
 	m.entityIndex = make(map[string][]string)
+	m.adjacency = make(map[string][]string)
 	return nil
 }
+
+// putEntity stores entity and keeps the type index in sync. The caller holds the lock.
+func (m *MemoryGraph) putEntity(entity *rag.Entity) {
+	if old, exists := m.entities[entity.ID]; exists {
+		m.unindexEntity(old)
+	}
+	m.entities[entity.ID] = *entity
+	m.entityIndex[entity.Type] = append(m.entityIndex[entity.Type], entity.ID)
+}
+
+// unindexEntity removes entity from the type index. The caller holds the lock.
+func (m *MemoryGraph) unindexEntity(entity rag.Entity) {
+	ids := slices.DeleteFunc(m.entityIndex[entity.Type], func(id string) bool { return id == entity.ID })
+	if len(ids) == 0 {
+		delete(m.entityIndex, entity.Type)
+	} else {
+		m.entityIndex[entity.Type] = ids
+	}
+}
+
+// putRelationship stores rel and keeps the adjacency lists in sync. The caller holds the lock.
+func (m *MemoryGraph) putRelationship(rel *rag.Relationship) {
+	m.removeRelationship(rel.ID)
+	m.relationships[rel.ID] = *rel
+	m.adjacency[rel.Source] = append(m.adjacency[rel.Source], rel.ID)
+	if rel.Target != rel.Source {
+		m.adjacency[rel.Target] = append(m.adjacency[rel.Target], rel.ID)
+	}
+}
+
+// removeRelationship deletes a relationship and its adjacency entries. The caller holds the lock.
+func (m *MemoryGraph) removeRelationship(id string) {
+	rel, exists := m.relationships[id]
+	if !exists {
+		return
+	}
+	delete(m.relationships, id)
+	for _, endpoint := range []string{rel.Source, rel.Target} {
+		ids := slices.DeleteFunc(m.adjacency[endpoint], func(relID string) bool { return relID == id })
+		if len(ids) == 0 {
+			delete(m.adjacency, endpoint)
+		} else {
+			m.adjacency[endpoint] = ids
+		}
+	}
+}
+
+// traverse walks the graph breadth-first from start, in both directions, up to maxDepth hops.
+// It returns the reached entity IDs (excluding start) nearest first, and the traversed
+// relationship IDs. Only relationships of relTypes are followed when it is not empty.
+// The caller holds the lock.
+func (m *MemoryGraph) traverse(start string, maxDepth int, relTypes map[string]bool) ([]string, []string) {
+	visited := map[string]bool{start: true}
+	var reached, traversed []string
+	frontier := []string{start}
+	for depth := 0; depth < maxDepth && len(frontier) > 0; depth++ {
+		var next []string
+		for _, id := range frontier {
+			for _, relID := range m.adjacency[id] {
+				rel := m.relationships[relID]
+				if len(relTypes) > 0 && !relTypes[rel.Type] {
+					continue
+				}
+				neighbor := rel.Target
+				if neighbor == id {
+					neighbor = rel.Source
+				}
+				if visited[neighbor] {
+					continue
+				}
+				visited[neighbor] = true
+				traversed = append(traversed, relID)
+				reached = append(reached, neighbor)
+				next = append(next, neighbor)
+			}
+		}
+		frontier = next
+	}
+	return reached, traversed
+}
+
+// matchesFilters reports whether every filter equals the entity's property of the same name.
+// The "name" and "type" filters match the entity's Name and Type.
+func matchesFilters(entity rag.Entity, filters map[string]any) bool {
+	for key, want := range filters {
+		var got any
+		switch key {
+		case "name":
+			got = entity.Name
+		case "type":
+			got = entity.Type
+		default:
+			got = entity.Properties[key]
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			return false
+		}
+	}
+	return true
+}
+
+// newBatchWriteError returns a *rag.BatchWriteError for the failed items, or nil if there are none
+func newBatchWriteError(failed map[int]error) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	return &rag.BatchWriteError{Errors: failed}
+}
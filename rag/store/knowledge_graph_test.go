@@ -6,6 +6,7 @@ import (
 
 	"github.com/smallnest/langgraphgo/rag"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestInMemoryKnowledgeGraph(t *testing.T) {
@@ -35,6 +36,34 @@ func TestInMemoryKnowledgeGraph(t *testing.T) {
 		assert.Equal(t, "knows", rel.Type)
 	})
 
+	t.Run("Batch Get", func(t *testing.T) {
+		entities, err := kg.GetEntities(ctx, []string{"missing", "e1"})
+		assert.NoError(t, err)
+		assert.Len(t, entities, 1)
+		assert.Equal(t, "e1", entities[0].ID)
+
+		rels, err := kg.GetRelationships(ctx, []string{"r1", "missing"})
+		assert.NoError(t, err)
+		assert.Len(t, rels, 1)
+		assert.Equal(t, "r1", rels[0].ID)
+	})
+
+	t.Run("Batch Add", func(t *testing.T) {
+		err := kg.AddEntities(ctx, []*rag.Entity{{ID: "b1", Name: "batch1"}, nil, {ID: "b2", Name: "batch2"}})
+		var batchErr *rag.BatchWriteError
+		require.ErrorAs(t, err, &batchErr)
+		assert.Equal(t, []int{1}, batchErr.Indices())
+
+		entities, err := kg.GetEntities(ctx, []string{"b1", "b2"})
+		assert.NoError(t, err)
+		assert.Len(t, entities, 2)
+
+		err = kg.AddRelationships(ctx, []*rag.Relationship{{ID: "br1", Source: "b1", Target: "b2", Type: "knows"}})
+		assert.NoError(t, err)
+		_, err = kg.GetRelationship(ctx, "br1")
+		assert.NoError(t, err)
+	})
+
 	t.Run("Related Entities", func(t *testing.T) {
 		kg.AddEntity(ctx, &rag.Entity{ID: "e2", Name: "entity2"})
 		related, err := kg.GetRelatedEntities(ctx, "e1", 1)
@@ -46,7 +75,7 @@ func TestInMemoryKnowledgeGraph(t *testing.T) {
 		err := kg.DeleteEntity(ctx, "e1")
 		assert.NoError(t, err)
 		_, err = kg.GetEntity(ctx, "e1")
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, rag.ErrNotFound)
 	})
 
 	t.Run("Query", func(t *testing.T) {
@@ -117,3 +146,69 @@ func TestInMemoryKnowledgeGraph(t *testing.T) {
 		assert.NoError(t, kg.Close())
 	})
 }
+
+func TestNewInMemoryGraph_Traversal(t *testing.T) {
+	ctx := context.Background()
+	kg := NewInMemoryGraph()
+	var _ rag.KnowledgeGraph = kg
+
+	require.NoError(t, kg.AddEntities(ctx, []*rag.Entity{
+		{ID: "alice", Name: "Alice", Type: "PERSON", Properties: map[string]any{"team": "search"}},
+		{ID: "bob", Name: "Bob", Type: "PERSON", Properties: map[string]any{"team": "infra"}},
+		{ID: "acme", Name: "Acme", Type: "ORGANIZATION"},
+		{ID: "paris", Name: "Paris", Type: "CITY"},
+	}))
+	require.NoError(t, kg.AddRelationships(ctx, []*rag.Relationship{
+		{ID: "alice_acme", Source: "alice", Target: "acme", Type: "WORKS_AT"},
+		{ID: "bob_acme", Source: "bob", Target: "acme", Type: "WORKS_AT"},
+		{ID: "acme_paris", Source: "acme", Target: "paris", Type: "LOCATED_IN"},
+	}))
+
+	t.Run("Related entities by depth", func(t *testing.T) {
+		related, err := kg.GetRelatedEntities(ctx, "alice", 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"acme"}, entityIDs(related))
+
+		related, err = kg.GetRelatedEntities(ctx, "alice", 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"acme", "bob", "paris"}, entityIDs(related))
+	})
+
+	t.Run("Traversal query", func(t *testing.T) {
+		res, err := kg.Query(ctx, &rag.GraphQuery{StartEntity: "alice", MaxDepth: 2, EntityType: "PERSON"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alice", "bob"}, entityIDs(res.Entities))
+		assert.Len(t, res.Relationships, 2, "the WORKS_AT relationships touch the returned people")
+
+		res, err = kg.Query(ctx, &rag.GraphQuery{StartEntity: "alice", MaxDepth: 3, Relationships: []string{"WORKS_AT"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alice", "acme", "bob"}, entityIDs(res.Entities), "only WORKS_AT edges are followed")
+	})
+
+	t.Run("Filters and limit", func(t *testing.T) {
+		res, err := kg.Query(ctx, &rag.GraphQuery{EntityTypes: []string{"PERSON"}, Filters: map[string]any{"team": "infra"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bob"}, entityIDs(res.Entities))
+
+		res, err = kg.Query(ctx, &rag.GraphQuery{Limit: 2})
+		require.NoError(t, err)
+		assert.Len(t, res.Entities, 2)
+	})
+
+	t.Run("Deleting an entity removes its relationships", func(t *testing.T) {
+		require.NoError(t, kg.DeleteEntity(ctx, "acme"))
+		_, err := kg.GetRelationship(ctx, "alice_acme")
+		assert.Error(t, err)
+		related, err := kg.GetRelatedEntities(ctx, "alice", 3)
+		require.NoError(t, err)
+		assert.Empty(t, related)
+	})
+}
+
+func entityIDs(entities []*rag.Entity) []string {
+	ids := make([]string, len(entities))
+	for i, e := range entities {
+		ids[i] = e.ID
+	}
+	return ids
+}
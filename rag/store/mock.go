@@ -5,6 +5,7 @@ import (
 	"math"
 	"strings"
 
+	"github.com/smallnest/langgraphgo/internal/vector"
 	"github.com/smallnest/langgraphgo/rag"
 )
 
@@ -51,18 +52,7 @@ func (e *MockEmbedder) generateEmbedding(text string) []float32 {
 		embedding[i] = float32(math.Sin(sum / 1000.0))
 	}
 
-	// Normalize
-	var norm float32
-	for _, v := range embedding {
-		norm += v * v
-	}
-	norm = float32(math.Sqrt(float64(norm)))
-
-	if norm > 0 {
-		for i := range embedding {
-			embedding[i] /= norm
-		}
-	}
+	vector.Normalize(embedding)
 
 	return embedding
 }
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/smallnest/langgraphgo/rag"
+)
+
+// ErrUnsupportedVectorStore is returned by Open for DSN schemes without an implementation
+var ErrUnsupportedVectorStore = errors.New("unsupported vector store")
+
+// Open creates a vector store from a DSN, so backends can be switched without code changes
+// (e.g. by reading the DSN from an environment variable).
+//
+// Supported formats:
+//
+//	memory://[?dimension=N]                          in-memory store
+//	chromem://[/path/to/dir][?collection=name]       chromem-go, in-memory when no path is given
+//	chroma+http://host:port/collection               Chroma v2 HTTP API (also chroma+https)
+//	    [?tenant=t&database=d&token=secret&auth_header=X-Chroma-Token]
+//
+// Schemes without an implementation in this package (e.g. qdrant://) return ErrUnsupportedVectorStore.
+func Open(dsn string, embedder rag.Embedder) (rag.VectorStore, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vector store DSN: %w", err)
+	}
+	query := u.Query()
+
+	switch u.Scheme {
+	case "memory":
+		dimension := 0
+		if d := query.Get("dimension"); d != "" {
+			if dimension, err = strconv.Atoi(d); err != nil {
+				return nil, fmt.Errorf("invalid dimension %q: %w", d, err)
+			}
+		}
+		return NewInMemoryVectorStoreWithDimension(embedder, dimension), nil
+
+	case "chromem":
+		return NewChromemVectorStore(ChromemConfig{
+			PersistenceDir: u.Host + u.Path,
+			CollectionName: query.Get("collection"),
+			Embedder:       embedder,
+		})
+
+	case "chroma+http", "chroma+https":
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid vector store DSN: missing host")
+		}
+		return NewChromaV2VectorStore(ChromaV2Config{
+			BaseURL:    strings.TrimPrefix(u.Scheme, "chroma+") + "://" + u.Host,
+			Collection: strings.Trim(u.Path, "/"),
+			Tenant:     query.Get("tenant"),
+			Database:   query.Get("database"),
+			AuthToken:  query.Get("token"),
+			AuthHeader: query.Get("auth_header"),
+			Embedder:   embedder,
+		})
+
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedVectorStore, u.Scheme)
+	}
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen(t *testing.T) {
+	embedder := NewMockEmbedder(3)
+
+	t.Run("Memory", func(t *testing.T) {
+		s, err := Open("memory://?dimension=3", embedder)
+		require.NoError(t, err)
+		require.IsType(t, &InMemoryVectorStore{}, s)
+		assert.Equal(t, 3, s.(*InMemoryVectorStore).Dimension())
+	})
+
+	t.Run("Chromem", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "db")
+		s, err := Open("chromem://"+dir+"?collection=docs", embedder)
+		require.NoError(t, err)
+		require.IsType(t, &ChromemVectorStore{}, s)
+		assert.Equal(t, "docs", s.(*ChromemVectorStore).collectionName)
+		assert.DirExists(t, dir)
+	})
+
+	t.Run("Chroma", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v2/tenants/acme/databases/default_database/collections", r.URL.Path)
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte(`[{"id":"c1","name":"docs"}]`))
+		}))
+		defer server.Close()
+
+		s, err := Open("chroma+"+server.URL+"/docs?tenant=acme&token=secret", embedder)
+		require.NoError(t, err)
+		require.IsType(t, &ChromaV2VectorStore{}, s)
+		assert.Equal(t, "c1", s.(*ChromaV2VectorStore).collectionID)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		_, err := Open("qdrant://localhost:6333/docs", embedder)
+		assert.ErrorIs(t, err, ErrUnsupportedVectorStore)
+	})
+}
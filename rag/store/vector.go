@@ -3,9 +3,13 @@ package store
 import (
 	"context"
 	"fmt"
+	"maps"
 	"math"
+	"slices"
+	"sort"
 	"time"
 
+	"github.com/smallnest/langgraphgo/internal/vector"
 	"github.com/smallnest/langgraphgo/rag"
 )
 
@@ -14,27 +18,73 @@ type InMemoryVectorStore struct {
 	documents  []rag.Document
 	embeddings [][]float32
 	embedder   rag.Embedder
+	dimension  int
+	// fixedDimension is set when the dimension was given at construction
+	fixedDimension bool
 }
 
-// NewInMemoryVectorStore creates a new InMemoryVectorStore
+// NewInMemoryVectorStore creates a new InMemoryVectorStore.
+// The store's dimension is set by the first embedding added; embeddings of any
+// other dimension are rejected with a *rag.DimensionMismatchError.
 func NewInMemoryVectorStore(embedder rag.Embedder) *InMemoryVectorStore {
+	return NewInMemoryVectorStoreWithDimension(embedder, 0)
+}
+
+// NewInMemoryVectorStoreWithDimension creates a new InMemoryVectorStore that only accepts
+// embeddings of the given dimension (0 means the first added embedding decides)
+func NewInMemoryVectorStoreWithDimension(embedder rag.Embedder, dimension int) *InMemoryVectorStore {
 	return &InMemoryVectorStore{
-		documents:  make([]rag.Document, 0),
-		embeddings: make([][]float32, 0),
-		embedder:   embedder,
+		documents:      make([]rag.Document, 0),
+		embeddings:     make([][]float32, 0),
+		embedder:       embedder,
+		dimension:      dimension,
+		fixedDimension: dimension > 0,
 	}
 }
 
+// Dimension returns the embedding dimension of the store (0 if not yet known)
+func (s *InMemoryVectorStore) Dimension() int {
+	return s.dimension
+}
+
+// checkDimension validates an embedding against the store's dimension without changing it
+// and returns the dimension the store has once the embedding is stored
+func (s *InMemoryVectorStore) checkDimension(embedding []float32, documentID string) (int, error) {
+	return batchDimension(s.dimension, [][]float32{embedding}, []string{documentID})
+}
+
+// addAll stores documents with their embeddings. The whole batch is validated first, so a
+// rejected batch leaves the store unchanged. Documents without an ID get rag.DocumentID.
+func (s *InMemoryVectorStore) addAll(documents []rag.Document, embeddings [][]float32) error {
+	documents = slices.Clone(documents)
+	ids := make([]string, len(documents))
+	for i := range documents {
+		if documents[i].ID == "" {
+			documents[i].ID = rag.DocumentID(documents[i])
+		}
+		ids[i] = documents[i].ID
+	}
+	dimension, err := batchDimension(s.dimension, embeddings, ids)
+	if err != nil {
+		return err
+	}
+
+	s.dimension = dimension
+	s.documents = append(s.documents, documents...)
+	s.embeddings = append(s.embeddings, embeddings...)
+	return nil
+}
+
 // AddWithEmbedding adds a document to the in-memory vector store with an explicit embedding
 func (s *InMemoryVectorStore) AddWithEmbedding(ctx context.Context, doc rag.Document, embedding []float32) error {
-	s.documents = append(s.documents, doc)
-	s.embeddings = append(s.embeddings, embedding)
-	return nil
+	return s.addAll([]rag.Document{doc}, [][]float32{embedding})
 }
 
-// Add adds multiple documents to the in-memory vector store
+// Add adds multiple documents to the in-memory vector store. Nothing is added if a
+// document cannot be embedded or has an embedding of the wrong dimension.
 func (s *InMemoryVectorStore) Add(ctx context.Context, documents []rag.Document) error {
-	for _, doc := range documents {
+	embeddings := make([][]float32, len(documents))
+	for i, doc := range documents {
 		embedding := doc.Embedding
 		if len(embedding) == 0 {
 			if s.embedder == nil {
@@ -46,27 +96,27 @@ func (s *InMemoryVectorStore) Add(ctx context.Context, documents []rag.Document)
 				return fmt.Errorf("failed to embed document: %w", err)
 			}
 		}
-		s.documents = append(s.documents, doc)
-		s.embeddings = append(s.embeddings, embedding)
+		embeddings[i] = embedding
 	}
-	return nil
+	return s.addAll(documents, embeddings)
 }
 
-// AddBatch adds multiple documents with explicit embeddings
+// AddBatch adds multiple documents with explicit embeddings. Nothing is added if an
+// embedding has the wrong dimension.
 func (s *InMemoryVectorStore) AddBatch(ctx context.Context, documents []rag.Document, embeddings [][]float32) error {
 	if len(documents) != len(embeddings) {
 		return fmt.Errorf("documents and embeddings must have same length")
 	}
-
-	s.documents = append(s.documents, documents...)
-	s.embeddings = append(s.embeddings, embeddings...)
-	return nil
+	return s.addAll(documents, embeddings)
 }
 
 // Search performs similarity search
 func (s *InMemoryVectorStore) Search(ctx context.Context, queryEmbedding []float32, k int) ([]rag.DocumentSearchResult, error) {
 	if k <= 0 {
-		return nil, fmt.Errorf("k must be positive")
+		return nil, fmt.Errorf("%w: k must be positive", rag.ErrInvalidQuery)
+	}
+	if err := rag.CheckDimension(s.dimension, queryEmbedding, ""); err != nil {
+		return nil, err
 	}
 
 	if len(s.documents) == 0 {
@@ -81,7 +131,7 @@ func (s *InMemoryVectorStore) Search(ctx context.Context, queryEmbedding []float
 
 	scores := make([]docScore, len(s.documents))
 	for i, docEmb := range s.embeddings {
-		similarity := cosineSimilarity32(queryEmbedding, docEmb)
+		similarity := vector.Cosine(queryEmbedding, docEmb)
 		scores[i] = docScore{index: i, score: similarity}
 	}
 
@@ -109,10 +159,70 @@ func (s *InMemoryVectorStore) Search(ctx context.Context, queryEmbedding []float
 	return results, nil
 }
 
+// SearchFields performs similarity search over the named embeddings of the documents,
+// scoring each document by its best-matching field. Fields whose dimension differs
+// from the query are skipped.
+func (s *InMemoryVectorStore) SearchFields(ctx context.Context, queryEmbedding []float32, k int, fields ...string) ([]rag.DocumentSearchResult, error) {
+	if k <= 0 {
+		return nil, fmt.Errorf("%w: k must be positive", rag.ErrInvalidQuery)
+	}
+
+	allFields := len(fields) == 0
+	results := make([]rag.DocumentSearchResult, 0, len(s.documents))
+	for i, doc := range s.documents {
+		bestField, bestScore := "", math.Inf(-1)
+		consider := func(field string, embedding []float32) {
+			if len(embedding) != len(queryEmbedding) {
+				return
+			}
+			if score := vector.Cosine(queryEmbedding, embedding); score > bestScore {
+				bestField, bestScore = field, score
+			}
+		}
+
+		if allFields || slices.Contains(fields, rag.DefaultVectorField) {
+			consider(rag.DefaultVectorField, s.embeddings[i])
+		}
+		for _, field := range slices.Sorted(maps.Keys(doc.Embeddings)) {
+			if allFields || slices.Contains(fields, field) {
+				consider(field, doc.Embeddings[field])
+			}
+		}
+
+		if bestField != "" {
+			results = append(results, rag.DocumentSearchResult{
+				Document: doc,
+				Score:    bestScore,
+				Metadata: map[string]any{rag.VectorFieldMetadataKey: bestField},
+			})
+		}
+	}
+
+	sort.SliceStable(results, func(a, b int) bool { return results[a].Score > results[b].Score })
+	if k < len(results) {
+		results = results[:k]
+	}
+	return results, nil
+}
+
 // SearchWithFilter performs similarity search with filters
 func (s *InMemoryVectorStore) SearchWithFilter(ctx context.Context, queryEmbedding []float32, k int, filter map[string]any) ([]rag.DocumentSearchResult, error) {
+	return s.searchFiltered(queryEmbedding, k, filter, false)
+}
+
+// SearchWithEmbeddings performs similarity search with filters and returns the embedding
+// of each result
+func (s *InMemoryVectorStore) SearchWithEmbeddings(ctx context.Context, queryEmbedding []float32, k int, filter map[string]any) ([]rag.DocumentSearchResult, error) {
+	return s.searchFiltered(queryEmbedding, k, filter, true)
+}
+
+// searchFiltered ranks the documents matching filter by similarity to queryEmbedding
+func (s *InMemoryVectorStore) searchFiltered(queryEmbedding []float32, k int, filter map[string]any, includeEmbeddings bool) ([]rag.DocumentSearchResult, error) {
 	if k <= 0 {
-		return nil, fmt.Errorf("k must be positive")
+		return nil, fmt.Errorf("%w: k must be positive", rag.ErrInvalidQuery)
+	}
+	if err := rag.CheckDimension(s.dimension, queryEmbedding, ""); err != nil {
+		return nil, err
 	}
 
 	// Filter documents first
@@ -138,7 +248,7 @@ func (s *InMemoryVectorStore) SearchWithFilter(ctx context.Context, queryEmbeddi
 
 	scores := make([]docScore, len(filteredDocs))
 	for i, docEmb := range filteredEmbeddings {
-		similarity := cosineSimilarity32(queryEmbedding, docEmb)
+		similarity := vector.Cosine(queryEmbedding, docEmb)
 		scores[i] = docScore{index: i, score: similarity}
 	}
 
@@ -161,6 +271,9 @@ func (s *InMemoryVectorStore) SearchWithFilter(ctx context.Context, queryEmbeddi
 			Document: filteredDocs[scores[i].index],
 			Score:    float64(scores[i].score),
 		}
+		if includeEmbeddings {
+			results[i].Embedding = filteredEmbeddings[scores[i].index]
+		}
 	}
 
 	return results, nil
@@ -188,16 +301,32 @@ func (s *InMemoryVectorStore) Delete(ctx context.Context, ids []string) error {
 	return nil
 }
 
+// Clear removes all documents. The dimension fixed at construction is kept; a dimension
+// learned from the first embedding is reset.
+func (s *InMemoryVectorStore) Clear(ctx context.Context) error {
+	s.documents = make([]rag.Document, 0)
+	s.embeddings = make([][]float32, 0)
+	if !s.fixedDimension {
+		s.dimension = 0
+	}
+	return nil
+}
+
 // UpdateWithEmbedding updates a document and its embedding
 func (s *InMemoryVectorStore) UpdateWithEmbedding(ctx context.Context, doc rag.Document, embedding []float32) error {
 	for i, existingDoc := range s.documents {
 		if existingDoc.ID == doc.ID {
+			dimension, err := s.checkDimension(embedding, doc.ID)
+			if err != nil {
+				return err
+			}
+			s.dimension = dimension
 			s.documents[i] = doc
 			s.embeddings[i] = embedding
 			return nil
 		}
 	}
-	return fmt.Errorf("document not found: %s", doc.ID)
+	return fmt.Errorf("%w: %s", rag.ErrDocumentNotFound, doc.ID)
 }
 
 // Update updates documents in the vector store
@@ -214,10 +343,15 @@ func (s *InMemoryVectorStore) Update(ctx context.Context, documents []rag.Docume
 				return fmt.Errorf("failed to embed document %s: %w", doc.ID, err)
 			}
 		}
+		dimension, err := s.checkDimension(embedding, doc.ID)
+		if err != nil {
+			return err
+		}
 
 		found := false
 		for i, existingDoc := range s.documents {
 			if existingDoc.ID == doc.ID {
+				s.dimension = dimension
 				s.documents[i] = doc
 				s.embeddings[i] = embedding
 				found = true
@@ -225,12 +359,32 @@ func (s *InMemoryVectorStore) Update(ctx context.Context, documents []rag.Docume
 			}
 		}
 		if !found {
-			return fmt.Errorf("document not found: %s", doc.ID)
+			return fmt.Errorf("%w: %s", rag.ErrDocumentNotFound, doc.ID)
 		}
 	}
 	return nil
 }
 
+// Sync makes the store contain exactly docs, re-embedding only new and changed documents
+func (s *InMemoryVectorStore) Sync(ctx context.Context, docs []rag.Document) (*rag.SyncResult, error) {
+	current := make(map[string]string, len(s.documents))
+	for _, doc := range s.documents {
+		current[doc.ID] = rag.StoredContentHash(doc)
+	}
+
+	plan := rag.PlanSync(current, docs)
+	if err := s.Delete(ctx, plan.Delete); err != nil {
+		return nil, err
+	}
+	if err := s.Update(ctx, plan.Update); err != nil {
+		return nil, err
+	}
+	if err := s.Add(ctx, plan.Add); err != nil {
+		return nil, err
+	}
+	return &plan.Result, nil
+}
+
 // GetStats returns statistics about the vector store
 func (s *InMemoryVectorStore) GetStats(ctx context.Context) (*rag.VectorStoreStats, error) {
 	stats := &rag.VectorStoreStats{
@@ -256,34 +410,5 @@ func (s *InMemoryVectorStore) Close() error {
 
 // matchesFilter checks if a document matches the given filter
 func (s *InMemoryVectorStore) matchesFilter(doc rag.Document, filter map[string]any) bool {
-	for key, value := range filter {
-		docValue, exists := doc.Metadata[key]
-		if !exists || docValue != value {
-			return false
-		}
-	}
-	return true
-}
-
-// cosineSimilarity32 calculates cosine similarity between two float32 vectors
-func cosineSimilarity32(a, b []float32) float64 {
-	if len(a) != len(b) {
-		return 0
-	}
-
-	var dotProduct float64
-	var normA float64
-	var normB float64
-
-	for i := range a {
-		dotProduct += float64(a[i] * b[i])
-		normA += float64(a[i] * a[i])
-		normB += float64(b[i] * b[i])
-	}
-
-	if normA == 0 || normB == 0 {
-		return 0
-	}
-
-	return dotProduct / (math.Sqrt(normA) * math.Sqrt(normB))
+	return matchesMetadata(doc, filter)
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/smallnest/langgraphgo/rag"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockEmbedder struct {
@@ -132,14 +133,225 @@ func TestInMemoryVectorStore(t *testing.T) {
 	})
 }
 
-func TestCosineSimilarity32(t *testing.T) {
-	v1 := []float32{1, 0}
-	v2 := []float32{1, 0}
-	assert.InDelta(t, 1.0, cosineSimilarity32(v1, v2), 1e-6)
+func TestInMemoryVectorStore_DimensionValidation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("First embedding sets dimension", func(t *testing.T) {
+		s := NewInMemoryVectorStore(&mockEmbedder{dim: 3})
+		assert.NoError(t, s.Add(ctx, []rag.Document{{ID: "a", Content: "a"}}))
+		assert.Equal(t, 3, s.Dimension())
+
+		err := s.AddBatch(ctx, []rag.Document{{ID: "b"}}, [][]float32{{1, 2, 3, 4}})
+		var dimErr *rag.DimensionMismatchError
+		assert.ErrorAs(t, err, &dimErr)
+		assert.Equal(t, 3, dimErr.Expected)
+		assert.Equal(t, 4, dimErr.Actual)
+		assert.Equal(t, "b", dimErr.DocumentID)
+
+		_, err = s.Search(ctx, []float32{1, 2}, 1)
+		assert.ErrorAs(t, err, &dimErr)
+	})
+
+	t.Run("Declared dimension", func(t *testing.T) {
+		s := NewInMemoryVectorStoreWithDimension(&mockEmbedder{dim: 4096}, 128)
+		err := s.Add(ctx, []rag.Document{{ID: "qwen", Content: "text"}})
+		var dimErr *rag.DimensionMismatchError
+		assert.ErrorAs(t, err, &dimErr)
+		assert.Contains(t, err.Error(), "store expects 128, document qwen has 4096")
+	})
+
+	t.Run("Rejected batch adds nothing", func(t *testing.T) {
+		s := NewInMemoryVectorStore(nil)
+		err := s.Add(ctx, []rag.Document{
+			{ID: "a", Embedding: []float32{1, 2}},
+			{ID: "b", Embedding: []float32{1, 2, 3}},
+		})
+		var dimErr *rag.DimensionMismatchError
+		assert.ErrorAs(t, err, &dimErr)
+		assert.Equal(t, "b", dimErr.DocumentID)
+
+		err = s.AddBatch(ctx, []rag.Document{{ID: "c"}, {ID: "d"}}, [][]float32{{1, 2, 3}, {1}})
+		assert.ErrorAs(t, err, &dimErr)
+
+		assert.Zero(t, s.Dimension())
+		assert.Empty(t, s.documents)
+
+		assert.NoError(t, s.AddWithEmbedding(ctx, rag.Document{ID: "e"}, []float32{1, 2, 3, 4}))
+		assert.Equal(t, 4, s.Dimension())
+	})
+
+	t.Run("Same ID on every add path", func(t *testing.T) {
+		doc := rag.Document{Content: "text", Metadata: map[string]any{"source": "a.txt"}}
+		embedding := []float32{1, 2, 3}
+		want := rag.DocumentID(doc)
+
+		s := NewInMemoryVectorStore(nil)
+		assert.NoError(t, s.Add(ctx, []rag.Document{{Content: doc.Content, Metadata: doc.Metadata, Embedding: embedding}}))
+		assert.NoError(t, s.AddBatch(ctx, []rag.Document{doc}, [][]float32{embedding}))
+		assert.NoError(t, s.AddWithEmbedding(ctx, doc, embedding))
+
+		require.Len(t, s.documents, 3)
+		for _, stored := range s.documents {
+			assert.Equal(t, want, stored.ID)
+		}
+		assert.Empty(t, doc.ID)
+	})
+
+	t.Run("Chromem dimension from first embedding", func(t *testing.T) {
+		// The embedder's reported dimension is not trusted
+		s, err := NewChromemVectorStoreSimple("", misreportingEmbedder{Embedder: &mockEmbedder{dim: 4}, dim: 2560})
+		require.NoError(t, err)
+
+		err = s.Add(ctx, []rag.Document{{ID: "a", Content: "a"}})
+		require.NoError(t, err)
+
+		err = s.Add(ctx, []rag.Document{{ID: "b", Content: "b", Embedding: []float32{1, 2, 3}}})
+		var dimErr *rag.DimensionMismatchError
+		require.ErrorAs(t, err, &dimErr)
+		assert.Equal(t, 4, dimErr.Expected)
+
+		_, err = s.Search(ctx, []float32{1, 2}, 1)
+		assert.ErrorAs(t, err, &dimErr)
+	})
+}
+
+// misreportingEmbedder reports a dimension that differs from its embeddings
+type misreportingEmbedder struct {
+	rag.Embedder
+	dim int
+}
+
+func (e misreportingEmbedder) GetDimension() int {
+	return e.dim
+}
+
+type countingEmbedder struct {
+	mockEmbedder
+	calls int
+}
+
+func (c *countingEmbedder) EmbedDocument(ctx context.Context, text string) ([]float32, error) {
+	c.calls++
+	return c.mockEmbedder.EmbedDocument(ctx, text)
+}
+
+func TestInMemoryVectorStore_Sync(t *testing.T) {
+	ctx := context.Background()
+	embedder := &countingEmbedder{mockEmbedder: mockEmbedder{dim: 3}}
+	s := NewInMemoryVectorStore(embedder)
+
+	result, err := s.Sync(ctx, []rag.Document{
+		{ID: "a", Content: "alpha"},
+		{ID: "b", Content: "beta"},
+		{ID: "c", Content: "gamma"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, result.Added)
+	assert.Equal(t, 3, embedder.calls)
+
+	// Only the changed document is re-embedded, the removed one is deleted
+	result, err = s.Sync(ctx, []rag.Document{
+		{ID: "a", Content: "alpha"},
+		{ID: "b", Content: "beta v2"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &rag.SyncResult{Updated: []string{"b"}, Deleted: []string{"c"}, Unchanged: 1}, result)
+	assert.Equal(t, 4, embedder.calls)
+
+	stats, err := s.GetStats(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.TotalDocuments)
+	assert.Equal(t, rag.ContentHash("beta v2"), s.documents[1].Metadata[rag.ContentHashMetadataKey])
+}
+
+func TestInMemoryVectorStore_SyncWithoutIDs(t *testing.T) {
+	ctx := context.Background()
+	embedder := &countingEmbedder{mockEmbedder: mockEmbedder{dim: 3}}
+	s := NewInMemoryVectorStore(embedder)
+	docs := []rag.Document{{Content: "alpha"}, {Content: "beta"}}
+
+	result, err := s.Sync(ctx, docs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{rag.DocumentID(docs[0]), rag.DocumentID(docs[1])}, result.Added)
+
+	// The same ID-less documents are recognized on the next sync
+	result, err = s.Sync(ctx, docs)
+	require.NoError(t, err)
+	assert.Equal(t, &rag.SyncResult{Unchanged: len(docs)}, result)
+	assert.Equal(t, 2, embedder.calls)
+	assert.Empty(t, docs[0].ID, "caller documents must not be modified")
+}
+
+func TestInMemoryVectorStore_SearchFields(t *testing.T) {
+	ctx := context.Background()
+	s := NewInMemoryVectorStore(nil)
+
+	err := s.Add(ctx, []rag.Document{
+		{ID: "title-match", Embedding: []float32{0, 1}, Embeddings: map[string][]float32{"title": {1, 0}}},
+		{ID: "body-match", Embedding: []float32{0.9, 0.1}, Embeddings: map[string][]float32{"title": {0, 1}}},
+	})
+	assert.NoError(t, err)
+	query := []float32{1, 0}
+
+	// The max across fields prefers the exact title match
+	results, err := s.SearchFields(ctx, query, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "title-match", results[0].Document.ID)
+		assert.Equal(t, "title", results[0].Metadata[rag.VectorFieldMetadataKey])
+		assert.Equal(t, "body-match", results[1].Document.ID)
+		assert.Equal(t, rag.DefaultVectorField, results[1].Metadata[rag.VectorFieldMetadataKey])
+	}
+
+	// Restricting to the primary embedding matches the single-vector Search
+	results, err = s.SearchFields(ctx, query, 1, rag.DefaultVectorField)
+	assert.NoError(t, err)
+	single, err := s.Search(ctx, query, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, single[0].Document.ID, results[0].Document.ID)
+	assert.Equal(t, "body-match", results[0].Document.ID)
+}
+
+func TestInMemoryVectorStore_Clear(t *testing.T) {
+	ctx := context.Background()
+
+	s := NewInMemoryVectorStore(&mockEmbedder{dim: 3})
+	require.NoError(t, s.Add(ctx, []rag.Document{{ID: "a", Content: "a"}}))
+	require.NoError(t, s.Clear(ctx))
+
+	stats, err := s.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.TotalDocuments)
+	assert.Equal(t, 0, s.Dimension(), "a learned dimension is reset")
+
+	fixed := NewInMemoryVectorStoreWithDimension(&mockEmbedder{dim: 3}, 3)
+	require.NoError(t, fixed.Clear(ctx))
+	assert.Equal(t, 3, fixed.Dimension(), "a declared dimension is kept")
+}
+
+func TestInMemoryVectorStore_SearchWithEmbeddings(t *testing.T) {
+	ctx := context.Background()
+	s := NewInMemoryVectorStore(nil)
+	require.NoError(t, s.Add(ctx, []rag.Document{
+		{ID: "a", Content: "a", Embedding: []float32{1, 0}, Metadata: map[string]any{"lang": "en"}},
+		{ID: "b", Content: "b", Embedding: []float32{0, 1}, Metadata: map[string]any{"lang": "fr"}},
+	}))
+
+	var es rag.EmbeddingVectorStore = s
+	results, err := es.SearchWithEmbeddings(ctx, []float32{1, 0}, 2, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, []float32{1, 0}, results[0].Embedding)
+	assert.Equal(t, []float32{0, 1}, results[1].Embedding)
 
-	v3 := []float32{0, 1}
-	assert.InDelta(t, 0.0, cosineSimilarity32(v1, v3), 1e-6)
+	results, err = es.SearchWithEmbeddings(ctx, []float32{1, 0}, 2, map[string]any{"lang": "fr"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "b", results[0].Document.ID)
+	assert.Equal(t, []float32{0, 1}, results[0].Embedding)
 
-	assert.Equal(t, 0.0, cosineSimilarity32([]float32{1}, []float32{1, 2}))
-	assert.Equal(t, 0.0, cosineSimilarity32([]float32{0}, []float32{0}))
+	// Plain searches do not return embeddings
+	results, err = rag.SearchVectorStore(ctx, s, []float32{1, 0}, 1, nil, false)
+	require.NoError(t, err)
+	assert.Nil(t, results[0].Embedding)
 }
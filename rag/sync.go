@@ -0,0 +1,101 @@
+package rag
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"slices"
+	"sort"
+)
+
+// ContentHashMetadataKey is the document metadata key holding the content hash used to
+// detect changed documents when syncing a vector store
+const ContentHashMetadataKey = "content_hash"
+
+// ContentHash returns the hex encoded SHA-256 hash of a document's content
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// SyncableVectorStore is implemented by vector stores that can incrementally
+// synchronize their contents with a document set
+type SyncableVectorStore interface {
+	// Sync makes the store contain exactly docs. Only new documents and documents
+	// whose content hash changed are embedded; documents not in docs are deleted.
+	Sync(ctx context.Context, docs []Document) (*SyncResult, error)
+}
+
+// SyncResult reports the changes applied by a Sync
+type SyncResult struct {
+	// Added holds the IDs of new documents
+	Added []string
+	// Updated holds the IDs of documents whose content changed
+	Updated []string
+	// Deleted holds the IDs of documents that are no longer present
+	Deleted []string
+	// Unchanged is the number of documents that were not re-embedded
+	Unchanged int
+}
+
+// SyncPlan is the set of changes needed to bring a store in line with a document set
+type SyncPlan struct {
+	Add    []Document
+	Update []Document
+	Delete []string
+	Result SyncResult
+}
+
+// PlanSync diffs docs against the current contents of a store, given as document ID to
+// content hash. Documents without an ID are given DocumentID, as stores do when adding them.
+// The planned documents carry their hash under ContentHashMetadataKey; docs and their
+// metadata maps are copied, not modified.
+func PlanSync(current map[string]string, docs []Document) *SyncPlan {
+	docs = EnsureIDs(slices.Clone(docs))
+	plan := &SyncPlan{}
+	seen := make(map[string]bool, len(docs))
+
+	for _, doc := range docs {
+		seen[doc.ID] = true
+		hash := ContentHash(doc.Content)
+
+		existing, ok := current[doc.ID]
+		if ok && existing == hash {
+			plan.Result.Unchanged++
+			continue
+		}
+
+		metadata := make(map[string]any, len(doc.Metadata)+1)
+		maps.Copy(metadata, doc.Metadata)
+		metadata[ContentHashMetadataKey] = hash
+		doc.Metadata = metadata
+
+		if ok {
+			plan.Update = append(plan.Update, doc)
+			plan.Result.Updated = append(plan.Result.Updated, doc.ID)
+		} else {
+			plan.Add = append(plan.Add, doc)
+			plan.Result.Added = append(plan.Result.Added, doc.ID)
+		}
+	}
+
+	for id := range current {
+		if !seen[id] {
+			plan.Delete = append(plan.Delete, id)
+		}
+	}
+	sort.Strings(plan.Delete)
+	plan.Result.Deleted = plan.Delete
+
+	return plan
+}
+
+// StoredContentHash returns the content hash recorded in the metadata of a document
+// read back from a store, falling back to hashing its content for documents stored without one
+func StoredContentHash(doc Document) string {
+	if hash, ok := doc.Metadata[ContentHashMetadataKey].(string); ok && hash != "" {
+		return hash
+	}
+	return ContentHash(doc.Content)
+}
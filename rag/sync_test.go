@@ -0,0 +1,37 @@
+package rag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanSync(t *testing.T) {
+	current := map[string]string{
+		"same":    ContentHash("unchanged"),
+		"changed": ContentHash("old"),
+		"gone":    ContentHash("removed"),
+	}
+	metadata := map[string]any{"source": "docs"}
+	docs := []Document{
+		{ID: "same", Content: "unchanged"},
+		{ID: "changed", Content: "new", Metadata: metadata},
+		{ID: "fresh", Content: "added"},
+	}
+
+	plan := PlanSync(current, docs)
+
+	assert.Equal(t, SyncResult{Added: []string{"fresh"}, Updated: []string{"changed"}, Deleted: []string{"gone"}, Unchanged: 1}, plan.Result)
+	assert.Equal(t, []string{"gone"}, plan.Delete)
+	if assert.Len(t, plan.Update, 1) {
+		assert.Equal(t, ContentHash("new"), plan.Update[0].Metadata[ContentHashMetadataKey])
+		assert.Equal(t, "docs", plan.Update[0].Metadata["source"])
+	}
+	assert.Len(t, plan.Add, 1)
+	assert.NotContains(t, metadata, ContentHashMetadataKey, "caller metadata must not be modified")
+}
+
+func TestStoredContentHash(t *testing.T) {
+	assert.Equal(t, ContentHash("text"), StoredContentHash(Document{Content: "text"}))
+	assert.Equal(t, "recorded", StoredContentHash(Document{Content: "text", Metadata: map[string]any{ContentHashMetadataKey: "recorded"}}))
+}
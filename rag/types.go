@@ -15,8 +15,11 @@ type Document struct {
 	Content   string         `json:"content"`
 	Metadata  map[string]any `json:"metadata"`
 	Embedding []float32      `json:"embedding,omitempty"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
+	// Embeddings holds optional named embeddings of other representations of the
+	// document (e.g. "title", "summary") for multi-vector retrieval
+	Embeddings map[string][]float32 `json:"embeddings,omitempty"`
+	CreatedAt  time.Time            `json:"created_at"`
+	UpdatedAt  time.Time            `json:"updated_at"`
 }
 
 // Entity represents a knowledge graph entity
@@ -62,6 +65,9 @@ type DocumentSearchResult struct {
 	Document Document       `json:"document"`
 	Score    float64        `json:"score"`
 	Metadata map[string]any `json:"metadata,omitempty"`
+	// Embedding is the stored embedding of the document, set when it was requested with
+	// RetrievalConfig.IncludeEmbeddings
+	Embedding []float32 `json:"embedding,omitempty"`
 }
 
 // GraphQuery represents a query to the knowledge graph
@@ -96,6 +102,12 @@ type RetrievalConfig struct {
 	SearchType     string         `json:"search_type"`
 	Filter         map[string]any `json:"filter,omitempty"`
 	IncludeScores  bool           `json:"include_scores"`
+	// IncludeEmbeddings sets DocumentSearchResult.Embedding on the results, so later steps
+	// (MMR, clustering, visualization) do not have to re-embed them
+	IncludeEmbeddings bool `json:"include_embeddings"`
+	// MetadataFields, when not empty, keeps only these keys in the metadata of the returned
+	// documents, reducing the payload of large result sets
+	MetadataFields []string `json:"metadata_fields,omitempty"`
 }
 
 // VectorStoreStats contains statistics about a vector store
@@ -128,6 +140,15 @@ type GraphRAGConfig struct {
 	MaxDepth         int                 `json:"max_depth"`
 	EnableReasoning  bool                `json:"enable_reasoning"`
 	ExtractionPrompt string              `json:"extraction_prompt"`
+	// ConfidenceFunc computes a query's confidence from its signals; nil uses DefaultGraphConfidence
+	ConfidenceFunc func(GraphConfidenceSignals) float64 `json:"-"`
+	// QueryTimeout bounds a whole query, including entity extraction and graph traversal.
+	// 0 uses the engine's default; a negative value disables the timeout.
+	QueryTimeout time.Duration `json:"query_timeout"`
+	// VectorStore makes the engine hybrid: documents are also indexed by embedding, and
+	// queries retrieve the most similar chunks in addition to traversing the graph, adding
+	// them to the context. Requires an embedder.
+	VectorStore VectorStore `json:"-"`
 }
 
 // LightRAGConfig represents configuration for LightRAG
@@ -264,6 +285,64 @@ type VectorStore interface {
 	Delete(ctx context.Context, ids []string) error
 	Update(ctx context.Context, documents []Document) error
 	GetStats(ctx context.Context) (*VectorStoreStats, error)
+	// Clear removes all documents, leaving the store empty and usable
+	Clear(ctx context.Context) error
+}
+
+// DroppableVectorStore is implemented by persistent vector stores whose underlying
+// collection can be deleted
+type DroppableVectorStore interface {
+	// Drop deletes the underlying collection. The store must not be used afterwards.
+	Drop(ctx context.Context) error
+}
+
+// WarmableVectorStore is implemented by vector stores that have a first-query penalty, such
+// as persistent stores that open connections or load indexes lazily. Stores that do not
+// implement it are ready as soon as they are created.
+type WarmableVectorStore interface {
+	// Warmup prepares the store by running a trivial query, so the first real query is fast
+	Warmup(ctx context.Context) error
+	// Ready reports whether Warmup has succeeded, e.g. to gate traffic in a readiness probe
+	Ready() bool
+}
+
+// DefaultVectorField names the primary Embedding of a document in multi-vector searches
+const DefaultVectorField = "default"
+
+// VectorFieldMetadataKey is the search result metadata key holding the vector field that matched
+const VectorFieldMetadataKey = "vector_field"
+
+// MultiVectorStore is implemented by vector stores that keep the named Embeddings of documents
+type MultiVectorStore interface {
+	// SearchFields scores each document by its best match among the given vector fields
+	// (DefaultVectorField for the primary embedding); with no fields, all fields are used.
+	// The matching field is reported under VectorFieldMetadataKey.
+	SearchFields(ctx context.Context, query []float32, k int, fields ...string) ([]DocumentSearchResult, error)
+}
+
+// EmbeddingVectorStore is implemented by vector stores that can return the stored embedding
+// of each search result in DocumentSearchResult.Embedding
+type EmbeddingVectorStore interface {
+	// SearchWithEmbeddings is like SearchWithFilter (a nil filter matches all documents)
+	// and also sets the Embedding of each result
+	SearchWithEmbeddings(ctx context.Context, query []float32, k int, filter map[string]any) ([]DocumentSearchResult, error)
+}
+
+// ParentIDMetadataKey is the chunk metadata key holding the ID of the parent document, whose
+// full text is kept in a DocStore instead of the vector store
+const ParentIDMetadataKey = "parent_id"
+
+// DocStore stores full documents by ID, independent of vector search, e.g. the parent
+// documents of chunks in parent-document retrieval
+type DocStore interface {
+	// Put adds the documents, replacing documents with the same ID
+	Put(ctx context.Context, docs []Document) error
+	// Get returns the document with the given ID, or an error wrapping ErrDocumentNotFound
+	Get(ctx context.Context, id string) (Document, error)
+	// GetMany returns the documents with the given IDs in order; IDs that do not exist are omitted
+	GetMany(ctx context.Context, ids []string) ([]Document, error)
+	// Delete removes the documents with the given IDs; IDs that do not exist are ignored
+	Delete(ctx context.Context, ids []string) error
 }
 
 // Retriever interface for document retrieval
@@ -299,6 +378,21 @@ type KnowledgeGraph interface {
 	GetEntity(ctx context.Context, entityID string) (*Entity, error)
 }
 
+// BatchKnowledgeGraph is implemented by knowledge graphs that can fetch several entities or
+// relationships in a single round-trip. IDs that do not exist are omitted from the results.
+type BatchKnowledgeGraph interface {
+	GetEntities(ctx context.Context, entityIDs []string) ([]*Entity, error)
+	GetRelationships(ctx context.Context, relationshipIDs []string) ([]*Relationship, error)
+}
+
+// BatchWriteKnowledgeGraph is implemented by knowledge graphs that can insert several entities
+// or relationships in a single round-trip. Items that could not be written are reported by
+// index in a *BatchWriteError; the rest of the batch is still written.
+type BatchWriteKnowledgeGraph interface {
+	AddEntities(ctx context.Context, entities []*Entity) error
+	AddRelationships(ctx context.Context, relationships []*Relationship) error
+}
+
 // Engine interface for RAG engines
 type Engine interface {
 	Query(ctx context.Context, query string) (*QueryResult, error)
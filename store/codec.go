@@ -0,0 +1,73 @@
+package store
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"reflect"
+)
+
+// StateCodec serializes checkpoint states. Stores that accept a codec (see
+// memory.NewMemoryCheckpointStoreWithCodec and file.NewFileCheckpointStoreWithCodec)
+// encode the state when saving a checkpoint and decode it when loading one.
+type StateCodec interface {
+	// Encode serializes a state
+	Encode(state any) ([]byte, error)
+	// Decode deserializes data into target, a pointer to the state type
+	Decode(data []byte, target any) error
+}
+
+// StateTypeSetter is implemented by stores that decode checkpoint states into the concrete
+// state type of the graph, so loaded checkpoints carry the original struct instead of the
+// generic map[string]any a JSON round trip produces. Checkpointable graphs register their
+// state type with their store when compiled; a store serves a single state type.
+type StateTypeSetter interface {
+	SetStateType(t reflect.Type)
+}
+
+// JSONCodec encodes states as JSON. It is the default codec of the file store.
+type JSONCodec struct{}
+
+// Encode implements StateCodec
+func (JSONCodec) Encode(state any) ([]byte, error) {
+	return json.Marshal(state)
+}
+
+// Decode implements StateCodec
+func (JSONCodec) Decode(data []byte, target any) error {
+	return json.Unmarshal(data, target)
+}
+
+// GobCodec encodes states with encoding/gob, which keeps Go types such as integers,
+// time.Duration or []byte that JSON turns into float64 or strings. Decoding requires
+// the state type, registered with SetStateType or by compiling a checkpointable graph.
+type GobCodec struct{}
+
+// Encode implements StateCodec
+func (GobCodec) Encode(state any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(state); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode implements StateCodec
+func (GobCodec) Decode(data []byte, target any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(target)
+}
+
+// DecodeStateAs decodes data with codec into a value of stateType, or into the codec's
+// generic form (e.g. map[string]any for JSON) when stateType is nil
+func DecodeStateAs(codec StateCodec, data []byte, stateType reflect.Type) (any, error) {
+	if stateType == nil {
+		var state any
+		err := codec.Decode(data, &state)
+		return state, err
+	}
+	target := reflect.New(stateType)
+	if err := codec.Decode(data, target.Interface()); err != nil {
+		return nil, err
+	}
+	return target.Elem().Interface(), nil
+}
@@ -0,0 +1,141 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ValueChange describes a value that changed between two states
+type ValueChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// StateDiff describes the difference between two states.
+// States are compared through their JSON representation: map keys and struct
+// fields (by JSON name) are compared at the top level. States that are not
+// JSON objects are reported as a single change under the empty key.
+type StateDiff struct {
+	Added   map[string]any         `json:"added,omitempty"`
+	Changed map[string]ValueChange `json:"changed,omitempty"`
+	Removed map[string]any         `json:"removed,omitempty"`
+}
+
+// IsEmpty reports whether the diff contains no changes
+func (d StateDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0
+}
+
+// StepDiff is the state diff introduced by a single checkpointed step
+type StepDiff struct {
+	CheckpointID string    `json:"checkpoint_id"`
+	NodeName     string    `json:"node_name"`
+	Version      int       `json:"version"`
+	Diff         StateDiff `json:"diff"`
+}
+
+// DiffStates computes the difference between two states
+func DiffStates(oldState, newState any) (StateDiff, error) {
+	diff := StateDiff{
+		Added:   make(map[string]any),
+		Changed: make(map[string]ValueChange),
+		Removed: make(map[string]any),
+	}
+
+	oldVal, err := toJSONValue(oldState)
+	if err != nil {
+		return diff, fmt.Errorf("failed to convert old state: %w", err)
+	}
+	newVal, err := toJSONValue(newState)
+	if err != nil {
+		return diff, fmt.Errorf("failed to convert new state: %w", err)
+	}
+
+	oldMap, oldIsMap := oldVal.(map[string]any)
+	newMap, newIsMap := newVal.(map[string]any)
+	if oldVal == nil {
+		oldMap, oldIsMap = map[string]any{}, newIsMap
+	}
+	if !oldIsMap || !newIsMap {
+		if !reflect.DeepEqual(oldVal, newVal) {
+			diff.Changed[""] = ValueChange{Old: oldVal, New: newVal}
+		}
+		return diff, nil
+	}
+
+	for k, newV := range newMap {
+		oldV, ok := oldMap[k]
+		switch {
+		case !ok:
+			diff.Added[k] = newV
+		case !reflect.DeepEqual(oldV, newV):
+			diff.Changed[k] = ValueChange{Old: oldV, New: newV}
+		}
+	}
+	for k, oldV := range oldMap {
+		if _, ok := newMap[k]; !ok {
+			diff.Removed[k] = oldV
+		}
+	}
+
+	return diff, nil
+}
+
+// Diff returns the per-step state diffs for all checkpoints of a thread,
+// ordered by version. The first step is diffed against an empty state.
+// If no checkpoints are found by thread_id, threadID is treated as an execution ID.
+func Diff(ctx context.Context, s CheckpointStore, threadID string) ([]StepDiff, error) {
+	checkpoints, err := s.ListByThread(ctx, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints by thread: %w", err)
+	}
+	if len(checkpoints) == 0 {
+		checkpoints, err = s.List(ctx, threadID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+		}
+	}
+
+	sorted := make([]*Checkpoint, len(checkpoints))
+	copy(sorted, checkpoints)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	diffs := make([]StepDiff, 0, len(sorted))
+	var previous any
+	for _, cp := range sorted {
+		d, err := DiffStates(previous, cp.State)
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff checkpoint %s: %w", cp.ID, err)
+		}
+		diffs = append(diffs, StepDiff{
+			CheckpointID: cp.ID,
+			NodeName:     cp.NodeName,
+			Version:      cp.Version,
+			Diff:         d,
+		})
+		previous = cp.State
+	}
+
+	return diffs, nil
+}
+
+// toJSONValue converts a value to its generic JSON representation
+func toJSONValue(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var result any
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
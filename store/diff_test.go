@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffStates(t *testing.T) {
+	t.Run("Map state", func(t *testing.T) {
+		d, err := DiffStates(
+			map[string]any{"a": 1, "b": "x", "c": true},
+			map[string]any{"a": 2, "c": true, "d": []string{"y"}},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"d": []any{"y"}}, d.Added)
+		assert.Equal(t, map[string]ValueChange{"a": {Old: float64(1), New: float64(2)}}, d.Changed)
+		assert.Equal(t, map[string]any{"b": "x"}, d.Removed)
+	})
+
+	t.Run("Struct state", func(t *testing.T) {
+		d, err := DiffStates(TestState{Name: "a", Count: 1}, TestState{Name: "a", Count: 2})
+		require.NoError(t, err)
+		assert.Len(t, d.Changed, 1)
+		assert.Contains(t, d.Changed, "count")
+		assert.Empty(t, d.Added)
+	})
+
+	t.Run("Identical states", func(t *testing.T) {
+		d, err := DiffStates(map[string]any{"a": 1}, map[string]any{"a": 1})
+		require.NoError(t, err)
+		assert.True(t, d.IsEmpty())
+	})
+}
+
+// sliceStore is a minimal CheckpointStore backed by a slice
+type sliceStore struct {
+	CheckpointStore
+	checkpoints []*Checkpoint
+	threadErr   error
+}
+
+func (s *sliceStore) ListByThread(ctx context.Context, threadID string) ([]*Checkpoint, error) {
+	return s.checkpoints, s.threadErr
+}
+
+func (s *sliceStore) List(ctx context.Context, executionID string) ([]*Checkpoint, error) {
+	return s.checkpoints, nil
+}
+
+func TestDiff(t *testing.T) {
+	s := &sliceStore{checkpoints: []*Checkpoint{
+		{ID: "2", NodeName: "b", Version: 2, State: map[string]any{"x": 1, "y": 2}},
+		{ID: "1", NodeName: "a", Version: 1, State: map[string]any{"x": 1}},
+	}}
+
+	steps, err := Diff(context.Background(), s, "thread")
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.Equal(t, "a", steps[0].NodeName)
+	assert.Equal(t, map[string]any{"x": float64(1)}, steps[0].Diff.Added)
+	assert.Equal(t, "b", steps[1].NodeName)
+	assert.Equal(t, map[string]any{"y": float64(2)}, steps[1].Diff.Added)
+}
+
+func TestDiff_ListByThreadError(t *testing.T) {
+	threadErr := errors.New("connection refused")
+	s := &sliceStore{
+		checkpoints: []*Checkpoint{{ID: "1", NodeName: "a", Version: 1, State: map[string]any{"x": 1}}},
+		threadErr:   threadErr,
+	}
+
+	// The error is returned instead of falling back to List
+	_, err := Diff(context.Background(), s, "thread")
+	assert.ErrorIs(t, err, threadErr)
+}
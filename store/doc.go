@@ -43,6 +43,19 @@
 //	    Clear(ctx context.Context, threadID string) error
 //	}
 //
+// ## State Codecs
+//
+// Persistent stores serialize states, which turns structs into map[string]any once they
+// are loaded back. The file store encodes states with a StateCodec (JSONCodec by default,
+// GobCodec keeps Go types JSON cannot represent) and, like the in-memory store created
+// with a codec, implements StateTypeSetter: compiling a checkpointable graph registers
+// its state type, so loaded checkpoints carry the original struct.
+//
+//	store, err := file.NewFileCheckpointStoreWithCodec("./checkpoints", store.GobCodec{})
+//	g := graph.NewCheckpointableStateGraphWithConfig[OrderState](graph.CheckpointConfig{Store: store})
+//	runnable, err := g.CompileCheckpointable()
+//	// cp.State is an OrderState
+//
 // # Available Implementations
 //
 // ## SQLite Store (store/sqlite)
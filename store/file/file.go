@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"sync"
 
@@ -14,17 +15,35 @@ import (
 
 // FileCheckpointStore provides file-based checkpoint storage
 type FileCheckpointStore struct {
-	path  string
-	mutex sync.RWMutex
+	path      string
+	mutex     sync.RWMutex
+	codec     store.StateCodec
+	stateType reflect.Type // type states are decoded into, nil for the codec's generic form
 }
 
+// fileCheckpoint is the content of a checkpoint file. The state is stored inline when the
+// codec produces JSON, and as a base64 string otherwise.
+type fileCheckpoint struct {
+	store.Checkpoint
+	State         json.RawMessage `json:"state"`
+	StateEncoding string          `json:"state_encoding,omitempty"`
+}
+
+const base64StateEncoding = "base64"
+
 // threadIndex represents the in-memory index for thread_id -> checkpoint IDs
 type threadIndex struct {
 	Threads map[string][]string // thread_id -> []checkpoint IDs
 }
 
-// NewFileCheckpointStore creates a new file-based checkpoint store
+// NewFileCheckpointStore creates a new file-based checkpoint store that encodes states as JSON
 func NewFileCheckpointStore(path string) (store.CheckpointStore, error) {
+	return NewFileCheckpointStoreWithCodec(path, store.JSONCodec{})
+}
+
+// NewFileCheckpointStoreWithCodec creates a new file-based checkpoint store that encodes
+// states with codec
+func NewFileCheckpointStoreWithCodec(path string, codec store.StateCodec) (store.CheckpointStore, error) {
 	// Ensure directory exists
 	if err := os.MkdirAll(path, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
@@ -37,10 +56,64 @@ func NewFileCheckpointStore(path string) (store.CheckpointStore, error) {
 	}
 
 	return &FileCheckpointStore{
-		path: path,
+		path:  path,
+		codec: codec,
 	}, nil
 }
 
+// SetStateType implements store.StateTypeSetter
+func (f *FileCheckpointStore) SetStateType(t reflect.Type) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.stateType = t
+}
+
+// encode returns the file content of a checkpoint
+func (f *FileCheckpointStore) encode(checkpoint *store.Checkpoint) ([]byte, error) {
+	state, err := f.codec.Encode(checkpoint.State)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	record := fileCheckpoint{Checkpoint: *checkpoint}
+	if json.Valid(state) {
+		record.State = state
+	} else {
+		if record.State, err = json.Marshal(state); err != nil {
+			return nil, err
+		}
+		record.StateEncoding = base64StateEncoding
+	}
+	return json.Marshal(record)
+}
+
+// decode parses the file content of a checkpoint, decoding its state with the codec
+func (f *FileCheckpointStore) decode(data []byte) (*store.Checkpoint, error) {
+	var record fileCheckpoint
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+
+	checkpoint := record.Checkpoint
+	checkpoint.State = nil
+	state := []byte(record.State)
+	if len(state) == 0 || string(state) == "null" {
+		return &checkpoint, nil
+	}
+	if record.StateEncoding == base64StateEncoding {
+		if err := json.Unmarshal(record.State, &state); err != nil {
+			return nil, err
+		}
+	}
+
+	decoded, err := store.DecodeStateAs(f.codec, state, f.stateType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode state: %w", err)
+	}
+	checkpoint.State = decoded
+	return &checkpoint, nil
+}
+
 // Save implements CheckpointStore interface for file storage
 func (f *FileCheckpointStore) Save(_ context.Context, checkpoint *store.Checkpoint) error {
 	f.mutex.Lock()
@@ -49,7 +122,7 @@ func (f *FileCheckpointStore) Save(_ context.Context, checkpoint *store.Checkpoi
 	// Create filename from ID
 	filename := filepath.Join(f.path, fmt.Sprintf("%s.json", checkpoint.ID))
 
-	data, err := json.Marshal(checkpoint)
+	data, err := f.encode(checkpoint)
 	if err != nil {
 		return fmt.Errorf("failed to marshal checkpoint: %w", err)
 	}
@@ -84,13 +157,12 @@ func (f *FileCheckpointStore) Load(_ context.Context, checkpointID string) (*sto
 		return nil, fmt.Errorf("failed to read checkpoint file: %w", err)
 	}
 
-	var checkpoint store.Checkpoint
-	err = json.Unmarshal(data, &checkpoint)
+	checkpoint, err := f.decode(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal checkpoint: %w", err)
 	}
 
-	return &checkpoint, nil
+	return checkpoint, nil
 }
 
 // List implements CheckpointStore interface for file storage
@@ -117,8 +189,8 @@ func (f *FileCheckpointStore) List(_ context.Context, executionID string) ([]*st
 			continue
 		}
 
-		var checkpoint store.Checkpoint
-		if err := json.Unmarshal(data, &checkpoint); err != nil {
+		checkpoint, err := f.decode(data)
+		if err != nil {
 			// Skip invalid files
 			continue
 		}
@@ -130,7 +202,7 @@ func (f *FileCheckpointStore) List(_ context.Context, executionID string) ([]*st
 		workflowID, _ := checkpoint.Metadata["workflow_id"].(string)
 
 		if execID == executionID || threadID == executionID || sessionID == executionID || workflowID == executionID {
-			checkpoints = append(checkpoints, &checkpoint)
+			checkpoints = append(checkpoints, checkpoint)
 		}
 	}
 
@@ -167,13 +239,13 @@ func (f *FileCheckpointStore) ListByThread(_ context.Context, threadID string) (
 			continue
 		}
 
-		var checkpoint store.Checkpoint
-		if err := json.Unmarshal(data, &checkpoint); err != nil {
+		checkpoint, err := f.decode(data)
+		if err != nil {
 			// Skip invalid files
 			continue
 		}
 
-		checkpoints = append(checkpoints, &checkpoint)
+		checkpoints = append(checkpoints, checkpoint)
 	}
 
 	// Sort by version (ascending order)
@@ -384,14 +456,14 @@ func (f *FileCheckpointStore) listByThreadScan(threadID string) ([]*store.Checkp
 			continue
 		}
 
-		var checkpoint store.Checkpoint
-		if err := json.Unmarshal(data, &checkpoint); err != nil {
+		checkpoint, err := f.decode(data)
+		if err != nil {
 			continue
 		}
 
 		// Filter by thread_id
 		if cpThreadID, ok := checkpoint.Metadata["thread_id"].(string); ok && cpThreadID == threadID {
-			checkpoints = append(checkpoints, &checkpoint)
+			checkpoints = append(checkpoints, checkpoint)
 		}
 	}
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -582,3 +583,75 @@ func TestFileCheckpointStore_Concurrent(t *testing.T) {
 		t.Errorf("Expected %d checkpoint files, got %d", expectedTotal, jsonCount)
 	}
 }
+
+type codecTestState struct {
+	Order   string
+	Price   float64
+	Items   []string
+	Retries int
+	Wait    time.Duration
+}
+
+func TestFileCheckpointStore_StateType(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	state := codecTestState{Order: "A-1", Price: 9.5, Items: []string{"pen"}, Retries: 2, Wait: time.Second}
+
+	for name, codec := range map[string]store.StateCodec{
+		"json": store.JSONCodec{},
+		"gob":  store.GobCodec{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			s, err := NewFileCheckpointStoreWithCodec(t.TempDir(), codec)
+			if err != nil {
+				t.Fatalf("Failed to create store: %v", err)
+			}
+			s.(store.StateTypeSetter).SetStateType(reflect.TypeFor[codecTestState]())
+
+			cp := &store.Checkpoint{ID: "cp1", NodeName: "n", State: state, Metadata: map[string]any{"thread_id": "t1"}}
+			if err := s.Save(ctx, cp); err != nil {
+				t.Fatalf("Failed to save: %v", err)
+			}
+
+			loaded, err := s.Load(ctx, "cp1")
+			if err != nil {
+				t.Fatalf("Failed to load: %v", err)
+			}
+			if !reflect.DeepEqual(loaded.State, state) {
+				t.Errorf("Loaded state = %#v, want %#v", loaded.State, state)
+			}
+
+			latest, err := s.GetLatestByThread(ctx, "t1")
+			if err != nil {
+				t.Fatalf("Failed to get latest: %v", err)
+			}
+			if !reflect.DeepEqual(latest.State, state) {
+				t.Errorf("Latest state = %#v, want %#v", latest.State, state)
+			}
+		})
+	}
+}
+
+func TestFileCheckpointStore_GenericState(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s, err := NewFileCheckpointStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	// Without a state type, JSON states load in their generic form
+	cp := &store.Checkpoint{ID: "cp1", State: codecTestState{Order: "A-1"}}
+	if err := s.Save(ctx, cp); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	loaded, err := s.Load(ctx, "cp1")
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	m, ok := loaded.State.(map[string]any)
+	if !ok || m["Order"] != "A-1" {
+		t.Errorf("Loaded state = %#v, want a map with Order A-1", loaded.State)
+	}
+}
@@ -3,6 +3,7 @@ package memory
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"sort"
 	"sync"
 
@@ -15,6 +16,10 @@ type MemoryCheckpointStore struct {
 	threadIndex    map[string][]string          // thread_id -> []checkpoint IDs
 	executionIndex map[string][]string          // execution_id -> []checkpoint IDs
 	mutex          sync.RWMutex
+
+	codec     store.StateCodec  // encodes states when set, otherwise they are kept as saved
+	states    map[string][]byte // id -> encoded state
+	stateType reflect.Type      // type states are decoded into
 }
 
 // NewMemoryCheckpointStore creates a new in-memory checkpoint store
@@ -26,12 +31,72 @@ func NewMemoryCheckpointStore() store.CheckpointStore {
 	}
 }
 
+// NewMemoryCheckpointStoreWithCodec creates an in-memory checkpoint store that encodes
+// states with codec when saving and decodes them when loading, so later changes to a
+// saved state do not affect its checkpoint and states behave as with a persistent store
+func NewMemoryCheckpointStoreWithCodec(codec store.StateCodec) store.CheckpointStore {
+	return &MemoryCheckpointStore{
+		checkpoints:    make(map[string]*store.Checkpoint),
+		threadIndex:    make(map[string][]string),
+		executionIndex: make(map[string][]string),
+		codec:          codec,
+		states:         make(map[string][]byte),
+	}
+}
+
+// SetStateType implements store.StateTypeSetter
+func (m *MemoryCheckpointStore) SetStateType(t reflect.Type) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.stateType = t
+}
+
+// output returns the checkpoint as returned to callers, with its state decoded
+func (m *MemoryCheckpointStore) output(checkpoint *store.Checkpoint) (*store.Checkpoint, error) {
+	if m.codec == nil {
+		return checkpoint, nil
+	}
+	data, ok := m.states[checkpoint.ID]
+	if !ok {
+		return checkpoint, nil
+	}
+	state, err := store.DecodeStateAs(m.codec, data, m.stateType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode state of checkpoint %s: %w", checkpoint.ID, err)
+	}
+	decoded := *checkpoint
+	decoded.State = state
+	return &decoded, nil
+}
+
+// outputAll returns the checkpoints as returned to callers
+func (m *MemoryCheckpointStore) outputAll(checkpoints []*store.Checkpoint) ([]*store.Checkpoint, error) {
+	for i, cp := range checkpoints {
+		decoded, err := m.output(cp)
+		if err != nil {
+			return nil, err
+		}
+		checkpoints[i] = decoded
+	}
+	return checkpoints, nil
+}
+
 // Save implements CheckpointStore interface
 func (m *MemoryCheckpointStore) Save(_ context.Context, checkpoint *store.Checkpoint) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
 	// Store checkpoint
+	if m.codec != nil {
+		data, err := m.codec.Encode(checkpoint.State)
+		if err != nil {
+			return fmt.Errorf("failed to encode state: %w", err)
+		}
+		stored := *checkpoint
+		stored.State = nil
+		m.states[checkpoint.ID] = data
+		checkpoint = &stored
+	}
 	m.checkpoints[checkpoint.ID] = checkpoint
 
 	// Update execution_id index
@@ -57,7 +122,7 @@ func (m *MemoryCheckpointStore) Load(_ context.Context, checkpointID string) (*s
 		return nil, fmt.Errorf("checkpoint not found: %s", checkpointID)
 	}
 
-	return checkpoint, nil
+	return m.output(checkpoint)
 }
 
 // List implements CheckpointStore interface
@@ -83,7 +148,7 @@ func (m *MemoryCheckpointStore) List(_ context.Context, executionID string) ([]*
 		return checkpoints[i].Version < checkpoints[j].Version
 	})
 
-	return checkpoints, nil
+	return m.outputAll(checkpoints)
 }
 
 // ListByThread returns all checkpoints for a specific thread_id
@@ -108,7 +173,7 @@ func (m *MemoryCheckpointStore) ListByThread(_ context.Context, threadID string)
 		return checkpoints[i].Version < checkpoints[j].Version
 	})
 
-	return checkpoints, nil
+	return m.outputAll(checkpoints)
 }
 
 // GetLatestByThread returns the latest checkpoint for a thread_id
@@ -129,7 +194,7 @@ func (m *MemoryCheckpointStore) GetLatestByThread(_ context.Context, threadID st
 		}
 	}
 
-	return latest, nil
+	return m.output(latest)
 }
 
 // Delete implements CheckpointStore interface
@@ -166,6 +231,7 @@ func (m *MemoryCheckpointStore) Delete(_ context.Context, checkpointID string) e
 	}
 
 	delete(m.checkpoints, checkpointID)
+	delete(m.states, checkpointID)
 	return nil
 }
 
@@ -217,6 +283,7 @@ func (m *MemoryCheckpointStore) Clear(_ context.Context, executionID string) err
 		}
 
 		delete(m.checkpoints, id)
+		delete(m.states, id)
 	}
 
 	return nil
@@ -3,6 +3,7 @@ package memory
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -495,3 +496,44 @@ func TestMemoryCheckpointStore_ThreadSafety(t *testing.T) {
 		}
 	}
 }
+
+type codecTestState struct {
+	Order string
+	Items []string
+}
+
+func TestMemoryCheckpointStore_Codec(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryCheckpointStoreWithCodec(store.JSONCodec{})
+	s.(store.StateTypeSetter).SetStateType(reflect.TypeFor[codecTestState]())
+
+	state := codecTestState{Order: "A-1", Items: []string{"pen"}}
+	cp := &store.Checkpoint{ID: "cp1", State: state, Metadata: map[string]any{"thread_id": "t1"}}
+	if err := s.Save(ctx, cp); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+
+	// The checkpoint keeps the state as saved
+	state.Items[0] = "pencil"
+
+	loaded, err := s.Load(ctx, "cp1")
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	want := codecTestState{Order: "A-1", Items: []string{"pen"}}
+	if !reflect.DeepEqual(loaded.State, want) {
+		t.Errorf("Loaded state = %#v, want %#v", loaded.State, want)
+	}
+
+	checkpoints, err := s.ListByThread(ctx, "t1")
+	if err != nil || len(checkpoints) != 1 || !reflect.DeepEqual(checkpoints[0].State, want) {
+		t.Errorf("ListByThread = %v, %v", checkpoints, err)
+	}
+
+	if err := s.Delete(ctx, "cp1"); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if _, err := s.Load(ctx, "cp1"); err == nil {
+		t.Error("Expected the deleted checkpoint to be gone")
+	}
+}
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TypedCheckpoint is a Checkpoint whose State is decoded into the concrete state type S
+type TypedCheckpoint[S any] struct {
+	ID        string         `json:"id"`
+	NodeName  string         `json:"node_name"`
+	State     S              `json:"state"`
+	Metadata  map[string]any `json:"metadata"`
+	Timestamp time.Time      `json:"timestamp"`
+	Version   int            `json:"version"`
+}
+
+// NewTypedCheckpoint converts an untyped checkpoint, decoding its State with DecodeState
+func NewTypedCheckpoint[S any](cp *Checkpoint) (*TypedCheckpoint[S], error) {
+	state, err := DecodeState[S](cp.State)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint %s: %w", cp.ID, err)
+	}
+
+	return &TypedCheckpoint[S]{
+		ID:        cp.ID,
+		NodeName:  cp.NodeName,
+		State:     state,
+		Metadata:  cp.Metadata,
+		Timestamp: cp.Timestamp,
+		Version:   cp.Version,
+	}, nil
+}
+
+// DecodeState converts a checkpoint state into S.
+// In-memory stores return the state as saved; persistent stores return the generic
+// JSON form (map[string]any, []any, ...), which is re-decoded into S. States saved with
+// the type registry's wrapper ({"_type", "_value"}) are decoded with the global registry.
+func DecodeState[S any](state any) (S, error) {
+	var zero S
+	if s, ok := state.(S); ok {
+		return s, nil
+	}
+	if state == nil {
+		return zero, nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return zero, fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	if m, ok := state.(map[string]any); ok {
+		if _, typed := m["_type"]; typed {
+			value, err := GlobalTypeRegistry().Unmarshal(data)
+			if err != nil {
+				return zero, err
+			}
+			if s, ok := value.(S); ok {
+				return s, nil
+			}
+			return zero, fmt.Errorf("state of type %T is not %T", value, zero)
+		}
+	}
+
+	var s S
+	if err := json.Unmarshal(data, &s); err != nil {
+		return zero, fmt.Errorf("failed to decode state into %T: %w", zero, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedCheckpointState struct {
+	Name  string   `json:"name"`
+	Steps []string `json:"steps"`
+}
+
+func TestDecodeState(t *testing.T) {
+	state := typedCheckpointState{Name: "order", Steps: []string{"a", "b"}}
+
+	t.Run("in-memory state", func(t *testing.T) {
+		decoded, err := DecodeState[typedCheckpointState](state)
+		require.NoError(t, err)
+		assert.Equal(t, state, decoded)
+	})
+
+	t.Run("JSON round-tripped state", func(t *testing.T) {
+		var generic any
+		data, _ := json.Marshal(state)
+		require.NoError(t, json.Unmarshal(data, &generic))
+
+		decoded, err := DecodeState[typedCheckpointState](generic)
+		require.NoError(t, err)
+		assert.Equal(t, state, decoded)
+	})
+
+	t.Run("type registry wrapper", func(t *testing.T) {
+		require.NoError(t, RegisterTypeWithValue(state, "typedCheckpointState"))
+		data, err := GlobalTypeRegistry().Marshal(state)
+		require.NoError(t, err)
+		var generic any
+		require.NoError(t, json.Unmarshal(data, &generic))
+
+		decoded, err := DecodeState[typedCheckpointState](generic)
+		require.NoError(t, err)
+		assert.Equal(t, state, decoded)
+
+		_, err = DecodeState[TestState](generic)
+		assert.Error(t, err)
+	})
+
+	t.Run("mismatched state", func(t *testing.T) {
+		_, err := DecodeState[typedCheckpointState]("not a struct")
+		assert.Error(t, err)
+	})
+}
+
+func TestNewTypedCheckpoint(t *testing.T) {
+	cp := &Checkpoint{
+		ID:       "cp-1",
+		NodeName: "node",
+		State:    map[string]any{"name": "order", "steps": []any{"a"}},
+		Version:  2,
+	}
+
+	typed, err := NewTypedCheckpoint[typedCheckpointState](cp)
+	require.NoError(t, err)
+	assert.Equal(t, "cp-1", typed.ID)
+	assert.Equal(t, 2, typed.Version)
+	assert.Equal(t, typedCheckpointState{Name: "order", Steps: []string{"a"}}, typed.State)
+}